@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -15,6 +18,43 @@ type Config struct {
 	DBUser     string
 	DBPassword string
 	DBName     string
+
+	JWTSecret     string
+	JWTAccessTTL  time.Duration
+	JWTRefreshTTL time.Duration
+
+	GeofenceRadiusMeters float64 // Maximum distance from the client's home for a valid clock-in/out
+	GeofencePolicy       string  // "flag" records out-of-geofence visits, "reject" refuses them
+
+	LateClockInGrace     time.Duration // Clock-ins later than this after the shift start raise an exception
+	MissingClockOutAfter time.Duration // Visits in progress for longer than this raise an exception
+	ClockSkewTolerance   time.Duration // Clock-ins/outs whose device time differs more from the server time are flagged
+	OfflineEventMaxAge   time.Duration // Offline clock-ins/outs synced longer than this after their device time are flagged
+	UnresolvedTaskPolicy string        // "reject" refuses clock-out while tasks are open, "mark_not_completed" closes them
+
+	MissedVisitGrace time.Duration // Upcoming visits not started this long after the shift start are marked missed
+	SweeperInterval  time.Duration // How often the background sweeper runs
+
+	SeriesHorizon time.Duration // How far ahead recurring series are expanded into schedules
+
+	IdempotencyKeyTTL  time.Duration // How long a retried request with the same Idempotency-Key gets the stored response
+	IdempotencyLockTTL time.Duration // How long a request with an Idempotency-Key may run before a retry runs it again
+
+	VisitNoteEditWindow time.Duration // How long after writing it the author may still edit a visit note
+
+	AttachmentStorage      string        // "local" keeps uploads in AttachmentStorageDir, "s3" in an S3-compatible bucket
+	AttachmentStorageDir   string        // Directory for uploads with the local storage
+	AttachmentMaxBytes     int64         // Largest file accepted as an attachment
+	AttachmentAllowedTypes []string      // Content types accepted as attachments
+	AttachmentURLTTL       time.Duration // How long a signed download link stays valid
+	AttachmentURLSecret    string        // Key for signing download links, kept apart from the JWT secret
+
+	S3Endpoint        string // e.g. "https://s3.eu-central-1.amazonaws.com" or "http://minio:9000"
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3ForcePathStyle  bool // Needed for MinIO and most other S3-compatible servers
 }
 
 // LoadConfig loads configuration from environment variables
@@ -32,6 +72,43 @@ func LoadConfig() *Config {
 		DBUser:     getEnv("DB_USER", ""),
 		DBPassword: getEnv("DB_PASSWORD", ""),
 		DBName:     getEnv("DB_NAME", ""),
+
+		JWTSecret:     getEnv("JWT_SECRET", ""),
+		JWTAccessTTL:  getEnvDuration("JWT_ACCESS_TTL", 15*time.Minute),
+		JWTRefreshTTL: getEnvDuration("JWT_REFRESH_TTL", 7*24*time.Hour),
+
+		GeofenceRadiusMeters: getEnvFloat("GEOFENCE_RADIUS_METERS", 150),
+		GeofencePolicy:       getEnv("GEOFENCE_POLICY", "flag"),
+
+		LateClockInGrace:     getEnvDuration("LATE_CLOCK_IN_GRACE", 15*time.Minute),
+		MissingClockOutAfter: getEnvDuration("MISSING_CLOCK_OUT_AFTER", 12*time.Hour),
+		ClockSkewTolerance:   getEnvDuration("CLOCK_SKEW_TOLERANCE", 2*time.Minute),
+		OfflineEventMaxAge:   getEnvDuration("OFFLINE_EVENT_MAX_AGE", 24*time.Hour),
+		UnresolvedTaskPolicy: getEnv("UNRESOLVED_TASK_POLICY", "reject"),
+
+		MissedVisitGrace: getEnvDuration("MISSED_VISIT_GRACE", time.Hour),
+		SweeperInterval:  getEnvDuration("SWEEPER_INTERVAL", 5*time.Minute),
+
+		SeriesHorizon: getEnvDuration("SERIES_HORIZON", 28*24*time.Hour),
+
+		IdempotencyKeyTTL:  getEnvDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+		IdempotencyLockTTL: getEnvDuration("IDEMPOTENCY_LOCK_TTL", time.Minute),
+
+		VisitNoteEditWindow: getEnvDuration("VISIT_NOTE_EDIT_WINDOW", time.Hour),
+
+		AttachmentStorage:      getEnv("ATTACHMENT_STORAGE", "local"),
+		AttachmentStorageDir:   getEnv("ATTACHMENT_STORAGE_DIR", "./data/attachments"),
+		AttachmentMaxBytes:     getEnvInt64("ATTACHMENT_MAX_BYTES", 10*1024*1024),
+		AttachmentAllowedTypes: getEnvList("ATTACHMENT_ALLOWED_TYPES", []string{"image/jpeg", "image/png", "image/webp", "application/pdf"}),
+		AttachmentURLTTL:       getEnvDuration("ATTACHMENT_URL_TTL", 5*time.Minute),
+		AttachmentURLSecret:    getEnv("ATTACHMENT_URL_SECRET", ""),
+
+		S3Endpoint:        getEnv("S3_ENDPOINT", ""),
+		S3Region:          getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:          getEnv("S3_BUCKET", ""),
+		S3AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+		S3SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+		S3ForcePathStyle:  getEnvBool("S3_FORCE_PATH_STYLE", false),
 	}
 }
 
@@ -42,3 +119,74 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getEnvDuration parses a duration (e.g. "15m", "168h") from the environment or uses fallback
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		fmt.Printf("Invalid duration %q for %s: %v. Using default %s.\n", value, key, err, fallback)
+		return fallback
+	}
+	return d
+}
+
+// getEnvFloat parses a float from the environment or uses fallback
+func getEnvFloat(key string, fallback float64) float64 {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		fmt.Printf("Invalid number %q for %s: %v. Using default %v.\n", value, key, err, fallback)
+		return fallback
+	}
+	return f
+}
+
+// getEnvInt64 parses an integer from the environment or uses fallback
+func getEnvInt64(key string, fallback int64) int64 {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		fmt.Printf("Invalid integer %q for %s: %v. Using default %d.\n", value, key, err, fallback)
+		return fallback
+	}
+	return n
+}
+
+// getEnvBool parses a boolean (e.g. "true", "1") from the environment or uses fallback
+func getEnvBool(key string, fallback bool) bool {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		fmt.Printf("Invalid boolean %q for %s: %v. Using default %t.\n", value, key, err, fallback)
+		return fallback
+	}
+	return b
+}
+
+// getEnvList splits a comma-separated list from the environment or uses fallback
+func getEnvList(key string, fallback []string) []string {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
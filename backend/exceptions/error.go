@@ -14,6 +14,7 @@ type CustomError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 	Details string `json:"details,omitempty"`
+	Data    any    `json:"data,omitempty"` // Machine-readable context, e.g. the statuses of a refused transition
 }
 
 // Error implements the error interface for CustomError
@@ -32,6 +33,13 @@ func (e *CustomError) WithDetails(details string) *CustomError {
 	return &newErr
 }
 
+// WithData attaches machine-readable context to an existing CustomError
+func (e *CustomError) WithData(data any) *CustomError {
+	newErr := *e
+	newErr.Data = data
+	return &newErr
+}
+
 // NewCustomError creates a new CustomError instance
 func NewCustomError(code int, message string, details ...string) *CustomError {
 	ce := &CustomError{
@@ -80,5 +88,9 @@ var (
 	ErrUnauthorized        = NewCustomError(http.StatusUnauthorized, "Unauthorized")
 	ErrForbidden           = NewCustomError(http.StatusForbidden, "Forbidden")
 	ErrConflict            = NewCustomError(http.StatusConflict, "Conflict")
+	ErrPreconditionFailed  = NewCustomError(http.StatusPreconditionFailed, "Precondition failed")
 	ErrUnprocessableEntity = NewCustomError(http.StatusUnprocessableEntity, "Unprocessable Entity")
+
+	ErrRequestEntityTooLarge = NewCustomError(http.StatusRequestEntityTooLarge, "Request entity too large")
+	ErrUnsupportedMediaType  = NewCustomError(http.StatusUnsupportedMediaType, "Unsupported media type")
 )
@@ -0,0 +1,74 @@
+package jobs
+
+import (
+	"context" // Import context
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// VisitSweeper is the part of the schedule service the sweeper drives
+type VisitSweeper interface {
+	MarkOverdueSchedulesMissed(ctx context.Context) (int, error)
+	RaiseMissingClockOuts(ctx context.Context) (int64, error)
+}
+
+// SeriesGenerator is the part of the series service the sweeper drives
+type SeriesGenerator interface {
+	GenerateOccurrences(ctx context.Context) (int, error)
+}
+
+// KeyPurger is the part of the idempotency service the sweeper drives
+type KeyPurger interface {
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+// Sweeper periodically closes out visits that nobody acted on: upcoming schedules
+// past their grace period become "missed" and forgotten clock-outs raise exceptions.
+// It also keeps recurring series expanded up to their horizon and drops expired idempotency keys.
+type Sweeper struct {
+	svc       VisitSweeper
+	generator SeriesGenerator
+	purger    KeyPurger
+	interval  time.Duration
+}
+
+// NewSweeper creates a new Sweeper that runs every interval
+func NewSweeper(svc VisitSweeper, generator SeriesGenerator, purger KeyPurger, interval time.Duration) *Sweeper {
+	return &Sweeper{svc: svc, generator: generator, purger: purger, interval: interval}
+}
+
+// Run sweeps once immediately and then on every tick until ctx is cancelled
+func (s *Sweeper) Run(ctx context.Context) {
+	log.Info().Dur("interval", s.interval).Msg("Starting schedule sweeper")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.Sweep(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Stopping schedule sweeper")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep runs a single pass. Errors are logged and retried on the next pass.
+func (s *Sweeper) Sweep(ctx context.Context) {
+	if _, err := s.svc.MarkOverdueSchedulesMissed(ctx); err != nil {
+		log.Error().Err(err).Msg("Sweeper failed to mark overdue schedules as missed")
+	}
+	if _, err := s.svc.RaiseMissingClockOuts(ctx); err != nil {
+		log.Error().Err(err).Msg("Sweeper failed to raise missing clock-out exceptions")
+	}
+	if _, err := s.generator.GenerateOccurrences(ctx); err != nil {
+		log.Error().Err(err).Msg("Sweeper failed to generate schedules from series")
+	}
+	if _, err := s.purger.PurgeExpired(ctx); err != nil {
+		log.Error().Err(err).Msg("Sweeper failed to purge expired idempotency keys")
+	}
+}
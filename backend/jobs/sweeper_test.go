@@ -0,0 +1,94 @@
+package jobs_test
+
+import (
+	"context"
+	"mini-evv-logger-backend/jobs"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeVisitSweeper counts calls and can be made to fail
+type fakeVisitSweeper struct {
+	missedCalls   atomic.Int32
+	clockOutCalls atomic.Int32
+	err           error
+}
+
+func (f *fakeVisitSweeper) MarkOverdueSchedulesMissed(ctx context.Context) (int, error) {
+	f.missedCalls.Add(1)
+	return 0, f.err
+}
+
+func (f *fakeVisitSweeper) RaiseMissingClockOuts(ctx context.Context) (int64, error) {
+	f.clockOutCalls.Add(1)
+	return 0, f.err
+}
+
+// fakeSeriesGenerator counts calls and can be made to fail
+type fakeSeriesGenerator struct {
+	calls atomic.Int32
+	err   error
+}
+
+func (f *fakeSeriesGenerator) GenerateOccurrences(ctx context.Context) (int, error) {
+	f.calls.Add(1)
+	return 0, f.err
+}
+
+// fakeKeyPurger counts calls and can be made to fail
+type fakeKeyPurger struct {
+	calls atomic.Int32
+	err   error
+}
+
+func (f *fakeKeyPurger) PurgeExpired(ctx context.Context) (int64, error) {
+	f.calls.Add(1)
+	return 0, f.err
+}
+
+func TestSweep(t *testing.T) {
+	t.Run("TestSweep: OK", func(t *testing.T) {
+		fake, generator, purger := &fakeVisitSweeper{}, &fakeSeriesGenerator{}, &fakeKeyPurger{}
+		jobs.NewSweeper(fake, generator, purger, time.Minute).Sweep(context.Background())
+
+		assert.Equal(t, int32(1), fake.missedCalls.Load())
+		assert.Equal(t, int32(1), fake.clockOutCalls.Load())
+		assert.Equal(t, int32(1), generator.calls.Load())
+		assert.Equal(t, int32(1), purger.calls.Load())
+	})
+
+	t.Run("TestSweep: Errors Do Not Stop The Pass", func(t *testing.T) {
+		fake, generator, purger := &fakeVisitSweeper{err: assert.AnError}, &fakeSeriesGenerator{err: assert.AnError}, &fakeKeyPurger{err: assert.AnError}
+		jobs.NewSweeper(fake, generator, purger, time.Minute).Sweep(context.Background())
+
+		assert.Equal(t, int32(1), fake.missedCalls.Load())
+		assert.Equal(t, int32(1), fake.clockOutCalls.Load())
+		assert.Equal(t, int32(1), generator.calls.Load())
+		assert.Equal(t, int32(1), purger.calls.Load())
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Run("TestRun: Sweeps Until Cancelled", func(t *testing.T) {
+		fake := &fakeVisitSweeper{}
+		ctx, cancel := context.WithCancel(context.Background())
+
+		done := make(chan struct{})
+		go func() {
+			jobs.NewSweeper(fake, &fakeSeriesGenerator{}, &fakeKeyPurger{}, 10*time.Millisecond).Run(ctx)
+			close(done)
+		}()
+
+		assert.Eventually(t, func() bool { return fake.missedCalls.Load() >= 3 }, time.Second, 5*time.Millisecond)
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("sweeper did not stop after cancellation")
+		}
+	})
+}
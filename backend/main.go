@@ -1,17 +1,53 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"mini-evv-logger-backend/config"
+	"mini-evv-logger-backend/jobs"
+	"mini-evv-logger-backend/middleware"
+	attachmentController "mini-evv-logger-backend/src/domains/attachment/controller"
+	attachmentRepo "mini-evv-logger-backend/src/domains/attachment/repository"
+	attachmentService "mini-evv-logger-backend/src/domains/attachment/service"
+	auditRepo "mini-evv-logger-backend/src/domains/audit/repository"
+	authController "mini-evv-logger-backend/src/domains/auth/controller"
+	authRepo "mini-evv-logger-backend/src/domains/auth/repository"
+	authService "mini-evv-logger-backend/src/domains/auth/service"
+	caregiverController "mini-evv-logger-backend/src/domains/caregiver/controller"
+	caregiverRepo "mini-evv-logger-backend/src/domains/caregiver/repository"
+	caregiverService "mini-evv-logger-backend/src/domains/caregiver/service"
+	carePlanController "mini-evv-logger-backend/src/domains/careplan/controller"
+	carePlanRepo "mini-evv-logger-backend/src/domains/careplan/repository"
+	carePlanService "mini-evv-logger-backend/src/domains/careplan/service"
+	clientController "mini-evv-logger-backend/src/domains/client/controller"
+	clientRepo "mini-evv-logger-backend/src/domains/client/repository"
+	clientService "mini-evv-logger-backend/src/domains/client/service"
+	idempotencyRepo "mini-evv-logger-backend/src/domains/idempotency/repository"
+	idempotencyService "mini-evv-logger-backend/src/domains/idempotency/service"
 	"mini-evv-logger-backend/src/domains/schedule/controller"
 	scheduleRepo "mini-evv-logger-backend/src/domains/schedule/repository"
 	scheduleService "mini-evv-logger-backend/src/domains/schedule/service"
+	seriesController "mini-evv-logger-backend/src/domains/series/controller"
+	seriesRepo "mini-evv-logger-backend/src/domains/series/repository"
+	seriesService "mini-evv-logger-backend/src/domains/series/service"
+	syncController "mini-evv-logger-backend/src/domains/sync/controller"
+	syncRepo "mini-evv-logger-backend/src/domains/sync/repository"
+	syncService "mini-evv-logger-backend/src/domains/sync/service"
 	taskController "mini-evv-logger-backend/src/domains/task/controller"
 	taskRepo "mini-evv-logger-backend/src/domains/task/repository"
 	taskService "mini-evv-logger-backend/src/domains/task/service"
+	visitExceptionController "mini-evv-logger-backend/src/domains/visitexception/controller"
+	visitExceptionRepo "mini-evv-logger-backend/src/domains/visitexception/repository"
+	visitExceptionService "mini-evv-logger-backend/src/domains/visitexception/service"
+	visitNoteController "mini-evv-logger-backend/src/domains/visitnote/controller"
+	visitNoteRepo "mini-evv-logger-backend/src/domains/visitnote/repository"
+	visitNoteService "mini-evv-logger-backend/src/domains/visitnote/service"
+	"mini-evv-logger-backend/storage"
 	"mini-evv-logger-backend/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -37,6 +73,22 @@ func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
 
+	if cfg.JWTSecret == "" {
+		mainLogger.Fatal().Msg("JWT_SECRET must be set")
+	}
+	if cfg.AttachmentURLSecret == "" {
+		mainLogger.Fatal().Msg("ATTACHMENT_URL_SECRET must be set")
+	}
+
+	geofencePolicy := scheduleService.GeofencePolicy(cfg.GeofencePolicy)
+	if geofencePolicy != scheduleService.GeofencePolicyFlag && geofencePolicy != scheduleService.GeofencePolicyReject {
+		mainLogger.Fatal().Str("policy", cfg.GeofencePolicy).Msg("GEOFENCE_POLICY must be either flag or reject")
+	}
+	unresolvedTaskPolicy := scheduleService.UnresolvedTaskPolicy(cfg.UnresolvedTaskPolicy)
+	if unresolvedTaskPolicy != scheduleService.UnresolvedTaskPolicyReject && unresolvedTaskPolicy != scheduleService.UnresolvedTaskPolicyMarkNotCompleted {
+		mainLogger.Fatal().Str("policy", cfg.UnresolvedTaskPolicy).Msg("UNRESOLVED_TASK_POLICY must be either reject or mark_not_completed")
+	}
+
 	// Connect to PostgreSQL
 	db, err := config.InitDB(cfg, mainLogger)
 	if err != nil {
@@ -46,21 +98,101 @@ func main() {
 
 	mainLogger.Info().Msg("Successfully connected to PostgreSQL database")
 
+	// Choose where the content of uploaded attachments is kept
+	var attachmentStorage storage.Storage
+	switch cfg.AttachmentStorage {
+	case "local":
+		attachmentStorage, err = storage.NewLocalStorage(cfg.AttachmentStorageDir)
+	case "s3":
+		attachmentStorage, err = storage.NewS3Storage(storage.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			ForcePathStyle:  cfg.S3ForcePathStyle,
+		})
+	default:
+		mainLogger.Fatal().Str("storage", cfg.AttachmentStorage).Msg("ATTACHMENT_STORAGE must be either local or s3")
+	}
+	if err != nil {
+		mainLogger.Fatal().Err(err).Msg("Failed to initialize attachment storage")
+	}
+
 	// Initialize Repositories (now returning interfaces)
 	scheduleRepository := scheduleRepo.NewScheduleRepository(db, mainLogger)
 	taskRepository := taskRepo.NewTaskRepository(db, mainLogger)
+	caregiverRepository := caregiverRepo.NewCaregiverRepository(db, mainLogger)
+	clientRepository := clientRepo.NewClientRepository(db, mainLogger)
+	visitExceptionRepository := visitExceptionRepo.NewVisitExceptionRepository(db, mainLogger)
+	seriesRepository := seriesRepo.NewSeriesRepository(db, mainLogger)
+	carePlanRepository := carePlanRepo.NewCarePlanRepository(db, mainLogger)
+	userRepository := authRepo.NewUserRepository(db, mainLogger)
+	idempotencyRepository := idempotencyRepo.NewIdempotencyRepository(db, mainLogger)
+	syncRepository := syncRepo.NewSyncRepository(db, mainLogger)
+	auditRepository := auditRepo.NewAuditRepository(db, mainLogger)
+	visitNoteRepository := visitNoteRepo.NewVisitNoteRepository(db, mainLogger)
+	attachmentRepository := attachmentRepo.NewAttachmentRepository(db, mainLogger)
 
 	// Initialize Services (now returning interfaces)
 	// Now injecting taskRepository directly into NewScheduleService
-	scheduleSvc := scheduleService.NewScheduleService(scheduleRepository, taskRepository)
-	taskSvc := taskService.NewTaskService(taskRepository)
+	scheduleSvc := scheduleService.NewScheduleService(scheduleRepository, taskRepository, visitExceptionRepository, caregiverRepository, clientRepository, carePlanRepository, auditRepository, visitNoteRepository, scheduleService.Settings{
+		GeofenceRadiusMeters: cfg.GeofenceRadiusMeters,
+		GeofencePolicy:       geofencePolicy,
+		LateClockInGrace:     cfg.LateClockInGrace,
+		MissingClockOutAfter: cfg.MissingClockOutAfter,
+		MissedVisitGrace:     cfg.MissedVisitGrace,
+		ClockSkewTolerance:   cfg.ClockSkewTolerance,
+		OfflineEventMaxAge:   cfg.OfflineEventMaxAge,
+		UnresolvedTaskPolicy: unresolvedTaskPolicy,
+	})
+	taskSvc := taskService.NewTaskService(taskRepository, scheduleRepository)
+	caregiverSvc := caregiverService.NewCaregiverService(caregiverRepository)
+	clientSvc := clientService.NewClientService(clientRepository)
+	visitExceptionSvc := visitExceptionService.NewVisitExceptionService(visitExceptionRepository)
+	seriesSvc := seriesService.NewSeriesService(seriesRepository, caregiverRepository, clientRepository, carePlanRepository, seriesService.Settings{
+		Horizon: cfg.SeriesHorizon,
+	})
+	carePlanSvc := carePlanService.NewCarePlanService(carePlanRepository, clientRepository)
+	authSvc := authService.NewAuthService(userRepository, cfg.JWTSecret, cfg.JWTAccessTTL, cfg.JWTRefreshTTL)
+	syncSvc := syncService.NewSyncService(syncRepository, scheduleSvc, taskSvc)
+	idempotencySvc := idempotencyService.NewIdempotencyService(idempotencyRepository, idempotencyService.Settings{
+		TTL:     cfg.IdempotencyKeyTTL,
+		LockTTL: cfg.IdempotencyLockTTL,
+	})
+	visitNoteSvc := visitNoteService.NewVisitNoteService(visitNoteRepository, scheduleRepository, visitNoteService.Settings{
+		EditWindow: cfg.VisitNoteEditWindow,
+	})
+	attachmentSvc := attachmentService.NewAttachmentService(attachmentRepository, scheduleRepository, taskRepository, attachmentStorage, attachmentService.Settings{
+		MaxBytes:     cfg.AttachmentMaxBytes,
+		AllowedTypes: cfg.AttachmentAllowedTypes,
+		URLSecret:    cfg.AttachmentURLSecret,
+		URLTTL:       cfg.AttachmentURLTTL,
+		URLPrefix:    "/api/attachments",
+	})
+
+	// Retried clock-in, clock-out and task status requests get the original response back
+	idempotent := middleware.Idempotent(idempotencySvc)
 
 	// Initialize Controllers (now injecting service interfaces)
-	scheduleCtrl := controller.NewScheduleController(scheduleSvc)
-	taskCtrl := taskController.NewTaskController(taskSvc)
+	scheduleCtrl := controller.NewScheduleController(scheduleSvc, idempotent)
+	taskCtrl := taskController.NewTaskController(taskSvc, idempotent)
+	caregiverCtrl := caregiverController.NewCaregiverController(caregiverSvc)
+	clientCtrl := clientController.NewClientController(clientSvc)
+	visitExceptionCtrl := visitExceptionController.NewVisitExceptionController(visitExceptionSvc)
+	seriesCtrl := seriesController.NewSeriesController(seriesSvc)
+	carePlanCtrl := carePlanController.NewCarePlanController(carePlanSvc)
+	authCtrl := authController.NewAuthController(authSvc)
+	syncCtrl := syncController.NewSyncController(syncSvc)
+	visitNoteCtrl := visitNoteController.NewVisitNoteController(visitNoteSvc)
+	attachmentCtrl := attachmentController.NewAttachmentController(attachmentSvc)
 
 	// Initialize Fiber app
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		BodyLimit:                    fiber.DefaultBodyLimit,
+		StreamRequestBody:            true, // Larger bodies are streamed so middleware.BodyLimit can allow them per route
+		DisablePreParseMultipartForm: true, // Uploads are parsed by their handler, after the body limit was checked
+	})
 
 	// Add Fiber Logger middleware
 	app.Use(logger.New(logger.Config{
@@ -72,9 +204,10 @@ func main() {
 
 	// Apply CORS middleware to allow cross-origin requests
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",                                           // Allows all origins, you can restrict this to specific origins (e.g., "http://localhost:3000")
-		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",                 // Allowed HTTP methods
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization", // Allowed headers
+		AllowOrigins:  "*",                                                                      // Allows all origins, you can restrict this to specific origins (e.g., "http://localhost:3000")
+		AllowMethods:  "GET,POST,PUT,PATCH,DELETE,OPTIONS",                                      // Allowed HTTP methods
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, If-Match, Idempotency-Key", // Allowed headers
+		ExposeHeaders: "ETag, Idempotent-Replayed",                                              // Row version for optimistic concurrency, replayed retries
 	}))
 
 	// Basic root route
@@ -88,9 +221,47 @@ func main() {
 	// Define API group
 	api := app.Group("/api")
 
+	// Record the client's IP address and user agent for the audit trail
+	api.Use(middleware.RequestInfo())
+
+	// Only attachment uploads may exceed the default body limit, by room for the multipart framing
+	api.Use(middleware.BodyLimit(fiber.DefaultBodyLimit, map[string]int{
+		"POST /api/schedules/:id/attachments": int(cfg.AttachmentMaxBytes + 1024*1024),
+	}))
+
+	// Public routes must be registered before the auth middleware
+	authCtrl.Routes(api)
+	attachmentCtrl.PublicRoutes(api)
+
+	// Every route registered below requires a valid access token
+	api.Use(middleware.RequireAuth(authSvc))
+
 	// Register routes using controller methods
 	scheduleCtrl.Routes(api)
 	taskCtrl.Routes(api)
+	caregiverCtrl.Routes(api)
+	clientCtrl.Routes(api)
+	visitExceptionCtrl.Routes(api)
+	seriesCtrl.Routes(api)
+	carePlanCtrl.Routes(api)
+	syncCtrl.Routes(api)
+	visitNoteCtrl.Routes(api)
+	attachmentCtrl.Routes(api)
+
+	// Stop the background jobs and the server on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Start the background sweeper that closes out stale visits, expands recurring series and purges expired idempotency keys
+	go jobs.NewSweeper(scheduleSvc, seriesSvc, idempotencySvc, cfg.SweeperInterval).Run(ctx)
+
+	go func() {
+		<-ctx.Done()
+		mainLogger.Info().Msg("Shutting down server")
+		if err := app.Shutdown(); err != nil {
+			mainLogger.Error().Err(err).Msg("Failed to shut down server")
+		}
+	}()
 
 	// Start the server
 	port := os.Getenv("PORT")
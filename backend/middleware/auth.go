@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"mini-evv-logger-backend/exceptions"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	authService "mini-evv-logger-backend/src/domains/auth/service"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireAuth rejects requests without a valid bearer access token and stores
+// the authenticated principal in the request's user context.
+func RequireAuth(svc authService.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			return exceptions.HandleError(c, exceptions.ErrUnauthorized.WithDetails("Missing or malformed Authorization header"))
+		}
+
+		principal, err := svc.Authenticate(c.UserContext(), strings.TrimSpace(token))
+		if err != nil {
+			return exceptions.HandleError(c, err)
+		}
+
+		c.SetUserContext(authModel.WithPrincipal(c.UserContext(), principal))
+		return c.Next()
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BodyLimit refuses requests whose body is larger than limit with 413 Request Entity Too Large.
+// The routes in larger, keyed by method and path such as "POST /api/schedules/:id/attachments",
+// accept bodies up to their own limit instead.
+//
+// The app must run with StreamRequestBody, so that bodies above its BodyLimit are streamed rather
+// than read before this check, and with DisablePreParseMultipartForm, so that uploads are not parsed
+// before it either. Streamed bodies without a Content-Length cannot be checked up front and are refused.
+func BodyLimit(limit int, larger map[string]int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		maxBytes := limit
+		for route, routeLimit := range larger {
+			if matchRoute(route, c.Method(), c.Path()) {
+				maxBytes = routeLimit
+				break
+			}
+		}
+
+		// The refused body is left unread, so the connection cannot be reused for another request
+		length := c.Request().Header.ContentLength()
+		if length > maxBytes {
+			c.Context().SetConnectionClose()
+			return exceptions.HandleError(c, exceptions.ErrRequestEntityTooLarge.WithDetails(fmt.Sprintf("Request body must be at most %d bytes", maxBytes)))
+		}
+		if length < 0 && c.Request().IsBodyStream() {
+			c.Context().SetConnectionClose()
+			return exceptions.HandleError(c, exceptions.ErrRequestEntityTooLarge.WithDetails("Request body must be sent with a Content-Length"))
+		}
+		return c.Next()
+	}
+}
+
+// matchRoute reports whether a request matches a route given as method and path,
+// where path segments starting with ":" match any single segment
+func matchRoute(route, method, path string) bool {
+	routeMethod, routePath, _ := strings.Cut(route, " ")
+	if routeMethod != method {
+		return false
+	}
+
+	routeSegments := strings.Split(strings.Trim(routePath, "/"), "/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(routeSegments) != len(segments) {
+		return false
+	}
+	for i, segment := range routeSegments {
+		if segments[i] == "" || (!strings.HasPrefix(segment, ":") && segment != segments[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"mini-evv-logger-backend/exceptions"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	idempotencyModel "mini-evv-logger-backend/src/domains/idempotency/model"
+	idempotencyService "mini-evv-logger-backend/src/domains/idempotency/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// HeaderIdempotencyKey is the request header a client sets to make a retry safe
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed is set on responses that were replayed from an earlier request
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// Idempotent makes a route safe to retry. The first request with a given Idempotency-Key
+// runs the handler and its response is stored; retries with the same key and body get the
+// stored response back instead of running the handler again. Requests without the header
+// are passed through. It must run after RequireAuth, as keys are scoped to the user.
+func Idempotent(svc idempotencyService.IdempotencyService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+		if key == "" {
+			return c.Next()
+		}
+
+		ctx := c.UserContext()
+		userID := authModel.ActorID(ctx)
+		stored, err := svc.Begin(ctx, idempotencyModel.BeginRequest{
+			UserID: userID,
+			Key:    key,
+			Method: c.Method(),
+			Path:   c.Path(),
+			Body:   c.Body(),
+		})
+		if err != nil {
+			return exceptions.HandleError(c, err)
+		}
+
+		// Replay the response of the original request
+		if stored != nil {
+			c.Set(HeaderIdempotentReplayed, "true")
+			if stored.ContentType != "" {
+				c.Set(fiber.HeaderContentType, stored.ContentType)
+			}
+			if stored.ETag != "" {
+				c.Set(fiber.HeaderETag, stored.ETag)
+			}
+			return c.Status(stored.StatusCode).Send(stored.Body)
+		}
+
+		// Run the request and store its response for retries
+		err = c.Next()
+		if err != nil {
+			if abandonErr := svc.Abandon(ctx, userID, key); abandonErr != nil {
+				log.Error().Err(abandonErr).Str("key", key).Msg("Failed to release idempotency key after handler error")
+			}
+			return err
+		}
+
+		resp := c.Response()
+		err = svc.Complete(ctx, userID, key, idempotencyModel.Response{
+			StatusCode:  resp.StatusCode(),
+			ContentType: string(resp.Header.ContentType()),
+			ETag:        string(resp.Header.Peek(fiber.HeaderETag)),
+			Body:        append([]byte(nil), resp.Body()...), // The response buffer is reused once the request is done
+		})
+		if err != nil {
+			// The response was produced, so it is still sent; release the key rather than leave it pending until it expires
+			if abandonErr := svc.Abandon(ctx, userID, key); abandonErr != nil {
+				log.Error().Err(abandonErr).Str("key", key).Msg("Failed to release idempotency key after storing the response failed")
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	auditModel "mini-evv-logger-backend/src/domains/audit/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestInfo stores the client's IP address and user agent in the request's user context,
+// so that the audit trail can record where a change was made from.
+func RequestInfo() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.SetUserContext(auditModel.WithRequestInfo(c.UserContext(), auditModel.RequestInfo{
+			IPAddress: c.IP(),
+			UserAgent: c.Get(fiber.HeaderUserAgent),
+		}))
+		return c.Next()
+	}
+}
@@ -1,22 +1,100 @@
--- DDL for schedules table
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp"; -- Required for UUID generation
 
+-- DDL for branches table (agency offices that coordinators manage)
+CREATE TABLE IF NOT EXISTS branches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- DDL for caregivers table
+CREATE TABLE IF NOT EXISTS caregivers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL UNIQUE,
+    phone VARCHAR(50) NULL,
+    branch_id UUID NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_caregiver_branch
+        FOREIGN KEY(branch_id)
+            REFERENCES branches(id)
+            ON DELETE SET NULL
+);
+
+-- DDL for users table (API accounts, optionally linked to a caregiver)
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    email VARCHAR(255) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL, -- bcrypt hash
+    role VARCHAR(50) NOT NULL DEFAULT 'caregiver', -- 'caregiver', 'coordinator' or 'admin'
+    caregiver_id UUID NULL, -- Required for caregivers
+    branch_id UUID NULL, -- Required for coordinators
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user_caregiver
+        FOREIGN KEY(caregiver_id)
+            REFERENCES caregivers(id)
+            ON DELETE CASCADE,
+    CONSTRAINT fk_user_branch
+        FOREIGN KEY(branch_id)
+            REFERENCES branches(id)
+            ON DELETE SET NULL,
+    CONSTRAINT chk_user_role CHECK (role IN ('caregiver', 'coordinator', 'admin'))
+);
+
+-- DDL for clients table (the people receiving care, with a geocoded home address)
+CREATE TABLE IF NOT EXISTS clients (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    address_line1 VARCHAR(255) NOT NULL,
+    address_line2 VARCHAR(255) NULL,
+    city VARCHAR(100) NOT NULL,
+    state VARCHAR(50) NOT NULL,
+    postal_code VARCHAR(20) NULL,
+    latitude NUMERIC(10, 8) NULL, -- Home location used to verify visits
+    longitude NUMERIC(11, 8) NULL,
+    phone VARCHAR(50) NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC', -- IANA name, e.g. 'America/Chicago'
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- DDL for schedules table
 CREATE TABLE IF NOT EXISTS schedules (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    caregiver_id UUID NULL, -- Caregiver assigned to the visit, NULL when unassigned
     client_name VARCHAR(255) NOT NULL,
     shift_time TIMESTAMPTZ NOT NULL,
     location VARCHAR(255) NOT NULL, -- General location string, e.g., "123 Main St, Anytown"
-    status VARCHAR(50) NOT NULL DEFAULT 'upcoming', -- e.g., 'upcoming', 'in-progress', 'completed', 'missed'
+    status VARCHAR(50) NOT NULL DEFAULT 'upcoming', -- e.g., 'upcoming', 'in-progress', 'completed', 'missed', 'cancelled'
+    status_reason TEXT NULL, -- Why the status was last changed outside a clock-in/out, e.g. by the sweeper
+    status_changed_at TIMESTAMPTZ NULL,
     start_time TIMESTAMPTZ NULL,
     start_latitude NUMERIC(10, 8) NULL,
     start_longitude NUMERIC(11, 8) NULL,
+    start_distance_meters NUMERIC(12, 2) NULL, -- Distance from the client's home at clock-in
+    start_out_of_geofence BOOLEAN NOT NULL DEFAULT FALSE,
     end_time TIMESTAMPTZ NULL,
     end_latitude NUMERIC(10, 8) NULL,
     end_longitude NUMERIC(11, 8) NULL,
+    end_distance_meters NUMERIC(12, 2) NULL, -- Distance from the client's home at clock-out
+    end_out_of_geofence BOOLEAN NOT NULL DEFAULT FALSE,
     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_caregiver
+        FOREIGN KEY(caregiver_id)
+            REFERENCES caregivers(id)
+            ON DELETE SET NULL
 );
 
+-- Index for faster lookup of a caregiver's schedules
+CREATE INDEX IF NOT EXISTS idx_schedules_caregiver_id ON schedules (caregiver_id);
+
+-- Index for the sweeper's lookup of overdue visits
+CREATE INDEX IF NOT EXISTS idx_schedules_status_shift_time ON schedules (status, shift_time);
+
 -- DDL for tasks table
 CREATE TABLE IF NOT EXISTS tasks (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -35,8 +113,62 @@ CREATE TABLE IF NOT EXISTS tasks (
 -- Index for faster lookup by schedule_id in tasks table
 CREATE INDEX IF NOT EXISTS idx_tasks_schedule_id ON tasks (schedule_id);
 
+-- DDL for visit_exceptions table (compliance issues that must be resolved before billing)
+CREATE TABLE IF NOT EXISTS visit_exceptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    schedule_id UUID NOT NULL,
+    type VARCHAR(50) NOT NULL, -- 'late_clock_in', 'clock_in_out_of_geofence', 'clock_out_out_of_geofence', 'missing_clock_out'
+    status VARCHAR(50) NOT NULL DEFAULT 'open', -- 'open', 'submitted', 'approved', 'rejected'
+    details TEXT NULL, -- What triggered the exception
+    reason_code VARCHAR(50) NULL, -- Attached by the caregiver
+    comment TEXT NULL,
+    submitted_by UUID NULL,
+    submitted_at TIMESTAMPTZ NULL,
+    resolved_by UUID NULL, -- Coordinator who approved or rejected
+    resolved_at TIMESTAMPTZ NULL,
+    resolution_note TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_visit_exception_schedule
+        FOREIGN KEY(schedule_id)
+            REFERENCES schedules(id)
+            ON DELETE CASCADE,
+    CONSTRAINT fk_visit_exception_submitted_by
+        FOREIGN KEY(submitted_by)
+            REFERENCES users(id)
+            ON DELETE SET NULL,
+    CONSTRAINT fk_visit_exception_resolved_by
+        FOREIGN KEY(resolved_by)
+            REFERENCES users(id)
+            ON DELETE SET NULL
+);
+
+-- A visit has at most one unresolved exception of each type; a reopened visit can raise it again
+CREATE UNIQUE INDEX IF NOT EXISTS uq_visit_exception_schedule_type_unresolved ON visit_exceptions (schedule_id, type)
+    WHERE status IN ('open', 'submitted');
+
+-- Index for the coordinators' review queue
+CREATE INDEX IF NOT EXISTS idx_visit_exceptions_status ON visit_exceptions (status);
+
 -- Test Data (Optional: You can run these inserts after creating tables)
 
+-- Insert sample branches
+INSERT INTO branches (id, name) VALUES
+('b1eebc99-9c0b-4ef8-bb6d-6bb9bd380d01', 'North Branch'),
+('b2eebc99-9c0b-4ef8-bb6d-6bb9bd380d02', 'South Branch');
+
+-- Insert sample caregivers
+INSERT INTO caregivers (id, name, email, phone, branch_id) VALUES
+('c1eebc99-9c0b-4ef8-bb6d-6bb9bd380b01', 'Louis Carter', 'louis.carter@example.com', '555-0101', 'b1eebc99-9c0b-4ef8-bb6d-6bb9bd380d01'),
+('c2eebc99-9c0b-4ef8-bb6d-6bb9bd380b02', 'Maria Lopez', 'maria.lopez@example.com', '555-0102', 'b2eebc99-9c0b-4ef8-bb6d-6bb9bd380d02');
+
+-- Insert sample users (password for all: "password123")
+INSERT INTO users (id, email, password_hash, role, caregiver_id, branch_id) VALUES
+('d1eebc99-9c0b-4ef8-bb6d-6bb9bd380c01', 'louis.carter@example.com', '$2a$10$PFdodW4rXoa9AIKTv0lNGebdAZwOtYroT9reYeuuxKR68BNJj.vX6', 'caregiver', 'c1eebc99-9c0b-4ef8-bb6d-6bb9bd380b01', NULL),
+('d2eebc99-9c0b-4ef8-bb6d-6bb9bd380c02', 'maria.lopez@example.com', '$2a$10$PFdodW4rXoa9AIKTv0lNGebdAZwOtYroT9reYeuuxKR68BNJj.vX6', 'caregiver', 'c2eebc99-9c0b-4ef8-bb6d-6bb9bd380b02', NULL),
+('d3eebc99-9c0b-4ef8-bb6d-6bb9bd380c03', 'coordinator.north@example.com', '$2a$10$PFdodW4rXoa9AIKTv0lNGebdAZwOtYroT9reYeuuxKR68BNJj.vX6', 'coordinator', NULL, 'b1eebc99-9c0b-4ef8-bb6d-6bb9bd380d01'),
+('d4eebc99-9c0b-4ef8-bb6d-6bb9bd380c04', 'admin@example.com', '$2a$10$PFdodW4rXoa9AIKTv0lNGebdAZwOtYroT9reYeuuxKR68BNJj.vX6', 'admin', NULL, NULL);
+
 -- Insert sample schedules
 INSERT INTO schedules (id, client_name, shift_time, location, status) VALUES
 ('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', 'Alice Johnson', NOW() + INTERVAL '2 hour', '123 Oak Ave, City, ST', 'upcoming'),
@@ -174,4 +306,461 @@ INSERT INTO tasks (id, schedule_id, description, status) VALUES
 
 INSERT INTO tasks (id, schedule_id, description, status, reason) VALUES
 ('01eebc99-9c0b-4ef8-bb6d-6bb9bd380a43', '70eebc99-9c0b-4ef8-bb6d-6bb9bd380a36', 'Pick up prescription', 'completed', NULL), 
-('02eebc99-9c0b-4ef8-bb6d-6bb9bd380a44', '70eebc99-9c0b-4ef8-bb6d-6bb9bd380a36', 'Companionship visit', 'completed', NULL); 
\ No newline at end of file
+('02eebc99-9c0b-4ef8-bb6d-6bb9bd380a44', '70eebc99-9c0b-4ef8-bb6d-6bb9bd380a36', 'Companionship visit', 'completed', NULL);
+
+-- Assign the sample schedules to the sample caregivers
+UPDATE schedules
+SET caregiver_id = 'c1eebc99-9c0b-4ef8-bb6d-6bb9bd380b01'
+WHERE id IN (
+    'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11',
+    'b0eebc99-9c0b-4ef8-bb6d-6bb9bd380a12',
+    'c0eebc99-9c0b-4ef8-bb6d-6bb9bd380a13',
+    'd0eebc99-9c0b-4ef8-bb6d-6bb9bd380a14',
+    'e0eebc99-9c0b-4ef8-bb6d-6bb9bd380a21',
+    'f0eebc99-9c0b-4ef8-bb6d-6bb9bd380a22',
+    '11eebc99-9c0b-4ef8-bb6d-6bb9bd380a23',
+    '22eebc99-9c0b-4ef8-bb6d-6bb9bd380a24',
+    '33eebc99-9c0b-4ef8-bb6d-6bb9bd380a25'
+);
+
+UPDATE schedules
+SET caregiver_id = 'c2eebc99-9c0b-4ef8-bb6d-6bb9bd380b02'
+WHERE id IN (
+    '30eebc99-9c0b-4ef8-bb6d-6bb9bd380a32',
+    '40eebc99-9c0b-4ef8-bb6d-6bb9bd380a33',
+    '50eebc99-9c0b-4ef8-bb6d-6bb9bd380a34',
+    '60eebc99-9c0b-4ef8-bb6d-6bb9bd380a35',
+    '70eebc99-9c0b-4ef8-bb6d-6bb9bd380a36'
+);
+
+-- Migration: move the free-text client_name/location columns of schedules into clients
+
+-- Create one client per distinct client on the existing schedules (IDs are derived from the name so reruns are stable)
+INSERT INTO clients (id, name, address_line1, city, state)
+SELECT DISTINCT ON (client_name)
+    uuid_generate_v5(uuid_ns_url(), client_name),
+    client_name,
+    split_part(location, ', ', 1),
+    split_part(location, ', ', 2),
+    split_part(location, ', ', 3)
+FROM schedules
+ORDER BY client_name, created_at
+ON CONFLICT (id) DO NOTHING;
+
+-- Geocode the sample clients
+UPDATE clients c
+SET latitude = g.latitude,
+    longitude = g.longitude,
+    timezone = g.timezone
+FROM (VALUES
+    ('Alice Johnson', 34.0689, -118.4452, 'America/Los_Angeles'),
+    ('Bob Smith', 34.0522, -118.2437, 'America/Los_Angeles'),
+    ('Charlie Brown', 33.99, -118.45, 'America/Los_Angeles'),
+    ('Diana Miller', 34.1478, -118.1445, 'America/Los_Angeles'),
+    ('Eve Geller', 34.0195, -118.4912, 'America/Los_Angeles'),
+    ('Frank White', 34.1808, -118.3090, 'America/Los_Angeles'),
+    ('Grace Lee', 34.0928, -118.3287, 'America/Los_Angeles'),
+    ('Henry Adams', 40.7128, -74.0060, 'America/New_York'),
+    ('Ivy King', 34.0259, -118.7798, 'America/Los_Angeles'),
+    ('Olivia Green', 30.2672, -97.7431, 'America/Chicago'),
+    ('Peter Black', 39.7392, -104.9903, 'America/Denver'),
+    ('Quinn Taylor', 41.8781, -87.6298, 'America/Chicago'),
+    ('Rachel King', 42.3601, -71.0589, 'America/New_York'),
+    ('Sam Clark', 32.7767, -96.7970, 'America/Chicago')
+) AS g(name, latitude, longitude, timezone)
+WHERE c.name = g.name;
+
+-- Point every schedule at its client
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS client_id UUID NULL;
+
+UPDATE schedules s
+SET client_id = c.id
+FROM clients c
+WHERE c.name = s.client_name AND s.client_id IS NULL;
+
+ALTER TABLE schedules ALTER COLUMN client_id SET NOT NULL;
+ALTER TABLE schedules
+    ADD CONSTRAINT fk_schedule_client
+        FOREIGN KEY(client_id)
+            REFERENCES clients(id)
+            ON DELETE RESTRICT;
+
+-- Index for faster lookup of a client's schedules
+CREATE INDEX IF NOT EXISTS idx_schedules_client_id ON schedules (client_id);
+
+-- The client's name and address are now read from clients
+ALTER TABLE schedules DROP COLUMN client_name;
+ALTER TABLE schedules DROP COLUMN location;
+
+-- DDL for schedule_series table (recurring visits that the sweeper expands into schedules)
+CREATE TABLE IF NOT EXISTS schedule_series (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    client_id UUID NOT NULL,
+    caregiver_id UUID NULL, -- Caregiver assigned to every occurrence, NULL when unassigned
+    rrule TEXT NOT NULL, -- iCalendar recurrence rule, e.g. 'FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR'
+    starts_at TIMESTAMPTZ NOT NULL, -- First occurrence; later ones keep its local time in the client's timezone
+    duration_minutes INTEGER NOT NULL,
+    task_template TEXT[] NOT NULL DEFAULT '{}', -- Task descriptions copied onto every occurrence
+    ends_at TIMESTAMPTZ NULL, -- Set when the series is split; later occurrences belong to the new series
+    generated_through TIMESTAMPTZ NULL, -- Occurrences up to this time have been created
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_series_client
+        FOREIGN KEY(client_id)
+            REFERENCES clients(id)
+            ON DELETE RESTRICT,
+    CONSTRAINT fk_series_caregiver
+        FOREIGN KEY(caregiver_id)
+            REFERENCES caregivers(id)
+            ON DELETE SET NULL
+);
+
+-- Link generated schedules back to their series
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS series_id UUID NULL;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS occurrence_time TIMESTAMPTZ NULL; -- Shift time originally generated by the series
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS is_detached BOOLEAN NOT NULL DEFAULT FALSE; -- Edited individually, no longer replaced by series changes
+ALTER TABLE schedules
+    ADD CONSTRAINT fk_schedule_series
+        FOREIGN KEY(series_id)
+            REFERENCES schedule_series(id)
+            ON DELETE SET NULL;
+
+-- Each occurrence is generated at most once, even if it is later cancelled or moved
+ALTER TABLE schedules
+    ADD CONSTRAINT uq_schedule_series_occurrence UNIQUE (series_id, occurrence_time);
+
+-- Tasks are ordered by an explicit position instead of their creation time
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS position INTEGER NOT NULL DEFAULT 0;
+
+UPDATE tasks t
+SET position = o.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY schedule_id ORDER BY created_at, id) AS position
+    FROM tasks
+) o
+WHERE o.id = t.id AND t.position = 0;
+
+-- Index for listing a schedule's tasks in order
+CREATE INDEX IF NOT EXISTS idx_tasks_schedule_id_position ON tasks (schedule_id, position);
+
+-- DDL for care_plans table (versioned list of the tasks a client needs, the highest version is active)
+CREATE TABLE IF NOT EXISTS care_plans (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    client_id UUID NOT NULL,
+    version INTEGER NOT NULL, -- 1 for the first plan of a client
+    created_by UUID NULL, -- User who created this version
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_care_plan_client
+        FOREIGN KEY(client_id)
+            REFERENCES clients(id)
+            ON DELETE CASCADE,
+    CONSTRAINT fk_care_plan_created_by
+        FOREIGN KEY(created_by)
+            REFERENCES users(id)
+            ON DELETE SET NULL,
+    CONSTRAINT uq_care_plan_client_version UNIQUE (client_id, version)
+);
+
+-- DDL for care_plan_tasks table (task templates of a care plan version)
+CREATE TABLE IF NOT EXISTS care_plan_tasks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    care_plan_id UUID NOT NULL,
+    description TEXT NOT NULL,
+    frequency VARCHAR(50) NOT NULL, -- 'every_visit' or 'weekly'
+    days TEXT[] NULL, -- Weekly tasks only, e.g. '{MO,WE,FR}'
+    position INTEGER NOT NULL,
+    CONSTRAINT fk_care_plan_task_plan
+        FOREIGN KEY(care_plan_id)
+            REFERENCES care_plans(id)
+            ON DELETE CASCADE
+);
+
+-- Index for loading a plan's tasks in order
+CREATE INDEX IF NOT EXISTS idx_care_plan_tasks_care_plan_id_position ON care_plan_tasks (care_plan_id, position);
+
+-- Record which care plan version a schedule's initial tasks were filled from, for auditors
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS care_plan_id UUID NULL;
+ALTER TABLE schedules
+    ADD CONSTRAINT fk_schedule_care_plan
+        FOREIGN KEY(care_plan_id)
+            REFERENCES care_plans(id)
+            ON DELETE RESTRICT;
+
+-- Planned end of each visit; existing visits get the duration of their series, or one hour
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS shift_end_time TIMESTAMPTZ NULL;
+
+UPDATE schedules s
+SET shift_end_time = s.shift_time + COALESCE(
+    (SELECT make_interval(mins => ss.duration_minutes) FROM schedule_series ss WHERE ss.id = s.series_id),
+    INTERVAL '1 hour')
+WHERE s.shift_end_time IS NULL;
+
+ALTER TABLE schedules ALTER COLUMN shift_end_time SET NOT NULL;
+ALTER TABLE schedules
+    ADD CONSTRAINT chk_schedule_shift_end_after_start CHECK (shift_end_time > shift_time);
+
+-- Actual length of completed visits and its difference to the plan, for payroll and billing
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS actual_minutes INTEGER NULL;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS variance_minutes INTEGER NULL; -- Negative when the visit ended early
+
+UPDATE schedules
+SET actual_minutes = ROUND(EXTRACT(EPOCH FROM end_time - start_time) / 60),
+    variance_minutes = ROUND(EXTRACT(EPOCH FROM end_time - start_time) / 60) - ROUND(EXTRACT(EPOCH FROM shift_end_time - shift_time) / 60)
+WHERE status = 'completed' AND start_time IS NOT NULL AND end_time IS NOT NULL AND actual_minutes IS NULL;
+
+-- Who last changed a visit's status; status_reason and status_changed_at now cover clock-in/out too
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS status_changed_by UUID NULL;
+ALTER TABLE schedules
+    ADD CONSTRAINT fk_schedule_status_changed_by
+        FOREIGN KEY(status_changed_by)
+            REFERENCES users(id)
+            ON DELETE SET NULL;
+
+-- Status history of every visit, one row per transition of the visit state machine
+CREATE TABLE IF NOT EXISTS schedule_status_transitions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    schedule_id UUID NOT NULL,
+    from_status VARCHAR(50) NOT NULL,
+    to_status VARCHAR(50) NOT NULL, -- 'upcoming', 'in-progress', 'completed', 'missed', 'cancelled', 'no-show' or 'reopened'
+    reason TEXT NULL,
+    changed_by UUID NULL, -- User who made the change, NULL for the sweeper
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_schedule_status_transition_schedule
+        FOREIGN KEY(schedule_id)
+            REFERENCES schedules(id)
+            ON DELETE CASCADE,
+    CONSTRAINT fk_schedule_status_transition_changed_by
+        FOREIGN KEY(changed_by)
+            REFERENCES users(id)
+            ON DELETE SET NULL
+);
+
+-- Index for loading a visit's status history in order
+CREATE INDEX IF NOT EXISTS idx_schedule_status_transitions_schedule_id_changed_at ON schedule_status_transitions (schedule_id, changed_at);
+
+-- Row versions for optimistic concurrency: every update is conditional on the version that was read
+-- and increments it; the API sends it as the ETag and checks it against If-Match
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+-- Version of the schedule after each status change; existing history rows are left at 0
+ALTER TABLE schedule_status_transitions ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 0;
+
+-- Idempotency keys of retried clock-in, clock-out and task status requests, with the response
+-- to replay; a key is scoped to the user who sent it and can be reused once it expires
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id UUID NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    request_hash CHAR(64) NOT NULL, -- SHA-256 of the request body, hex encoded
+    status_code INTEGER NULL, -- NULL while the original request is still being processed
+    locked_until TIMESTAMPTZ NULL, -- Lease of the request being processed; once it passes, a retry takes the key over
+    content_type VARCHAR(255) NULL,
+    etag VARCHAR(50) NULL,
+    body BYTEA NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, key),
+    CONSTRAINT fk_idempotency_key_user
+        FOREIGN KEY(user_id)
+            REFERENCES users(id)
+            ON DELETE CASCADE
+);
+
+-- Index for purging expired keys
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
+
+-- Events recorded on caregivers' devices while offline and applied by POST /api/sync,
+-- kept with their outcome so that a batch sent again is not applied twice
+CREATE TABLE IF NOT EXISTS sync_events (
+    id UUID NOT NULL, -- Generated by the device, so only unique per user
+    user_id UUID NOT NULL,
+    type VARCHAR(50) NOT NULL, -- 'clock_in', 'clock_out' or 'task_update'
+    schedule_id UUID NULL,
+    task_id UUID NULL,
+    occurred_at TIMESTAMPTZ NOT NULL, -- Device time of the event
+    result VARCHAR(50) NOT NULL, -- 'applied', 'conflict' or 'rejected'
+    error JSONB NULL, -- Why the event was not applied
+    synced_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, id),
+    CONSTRAINT fk_sync_event_user
+        FOREIGN KEY(user_id)
+            REFERENCES users(id)
+            ON DELETE CASCADE
+);
+
+-- Clock-ins and clock-outs keep the device time in start_time/end_time and the time the server
+-- received them, and are flagged when the two differ by more than CLOCK_SKEW_TOLERANCE.
+-- Flagged visits raise 'clock_in_clock_skew' and 'clock_out_clock_skew' visit exceptions.
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS start_received_at TIMESTAMPTZ NULL;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS start_gps_fix_at TIMESTAMPTZ NULL; -- Device time of the location fix
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS start_clock_skewed BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS end_received_at TIMESTAMPTZ NULL;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS end_gps_fix_at TIMESTAMPTZ NULL; -- Device time of the location fix
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS end_clock_skewed BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Append-only audit trail of every schedule and task change, written in the transaction of the
+-- change. Rows are never updated or deleted, and outlive the schedules and users they refer to.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY, -- Orders the events of a schedule
+    schedule_id UUID NOT NULL, -- Visit the change belongs to, also for task changes
+    entity_type VARCHAR(50) NOT NULL, -- 'schedule' or 'task'
+    entity_id UUID NOT NULL,
+    action VARCHAR(50) NOT NULL, -- e.g. 'visit_started', 'task_status_updated'
+    actor_id UUID NULL, -- User who made the change, NULL for background jobs
+    before JSONB NULL, -- Row before the change, NULL when it was created
+    after JSONB NULL, -- Row after the change, NULL when it was deleted
+    ip_address VARCHAR(45) NULL,
+    user_agent TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Index for loading a schedule's history in order
+CREATE INDEX IF NOT EXISTS idx_audit_events_schedule_id ON audit_events (schedule_id, id);
+
+CREATE OR REPLACE FUNCTION reject_audit_event_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_audit_events_append_only ON audit_events;
+CREATE TRIGGER trg_audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_event_change();
+
+-- Corrections of a visit's clock-in or clock-out proposed by caregivers or coordinators. An approved
+-- correction is applied to the schedule by a different user than the one who requested it.
+CREATE TABLE IF NOT EXISTS schedule_corrections (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    schedule_id UUID NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending', -- 'pending', 'approved', 'rejected'
+    start_time TIMESTAMPTZ NULL, -- Proposed values, NULL leaves the recorded value unchanged
+    start_latitude NUMERIC(10, 8) NULL,
+    start_longitude NUMERIC(11, 8) NULL,
+    end_time TIMESTAMPTZ NULL,
+    end_latitude NUMERIC(10, 8) NULL,
+    end_longitude NUMERIC(11, 8) NULL,
+    reason_code VARCHAR(50) NOT NULL, -- e.g. 'forgot_to_clock_out', 'device_issue', 'other'
+    comment TEXT NULL,
+    requested_by UUID NULL,
+    resolved_by UUID NULL, -- Coordinator who approved or rejected
+    resolved_at TIMESTAMPTZ NULL,
+    resolution_note TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_schedule_correction_schedule
+        FOREIGN KEY(schedule_id)
+            REFERENCES schedules(id)
+            ON DELETE CASCADE,
+    CONSTRAINT fk_schedule_correction_requested_by
+        FOREIGN KEY(requested_by)
+            REFERENCES users(id)
+            ON DELETE SET NULL,
+    CONSTRAINT fk_schedule_correction_resolved_by
+        FOREIGN KEY(resolved_by)
+            REFERENCES users(id)
+            ON DELETE SET NULL
+);
+
+-- At most one pending correction per schedule
+CREATE UNIQUE INDEX IF NOT EXISTS uq_schedule_corrections_pending ON schedule_corrections (schedule_id) WHERE status = 'pending';
+-- Index for the coordinators' review queue
+CREATE INDEX IF NOT EXISTS idx_schedule_corrections_status ON schedule_corrections (status);
+
+-- Clock-in and clock-out as recorded before the first approved correction, NULL when never corrected
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS original_start_time TIMESTAMPTZ NULL;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS original_start_latitude NUMERIC(10, 8) NULL;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS original_start_longitude NUMERIC(11, 8) NULL;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS original_end_time TIMESTAMPTZ NULL;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS original_end_latitude NUMERIC(10, 8) NULL;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS original_end_longitude NUMERIC(11, 8) NULL;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS corrected_at TIMESTAMPTZ NULL;
+
+-- DDL for schedule_correction_tasks table (task outcomes proposed by a correction)
+CREATE TABLE IF NOT EXISTS schedule_correction_tasks (
+    correction_id UUID NOT NULL,
+    task_id UUID NOT NULL,
+    status VARCHAR(50) NOT NULL, -- 'completed', 'not_completed', 'cancelled'
+    reason TEXT NULL, -- Required if status is 'not_completed'
+    PRIMARY KEY (correction_id, task_id),
+    CONSTRAINT fk_schedule_correction_task_correction
+        FOREIGN KEY(correction_id)
+            REFERENCES schedule_corrections(id)
+            ON DELETE CASCADE,
+    CONSTRAINT fk_schedule_correction_task_task
+        FOREIGN KEY(task_id)
+            REFERENCES tasks(id)
+            ON DELETE CASCADE
+);
+
+-- DDL for visit_attestations table (client confirmation of a visit, given at clock-out)
+CREATE TABLE IF NOT EXISTS visit_attestations (
+    schedule_id UUID PRIMARY KEY,
+    signer_name VARCHAR(200) NULL,
+    signer_relationship VARCHAR(50) NULL, -- 'client', 'family', 'guardian', 'representative', 'other'
+    signature_format VARCHAR(50) NULL, -- 'png' or 'strokes', NULL when the client was unable to sign
+    signature_png BYTEA NULL, -- Strokes are stored rendered as well
+    signature_strokes JSONB NULL, -- Strokes as sent by the device
+    unable_to_sign_reason TEXT NULL,
+    signed_at TIMESTAMPTZ NOT NULL, -- Device time of the clock-out
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_visit_attestation_schedule
+        FOREIGN KEY(schedule_id)
+            REFERENCES schedules(id)
+            ON DELETE CASCADE,
+    CONSTRAINT chk_visit_attestation_signed
+        CHECK ((signature_png IS NOT NULL AND signer_name IS NOT NULL) OR unable_to_sign_reason IS NOT NULL)
+);
+
+-- Notes written about a visit. A note can only be edited by its author, for a limited time after it was written.
+CREATE TABLE IF NOT EXISTS visit_notes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    schedule_id UUID NOT NULL,
+    author_id UUID NOT NULL,
+    category VARCHAR(50) NOT NULL, -- 'observation', 'change_in_condition' or 'family_communication'
+    text TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_visit_note_schedule
+        FOREIGN KEY(schedule_id)
+            REFERENCES schedules(id)
+            ON DELETE CASCADE,
+    CONSTRAINT fk_visit_note_author
+        FOREIGN KEY(author_id)
+            REFERENCES users(id),
+    CONSTRAINT chk_visit_note_category
+        CHECK (category IN ('observation', 'change_in_condition', 'family_communication'))
+);
+
+-- Index for listing a schedule's notes in order
+CREATE INDEX IF NOT EXISTS idx_visit_notes_schedule_id ON visit_notes (schedule_id, created_at);
+
+-- Photos and documents uploaded to a schedule or one of its tasks. The content is kept in the configured
+-- storage backend under storage_key.
+CREATE TABLE IF NOT EXISTS attachments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    schedule_id UUID NOT NULL,
+    task_id UUID NULL, -- NULL when attached to the schedule as a whole
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL, -- Detected from the content, e.g. 'image/jpeg', 'application/pdf'
+    size_bytes BIGINT NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    uploaded_by UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_attachment_schedule
+        FOREIGN KEY(schedule_id)
+            REFERENCES schedules(id)
+            ON DELETE CASCADE,
+    CONSTRAINT fk_attachment_task
+        FOREIGN KEY(task_id)
+            REFERENCES tasks(id)
+            ON DELETE SET NULL,
+    CONSTRAINT fk_attachment_uploaded_by
+        FOREIGN KEY(uploaded_by)
+            REFERENCES users(id)
+);
+
+-- Index for listing a schedule's attachments in order
+CREATE INDEX IF NOT EXISTS idx_attachments_schedule_id ON attachments (schedule_id, created_at);
@@ -0,0 +1,162 @@
+package policy
+
+import (
+	"context" // Import context
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Action identifies an operation guarded by the policy
+type Action string
+
+const (
+	ViewSchedule   Action = "schedule:view"
+	StartVisit     Action = "schedule:start"
+	EndVisit       Action = "schedule:end"
+	ManageSchedule Action = "schedule:manage"
+	ReportNoShow   Action = "schedule:no-show"
+	UpdateTask     Action = "task:update"
+	SyncVisits     Action = "visit:sync"
+	ViewCaregivers Action = "caregiver:view"
+	ViewClients    Action = "client:view"
+	ManageClients  Action = "client:manage"
+
+	ViewExceptions   Action = "exception:view"
+	ExplainException Action = "exception:explain"
+	ResolveException Action = "exception:resolve"
+
+	RequestCorrection Action = "correction:request"
+	ResolveCorrection Action = "correction:resolve"
+
+	WriteVisitNote   Action = "note:write"
+	UploadAttachment Action = "attachment:upload"
+)
+
+// rolePermissions lists the actions each role may perform.
+// Admins are allowed everything and are therefore not listed.
+var rolePermissions = map[authModel.Role]map[Action]bool{
+	authModel.RoleCaregiver: {
+		ViewSchedule: true,
+		StartVisit:   true,
+		EndVisit:     true,
+		ReportNoShow: true,
+		UpdateTask:   true,
+		SyncVisits:   true,
+
+		ViewExceptions:   true,
+		ExplainException: true,
+
+		RequestCorrection: true,
+
+		WriteVisitNote:   true,
+		UploadAttachment: true,
+	},
+	authModel.RoleCoordinator: {
+		ViewSchedule:   true,
+		StartVisit:     true,
+		EndVisit:       true,
+		ReportNoShow:   true,
+		UpdateTask:     true,
+		SyncVisits:     true,
+		ManageSchedule: true,
+		ViewCaregivers: true,
+		ViewClients:    true,
+		ManageClients:  true,
+
+		ViewExceptions:   true,
+		ExplainException: true,
+		ResolveException: true,
+
+		RequestCorrection: true,
+		ResolveCorrection: true,
+
+		WriteVisitNote:   true,
+		UploadAttachment: true,
+	},
+}
+
+// Resource describes who owns the record an action is performed on
+type Resource struct {
+	CaregiverID *string  // Caregiver assigned to the record
+	BranchID    *string  // Branch of the assigned caregiver
+	BranchIDs   []string // Branches sharing the record, e.g. those whose caregivers visit a client
+}
+
+// ResourceResolver loads the ownership of the record targeted by a request
+type ResourceResolver func(c *fiber.Ctx) (*Resource, error)
+
+// Authorize checks whether the principal may perform action on res.
+// A nil res means the action targets a collection, in which case only the
+// role is checked and the service is expected to scope the results.
+func Authorize(p *authModel.Principal, action Action, res *Resource) error {
+	if p == nil {
+		return exceptions.ErrUnauthorized
+	}
+	if p.Role == authModel.RoleAdmin {
+		return nil
+	}
+
+	if !rolePermissions[p.Role][action] {
+		return exceptions.ErrForbidden.WithDetails(fmt.Sprintf("Role %q is not allowed to perform %s", p.Role, action))
+	}
+	if res == nil {
+		return nil
+	}
+
+	switch p.Role {
+	case authModel.RoleCaregiver:
+		if !sameID(p.CaregiverID, res.CaregiverID) {
+			return exceptions.ErrForbidden.WithDetails("Caregivers may only access their own schedules")
+		}
+	case authModel.RoleCoordinator:
+		if !sameID(p.BranchID, res.BranchID) && !containsID(res.BranchIDs, p.BranchID) {
+			return exceptions.ErrForbidden.WithDetails("Coordinators may only access records in their branch")
+		}
+	}
+	return nil
+}
+
+// AuthorizeContext is Authorize using the principal stored in ctx
+func AuthorizeContext(ctx context.Context, action Action, res *Resource) error {
+	p, _ := authModel.PrincipalFromContext(ctx)
+	return Authorize(p, action, res)
+}
+
+// Require returns a Fiber handler that enforces action on the resource
+// resolved from the request. Pass a nil resolver for collection endpoints.
+func Require(action Action, resolve ResourceResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var res *Resource
+		if resolve != nil {
+			// Check the role first so that callers cannot probe for existence
+			if err := AuthorizeContext(c.UserContext(), action, nil); err != nil {
+				return exceptions.HandleError(c, err)
+			}
+
+			var err error
+			res, err = resolve(c)
+			if err != nil {
+				return exceptions.HandleError(c, err)
+			}
+		}
+
+		if err := AuthorizeContext(c.UserContext(), action, res); err != nil {
+			return exceptions.HandleError(c, err)
+		}
+		return c.Next()
+	}
+}
+
+// containsID reports whether id is set and one of ids
+func containsID(ids []string, id *string) bool {
+	return id != nil && slices.Contains(ids, *id)
+}
+
+// sameID reports whether both IDs are set and equal
+func sameID(a, b *string) bool {
+	return a != nil && b != nil && *a == *b
+}
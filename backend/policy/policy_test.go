@@ -0,0 +1,116 @@
+package policy_test
+
+import (
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/policy"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func assertCode(t *testing.T, err error, code int) {
+	t.Helper()
+	customErr, ok := err.(*exceptions.CustomError)
+	if assert.True(t, ok, "expected a CustomError, got %v", err) {
+		assert.Equal(t, code, customErr.Code)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	caregiverID, otherCaregiverID := uuid.NewString(), uuid.NewString()
+	branchID, otherBranchID := uuid.NewString(), uuid.NewString()
+
+	caregiver := &authModel.Principal{Role: authModel.RoleCaregiver, CaregiverID: &caregiverID}
+	coordinator := &authModel.Principal{Role: authModel.RoleCoordinator, BranchID: &branchID}
+	admin := &authModel.Principal{Role: authModel.RoleAdmin}
+
+	ownSchedule := &policy.Resource{CaregiverID: &caregiverID, BranchID: &branchID}
+	otherSchedule := &policy.Resource{CaregiverID: &otherCaregiverID, BranchID: &otherBranchID}
+	unassigned := &policy.Resource{}
+
+	t.Run("TestAuthorize: No Principal", func(t *testing.T) {
+		assertCode(t, policy.Authorize(nil, policy.ViewSchedule, nil), http.StatusUnauthorized)
+	})
+
+	t.Run("TestAuthorize: Caregiver Own Schedule", func(t *testing.T) {
+		assert.NoError(t, policy.Authorize(caregiver, policy.StartVisit, ownSchedule))
+		assert.NoError(t, policy.Authorize(caregiver, policy.EndVisit, ownSchedule))
+		assert.NoError(t, policy.Authorize(caregiver, policy.ReportNoShow, ownSchedule))
+		assert.NoError(t, policy.Authorize(caregiver, policy.UpdateTask, ownSchedule))
+		assert.NoError(t, policy.Authorize(caregiver, policy.SyncVisits, nil))
+	})
+
+	t.Run("TestAuthorize: Caregiver Other Schedule", func(t *testing.T) {
+		assertCode(t, policy.Authorize(caregiver, policy.StartVisit, otherSchedule), http.StatusForbidden)
+		assertCode(t, policy.Authorize(caregiver, policy.UpdateTask, unassigned), http.StatusForbidden)
+	})
+
+	t.Run("TestAuthorize: Caregiver Disallowed Action", func(t *testing.T) {
+		assertCode(t, policy.Authorize(caregiver, policy.ViewCaregivers, nil), http.StatusForbidden)
+	})
+
+	t.Run("TestAuthorize: Coordinator Branch", func(t *testing.T) {
+		assert.NoError(t, policy.Authorize(coordinator, policy.ViewSchedule, ownSchedule))
+		assert.NoError(t, policy.Authorize(coordinator, policy.EndVisit, ownSchedule))
+		assertCode(t, policy.Authorize(coordinator, policy.ViewSchedule, otherSchedule), http.StatusForbidden)
+		assertCode(t, policy.Authorize(coordinator, policy.ViewSchedule, unassigned), http.StatusForbidden)
+	})
+
+	t.Run("TestAuthorize: Manage Schedules", func(t *testing.T) {
+		assertCode(t, policy.Authorize(caregiver, policy.ManageSchedule, ownSchedule), http.StatusForbidden)
+		assert.NoError(t, policy.Authorize(coordinator, policy.ManageSchedule, ownSchedule))
+		assertCode(t, policy.Authorize(coordinator, policy.ManageSchedule, otherSchedule), http.StatusForbidden)
+	})
+
+	t.Run("TestAuthorize: Visit Exceptions", func(t *testing.T) {
+		assert.NoError(t, policy.Authorize(caregiver, policy.ExplainException, ownSchedule))
+		assertCode(t, policy.Authorize(caregiver, policy.ResolveException, ownSchedule), http.StatusForbidden)
+		assert.NoError(t, policy.Authorize(coordinator, policy.ResolveException, ownSchedule))
+		assertCode(t, policy.Authorize(coordinator, policy.ResolveException, otherSchedule), http.StatusForbidden)
+	})
+
+	t.Run("TestAuthorize: Visit Corrections", func(t *testing.T) {
+		assert.NoError(t, policy.Authorize(caregiver, policy.RequestCorrection, ownSchedule))
+		assertCode(t, policy.Authorize(caregiver, policy.RequestCorrection, otherSchedule), http.StatusForbidden)
+		assertCode(t, policy.Authorize(caregiver, policy.ResolveCorrection, ownSchedule), http.StatusForbidden)
+		assert.NoError(t, policy.Authorize(coordinator, policy.ResolveCorrection, ownSchedule))
+		assertCode(t, policy.Authorize(coordinator, policy.ResolveCorrection, otherSchedule), http.StatusForbidden)
+	})
+
+	t.Run("TestAuthorize: Visit Notes", func(t *testing.T) {
+		assert.NoError(t, policy.Authorize(caregiver, policy.WriteVisitNote, ownSchedule))
+		assertCode(t, policy.Authorize(caregiver, policy.WriteVisitNote, otherSchedule), http.StatusForbidden)
+		assert.NoError(t, policy.Authorize(coordinator, policy.WriteVisitNote, ownSchedule))
+		assertCode(t, policy.Authorize(coordinator, policy.WriteVisitNote, otherSchedule), http.StatusForbidden)
+	})
+
+	t.Run("TestAuthorize: Attachments", func(t *testing.T) {
+		assert.NoError(t, policy.Authorize(caregiver, policy.UploadAttachment, ownSchedule))
+		assertCode(t, policy.Authorize(caregiver, policy.UploadAttachment, otherSchedule), http.StatusForbidden)
+		assert.NoError(t, policy.Authorize(coordinator, policy.UploadAttachment, ownSchedule))
+		assertCode(t, policy.Authorize(coordinator, policy.UploadAttachment, otherSchedule), http.StatusForbidden)
+	})
+
+	t.Run("TestAuthorize: Clients", func(t *testing.T) {
+		sharedClient := &policy.Resource{BranchIDs: []string{otherBranchID, branchID}}
+		otherClient := &policy.Resource{BranchIDs: []string{otherBranchID}}
+		unscheduledClient := &policy.Resource{}
+		assert.NoError(t, policy.Authorize(coordinator, policy.ManageClients, sharedClient))
+		assertCode(t, policy.Authorize(coordinator, policy.ManageClients, otherClient), http.StatusForbidden)
+		assertCode(t, policy.Authorize(coordinator, policy.ViewClients, unscheduledClient), http.StatusForbidden)
+		assertCode(t, policy.Authorize(caregiver, policy.ViewClients, sharedClient), http.StatusForbidden)
+		assert.NoError(t, policy.Authorize(admin, policy.ManageClients, otherClient))
+	})
+
+	t.Run("TestAuthorize: Admin", func(t *testing.T) {
+		assert.NoError(t, policy.Authorize(admin, policy.StartVisit, otherSchedule))
+		assert.NoError(t, policy.Authorize(admin, policy.ViewCaregivers, nil))
+	})
+
+	t.Run("TestAuthorize: Unknown Role", func(t *testing.T) {
+		assertCode(t, policy.Authorize(&authModel.Principal{Role: "guest"}, policy.ViewSchedule, nil), http.StatusForbidden)
+	})
+}
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"mime"
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/policy"
+	"mini-evv-logger-backend/responses"
+	"mini-evv-logger-backend/src/domains/attachment/model"
+	"mini-evv-logger-backend/src/domains/attachment/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AttachmentController handles HTTP requests for attachments
+type AttachmentController struct {
+	svc service.AttachmentService
+}
+
+// NewAttachmentController creates a new AttachmentController
+func NewAttachmentController(svc service.AttachmentService) *AttachmentController {
+	return &AttachmentController{svc: svc}
+}
+
+// PublicRoutes sets up the download endpoint, which is authorized by the signature of the link instead of a token
+func (ac *AttachmentController) PublicRoutes(app fiber.Router) {
+	app.Get("/attachments/:id/content", ac.DownloadAttachment)
+}
+
+// Routes sets up the API endpoints for attachments
+func (ac *AttachmentController) Routes(app fiber.Router) {
+	attachmentRoutes := app.Group("/attachments")
+	attachmentRoutes.Get("/:id", policy.Require(policy.ViewSchedule, ac.attachmentResource), ac.GetAttachmentDetails)
+
+	scheduleAttachmentRoutes := app.Group("/schedules/:id/attachments")
+	scheduleAttachmentRoutes.Get("/", policy.Require(policy.ViewSchedule, ac.scheduleResource), ac.GetAttachments)
+	scheduleAttachmentRoutes.Post("/", policy.Require(policy.UploadAttachment, ac.scheduleResource), ac.UploadAttachment)
+}
+
+// attachmentResource resolves the ownership of the attachment in the :id route parameter
+func (ac *AttachmentController) attachmentResource(c *fiber.Ctx) (*policy.Resource, error) {
+	ownership, err := ac.svc.GetAttachmentOwnership(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return nil, err
+	}
+	return &policy.Resource{CaregiverID: ownership.CaregiverID, BranchID: ownership.BranchID}, nil
+}
+
+// scheduleResource resolves the ownership of the schedule in the :id route parameter
+func (ac *AttachmentController) scheduleResource(c *fiber.Ctx) (*policy.Resource, error) {
+	ownership, err := ac.svc.GetScheduleOwnership(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return nil, err
+	}
+	return &policy.Resource{CaregiverID: ownership.CaregiverID, BranchID: ownership.BranchID}, nil
+}
+
+// GetAttachments handles fetching the attachments of a schedule
+func (ac *AttachmentController) GetAttachments(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	filter := model.FilterAttachmentsRequest{}
+
+	err := c.QueryParser(&filter)
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+	}
+	filter.ScheduleID = c.Params("id") // Set the ScheduleID from the URL parameter
+
+	attachments, err := ac.svc.GetAttachments(ctx, filter)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, attachments, "Attachments retrieved successfully")
+}
+
+// GetAttachmentDetails handles fetching a single attachment with a fresh download link
+func (ac *AttachmentController) GetAttachmentDetails(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Attachment ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	attachment, err := ac.svc.GetAttachmentByID(ctx, id)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, attachment, "Attachment retrieved successfully")
+}
+
+// UploadAttachment handles a multipart upload with the file in the "file" field and an optional "task_id"
+func (ac *AttachmentController) UploadAttachment(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	scheduleID := c.Params("id")
+	if scheduleID == "" {
+		return responses.Error(c, http.StatusBadRequest, "Schedule ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "A file is required in the file field", err.Error())
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid file", err.Error())
+	}
+	defer file.Close()
+
+	req := model.UploadAttachmentRequest{ScheduleID: scheduleID, FileName: fileHeader.Filename, Size: fileHeader.Size}
+	if taskID := c.FormValue("task_id"); taskID != "" {
+		req.TaskID = &taskID
+	}
+
+	attachment, err := ac.svc.UploadAttachment(ctx, req, file)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.Created(c, attachment, "Attachment uploaded successfully")
+}
+
+// DownloadAttachment handles serving the content of an attachment through a signed link
+func (ac *AttachmentController) DownloadAttachment(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	req := model.DownloadAttachmentRequest{}
+	if err := c.QueryParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+	}
+	req.ID = c.Params("id") // Set the ID from the URL parameter
+
+	attachment, content, err := ac.svc.OpenDownload(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	c.Set(fiber.HeaderContentType, attachment.ContentType)
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	return c.SendStream(content, int(attachment.SizeBytes)) // Closes content once sent
+}
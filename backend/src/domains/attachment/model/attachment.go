@@ -0,0 +1,67 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Attachment is a photo or document uploaded to a schedule, optionally for one of its tasks
+type Attachment struct {
+	ID          string    `json:"id" db:"id"`
+	ScheduleID  string    `json:"schedule_id" db:"schedule_id"`
+	TaskID      *string   `json:"task_id" db:"task_id"`     // NULL when attached to the schedule as a whole
+	FileName    string    `json:"file_name" db:"file_name"` // As uploaded, without directories
+	ContentType string    `json:"content_type" db:"content_type"`
+	SizeBytes   int64     `json:"size_bytes" db:"size_bytes"`
+	StorageKey  string    `json:"-" db:"storage_key"`
+	UploadedBy  string    `json:"uploaded_by" db:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+
+	DownloadURL       string     `json:"download_url" db:"-"` // Signed link to the content, usable without a token until it expires
+	DownloadExpiresAt *time.Time `json:"download_expires_at" db:"-"`
+}
+
+// AttachmentOwnership identifies who an attachment's schedule belongs to, for authorization checks
+type AttachmentOwnership struct {
+	CaregiverID *string `db:"caregiver_id"`
+	BranchID    *string `db:"branch_id"`
+}
+
+// UploadAttachmentRequest describes an uploaded file; the content is passed separately
+type UploadAttachmentRequest struct {
+	ScheduleID string  `validate:"required,uuid"`
+	TaskID     *string `validate:"omitempty,uuid"`
+	FileName   string  `validate:"required,max=255"`
+	Size       int64   `validate:"gt=0"`
+}
+
+// FilterAttachmentsRequest defines the query parameters for listing a schedule's attachments
+type FilterAttachmentsRequest struct {
+	ScheduleID string `query:"-" validate:"required,uuid"`
+	TaskID     string `query:"task_id" validate:"omitempty,uuid"`
+}
+
+func (r *FilterAttachmentsRequest) String() string {
+	return fmt.Sprintf("FilterAttachmentsRequest{ScheduleID: %s, TaskID: %s}", r.ScheduleID, r.TaskID)
+}
+
+// DownloadAttachmentRequest carries the signed query parameters of a download link
+type DownloadAttachmentRequest struct {
+	ID        string `query:"-" validate:"required,uuid"`
+	Expires   int64  `query:"expires" validate:"required"`   // Unix time the link expires at
+	Signature string `query:"signature" validate:"required"` // Hex HMAC of the ID and expiry
+}
+
+func (r *UploadAttachmentRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *FilterAttachmentsRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *DownloadAttachmentRequest) Validate() error {
+	return validator.New().Struct(r)
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context" // Import context
+	"database/sql"
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/src/domains/attachment/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=./attachment_repo.go -destination=../mocks/repository/attachment_repo.go -package=mocks
+
+var attachmentColumns = []string{"id", "schedule_id", "task_id", "file_name", "content_type", "size_bytes", "storage_key", "uploaded_by", "created_at"}
+
+// AttachmentRepository defines the interface for attachment database operations
+type AttachmentRepository interface {
+	CreateAttachment(ctx context.Context, attachment model.Attachment) error
+	GetAttachments(ctx context.Context, filter model.FilterAttachmentsRequest) ([]model.Attachment, error)
+	GetAttachmentByID(ctx context.Context, id string) (*model.Attachment, error)
+	GetAttachmentOwnership(ctx context.Context, id string) (*model.AttachmentOwnership, error)
+}
+
+// attachmentRepositoryImpl implements the AttachmentRepository interface
+type attachmentRepositoryImpl struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+// NewAttachmentRepository creates a new AttachmentRepository (returns interface)
+func NewAttachmentRepository(db *sqlx.DB, logger zerolog.Logger) AttachmentRepository {
+	return &attachmentRepositoryImpl{db: db, logger: logger}
+}
+
+// CreateAttachment stores the metadata of an uploaded file
+func (r *attachmentRepositoryImpl) CreateAttachment(ctx context.Context, attachment model.Attachment) error {
+	qb := squirrel.Insert("attachments").
+		Columns("id", "schedule_id", "task_id", "file_name", "content_type", "size_bytes", "storage_key", "uploaded_by").
+		Values(attachment.ID, attachment.ScheduleID, attachment.TaskID, attachment.FileName, attachment.ContentType,
+			attachment.SizeBytes, attachment.StorageKey, attachment.UploadedBy).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", attachment.ScheduleID).Msg("Failed to build SQL query for CreateAttachment")
+		return exceptions.ErrInternalError
+	}
+
+	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", attachment.ScheduleID).Msg("Failed to execute SQL query for CreateAttachment")
+		return exceptions.ErrInternalError
+	}
+	return nil
+}
+
+// GetAttachments fetches the attachments of a schedule, oldest first
+func (r *attachmentRepositoryImpl) GetAttachments(ctx context.Context, filter model.FilterAttachmentsRequest) ([]model.Attachment, error) {
+	attachments := []model.Attachment{}
+	qb := squirrel.Select(attachmentColumns...).
+		From("attachments").
+		Where(squirrel.Eq{"schedule_id": filter.ScheduleID}).
+		OrderBy("created_at ASC").
+		PlaceholderFormat(squirrel.Dollar)
+
+	if filter.TaskID != "" {
+		qb = qb.Where(squirrel.Eq{"task_id": filter.TaskID})
+	}
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", filter.ScheduleID).Msg("Failed to build SQL query for GetAttachments")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.SelectContext(ctx, &attachments, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", filter.ScheduleID).Msg("Failed to execute SQL query for GetAttachments")
+		return nil, exceptions.ErrInternalError
+	}
+	return attachments, nil
+}
+
+// GetAttachmentByID fetches a single attachment by its ID
+func (r *attachmentRepositoryImpl) GetAttachmentByID(ctx context.Context, id string) (*model.Attachment, error) {
+	var attachment model.Attachment
+	qb := squirrel.Select(attachmentColumns...).
+		From("attachments").
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("attachment_id", id).Msg("Failed to build SQL query for GetAttachmentByID")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &attachment, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.Warn().Str("attachment_id", id).Msg("Attachment not found in database")
+			return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Attachment with ID %s not found", id))
+		}
+		r.logger.Error().Err(err).Str("attachment_id", id).Msg("Failed to execute SQL query for GetAttachmentByID")
+		return nil, exceptions.ErrInternalError
+	}
+	return &attachment, nil
+}
+
+// GetAttachmentOwnership fetches the caregiver and branch of the schedule an attachment belongs to
+func (r *attachmentRepositoryImpl) GetAttachmentOwnership(ctx context.Context, id string) (*model.AttachmentOwnership, error) {
+	var ownership model.AttachmentOwnership
+	qb := squirrel.Select("s.caregiver_id", "c.branch_id").
+		From("attachments a").
+		Join("schedules s ON s.id = a.schedule_id").
+		LeftJoin("caregivers c ON c.id = s.caregiver_id").
+		Where(squirrel.Eq{"a.id": id}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("attachment_id", id).Msg("Failed to build SQL query for GetAttachmentOwnership")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &ownership, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.Warn().Str("attachment_id", id).Msg("Attachment not found in database")
+			return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Attachment with ID %s not found", id))
+		}
+		r.logger.Error().Err(err).Str("attachment_id", id).Msg("Failed to execute SQL query for GetAttachmentOwnership")
+		return nil, exceptions.ErrInternalError
+	}
+	return &ownership, nil
+}
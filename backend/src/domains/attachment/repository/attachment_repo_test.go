@@ -0,0 +1,166 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	pkgmock "mini-evv-logger-backend/pkg_mock"
+	"mini-evv-logger-backend/src/domains/attachment/model"
+	"mini-evv-logger-backend/src/domains/attachment/repository"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	dbMock   *sql.DB
+	sqlxMock *sqlx.DB
+	mockSQL  sqlmock.Sqlmock
+	repo     repository.AttachmentRepository
+)
+
+func initMocks(t *testing.T) {
+	var err error
+	dbMock, mockSQL, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	// Wrap sqlmock in sqlx.DB
+	sqlxMock = sqlx.NewDb(dbMock, "sqlmock")
+	repo = repository.NewAttachmentRepository(sqlxMock, pkgmock.InitMockLogger())
+}
+
+const attachmentSelect = `SELECT id, schedule_id, task_id, file_name, content_type, size_bytes, storage_key, uploaded_by, created_at FROM attachments`
+
+var attachmentColumns = []string{"id", "schedule_id", "task_id", "file_name", "content_type", "size_bytes", "storage_key", "uploaded_by", "created_at"}
+
+func TestCreateAttachment(t *testing.T) {
+	initMocks(t)
+
+	taskID := uuid.NewString()
+	dummyAttachment := model.Attachment{ID: uuid.NewString(), ScheduleID: uuid.NewString(), TaskID: &taskID, FileName: "wound.jpg",
+		ContentType: "image/jpeg", SizeBytes: 2048, StorageKey: "schedules/s/a", UploadedBy: uuid.NewString()}
+	query := `INSERT INTO attachments (id,schedule_id,task_id,file_name,content_type,size_bytes,storage_key,uploaded_by) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`
+
+	t.Run("TestCreateAttachment: OK", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(dummyAttachment.ID, dummyAttachment.ScheduleID, &taskID, "wound.jpg", "image/jpeg", int64(2048), "schedules/s/a", dummyAttachment.UploadedBy).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := repo.CreateAttachment(context.Background(), dummyAttachment)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestCreateAttachment: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.CreateAttachment(context.Background(), dummyAttachment)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+	})
+}
+
+func TestGetAttachments(t *testing.T) {
+	initMocks(t)
+
+	dummyScheduleID, dummyTaskID := uuid.NewString(), uuid.NewString()
+
+	t.Run("TestGetAttachments: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(attachmentSelect + ` WHERE schedule_id = $1 ORDER BY created_at ASC`)).
+			WithArgs(dummyScheduleID).
+			WillReturnRows(sqlmock.NewRows(attachmentColumns).
+				AddRow(uuid.NewString(), dummyScheduleID, nil, "receipt.pdf", "application/pdf", 4096, "schedules/s/a", uuid.NewString(), time.Now()))
+
+		attachments, err := repo.GetAttachments(context.Background(), model.FilterAttachmentsRequest{ScheduleID: dummyScheduleID})
+		assert.NoError(t, err)
+		if assert.Len(t, attachments, 1) {
+			assert.Equal(t, "receipt.pdf", attachments[0].FileName)
+			assert.Nil(t, attachments[0].TaskID)
+		}
+	})
+
+	t.Run("TestGetAttachments: Filter By Task", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(attachmentSelect+` WHERE schedule_id = $1 AND task_id = $2 ORDER BY created_at ASC`)).
+			WithArgs(dummyScheduleID, dummyTaskID).
+			WillReturnRows(sqlmock.NewRows(attachmentColumns))
+
+		attachments, err := repo.GetAttachments(context.Background(), model.FilterAttachmentsRequest{ScheduleID: dummyScheduleID, TaskID: dummyTaskID})
+		assert.NoError(t, err)
+		assert.NotNil(t, attachments)
+		assert.Empty(t, attachments)
+	})
+
+	t.Run("TestGetAttachments: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(attachmentSelect)).
+			WillReturnError(sql.ErrConnDone)
+
+		attachments, err := repo.GetAttachments(context.Background(), model.FilterAttachmentsRequest{ScheduleID: dummyScheduleID})
+		assert.NotNil(t, err)
+		assert.Nil(t, attachments)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+	})
+}
+
+func TestGetAttachmentByID(t *testing.T) {
+	initMocks(t)
+
+	dummyID := uuid.NewString()
+	query := attachmentSelect + ` WHERE id = $1`
+
+	t.Run("TestGetAttachmentByID: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows(attachmentColumns).
+				AddRow(dummyID, uuid.NewString(), nil, "wound.jpg", "image/jpeg", 2048, "schedules/s/"+dummyID, uuid.NewString(), time.Now()))
+
+		attachment, err := repo.GetAttachmentByID(context.Background(), dummyID)
+		assert.NoError(t, err)
+		assert.Equal(t, dummyID, attachment.ID)
+		assert.Equal(t, "schedules/s/"+dummyID, attachment.StorageKey)
+	})
+
+	t.Run("TestGetAttachmentByID: No Rows", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnError(sql.ErrNoRows)
+
+		attachment, err := repo.GetAttachmentByID(context.Background(), dummyID)
+		assert.NotNil(t, err)
+		assert.Nil(t, attachment)
+		assert.Equal(t, "Error 404: Resource not found - Attachment with ID "+dummyID+" not found", err.Error())
+	})
+}
+
+func TestGetAttachmentOwnership(t *testing.T) {
+	initMocks(t)
+
+	dummyID, dummyCaregiverID, dummyBranchID := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	query := `SELECT s.caregiver_id, c.branch_id FROM attachments a JOIN schedules s ON s.id = a.schedule_id LEFT JOIN caregivers c ON c.id = s.caregiver_id WHERE a.id = $1`
+
+	t.Run("TestGetAttachmentOwnership: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows([]string{"caregiver_id", "branch_id"}).AddRow(dummyCaregiverID, dummyBranchID))
+
+		ownership, err := repo.GetAttachmentOwnership(context.Background(), dummyID)
+		assert.NoError(t, err)
+		assert.Equal(t, dummyCaregiverID, *ownership.CaregiverID)
+		assert.Equal(t, dummyBranchID, *ownership.BranchID)
+	})
+
+	t.Run("TestGetAttachmentOwnership: No Rows", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnError(sql.ErrNoRows)
+
+		ownership, err := repo.GetAttachmentOwnership(context.Background(), dummyID)
+		assert.NotNil(t, err)
+		assert.Nil(t, ownership)
+	})
+}
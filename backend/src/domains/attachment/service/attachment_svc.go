@@ -0,0 +1,276 @@
+package service
+
+import (
+	"bytes"
+	"context" // Import context
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/src/domains/attachment/model"
+	"mini-evv-logger-backend/src/domains/attachment/repository"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	scheduleModel "mini-evv-logger-backend/src/domains/schedule/model"
+	scheduleRepo "mini-evv-logger-backend/src/domains/schedule/repository"
+	taskRepo "mini-evv-logger-backend/src/domains/task/repository"
+	"mini-evv-logger-backend/storage"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// sniffLen is the number of leading bytes used to detect a file's content type, see http.DetectContentType
+const sniffLen = 512
+
+// AttachmentService defines the interface for attachment business logic
+type AttachmentService interface {
+	GetScheduleOwnership(ctx context.Context, scheduleID string) (*scheduleModel.ScheduleOwnership, error)
+	GetAttachmentOwnership(ctx context.Context, id string) (*model.AttachmentOwnership, error)
+	GetAttachments(ctx context.Context, filter model.FilterAttachmentsRequest) ([]model.Attachment, error)
+	GetAttachmentByID(ctx context.Context, id string) (*model.Attachment, error)
+	UploadAttachment(ctx context.Context, req model.UploadAttachmentRequest, content io.Reader) (*model.Attachment, error)
+	OpenDownload(ctx context.Context, req model.DownloadAttachmentRequest) (*model.Attachment, io.ReadCloser, error)
+}
+
+// Settings holds the configurable rules applied by the attachment service
+type Settings struct {
+	MaxBytes     int64         // Largest file accepted
+	AllowedTypes []string      // Content types accepted, detected from the file content, e.g. "image/jpeg"
+	URLSecret    string        // Key the download links are signed with
+	URLTTL       time.Duration // How long a download link stays valid
+	URLPrefix    string        // Path the download route is served below, e.g. "/api/attachments"
+}
+
+// attachmentServiceImpl implements the AttachmentService interface
+type attachmentServiceImpl struct {
+	repo         repository.AttachmentRepository
+	scheduleRepo scheduleRepo.ScheduleRepository
+	taskRepo     taskRepo.TaskRepository
+	storage      storage.Storage
+	settings     Settings
+}
+
+// NewAttachmentService creates a new AttachmentService (returns interface)
+func NewAttachmentService(repo repository.AttachmentRepository, scheduleRepo scheduleRepo.ScheduleRepository, taskRepo taskRepo.TaskRepository,
+	store storage.Storage, settings Settings) AttachmentService {
+	return &attachmentServiceImpl{repo: repo, scheduleRepo: scheduleRepo, taskRepo: taskRepo, storage: store, settings: settings}
+}
+
+// GetScheduleOwnership fetches who a schedule belongs to, for authorizing access to its attachments
+func (s *attachmentServiceImpl) GetScheduleOwnership(ctx context.Context, scheduleID string) (*scheduleModel.ScheduleOwnership, error) {
+	_, err := uuid.Parse(scheduleID)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", scheduleID).Msg("Invalid UUID format for schedule ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid schedule ID format")
+	}
+
+	ownership, err := s.scheduleRepo.GetScheduleOwnership(ctx, scheduleID)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to fetch schedule ownership from repository")
+		return nil, err
+	}
+	return ownership, nil
+}
+
+// GetAttachmentOwnership fetches who an attachment's schedule belongs to, for authorization checks
+func (s *attachmentServiceImpl) GetAttachmentOwnership(ctx context.Context, id string) (*model.AttachmentOwnership, error) {
+	_, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Str("attachment_id", id).Msg("Invalid UUID format for attachment ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid attachment ID format")
+	}
+
+	ownership, err := s.repo.GetAttachmentOwnership(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("attachment_id", id).Msg("Failed to fetch attachment ownership from repository")
+		return nil, err
+	}
+	return ownership, nil
+}
+
+// GetAttachments fetches the attachments of a schedule, each with a fresh download link
+func (s *attachmentServiceImpl) GetAttachments(ctx context.Context, filter model.FilterAttachmentsRequest) ([]model.Attachment, error) {
+	log.Info().Msgf("Fetching attachments with filter %s", filter.String())
+
+	err := filter.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for FilterAttachmentsRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	attachments, err := s.repo.GetAttachments(ctx, filter)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", filter.ScheduleID).Msg("Failed to fetch attachments from repository")
+		return nil, err
+	}
+	for i := range attachments {
+		s.signDownload(&attachments[i])
+	}
+	return attachments, nil
+}
+
+// GetAttachmentByID fetches a single attachment with a fresh download link
+func (s *attachmentServiceImpl) GetAttachmentByID(ctx context.Context, id string) (*model.Attachment, error) {
+	log.Info().Str("attachment_id", id).Msg("Fetching attachment by ID")
+
+	_, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Str("attachment_id", id).Msg("Invalid UUID format for attachment ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid attachment ID format")
+	}
+
+	attachment, err := s.repo.GetAttachmentByID(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("attachment_id", id).Msg("Failed to fetch attachment by ID from repository")
+		return nil, err
+	}
+	s.signDownload(attachment)
+	return attachment, nil
+}
+
+// UploadAttachment stores content in the storage backend and records it for the schedule, or for one of its tasks.
+// The content type is detected from the content itself, the one claimed by the client is ignored.
+func (s *attachmentServiceImpl) UploadAttachment(ctx context.Context, req model.UploadAttachmentRequest, content io.Reader) (*model.Attachment, error) {
+	log.Info().Str("schedule_id", req.ScheduleID).Str("user_id", authModel.ActorID(ctx)).Str("file_name", req.FileName).Int64("size", req.Size).Msg("Attempting to upload attachment")
+
+	req.FileName = path.Base(strings.ReplaceAll(req.FileName, `\`, "/")) // Drop any directories sent by the client
+	if req.FileName == "." || req.FileName == "/" {
+		req.FileName = ""
+	}
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for UploadAttachmentRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+	if req.Size > s.settings.MaxBytes {
+		return nil, exceptions.ErrRequestEntityTooLarge.WithDetails(fmt.Sprintf("Attachments can be at most %d bytes", s.settings.MaxBytes))
+	}
+
+	uploadedBy := authModel.ActorID(ctx)
+	if uploadedBy == "" {
+		return nil, exceptions.ErrUnauthorized
+	}
+
+	// 1. A task must belong to the schedule the file is uploaded to
+	if req.TaskID != nil {
+		task, err := s.taskRepo.GetTaskByID(ctx, *req.TaskID)
+		if err != nil {
+			if customErr, ok := err.(*exceptions.CustomError); ok && customErr.Code == http.StatusNotFound {
+				return nil, exceptions.ErrUnprocessableEntity.WithDetails(fmt.Sprintf("Task ID %s does not belong to schedule ID %s", *req.TaskID, req.ScheduleID))
+			}
+			log.Error().Err(err).Str("task_id", *req.TaskID).Msg("Failed to retrieve task before upload")
+			return nil, err
+		}
+		if task.ScheduleID != req.ScheduleID {
+			return nil, exceptions.ErrUnprocessableEntity.WithDetails(fmt.Sprintf("Task ID %s does not belong to schedule ID %s", *req.TaskID, req.ScheduleID))
+		}
+	}
+
+	// 2. Detect the content type from the first bytes
+	head := make([]byte, min(sniffLen, req.Size))
+	_, err = io.ReadFull(content, head)
+	if err != nil {
+		return nil, exceptions.ErrBadRequest.WithDetails("File is shorter than its declared size")
+	}
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil || !slices.Contains(s.settings.AllowedTypes, contentType) {
+		return nil, exceptions.ErrUnsupportedMediaType.WithDetails(fmt.Sprintf("Files of type %s are not accepted, allowed types are %s", contentType, strings.Join(s.settings.AllowedTypes, ", ")))
+	}
+
+	// 3. Store the content, then its metadata
+	attachment := model.Attachment{
+		ID:          uuid.NewString(),
+		ScheduleID:  req.ScheduleID,
+		TaskID:      req.TaskID,
+		FileName:    req.FileName,
+		ContentType: contentType,
+		SizeBytes:   req.Size,
+		UploadedBy:  uploadedBy,
+	}
+	attachment.StorageKey = fmt.Sprintf("schedules/%s/%s", attachment.ScheduleID, attachment.ID)
+
+	err = s.storage.Put(ctx, attachment.StorageKey, io.MultiReader(bytes.NewReader(head), content), req.Size, contentType)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", req.ScheduleID).Str("key", attachment.StorageKey).Msg("Failed to store attachment content")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = s.repo.CreateAttachment(ctx, attachment)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", req.ScheduleID).Msg("Failed to create attachment in repository")
+		if delErr := s.storage.Delete(ctx, attachment.StorageKey); delErr != nil {
+			log.Error().Err(delErr).Str("key", attachment.StorageKey).Msg("Failed to remove content of unrecorded attachment")
+		}
+		return nil, err
+	}
+
+	return s.GetAttachmentByID(ctx, attachment.ID)
+}
+
+// OpenDownload checks a signed download link and opens the attachment's content; the caller must close it
+func (s *attachmentServiceImpl) OpenDownload(ctx context.Context, req model.DownloadAttachmentRequest) (*model.Attachment, io.ReadCloser, error) {
+	log.Info().Str("attachment_id", req.ID).Msg("Attempting to download attachment")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for DownloadAttachmentRequest")
+		return nil, nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	// 1. The link must be signed by us and not have expired
+	if !hmac.Equal([]byte(req.Signature), []byte(s.signature(req.ID, req.Expires))) {
+		return nil, nil, exceptions.ErrForbidden.WithDetails("Invalid download link")
+	}
+	if time.Now().Unix() > req.Expires {
+		return nil, nil, exceptions.ErrForbidden.WithDetails("Download link has expired")
+	}
+
+	// 2. Open the content
+	attachment, err := s.repo.GetAttachmentByID(ctx, req.ID)
+	if err != nil {
+		log.Error().Err(err).Str("attachment_id", req.ID).Msg("Failed to retrieve attachment before download")
+		return nil, nil, err
+	}
+
+	content, err := s.storage.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Error().Str("attachment_id", req.ID).Str("key", attachment.StorageKey).Msg("Attachment content is missing from storage")
+			return nil, nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Content of attachment ID %s not found", req.ID))
+		}
+		log.Error().Err(err).Str("attachment_id", req.ID).Msg("Failed to open attachment content")
+		return nil, nil, exceptions.ErrInternalError
+	}
+	return attachment, content, nil
+}
+
+// signDownload sets a download link for the attachment that is valid for the configured time
+func (s *attachmentServiceImpl) signDownload(attachment *model.Attachment) {
+	expiresAt := time.Now().Add(s.settings.URLTTL).Truncate(time.Second)
+	expires := expiresAt.Unix()
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", s.signature(attachment.ID, expires))
+
+	attachment.DownloadURL = fmt.Sprintf("%s/%s/content?%s", s.settings.URLPrefix, attachment.ID, query.Encode())
+	attachment.DownloadExpiresAt = &expiresAt
+}
+
+// signature is the hex HMAC of a download link for the attachment id that expires at the Unix time expires
+func (s *attachmentServiceImpl) signature(id string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(s.settings.URLSecret))
+	fmt.Fprintf(mac, "attachment:%s:%d", id, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,279 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"mini-evv-logger-backend/exceptions"
+	mocks "mini-evv-logger-backend/src/domains/attachment/mocks/repository"
+	"mini-evv-logger-backend/src/domains/attachment/model"
+	"mini-evv-logger-backend/src/domains/attachment/service"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	scheduleMocks "mini-evv-logger-backend/src/domains/schedule/mocks/repository"
+	taskMocks "mini-evv-logger-backend/src/domains/task/mocks/repository"
+	taskModel "mini-evv-logger-backend/src/domains/task/model"
+	"mini-evv-logger-backend/storage"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	mockAttachmentRepo *mocks.MockAttachmentRepository
+	mockScheduleRepo   *scheduleMocks.MockScheduleRepository
+	mockTaskRepo       *taskMocks.MockTaskRepository
+	store              storage.Storage
+	ctrl               *gomock.Controller
+	svc                service.AttachmentService
+)
+
+// dummySettings accepts images and PDFs of up to 1 MB, with download links valid for 5 minutes
+var dummySettings = service.Settings{MaxBytes: 1024 * 1024, AllowedTypes: []string{"image/jpeg", "image/png", "application/pdf"},
+	URLSecret: "secret", URLTTL: 5 * time.Minute, URLPrefix: "/api/attachments"}
+
+func initMocks(t *testing.T) {
+	ctrl = gomock.NewController(t)
+
+	mockAttachmentRepo = mocks.NewMockAttachmentRepository(ctrl)
+	mockScheduleRepo = scheduleMocks.NewMockScheduleRepository(ctrl)
+	mockTaskRepo = taskMocks.NewMockTaskRepository(ctrl)
+
+	var err error
+	store, err = storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create local storage: %v", err)
+	}
+
+	svc = service.NewAttachmentService(mockAttachmentRepo, mockScheduleRepo, mockTaskRepo, store, dummySettings)
+}
+
+// uploaderContext returns a context for the caregiver user userID
+func uploaderContext(userID string) context.Context {
+	caregiverID := uuid.NewString()
+	return authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: userID, Role: authModel.RoleCaregiver, CaregiverID: &caregiverID})
+}
+
+// dummyPNG encodes a small image as PNG
+func dummyPNG(t *testing.T) []byte {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 4, 4)))
+	if err != nil {
+		t.Fatalf("failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// downloadRequest turns the download link of attachment into the request the download route receives
+func downloadRequest(t *testing.T, attachment *model.Attachment) model.DownloadAttachmentRequest {
+	u, err := url.Parse(attachment.DownloadURL)
+	if err != nil {
+		t.Fatalf("invalid download URL %q: %v", attachment.DownloadURL, err)
+	}
+	expires, _ := strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+	return model.DownloadAttachmentRequest{ID: attachment.ID, Expires: expires, Signature: u.Query().Get("signature")}
+}
+
+func TestUploadAttachment(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyScheduleID, dummyUserID := uuid.NewString(), uuid.NewString()
+	content := dummyPNG(t)
+
+	t.Run("TestUploadAttachment: OK", func(t *testing.T) {
+		var created model.Attachment
+		mockAttachmentRepo.EXPECT().CreateAttachment(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, attachment model.Attachment) error {
+				created = attachment
+				return nil
+			}).Times(1)
+		mockAttachmentRepo.EXPECT().GetAttachmentByID(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, id string) (*model.Attachment, error) {
+				return &created, nil
+			}).Times(1)
+
+		req := model.UploadAttachmentRequest{ScheduleID: dummyScheduleID, FileName: `C:\Photos\wound.png`, Size: int64(len(content))}
+		attachment, err := svc.UploadAttachment(uploaderContext(dummyUserID), req, bytes.NewReader(content))
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", attachment.ContentType)
+		assert.Equal(t, "wound.png", attachment.FileName)
+		assert.Equal(t, dummyUserID, attachment.UploadedBy)
+		assert.Equal(t, "schedules/"+dummyScheduleID+"/"+attachment.ID, attachment.StorageKey)
+		assert.True(t, strings.HasPrefix(attachment.DownloadURL, "/api/attachments/"+attachment.ID+"/content?"))
+
+		rc, err := store.Get(context.Background(), attachment.StorageKey)
+		if assert.NoError(t, err) {
+			defer rc.Close()
+			stored, _ := io.ReadAll(rc)
+			assert.Equal(t, content, stored)
+		}
+	})
+
+	t.Run("TestUploadAttachment: For Task", func(t *testing.T) {
+		taskID := uuid.NewString()
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), taskID).Return(&taskModel.Task{ID: taskID, ScheduleID: dummyScheduleID}, nil).Times(1)
+		mockAttachmentRepo.EXPECT().CreateAttachment(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, attachment model.Attachment) error {
+				assert.Equal(t, &taskID, attachment.TaskID)
+				return nil
+			}).Times(1)
+		mockAttachmentRepo.EXPECT().GetAttachmentByID(gomock.Any(), gomock.Any()).Return(&model.Attachment{TaskID: &taskID}, nil).Times(1)
+
+		req := model.UploadAttachmentRequest{ScheduleID: dummyScheduleID, TaskID: &taskID, FileName: "wound.png", Size: int64(len(content))}
+		_, err := svc.UploadAttachment(uploaderContext(dummyUserID), req, bytes.NewReader(content))
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestUploadAttachment: Task Of Another Schedule", func(t *testing.T) {
+		taskID := uuid.NewString()
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), taskID).Return(&taskModel.Task{ID: taskID, ScheduleID: uuid.NewString()}, nil).Times(1)
+
+		req := model.UploadAttachmentRequest{ScheduleID: dummyScheduleID, TaskID: &taskID, FileName: "wound.png", Size: int64(len(content))}
+		attachment, err := svc.UploadAttachment(uploaderContext(dummyUserID), req, bytes.NewReader(content))
+		assert.Nil(t, attachment)
+		assert.Equal(t, "Error 422: Unprocessable Entity - Task ID "+taskID+" does not belong to schedule ID "+dummyScheduleID, err.Error())
+	})
+
+	t.Run("TestUploadAttachment: Too Large", func(t *testing.T) {
+		req := model.UploadAttachmentRequest{ScheduleID: dummyScheduleID, FileName: "wound.png", Size: dummySettings.MaxBytes + 1}
+		attachment, err := svc.UploadAttachment(uploaderContext(dummyUserID), req, bytes.NewReader(content))
+		assert.Nil(t, attachment)
+		assert.Equal(t, 413, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestUploadAttachment: Unsupported Type", func(t *testing.T) {
+		script := []byte("<html><script>alert(1)</script></html>")
+		req := model.UploadAttachmentRequest{ScheduleID: dummyScheduleID, FileName: "wound.png", Size: int64(len(script))}
+		attachment, err := svc.UploadAttachment(uploaderContext(dummyUserID), req, bytes.NewReader(script))
+		assert.Nil(t, attachment)
+		assert.Equal(t, 415, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestUploadAttachment: Shorter Than Declared", func(t *testing.T) {
+		req := model.UploadAttachmentRequest{ScheduleID: dummyScheduleID, FileName: "wound.png", Size: 2000}
+		attachment, err := svc.UploadAttachment(uploaderContext(dummyUserID), req, bytes.NewReader(content))
+		assert.Nil(t, attachment)
+		assert.Equal(t, 400, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestUploadAttachment: Repository Error Removes Content", func(t *testing.T) {
+		var key string
+		mockAttachmentRepo.EXPECT().CreateAttachment(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, attachment model.Attachment) error {
+				key = attachment.StorageKey
+				return exceptions.ErrInternalError
+			}).Times(1)
+
+		req := model.UploadAttachmentRequest{ScheduleID: dummyScheduleID, FileName: "wound.png", Size: int64(len(content))}
+		attachment, err := svc.UploadAttachment(uploaderContext(dummyUserID), req, bytes.NewReader(content))
+		assert.Nil(t, attachment)
+		assert.Equal(t, exceptions.ErrInternalError, err)
+
+		_, err = store.Get(context.Background(), key)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("TestUploadAttachment: No Principal", func(t *testing.T) {
+		req := model.UploadAttachmentRequest{ScheduleID: dummyScheduleID, FileName: "wound.png", Size: int64(len(content))}
+		attachment, err := svc.UploadAttachment(context.Background(), req, bytes.NewReader(content))
+		assert.Nil(t, attachment)
+		assert.Equal(t, exceptions.ErrUnauthorized, err)
+	})
+}
+
+func TestGetAttachments(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyScheduleID := uuid.NewString()
+
+	t.Run("TestGetAttachments: OK", func(t *testing.T) {
+		mockAttachmentRepo.EXPECT().GetAttachments(gomock.Any(), model.FilterAttachmentsRequest{ScheduleID: dummyScheduleID}).
+			Return([]model.Attachment{{ID: uuid.NewString()}, {ID: uuid.NewString()}}, nil).Times(1)
+
+		attachments, err := svc.GetAttachments(context.Background(), model.FilterAttachmentsRequest{ScheduleID: dummyScheduleID})
+		assert.NoError(t, err)
+		for _, attachment := range attachments {
+			assert.Contains(t, attachment.DownloadURL, attachment.ID)
+			assert.WithinDuration(t, time.Now().Add(dummySettings.URLTTL), *attachment.DownloadExpiresAt, 2*time.Second)
+		}
+	})
+
+	t.Run("TestGetAttachments: Invalid Task ID", func(t *testing.T) {
+		attachments, err := svc.GetAttachments(context.Background(), model.FilterAttachmentsRequest{ScheduleID: dummyScheduleID, TaskID: "not-a-uuid"})
+		assert.Nil(t, attachments)
+		assert.Equal(t, 400, err.(*exceptions.CustomError).Code)
+	})
+}
+
+func TestOpenDownload(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyID := uuid.NewString()
+	dummyAttachment := &model.Attachment{ID: dummyID, ContentType: "application/pdf", SizeBytes: 8, StorageKey: "schedules/s/" + dummyID}
+	err := store.Put(context.Background(), dummyAttachment.StorageKey, strings.NewReader("%PDF-1.7"), 8, "application/pdf")
+	if err != nil {
+		t.Fatalf("failed to store content: %v", err)
+	}
+
+	// Sign a link the way the listing does
+	mockAttachmentRepo.EXPECT().GetAttachmentByID(gomock.Any(), dummyID).Return(dummyAttachment, nil).Times(1)
+	signed, _ := svc.GetAttachmentByID(context.Background(), dummyID)
+	req := downloadRequest(t, signed)
+
+	t.Run("TestOpenDownload: OK", func(t *testing.T) {
+		mockAttachmentRepo.EXPECT().GetAttachmentByID(gomock.Any(), dummyID).Return(dummyAttachment, nil).Times(1)
+
+		attachment, content, err := svc.OpenDownload(context.Background(), req)
+		if assert.NoError(t, err) {
+			defer content.Close()
+			body, _ := io.ReadAll(content)
+			assert.Equal(t, "%PDF-1.7", string(body))
+			assert.Equal(t, "application/pdf", attachment.ContentType)
+		}
+	})
+
+	t.Run("TestOpenDownload: Tampered Expiry", func(t *testing.T) {
+		tampered := req
+		tampered.Expires += 3600
+
+		_, _, err := svc.OpenDownload(context.Background(), tampered)
+		assert.Equal(t, "Error 403: Forbidden - Invalid download link", err.Error())
+	})
+
+	t.Run("TestOpenDownload: Other Attachment", func(t *testing.T) {
+		other := req
+		other.ID = uuid.NewString()
+
+		_, _, err := svc.OpenDownload(context.Background(), other)
+		assert.Equal(t, "Error 403: Forbidden - Invalid download link", err.Error())
+	})
+
+	t.Run("TestOpenDownload: Expired", func(t *testing.T) {
+		expiredSvc := service.NewAttachmentService(mockAttachmentRepo, mockScheduleRepo, mockTaskRepo, store, service.Settings{URLSecret: "secret", URLTTL: -time.Minute})
+		mockAttachmentRepo.EXPECT().GetAttachmentByID(gomock.Any(), dummyID).Return(dummyAttachment, nil).Times(1)
+		expired, _ := expiredSvc.GetAttachmentByID(context.Background(), dummyID)
+
+		_, _, err := expiredSvc.OpenDownload(context.Background(), downloadRequest(t, expired))
+		assert.Equal(t, "Error 403: Forbidden - Download link has expired", err.Error())
+	})
+
+	t.Run("TestOpenDownload: Content Missing", func(t *testing.T) {
+		mockAttachmentRepo.EXPECT().GetAttachmentByID(gomock.Any(), dummyID).Return(&model.Attachment{ID: dummyID, StorageKey: "schedules/s/missing"}, nil).Times(1)
+
+		_, _, err := svc.OpenDownload(context.Background(), req)
+		assert.Equal(t, 404, err.(*exceptions.CustomError).Code)
+	})
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Entity types that are audited
+const (
+	EntitySchedule = "schedule"
+	EntityTask     = "task"
+)
+
+// Audited actions
+const (
+	ActionScheduleCreated   = "schedule_created"
+	ActionScheduleUpdated   = "schedule_updated"
+	ActionScheduleDeleted   = "schedule_deleted" // Occurrence replaced by a series split
+	ActionStatusChanged     = "status_changed"   // Cancel, no-show, reopen or marked missed by the sweeper
+	ActionVisitStarted      = "visit_started"
+	ActionVisitEnded        = "visit_ended"
+	ActionVisitCorrected    = "visit_corrected" // Approved clock-in/clock-out correction
+	ActionTaskCreated       = "task_created"
+	ActionTaskUpdated       = "task_updated"
+	ActionTaskStatusUpdated = "task_status_updated"
+	ActionTaskReordered     = "task_reordered"
+	ActionTaskDeleted       = "task_deleted"
+)
+
+// Event is an entry of the append-only audit trail, one per changed row
+type Event struct {
+	ID         int64           `json:"id"`
+	ScheduleID string          `json:"schedule_id"` // Visit the change belongs to, also for task changes
+	EntityType string          `json:"entity_type"` // "schedule" or "task"
+	EntityID   string          `json:"entity_id"`
+	Action     string          `json:"action"`     // e.g. "visit_started", "task_status_updated"
+	ActorID    *string         `json:"actor_id"`   // NULL for background jobs
+	Before     json.RawMessage `json:"before"`     // Row before the change, null when it was created
+	After      json.RawMessage `json:"after"`      // Row after the change, null when it was deleted
+	IPAddress  *string         `json:"ip_address"` // NULL for background jobs
+	UserAgent  *string         `json:"user_agent"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// RequestInfo identifies the client a change was made from
+type RequestInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+type requestInfoKey struct{}
+
+// WithRequestInfo returns a copy of ctx carrying the given request details
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFromContext returns the request details stored in ctx, if any
+func RequestInfoFromContext(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info, ok
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context" // Import context
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/src/domains/audit/model"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=./audit_repo.go -destination=../mocks/repository/audit_repo.go -package=mocks
+
+// AuditRepository defines the interface for reading the audit trail.
+// Events are written with Append, in the transaction of the change they describe.
+type AuditRepository interface {
+	GetScheduleHistory(ctx context.Context, scheduleID string) ([]model.Event, error)
+}
+
+// auditRepositoryImpl implements the AuditRepository interface
+type auditRepositoryImpl struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+// NewAuditRepository creates a new AuditRepository (returns interface)
+func NewAuditRepository(db *sqlx.DB, logger zerolog.Logger) AuditRepository {
+	return &auditRepositoryImpl{db: db, logger: logger}
+}
+
+// auditRow is an audit event as read from the database
+type auditRow struct {
+	ID         int64     `db:"id"`
+	ScheduleID string    `db:"schedule_id"`
+	EntityType string    `db:"entity_type"`
+	EntityID   string    `db:"entity_id"`
+	Action     string    `db:"action"`
+	ActorID    *string   `db:"actor_id"`
+	Before     []byte    `db:"before"`
+	After      []byte    `db:"after"`
+	IPAddress  *string   `db:"ip_address"`
+	UserAgent  *string   `db:"user_agent"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// GetScheduleHistory fetches every audited change of a schedule and its tasks, oldest first
+func (r *auditRepositoryImpl) GetScheduleHistory(ctx context.Context, scheduleID string) ([]model.Event, error) {
+	var rows []auditRow
+	sqlQuery, args, err := squirrel.Select("id", "schedule_id", "entity_type", "entity_id", "action", "actor_id", "before", "after", "ip_address", "user_agent", "created_at").
+		From("audit_events").
+		Where(squirrel.Eq{"schedule_id": scheduleID}).
+		OrderBy("id ASC").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to build SQL query for GetScheduleHistory")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.SelectContext(ctx, &rows, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to execute SQL query for GetScheduleHistory")
+		return nil, exceptions.ErrInternalError
+	}
+
+	events := make([]model.Event, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, model.Event{
+			ID:         row.ID,
+			ScheduleID: row.ScheduleID,
+			EntityType: row.EntityType,
+			EntityID:   row.EntityID,
+			Action:     row.Action,
+			ActorID:    row.ActorID,
+			Before:     row.Before,
+			After:      row.After,
+			IPAddress:  row.IPAddress,
+			UserAgent:  row.UserAgent,
+			CreatedAt:  row.CreatedAt,
+		})
+	}
+	return events, nil
+}
+
+// Snapshot reads the row of table with the given ID as JSON and locks it until tx ends.
+// A row that does not exist (yet) yields nil.
+func Snapshot(ctx context.Context, tx *sqlx.Tx, table, id string) (json.RawMessage, error) {
+	sqlQuery, args, err := squirrel.Select("to_jsonb(t)").
+		From(table + " t").
+		Where(squirrel.Eq{"t.id": id}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build snapshot query for %s: %w", table, err)
+	}
+
+	var row []byte
+	err = tx.QueryRowxContext(ctx, sqlQuery, args...).Scan(&row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot of %s %s: %w", table, id, err)
+	}
+	return row, nil
+}
+
+// Append adds event to the audit trail within tx. The actor and the client's IP address and
+// user agent are taken from ctx; they stay empty for background jobs.
+func Append(ctx context.Context, tx *sqlx.Tx, event model.Event) error {
+	var actorID, ipAddress, userAgent *string
+	if id := authModel.ActorID(ctx); id != "" {
+		actorID = &id
+	}
+	if info, ok := model.RequestInfoFromContext(ctx); ok {
+		if info.IPAddress != "" {
+			ipAddress = &info.IPAddress
+		}
+		if info.UserAgent != "" {
+			userAgent = &info.UserAgent
+		}
+	}
+
+	sqlQuery, args, err := squirrel.Insert("audit_events").
+		Columns("schedule_id", "entity_type", "entity_id", "action", "actor_id", "before", "after", "ip_address", "user_agent").
+		Values(event.ScheduleID, event.EntityType, event.EntityID, event.Action, actorID, jsonText(event.Before), jsonText(event.After), ipAddress, userAgent).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build audit event query: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("append %s audit event for %s %s: %w", event.Action, event.EntityType, event.EntityID, err)
+	}
+	return nil
+}
+
+// jsonText passes JSON to the driver as text, which the jsonb columns accept; nil stays NULL
+func jsonText(raw json.RawMessage) *string {
+	if raw == nil {
+		return nil
+	}
+	text := string(raw)
+	return &text
+}
@@ -0,0 +1,164 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	pkgmock "mini-evv-logger-backend/pkg_mock"
+	"mini-evv-logger-backend/src/domains/audit/model"
+	"mini-evv-logger-backend/src/domains/audit/repository"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	dbMock   *sql.DB
+	sqlxMock *sqlx.DB
+	mockSQL  sqlmock.Sqlmock
+	repo     repository.AuditRepository
+)
+
+func initMocks(t *testing.T) {
+	var err error
+	dbMock, mockSQL, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	// Wrap sqlmock in sqlx.DB
+	sqlxMock = sqlx.NewDb(dbMock, "sqlmock")
+	repo = repository.NewAuditRepository(sqlxMock, pkgmock.InitMockLogger())
+}
+
+func TestGetScheduleHistory(t *testing.T) {
+	initMocks(t)
+
+	dummyID, dummyTaskID, dummyActorID := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	query := `SELECT id, schedule_id, entity_type, entity_id, action, actor_id, before, after, ip_address, user_agent, created_at FROM audit_events WHERE schedule_id = $1 ORDER BY id ASC`
+	columns := []string{"id", "schedule_id", "entity_type", "entity_id", "action", "actor_id", "before", "after", "ip_address", "user_agent", "created_at"}
+
+	t.Run("TestGetScheduleHistory: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, dummyID, "schedule", dummyID, "schedule_created", dummyActorID, nil, []byte(`{"status":"upcoming"}`), "10.0.0.1", "Mozilla/5.0", time.Now()).
+				AddRow(2, dummyID, "task", dummyTaskID, "task_status_updated", nil, []byte(`{"status":"pending"}`), []byte(`{"status":"completed"}`), nil, nil, time.Now()))
+
+		events, err := repo.GetScheduleHistory(context.Background(), dummyID)
+		assert.Nil(t, err)
+		assert.Len(t, events, 2)
+		assert.Equal(t, "schedule_created", events[0].Action)
+		assert.Equal(t, dummyActorID, *events[0].ActorID)
+		assert.Nil(t, events[0].Before)
+		assert.JSONEq(t, `{"status":"upcoming"}`, string(events[0].After))
+		assert.Equal(t, dummyTaskID, events[1].EntityID)
+		assert.Nil(t, events[1].ActorID)
+		assert.JSONEq(t, `{"status":"pending"}`, string(events[1].Before))
+	})
+
+	t.Run("TestGetScheduleHistory: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		events, err := repo.GetScheduleHistory(context.Background(), dummyID)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+		assert.Nil(t, events)
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	initMocks(t)
+
+	dummyID := uuid.NewString()
+	query := `SELECT to_jsonb(t) FROM schedules t WHERE t.id = $1 FOR UPDATE`
+
+	t.Run("TestSnapshot: OK", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows([]string{"to_jsonb"}).AddRow([]byte(`{"id":"` + dummyID + `"}`)))
+
+		tx, _ := sqlxMock.Beginx()
+		row, err := repository.Snapshot(context.Background(), tx, "schedules", dummyID)
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"id":"`+dummyID+`"}`, string(row))
+	})
+
+	t.Run("TestSnapshot: Not Found", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows([]string{"to_jsonb"}))
+
+		tx, _ := sqlxMock.Beginx()
+		row, err := repository.Snapshot(context.Background(), tx, "schedules", dummyID)
+		assert.Nil(t, err)
+		assert.Nil(t, row)
+	})
+
+	t.Run("TestSnapshot: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		tx, _ := sqlxMock.Beginx()
+		row, err := repository.Snapshot(context.Background(), tx, "schedules", dummyID)
+		assert.NotNil(t, err)
+		assert.Nil(t, row)
+	})
+}
+
+func TestAppend(t *testing.T) {
+	initMocks(t)
+
+	dummyID, dummyUserID := uuid.NewString(), uuid.NewString()
+	before, after := `{"status":"upcoming"}`, `{"status":"in-progress"}`
+	event := model.Event{ScheduleID: dummyID, EntityType: model.EntitySchedule, EntityID: dummyID, Action: model.ActionVisitStarted, Before: []byte(before), After: []byte(after)}
+	query := `INSERT INTO audit_events (schedule_id,entity_type,entity_id,action,actor_id,before,after,ip_address,user_agent) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`
+
+	t.Run("TestAppend: From Request", func(t *testing.T) {
+		ctx := authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: dummyUserID, Role: authModel.RoleCaregiver})
+		ctx = model.WithRequestInfo(ctx, model.RequestInfo{IPAddress: "10.0.0.1", UserAgent: "Mozilla/5.0"})
+		ipAddress, userAgent := "10.0.0.1", "Mozilla/5.0"
+
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(dummyID, "schedule", dummyID, "visit_started", &dummyUserID, &before, &after, &ipAddress, &userAgent).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		tx, _ := sqlxMock.Beginx()
+		err := repository.Append(ctx, tx, event)
+		assert.Nil(t, err)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestAppend: Background Job", func(t *testing.T) {
+		created := model.Event{ScheduleID: dummyID, EntityType: model.EntitySchedule, EntityID: dummyID, Action: model.ActionScheduleCreated, After: []byte(after)}
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(dummyID, "schedule", dummyID, "schedule_created", nil, nil, &after, nil, nil).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		tx, _ := sqlxMock.Beginx()
+		err := repository.Append(context.Background(), tx, created)
+		assert.Nil(t, err)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestAppend: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		tx, _ := sqlxMock.Beginx()
+		err := repository.Append(context.Background(), tx, event)
+		assert.NotNil(t, err)
+	})
+}
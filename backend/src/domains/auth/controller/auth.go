@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/responses"
+	"mini-evv-logger-backend/src/domains/auth/model"
+	"mini-evv-logger-backend/src/domains/auth/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuthController handles HTTP requests for authentication
+type AuthController struct {
+	svc service.AuthService
+}
+
+// NewAuthController creates a new AuthController
+func NewAuthController(svc service.AuthService) *AuthController {
+	return &AuthController{svc: svc}
+}
+
+// Routes sets up the public API endpoints for authentication
+func (ac *AuthController) Routes(app fiber.Router) {
+	authRoutes := app.Group("/auth")
+	authRoutes.Post("/login", ac.Login)
+	authRoutes.Post("/refresh", ac.Refresh)
+}
+
+// Login handles exchanging credentials for a token pair
+func (ac *AuthController) Login(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var req model.LoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	tokens, err := ac.svc.Login(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, tokens, "Logged in successfully")
+}
+
+// Refresh handles exchanging a refresh token for a new token pair
+func (ac *AuthController) Refresh(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var req model.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	tokens, err := ac.svc.Refresh(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, tokens, "Token refreshed successfully")
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// LoginRequest defines the request body for signing in
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// RefreshTokenRequest defines the request body for exchanging a refresh token
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// TokenResponse holds a freshly issued access/refresh token pair
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // Access token lifetime in seconds
+}
+
+func (r *LoginRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *RefreshTokenRequest) Validate() error {
+	return validator.New().Struct(r)
+}
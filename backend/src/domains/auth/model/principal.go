@@ -0,0 +1,34 @@
+package model
+
+import "context"
+
+// Principal is the authenticated identity attached to a request context
+type Principal struct {
+	UserID      string  `json:"user_id"`
+	Email       string  `json:"email"`
+	Role        Role    `json:"role"`
+	CaregiverID *string `json:"caregiver_id,omitempty"`
+	BranchID    *string `json:"branch_id,omitempty"`
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the given principal
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal stored in ctx, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// ActorID returns the user ID of the principal in ctx, or an empty string for
+// calls that were not made on behalf of a user (e.g. background jobs)
+func ActorID(ctx context.Context) string {
+	if p, ok := PrincipalFromContext(ctx); ok {
+		return p.UserID
+	}
+	return ""
+}
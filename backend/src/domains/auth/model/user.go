@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// User represents an account that can sign in to the API
+type User struct {
+	ID           string    `json:"id" db:"id"`
+	Email        string    `json:"email" db:"email"`
+	PasswordHash string    `json:"-" db:"password_hash"`
+	Role         Role      `json:"role" db:"role"`
+	CaregiverID  *string   `json:"caregiver_id" db:"caregiver_id"` // Set when the account belongs to a caregiver
+	BranchID     *string   `json:"branch_id" db:"branch_id"`       // Branch a coordinator manages
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Role determines which actions a user may perform
+type Role string
+
+const (
+	RoleCaregiver   Role = "caregiver"
+	RoleCoordinator Role = "coordinator"
+	RoleAdmin       Role = "admin"
+)
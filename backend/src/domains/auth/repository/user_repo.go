@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context" // Import context
+	"database/sql"
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/src/domains/auth/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=./user_repo.go -destination=../mocks/repository/user_repo.go -package=mocks
+
+// UserRepository defines the interface for user database operations
+type UserRepository interface {
+	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
+	GetUserByID(ctx context.Context, id string) (*model.User, error)
+}
+
+// userRepositoryImpl implements the UserRepository interface
+type userRepositoryImpl struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+// NewUserRepository creates a new UserRepository (returns interface)
+func NewUserRepository(db *sqlx.DB, logger zerolog.Logger) UserRepository {
+	return &userRepositoryImpl{db: db, logger: logger}
+}
+
+// GetUserByEmail fetches a single user by email address
+func (r *userRepositoryImpl) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	return r.getUser(ctx, squirrel.Eq{"email": email}, "email", email)
+}
+
+// GetUserByID fetches a single user by ID
+func (r *userRepositoryImpl) GetUserByID(ctx context.Context, id string) (*model.User, error) {
+	return r.getUser(ctx, squirrel.Eq{"id": id}, "user_id", id)
+}
+
+// getUser fetches a single user matching the given predicate
+func (r *userRepositoryImpl) getUser(ctx context.Context, where squirrel.Eq, field, value string) (*model.User, error) {
+	var user model.User
+	qb := squirrel.Select("id", "email", "password_hash", "role", "caregiver_id", "branch_id", "created_at", "updated_at").
+		From("users").
+		Where(where).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str(field, value).Msg("Failed to build SQL query for GetUser")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &user, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.Warn().Str(field, value).Msg("User not found in database")
+			return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("User with %s %s not found", field, value))
+		}
+		r.logger.Error().Err(err).Str(field, value).Msg("Failed to execute SQL query for GetUser")
+		return nil, exceptions.ErrInternalError
+	}
+	return &user, nil
+}
@@ -0,0 +1,106 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	pkgmock "mini-evv-logger-backend/pkg_mock"
+	"mini-evv-logger-backend/src/domains/auth/repository"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	dbMock   *sql.DB
+	sqlxMock *sqlx.DB
+	mockSQL  sqlmock.Sqlmock
+	repo     repository.UserRepository
+)
+
+func initMocks(t *testing.T) {
+	var err error
+	dbMock, mockSQL, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	// Wrap sqlmock in sqlx.DB
+	sqlxMock = sqlx.NewDb(dbMock, "sqlmock")
+	repo = repository.NewUserRepository(sqlxMock, pkgmock.InitMockLogger())
+}
+
+var userColumns = []string{"id", "email", "password_hash", "role", "caregiver_id", "branch_id", "created_at", "updated_at"}
+
+func TestGetUserByEmail(t *testing.T) {
+	initMocks(t)
+
+	dummyEmail := "jane@example.com"
+	query := `SELECT id, email, password_hash, role, caregiver_id, branch_id, created_at, updated_at FROM users WHERE email = $1`
+
+	t.Run("TestGetUserByEmail: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyEmail).
+			WillReturnRows(sqlmock.NewRows(userColumns).
+				AddRow(uuid.NewString(), dummyEmail, "hash", "caregiver", nil, nil, time.Now(), time.Now()))
+
+		user, err := repo.GetUserByEmail(context.Background(), dummyEmail)
+		assert.NoError(t, err)
+		assert.Equal(t, dummyEmail, user.Email)
+		assert.Equal(t, "hash", user.PasswordHash)
+	})
+
+	t.Run("TestGetUserByEmail: No Rows", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyEmail).
+			WillReturnError(sql.ErrNoRows)
+
+		user, err := repo.GetUserByEmail(context.Background(), dummyEmail)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "Error 404")
+		assert.Nil(t, user)
+	})
+
+	t.Run("TestGetUserByEmail: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyEmail).
+			WillReturnError(sql.ErrConnDone)
+
+		user, err := repo.GetUserByEmail(context.Background(), dummyEmail)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+		assert.Nil(t, user)
+	})
+}
+
+func TestGetUserByID(t *testing.T) {
+	initMocks(t)
+
+	dummyID := uuid.NewString()
+	query := `SELECT id, email, password_hash, role, caregiver_id, branch_id, created_at, updated_at FROM users WHERE id = $1`
+
+	t.Run("TestGetUserByID: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows(userColumns).
+				AddRow(dummyID, "jane@example.com", "hash", "caregiver", nil, nil, time.Now(), time.Now()))
+
+		user, err := repo.GetUserByID(context.Background(), dummyID)
+		assert.NoError(t, err)
+		assert.Equal(t, dummyID, user.ID)
+	})
+
+	t.Run("TestGetUserByID: No Rows", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnError(sql.ErrNoRows)
+
+		user, err := repo.GetUserByID(context.Background(), dummyID)
+		assert.NotNil(t, err)
+		assert.Nil(t, user)
+	})
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context" // Import context
+	"errors"
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/src/domains/auth/model"
+	"mini-evv-logger-backend/src/domains/auth/repository"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+// tokenClaims are the JWT claims carried by both access and refresh tokens
+type tokenClaims struct {
+	TokenType   string     `json:"typ"`
+	Email       string     `json:"email,omitempty"`
+	Role        model.Role `json:"role,omitempty"`
+	CaregiverID *string    `json:"caregiver_id,omitempty"`
+	BranchID    *string    `json:"branch_id,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// AuthService defines the interface for authentication business logic
+type AuthService interface {
+	Login(ctx context.Context, req model.LoginRequest) (*model.TokenResponse, error)
+	Refresh(ctx context.Context, req model.RefreshTokenRequest) (*model.TokenResponse, error)
+	Authenticate(ctx context.Context, accessToken string) (*model.Principal, error)
+}
+
+// authServiceImpl implements the AuthService interface
+type authServiceImpl struct {
+	repo       repository.UserRepository
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewAuthService creates a new AuthService (returns interface)
+func NewAuthService(repo repository.UserRepository, secret string, accessTTL, refreshTTL time.Duration) AuthService {
+	return &authServiceImpl{repo: repo, secret: []byte(secret), accessTTL: accessTTL, refreshTTL: refreshTTL}
+}
+
+// Login checks the given credentials and issues a new token pair
+func (s *authServiceImpl) Login(ctx context.Context, req model.LoginRequest) (*model.TokenResponse, error) {
+	log.Info().Str("email", req.Email).Msg("Attempting to log in")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for LoginRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if customErr, ok := err.(*exceptions.CustomError); ok && customErr.Code == http.StatusNotFound {
+			// Do not reveal whether the email exists
+			return nil, exceptions.ErrUnauthorized.WithDetails("Invalid email or password")
+		}
+		log.Error().Err(err).Str("email", req.Email).Msg("Failed to fetch user for login")
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		log.Warn().Str("email", req.Email).Msg("Invalid password supplied")
+		return nil, exceptions.ErrUnauthorized.WithDetails("Invalid email or password")
+	}
+
+	return s.issueTokens(user)
+}
+
+// Refresh exchanges a valid refresh token for a new token pair
+func (s *authServiceImpl) Refresh(ctx context.Context, req model.RefreshTokenRequest) (*model.TokenResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for RefreshTokenRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	claims, err := s.parseToken(req.RefreshToken, tokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reload the user so that deleted accounts can no longer refresh
+	user, err := s.repo.GetUserByID(ctx, claims.Subject)
+	if err != nil {
+		if customErr, ok := err.(*exceptions.CustomError); ok && customErr.Code == http.StatusNotFound {
+			return nil, exceptions.ErrUnauthorized.WithDetails("User no longer exists")
+		}
+		log.Error().Err(err).Str("user_id", claims.Subject).Msg("Failed to fetch user for token refresh")
+		return nil, err
+	}
+
+	return s.issueTokens(user)
+}
+
+// Authenticate validates an access token and returns the principal it represents
+func (s *authServiceImpl) Authenticate(ctx context.Context, accessToken string) (*model.Principal, error) {
+	claims, err := s.parseToken(accessToken, tokenTypeAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Principal{
+		UserID:      claims.Subject,
+		Email:       claims.Email,
+		Role:        claims.Role,
+		CaregiverID: claims.CaregiverID,
+		BranchID:    claims.BranchID,
+	}, nil
+}
+
+// issueTokens signs a new access and refresh token for the given user
+func (s *authServiceImpl) issueTokens(user *model.User) (*model.TokenResponse, error) {
+	accessToken, err := s.signToken(user, tokenTypeAccess, s.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := s.signToken(user, tokenTypeRefresh, s.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.accessTTL.Seconds()),
+	}, nil
+}
+
+// signToken creates an HS256 signed token of the given type
+func (s *authServiceImpl) signToken(user *model.User, tokenType string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := tokenClaims{
+		TokenType: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   user.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	// Identity details are only needed on access tokens
+	if tokenType == tokenTypeAccess {
+		claims.Email = user.Email
+		claims.Role = user.Role
+		claims.CaregiverID = user.CaregiverID
+		claims.BranchID = user.BranchID
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", user.ID).Msg("Failed to sign token")
+		return "", exceptions.ErrInternalError
+	}
+	return signed, nil
+}
+
+// parseToken verifies the signature, expiry and type of a token
+func (s *authServiceImpl) parseToken(tokenString, expectedType string) (*tokenClaims, error) {
+	claims := &tokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, exceptions.ErrUnauthorized.WithDetails("Token has expired")
+		}
+		return nil, exceptions.ErrUnauthorized.WithDetails("Invalid token")
+	}
+
+	if claims.TokenType != expectedType {
+		return nil, exceptions.ErrUnauthorized.WithDetails(fmt.Sprintf("Expected %s token", expectedType))
+	}
+	return claims, nil
+}
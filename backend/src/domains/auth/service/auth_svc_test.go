@@ -0,0 +1,167 @@
+package service_test
+
+import (
+	"context"
+	"mini-evv-logger-backend/exceptions"
+	mocks "mini-evv-logger-backend/src/domains/auth/mocks/repository"
+	"mini-evv-logger-backend/src/domains/auth/model"
+	"mini-evv-logger-backend/src/domains/auth/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	mockUserRepo *mocks.MockUserRepository
+	ctrl         *gomock.Controller
+	svc          service.AuthService
+)
+
+const dummySecret = "test-secret"
+
+func initMocks(t *testing.T) {
+	ctrl = gomock.NewController(t)
+
+	mockUserRepo = mocks.NewMockUserRepository(ctrl)
+
+	svc = service.NewAuthService(mockUserRepo, dummySecret, 15*time.Minute, time.Hour)
+}
+
+func dummyUser(t *testing.T, password string) *model.User {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	caregiverID := uuid.NewString()
+	return &model.User{
+		ID:           uuid.NewString(),
+		Email:        "jane@example.com",
+		PasswordHash: string(hash),
+		Role:         model.RoleCaregiver,
+		CaregiverID:  &caregiverID,
+	}
+}
+
+func TestLogin(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	user := dummyUser(t, "password123")
+
+	t.Run("TestLogin: OK", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Return(user, nil).Times(1)
+
+		tokens, err := svc.Login(context.Background(), model.LoginRequest{Email: user.Email, Password: "password123"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.NotEmpty(t, tokens.RefreshToken)
+		assert.Equal(t, "Bearer", tokens.TokenType)
+		assert.Equal(t, 900, tokens.ExpiresIn)
+	})
+
+	t.Run("TestLogin: Wrong Password", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Return(user, nil).Times(1)
+
+		tokens, err := svc.Login(context.Background(), model.LoginRequest{Email: user.Email, Password: "wrong"})
+		assert.Error(t, err)
+		assert.Nil(t, tokens)
+		assert.Equal(t, exceptions.ErrUnauthorized.WithDetails("Invalid email or password").Error(), err.Error())
+	})
+
+	t.Run("TestLogin: Unknown Email", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetUserByEmail(gomock.Any(), "nobody@example.com").Return(nil, exceptions.ErrNotFound).Times(1)
+
+		tokens, err := svc.Login(context.Background(), model.LoginRequest{Email: "nobody@example.com", Password: "password123"})
+		assert.Error(t, err)
+		assert.Nil(t, tokens)
+		assert.Equal(t, exceptions.ErrUnauthorized.WithDetails("Invalid email or password").Error(), err.Error())
+	})
+
+	t.Run("TestLogin: Validation Error", func(t *testing.T) {
+		tokens, err := svc.Login(context.Background(), model.LoginRequest{Email: "not-an-email"})
+		assert.Error(t, err)
+		assert.Nil(t, tokens)
+	})
+
+	t.Run("TestLogin: Repository Error", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Return(nil, assert.AnError).Times(1)
+
+		tokens, err := svc.Login(context.Background(), model.LoginRequest{Email: user.Email, Password: "password123"})
+		assert.Error(t, err)
+		assert.Nil(t, tokens)
+	})
+}
+
+func TestRefreshAndAuthenticate(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	user := dummyUser(t, "password123")
+	mockUserRepo.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Return(user, nil).Times(1)
+	tokens, err := svc.Login(context.Background(), model.LoginRequest{Email: user.Email, Password: "password123"})
+	assert.NoError(t, err)
+
+	t.Run("TestAuthenticate: OK", func(t *testing.T) {
+		principal, err := svc.Authenticate(context.Background(), tokens.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, principal.UserID)
+		assert.Equal(t, user.Email, principal.Email)
+		assert.Equal(t, model.RoleCaregiver, principal.Role)
+		assert.Equal(t, *user.CaregiverID, *principal.CaregiverID)
+	})
+
+	t.Run("TestAuthenticate: Refresh Token Rejected", func(t *testing.T) {
+		principal, err := svc.Authenticate(context.Background(), tokens.RefreshToken)
+		assert.Error(t, err)
+		assert.Nil(t, principal)
+	})
+
+	t.Run("TestAuthenticate: Wrong Signature", func(t *testing.T) {
+		other := service.NewAuthService(mockUserRepo, "another-secret", time.Minute, time.Minute)
+		principal, err := other.Authenticate(context.Background(), tokens.AccessToken)
+		assert.Error(t, err)
+		assert.Nil(t, principal)
+		assert.Equal(t, exceptions.ErrUnauthorized.WithDetails("Invalid token").Error(), err.Error())
+	})
+
+	t.Run("TestAuthenticate: Expired Token", func(t *testing.T) {
+		expiring := service.NewAuthService(mockUserRepo, dummySecret, -time.Minute, time.Minute)
+		mockUserRepo.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Return(user, nil).Times(1)
+		expired, err := expiring.Login(context.Background(), model.LoginRequest{Email: user.Email, Password: "password123"})
+		assert.NoError(t, err)
+
+		principal, err := svc.Authenticate(context.Background(), expired.AccessToken)
+		assert.Error(t, err)
+		assert.Nil(t, principal)
+		assert.Equal(t, exceptions.ErrUnauthorized.WithDetails("Token has expired").Error(), err.Error())
+	})
+
+	t.Run("TestRefresh: OK", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+
+		refreshed, err := svc.Refresh(context.Background(), model.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, refreshed.AccessToken)
+	})
+
+	t.Run("TestRefresh: Access Token Rejected", func(t *testing.T) {
+		refreshed, err := svc.Refresh(context.Background(), model.RefreshTokenRequest{RefreshToken: tokens.AccessToken})
+		assert.Error(t, err)
+		assert.Nil(t, refreshed)
+	})
+
+	t.Run("TestRefresh: User Deleted", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(nil, exceptions.ErrNotFound).Times(1)
+
+		refreshed, err := svc.Refresh(context.Background(), model.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+		assert.Error(t, err)
+		assert.Nil(t, refreshed)
+		assert.Equal(t, exceptions.ErrUnauthorized.WithDetails("User no longer exists").Error(), err.Error())
+	})
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/policy"
+	"mini-evv-logger-backend/responses"
+	"mini-evv-logger-backend/src/domains/caregiver/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CaregiverController handles HTTP requests for caregivers
+type CaregiverController struct {
+	svc service.CaregiverService
+}
+
+// NewCaregiverController creates a new CaregiverController
+func NewCaregiverController(svc service.CaregiverService) *CaregiverController {
+	return &CaregiverController{svc: svc}
+}
+
+// Routes sets up the API endpoints for caregivers
+func (cc *CaregiverController) Routes(app fiber.Router) {
+	caregiverRoutes := app.Group("/caregivers")
+	caregiverRoutes.Get("/", policy.Require(policy.ViewCaregivers, nil), cc.GetCaregivers)
+	caregiverRoutes.Get("/:id", policy.Require(policy.ViewCaregivers, cc.caregiverResource), cc.GetCaregiverDetails)
+}
+
+// caregiverResource resolves the caregiver in the :id route parameter
+func (cc *CaregiverController) caregiverResource(c *fiber.Ctx) (*policy.Resource, error) {
+	caregiver, err := cc.svc.GetCaregiverByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return nil, err
+	}
+	return &policy.Resource{CaregiverID: &caregiver.ID, BranchID: caregiver.BranchID}, nil
+}
+
+// GetCaregivers handles fetching all caregivers
+func (cc *CaregiverController) GetCaregivers(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	caregivers, err := cc.svc.GetAllCaregivers(ctx)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, caregivers, "Caregivers retrieved successfully")
+}
+
+// GetCaregiverDetails handles fetching a single caregiver's details
+func (cc *CaregiverController) GetCaregiverDetails(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Caregiver ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	caregiver, err := cc.svc.GetCaregiverByID(ctx, id)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, caregiver, "Caregiver details retrieved successfully")
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// Caregiver represents a staff member who performs visits
+type Caregiver struct {
+	ID        string    `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Email     string    `json:"email" db:"email"`
+	Phone     *string   `json:"phone" db:"phone"`         // Pointer to allow NULL
+	BranchID  *string   `json:"branch_id" db:"branch_id"` // Branch the caregiver works for
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
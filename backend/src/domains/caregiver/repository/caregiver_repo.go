@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context" // Import context
+	"database/sql"
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/src/domains/caregiver/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=./caregiver_repo.go -destination=../mocks/repository/caregiver_repo.go -package=mocks
+
+// CaregiverRepository defines the interface for caregiver database operations
+type CaregiverRepository interface {
+	GetCaregivers(ctx context.Context, branchID *string) ([]model.Caregiver, error)
+	GetCaregiverByID(ctx context.Context, id string) (*model.Caregiver, error)
+}
+
+// caregiverRepositoryImpl implements the CaregiverRepository interface
+type caregiverRepositoryImpl struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+// NewCaregiverRepository creates a new CaregiverRepository (returns interface)
+func NewCaregiverRepository(db *sqlx.DB, logger zerolog.Logger) CaregiverRepository {
+	return &caregiverRepositoryImpl{db: db, logger: logger}
+}
+
+// GetCaregivers fetches all caregivers ordered by name, optionally limited to one branch
+func (r *caregiverRepositoryImpl) GetCaregivers(ctx context.Context, branchID *string) ([]model.Caregiver, error) {
+	var caregivers []model.Caregiver
+	qb := squirrel.Select("id", "name", "email", "phone", "branch_id", "created_at", "updated_at").
+		From("caregivers").
+		OrderBy("name ASC").
+		PlaceholderFormat(squirrel.Dollar)
+
+	if branchID != nil {
+		qb = qb.Where(squirrel.Eq{"branch_id": *branchID})
+	}
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to build SQL query for GetCaregivers")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.SelectContext(ctx, &caregivers, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return []model.Caregiver{}, nil
+		}
+		r.logger.Error().Err(err).Msg("Failed to execute SQL query for GetCaregivers")
+		return nil, exceptions.ErrInternalError
+	}
+	return caregivers, nil
+}
+
+// GetCaregiverByID fetches a single caregiver by ID
+func (r *caregiverRepositoryImpl) GetCaregiverByID(ctx context.Context, id string) (*model.Caregiver, error) {
+	var caregiver model.Caregiver
+	qb := squirrel.Select("id", "name", "email", "phone", "branch_id", "created_at", "updated_at").
+		From("caregivers").
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("caregiver_id", id).Msg("Failed to build SQL query for GetCaregiverByID")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &caregiver, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.Warn().Str("caregiver_id", id).Msg("Caregiver not found in database")
+			return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Caregiver with ID %s not found", id))
+		}
+		r.logger.Error().Err(err).Str("caregiver_id", id).Msg("Failed to execute SQL query for GetCaregiverByID")
+		return nil, exceptions.ErrInternalError
+	}
+	return &caregiver, nil
+}
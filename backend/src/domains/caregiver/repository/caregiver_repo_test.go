@@ -0,0 +1,116 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	pkgmock "mini-evv-logger-backend/pkg_mock"
+	"mini-evv-logger-backend/src/domains/caregiver/repository"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	dbMock   *sql.DB
+	sqlxMock *sqlx.DB
+	mockSQL  sqlmock.Sqlmock
+	repo     repository.CaregiverRepository
+)
+
+func initMocks(t *testing.T) {
+	var err error
+	dbMock, mockSQL, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	// Wrap sqlmock in sqlx.DB
+	sqlxMock = sqlx.NewDb(dbMock, "sqlmock")
+	repo = repository.NewCaregiverRepository(sqlxMock, pkgmock.InitMockLogger())
+}
+
+func TestGetCaregivers(t *testing.T) {
+	initMocks(t)
+
+	query := `SELECT id, name, email, phone, branch_id, created_at, updated_at FROM caregivers ORDER BY name ASC`
+	columns := []string{"id", "name", "email", "phone", "branch_id", "created_at", "updated_at"}
+
+	t.Run("TestGetCaregivers: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(uuid.NewString(), "Jane Doe", "jane@example.com", nil, nil, time.Now(), time.Now()).
+				AddRow(uuid.NewString(), "John Roe", "john@example.com", "555-0100", nil, time.Now(), time.Now()))
+
+		caregivers, err := repo.GetCaregivers(context.Background(), nil)
+		assert.NoError(t, err)
+		assert.Len(t, caregivers, 2)
+	})
+
+	t.Run("TestGetCaregivers: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		caregivers, err := repo.GetCaregivers(context.Background(), nil)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+		assert.Nil(t, caregivers)
+	})
+
+	t.Run("TestGetCaregivers: Branch Filter", func(t *testing.T) {
+		dummyBranchID := uuid.NewString()
+		mockSQL.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, email, phone, branch_id, created_at, updated_at FROM caregivers WHERE branch_id = $1 ORDER BY name ASC`)).
+			WithArgs(dummyBranchID).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(uuid.NewString(), "Jane Doe", "jane@example.com", nil, dummyBranchID, time.Now(), time.Now()))
+
+		caregivers, err := repo.GetCaregivers(context.Background(), &dummyBranchID)
+		assert.NoError(t, err)
+		assert.Len(t, caregivers, 1)
+		assert.Equal(t, dummyBranchID, *caregivers[0].BranchID)
+	})
+}
+
+func TestGetCaregiverByID(t *testing.T) {
+	initMocks(t)
+
+	dummyID := uuid.NewString()
+	query := `SELECT id, name, email, phone, branch_id, created_at, updated_at FROM caregivers WHERE id = $1`
+	columns := []string{"id", "name", "email", "phone", "branch_id", "created_at", "updated_at"}
+
+	t.Run("TestGetCaregiverByID: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(dummyID, "Jane Doe", "jane@example.com", nil, nil, time.Now(), time.Now()))
+
+		caregiver, err := repo.GetCaregiverByID(context.Background(), dummyID)
+		assert.NoError(t, err)
+		assert.Equal(t, dummyID, caregiver.ID)
+	})
+
+	t.Run("TestGetCaregiverByID: No Rows", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnError(sql.ErrNoRows)
+
+		caregiver, err := repo.GetCaregiverByID(context.Background(), dummyID)
+		assert.NotNil(t, err)
+		assert.Nil(t, caregiver)
+	})
+
+	t.Run("TestGetCaregiverByID: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnError(sql.ErrConnDone)
+
+		caregiver, err := repo.GetCaregiverByID(context.Background(), dummyID)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+		assert.Nil(t, caregiver)
+	})
+}
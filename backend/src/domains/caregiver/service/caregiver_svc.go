@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context" // Import context
+	"mini-evv-logger-backend/exceptions"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	"mini-evv-logger-backend/src/domains/caregiver/model"
+	"mini-evv-logger-backend/src/domains/caregiver/repository"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// CaregiverService defines the interface for caregiver business logic
+type CaregiverService interface {
+	GetAllCaregivers(ctx context.Context) ([]model.Caregiver, error)
+	GetCaregiverByID(ctx context.Context, id string) (*model.Caregiver, error)
+}
+
+// caregiverServiceImpl implements the CaregiverService interface
+type caregiverServiceImpl struct {
+	repo repository.CaregiverRepository
+}
+
+// NewCaregiverService creates a new CaregiverService (returns interface)
+func NewCaregiverService(repo repository.CaregiverRepository) CaregiverService {
+	return &caregiverServiceImpl{repo: repo}
+}
+
+// GetAllCaregivers fetches all caregivers
+func (s *caregiverServiceImpl) GetAllCaregivers(ctx context.Context) ([]model.Caregiver, error) {
+	log.Info().Msg("Fetching all caregivers")
+
+	// Coordinators only see the caregivers of their own branch
+	var branchID *string
+	if principal, ok := authModel.PrincipalFromContext(ctx); ok && principal.Role == authModel.RoleCoordinator {
+		if principal.BranchID == nil {
+			return nil, exceptions.ErrForbidden.WithDetails("Coordinator is not assigned to a branch")
+		}
+		branchID = principal.BranchID
+	}
+
+	caregivers, err := s.repo.GetCaregivers(ctx, branchID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch caregivers from repository")
+		return nil, err
+	}
+	return caregivers, nil
+}
+
+// GetCaregiverByID fetches a caregiver by its ID
+func (s *caregiverServiceImpl) GetCaregiverByID(ctx context.Context, id string) (*model.Caregiver, error) {
+	log.Info().Str("caregiver_id", id).Msg("Fetching caregiver by ID")
+
+	_, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Str("caregiver_id", id).Msg("Invalid UUID format for caregiver ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid caregiver ID format")
+	}
+
+	caregiver, err := s.repo.GetCaregiverByID(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("caregiver_id", id).Msg("Failed to fetch caregiver by ID from repository")
+		return nil, err
+	}
+	return caregiver, nil
+}
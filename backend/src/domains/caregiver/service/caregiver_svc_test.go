@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"context"
+	"mini-evv-logger-backend/exceptions"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	mocks "mini-evv-logger-backend/src/domains/caregiver/mocks/repository"
+	"mini-evv-logger-backend/src/domains/caregiver/model"
+	"mini-evv-logger-backend/src/domains/caregiver/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	mockCaregiverRepo *mocks.MockCaregiverRepository
+	ctrl              *gomock.Controller
+	svc               service.CaregiverService
+)
+
+func initMocks(t *testing.T) {
+	ctrl = gomock.NewController(t)
+
+	mockCaregiverRepo = mocks.NewMockCaregiverRepository(ctrl)
+
+	svc = service.NewCaregiverService(mockCaregiverRepo)
+}
+
+func TestGetAllCaregivers(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	t.Run("TestGetAllCaregivers: OK", func(t *testing.T) {
+		mockCaregiverRepo.EXPECT().GetCaregivers(gomock.Any(), nil).Return([]model.Caregiver{{ID: uuid.NewString()}}, nil).Times(1)
+
+		caregivers, err := svc.GetAllCaregivers(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, caregivers, 1)
+	})
+
+	t.Run("TestGetAllCaregivers: Repository Error", func(t *testing.T) {
+		mockCaregiverRepo.EXPECT().GetCaregivers(gomock.Any(), nil).Return(nil, assert.AnError).Times(1)
+
+		caregivers, err := svc.GetAllCaregivers(context.Background())
+		assert.Error(t, err)
+		assert.Nil(t, caregivers)
+	})
+
+	t.Run("TestGetAllCaregivers: Scoped To Coordinator Branch", func(t *testing.T) {
+		branchID := uuid.NewString()
+		ctx := authModel.WithPrincipal(context.Background(), &authModel.Principal{Role: authModel.RoleCoordinator, BranchID: &branchID})
+		mockCaregiverRepo.EXPECT().GetCaregivers(gomock.Any(), &branchID).Return([]model.Caregiver{}, nil).Times(1)
+
+		_, err := svc.GetAllCaregivers(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestGetAllCaregivers: Coordinator Without Branch", func(t *testing.T) {
+		ctx := authModel.WithPrincipal(context.Background(), &authModel.Principal{Role: authModel.RoleCoordinator})
+
+		caregivers, err := svc.GetAllCaregivers(ctx)
+		assert.Error(t, err)
+		assert.Nil(t, caregivers)
+	})
+}
+
+func TestGetCaregiverByID(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyID := uuid.NewString()
+
+	t.Run("TestGetCaregiverByID: OK", func(t *testing.T) {
+		mockCaregiverRepo.EXPECT().GetCaregiverByID(gomock.Any(), dummyID).Return(&model.Caregiver{ID: dummyID}, nil).Times(1)
+
+		caregiver, err := svc.GetCaregiverByID(context.Background(), dummyID)
+		assert.NoError(t, err)
+		assert.Equal(t, dummyID, caregiver.ID)
+	})
+
+	t.Run("TestGetCaregiverByID: Not Found", func(t *testing.T) {
+		mockCaregiverRepo.EXPECT().GetCaregiverByID(gomock.Any(), dummyID).Return(nil, exceptions.ErrNotFound).Times(1)
+
+		caregiver, err := svc.GetCaregiverByID(context.Background(), dummyID)
+		assert.Error(t, err)
+		assert.Nil(t, caregiver)
+	})
+
+	t.Run("TestGetCaregiverByID: Invalid UUID", func(t *testing.T) {
+		caregiver, err := svc.GetCaregiverByID(context.Background(), "invalid-uuid")
+		assert.Error(t, err)
+		assert.Nil(t, caregiver)
+		assert.Equal(t, exceptions.ErrBadRequest.WithDetails("Invalid caregiver ID format").Error(), err.Error())
+	})
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/policy"
+	"mini-evv-logger-backend/responses"
+	"mini-evv-logger-backend/src/domains/careplan/model"
+	"mini-evv-logger-backend/src/domains/careplan/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CarePlanController handles HTTP requests for client care plans
+type CarePlanController struct {
+	svc service.CarePlanService
+}
+
+// NewCarePlanController creates a new CarePlanController
+func NewCarePlanController(svc service.CarePlanService) *CarePlanController {
+	return &CarePlanController{svc: svc}
+}
+
+// Routes sets up the API endpoints for care plans
+func (cc *CarePlanController) Routes(app fiber.Router) {
+	carePlanRoutes := app.Group("/clients/:id/care-plans")
+	carePlanRoutes.Get("/", policy.Require(policy.ViewClients, cc.clientResource), cc.GetCarePlans)
+	carePlanRoutes.Post("/", policy.Require(policy.ManageClients, cc.clientResource), cc.CreateCarePlan)
+	carePlanRoutes.Get("/active", policy.Require(policy.ViewClients, cc.clientResource), cc.GetActiveCarePlan)
+	carePlanRoutes.Get("/:version", policy.Require(policy.ViewClients, cc.clientResource), cc.GetCarePlanVersion)
+}
+
+// clientResource resolves the branches serving the client in the :id route parameter
+func (cc *CarePlanController) clientResource(c *fiber.Ctx) (*policy.Resource, error) {
+	ownership, err := cc.svc.GetClientOwnership(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return nil, err
+	}
+	return &policy.Resource{BranchIDs: ownership.BranchIDs}, nil
+}
+
+// GetCarePlans handles fetching every version of a client's care plan
+func (cc *CarePlanController) GetCarePlans(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Client ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	plans, err := cc.svc.GetCarePlans(ctx, id)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, plans, "Care plans retrieved successfully")
+}
+
+// GetActiveCarePlan handles fetching the care plan applied to new visits
+func (cc *CarePlanController) GetActiveCarePlan(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Client ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	plan, err := cc.svc.GetActiveCarePlan(ctx, id)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, plan, "Care plan retrieved successfully")
+}
+
+// GetCarePlanVersion handles fetching one version of a client's care plan
+func (cc *CarePlanController) GetCarePlanVersion(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Client ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	version, err := c.ParamsInt("version")
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid care plan version", err.Error())
+	}
+
+	plan, err := cc.svc.GetCarePlanByVersion(ctx, id, version)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, plan, "Care plan retrieved successfully")
+}
+
+// CreateCarePlan handles creating a new version of a client's care plan
+func (cc *CarePlanController) CreateCarePlan(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Client ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	var req model.CreateCarePlanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	req.ClientID = id // Set the ID from the URL parameter
+
+	plan, err := cc.svc.CreateCarePlan(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.Created(c, plan, "Care plan created successfully")
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+)
+
+// Task frequencies of a care plan
+const (
+	FrequencyEveryVisit = "every_visit" // Added to every visit
+	FrequencyWeekly     = "weekly"      // Added to visits on the listed weekdays
+)
+
+// weekdays maps time.Weekday to the day codes used in Days, the same as RRULE's BYDAY
+var weekdays = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// CarePlan is one version of the tasks a client needs during their visits.
+// Plans are never edited; a new version replaces the previous one.
+type CarePlan struct {
+	ID        string         `json:"id" db:"id"`
+	ClientID  string         `json:"client_id" db:"client_id"`
+	Version   int            `json:"version" db:"version"`       // 1 for the first plan of a client, the highest version is active
+	CreatedBy *string        `json:"created_by" db:"created_by"` // User who created this version, NULL for internal calls
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+	Tasks     []CarePlanTask `json:"tasks,omitempty" db:"-"`
+}
+
+// CarePlanTask is a task template of a care plan
+type CarePlanTask struct {
+	ID          string         `json:"id" db:"id"`
+	CarePlanID  string         `json:"care_plan_id" db:"care_plan_id"`
+	Description string         `json:"description" db:"description"`
+	Frequency   string         `json:"frequency" db:"frequency"` // "every_visit" or "weekly"
+	Days        pq.StringArray `json:"days" db:"days"`           // Weekly tasks only, e.g. ["MO", "WE", "FR"]
+	Position    int            `json:"position" db:"position"`
+}
+
+// TasksFor returns the descriptions of the tasks due on a visit at t, in plan order.
+// t must be in the client's timezone so that the weekday matches the client's calendar.
+func (p *CarePlan) TasksFor(t time.Time) []string {
+	day := weekdays[t.Weekday()]
+
+	var descriptions []string
+	for _, task := range p.Tasks {
+		if task.Frequency == FrequencyWeekly && !contains(task.Days, day) {
+			continue
+		}
+		descriptions = append(descriptions, task.Description)
+	}
+	return descriptions
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateCarePlanRequest defines the request body for creating a new care plan version
+type CreateCarePlanRequest struct {
+	ClientID string                      `json:"-" validate:"required,uuid"`
+	Tasks    []CreateCarePlanTaskRequest `json:"tasks" validate:"required,min=1,max=50,dive"`
+}
+
+// CreateCarePlanTaskRequest defines a task template of a new care plan
+type CreateCarePlanTaskRequest struct {
+	Description string   `json:"description" validate:"required,max=1000"`
+	Frequency   string   `json:"frequency" validate:"required,oneof=every_visit weekly"`
+	Days        []string `json:"days" validate:"required_if=Frequency weekly,excluded_unless=Frequency weekly,unique,dive,oneof=MO TU WE TH FR SA SU"`
+}
+
+func (r *CreateCarePlanRequest) Validate() error {
+	err := validator.New().Struct(r)
+	if err != nil {
+		return err
+	}
+	for i, task := range r.Tasks {
+		if task.Frequency == FrequencyWeekly && len(task.Days) == 0 {
+			return fmt.Errorf("tasks[%d]: weekly tasks need at least one day", i)
+		}
+	}
+	return nil
+}
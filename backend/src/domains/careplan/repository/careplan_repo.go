@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context" // Import context
+	"database/sql"
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/src/domains/careplan/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=./careplan_repo.go -destination=../mocks/repository/careplan_repo.go -package=mocks
+
+var carePlanColumns = []string{"id", "client_id", "version", "created_by", "created_at"}
+
+// CarePlanRepository defines the interface for care plan database operations
+type CarePlanRepository interface {
+	GetCarePlans(ctx context.Context, clientID string) ([]model.CarePlan, error)
+	GetCarePlanByVersion(ctx context.Context, clientID string, version int) (*model.CarePlan, error)
+	GetActiveCarePlan(ctx context.Context, clientID string) (*model.CarePlan, error)
+	CreateCarePlan(ctx context.Context, plan model.CarePlan) (int, error)
+}
+
+// carePlanRepositoryImpl implements the CarePlanRepository interface
+type carePlanRepositoryImpl struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+// NewCarePlanRepository creates a new CarePlanRepository (returns interface)
+func NewCarePlanRepository(db *sqlx.DB, logger zerolog.Logger) CarePlanRepository {
+	return &carePlanRepositoryImpl{db: db, logger: logger}
+}
+
+// GetCarePlans fetches every version of a client's care plan, newest first, without their tasks
+func (r *carePlanRepositoryImpl) GetCarePlans(ctx context.Context, clientID string) ([]model.CarePlan, error) {
+	var plans []model.CarePlan
+	qb := squirrel.Select(carePlanColumns...).
+		From("care_plans").
+		Where(squirrel.Eq{"client_id": clientID}).
+		OrderBy("version DESC").
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("client_id", clientID).Msg("Failed to build SQL query for GetCarePlans")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.SelectContext(ctx, &plans, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return []model.CarePlan{}, nil
+		}
+		r.logger.Error().Err(err).Str("client_id", clientID).Msg("Failed to execute SQL query for GetCarePlans")
+		return nil, exceptions.ErrInternalError
+	}
+	return plans, nil
+}
+
+// GetCarePlanByVersion fetches one version of a client's care plan with its tasks
+func (r *carePlanRepositoryImpl) GetCarePlanByVersion(ctx context.Context, clientID string, version int) (*model.CarePlan, error) {
+	qb := squirrel.Select(carePlanColumns...).
+		From("care_plans").
+		Where(squirrel.Eq{"client_id": clientID, "version": version})
+
+	plan, err := r.getCarePlan(ctx, qb, "GetCarePlanByVersion")
+	if err == sql.ErrNoRows {
+		r.logger.Warn().Str("client_id", clientID).Int("version", version).Msg("Care plan not found in database")
+		return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Care plan version %d for client ID %s not found", version, clientID))
+	}
+	return plan, err
+}
+
+// GetActiveCarePlan fetches the latest version of a client's care plan with its tasks
+func (r *carePlanRepositoryImpl) GetActiveCarePlan(ctx context.Context, clientID string) (*model.CarePlan, error) {
+	qb := squirrel.Select(carePlanColumns...).
+		From("care_plans").
+		Where(squirrel.Eq{"client_id": clientID}).
+		OrderBy("version DESC").
+		Limit(1)
+
+	plan, err := r.getCarePlan(ctx, qb, "GetActiveCarePlan")
+	if err == sql.ErrNoRows {
+		return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Client ID %s has no care plan", clientID))
+	}
+	return plan, err
+}
+
+// getCarePlan runs a query for a single plan and loads its tasks.
+// It returns sql.ErrNoRows as is so that callers can describe the missing plan.
+func (r *carePlanRepositoryImpl) getCarePlan(ctx context.Context, qb squirrel.SelectBuilder, method string) (*model.CarePlan, error) {
+	var plan model.CarePlan
+	sqlQuery, args, err := qb.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Msgf("Failed to build SQL query for %s", method)
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &plan, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		r.logger.Error().Err(err).Msgf("Failed to execute SQL query for %s", method)
+		return nil, exceptions.ErrInternalError
+	}
+
+	sqlQuery, args, err = squirrel.Select("id", "care_plan_id", "description", "frequency", "days", "position").
+		From("care_plan_tasks").
+		Where(squirrel.Eq{"care_plan_id": plan.ID}).
+		OrderBy("position ASC").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("care_plan_id", plan.ID).Msgf("Failed to build SQL query for %s", method)
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.SelectContext(ctx, &plan.Tasks, sqlQuery, args...)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error().Err(err).Str("care_plan_id", plan.ID).Msgf("Failed to execute SQL query for %s", method)
+		return nil, exceptions.ErrInternalError
+	}
+	return &plan, nil
+}
+
+// CreateCarePlan inserts the next version of a client's care plan with its tasks, in one transaction.
+// It returns the version assigned to the plan.
+func (r *carePlanRepositoryImpl) CreateCarePlan(ctx context.Context, plan model.CarePlan) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.Error().Err(err).Str("care_plan_id", plan.ID).Msg("Failed to begin transaction for CreateCarePlan")
+		return 0, exceptions.ErrInternalError
+	}
+	defer tx.Rollback() // No-op once committed
+
+	// 1. Insert the plan as the next version of the client's plan
+	sqlQuery, args, err := squirrel.Insert("care_plans").
+		Columns("id", "client_id", "version", "created_by").
+		Values(plan.ID, plan.ClientID, squirrel.Expr("(SELECT COALESCE(MAX(version), 0) + 1 FROM care_plans WHERE client_id = ?)", plan.ClientID), plan.CreatedBy).
+		Suffix("RETURNING version").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("care_plan_id", plan.ID).Msg("Failed to build SQL query for CreateCarePlan")
+		return 0, exceptions.ErrInternalError
+	}
+
+	var version int
+	err = tx.QueryRowxContext(ctx, sqlQuery, args...).Scan(&version)
+	if err != nil {
+		r.logger.Error().Err(err).Str("care_plan_id", plan.ID).Msg("Failed to execute SQL query for CreateCarePlan")
+		return 0, exceptions.ErrInternalError
+	}
+
+	// 2. Insert the task templates in plan order
+	qb := squirrel.Insert("care_plan_tasks").
+		Columns("care_plan_id", "description", "frequency", "days", "position").
+		PlaceholderFormat(squirrel.Dollar)
+	for i, task := range plan.Tasks {
+		qb = qb.Values(plan.ID, task.Description, task.Frequency, task.Days, i+1)
+	}
+	sqlQuery, args, err = qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("care_plan_id", plan.ID).Msg("Failed to build SQL query for CreateCarePlan")
+		return 0, exceptions.ErrInternalError
+	}
+	_, err = tx.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("care_plan_id", plan.ID).Msg("Failed to execute SQL query for CreateCarePlan")
+		return 0, exceptions.ErrInternalError
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error().Err(err).Str("care_plan_id", plan.ID).Msg("Failed to commit transaction for CreateCarePlan")
+		return 0, exceptions.ErrInternalError
+	}
+	return version, nil
+}
@@ -0,0 +1,170 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	pkgmock "mini-evv-logger-backend/pkg_mock"
+	"mini-evv-logger-backend/src/domains/careplan/model"
+	"mini-evv-logger-backend/src/domains/careplan/repository"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	dbMock   *sql.DB
+	sqlxMock *sqlx.DB
+	mockSQL  sqlmock.Sqlmock
+	repo     repository.CarePlanRepository
+)
+
+func initMocks(t *testing.T) {
+	var err error
+	dbMock, mockSQL, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	// Wrap sqlmock in sqlx.DB
+	sqlxMock = sqlx.NewDb(dbMock, "sqlmock")
+	repo = repository.NewCarePlanRepository(sqlxMock, pkgmock.InitMockLogger())
+}
+
+func TestGetCarePlans(t *testing.T) {
+	initMocks(t)
+
+	dummyClientID := uuid.NewString()
+	query := `SELECT id, client_id, version, created_by, created_at FROM care_plans WHERE client_id = $1 ORDER BY version DESC`
+
+	t.Run("TestGetCarePlans: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyClientID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "client_id", "version", "created_by", "created_at"}).
+				AddRow(uuid.NewString(), dummyClientID, 2, nil, time.Now()).
+				AddRow(uuid.NewString(), dummyClientID, 1, nil, time.Now()))
+
+		plans, err := repo.GetCarePlans(context.Background(), dummyClientID)
+		assert.Nil(t, err)
+		assert.Len(t, plans, 2)
+		assert.Equal(t, 2, plans[0].Version)
+	})
+
+	t.Run("TestGetCarePlans: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		plans, err := repo.GetCarePlans(context.Background(), dummyClientID)
+		assert.Nil(t, plans)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+	})
+}
+
+func TestGetActiveCarePlan(t *testing.T) {
+	initMocks(t)
+
+	dummyID, dummyClientID := uuid.NewString(), uuid.NewString()
+	query := `SELECT id, client_id, version, created_by, created_at FROM care_plans WHERE client_id = $1 ORDER BY version DESC LIMIT 1`
+	taskQuery := `SELECT id, care_plan_id, description, frequency, days, position FROM care_plan_tasks WHERE care_plan_id = $1 ORDER BY position ASC`
+
+	t.Run("TestGetActiveCarePlan: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyClientID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "client_id", "version", "created_by", "created_at"}).
+				AddRow(dummyID, dummyClientID, 3, nil, time.Now()))
+		mockSQL.ExpectQuery(regexp.QuoteMeta(taskQuery)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "care_plan_id", "description", "frequency", "days", "position"}).
+				AddRow(uuid.NewString(), dummyID, "Medication reminder", "every_visit", nil, 1).
+				AddRow(uuid.NewString(), dummyID, "Laundry", "weekly", "{MO,TH}", 2))
+
+		plan, err := repo.GetActiveCarePlan(context.Background(), dummyClientID)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, plan.Version)
+		assert.Len(t, plan.Tasks, 2)
+		assert.Equal(t, pq.StringArray{"MO", "TH"}, plan.Tasks[1].Days)
+	})
+
+	t.Run("TestGetActiveCarePlan: No Rows", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyClientID).
+			WillReturnError(sql.ErrNoRows)
+
+		plan, err := repo.GetActiveCarePlan(context.Background(), dummyClientID)
+		assert.Nil(t, plan)
+		assert.Equal(t, "Error 404: Resource not found - Client ID "+dummyClientID+" has no care plan", err.Error())
+	})
+
+	t.Run("TestGetActiveCarePlan: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyClientID).
+			WillReturnError(sql.ErrConnDone)
+
+		plan, err := repo.GetActiveCarePlan(context.Background(), dummyClientID)
+		assert.Nil(t, plan)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+	})
+}
+
+func TestGetCarePlanByVersion(t *testing.T) {
+	initMocks(t)
+
+	dummyClientID := uuid.NewString()
+	query := `SELECT id, client_id, version, created_by, created_at FROM care_plans WHERE client_id = $1 AND version = $2`
+
+	t.Run("TestGetCarePlanByVersion: No Rows", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyClientID, 4).
+			WillReturnError(sql.ErrNoRows)
+
+		plan, err := repo.GetCarePlanByVersion(context.Background(), dummyClientID, 4)
+		assert.Nil(t, plan)
+		assert.Equal(t, "Error 404: Resource not found - Care plan version 4 for client ID "+dummyClientID+" not found", err.Error())
+	})
+}
+
+func TestCreateCarePlan(t *testing.T) {
+	initMocks(t)
+
+	dummyPlan := model.CarePlan{ID: uuid.NewString(), ClientID: uuid.NewString(), Tasks: []model.CarePlanTask{
+		{Description: "Medication reminder", Frequency: "every_visit"},
+		{Description: "Laundry", Frequency: "weekly", Days: pq.StringArray{"MO"}},
+	}}
+	planQuery := `INSERT INTO care_plans (id,client_id,version,created_by) VALUES ($1,$2,(SELECT COALESCE(MAX(version), 0) + 1 FROM care_plans WHERE client_id = $3),$4) RETURNING version`
+	taskQuery := `INSERT INTO care_plan_tasks (care_plan_id,description,frequency,days,position) VALUES ($1,$2,$3,$4,$5),($6,$7,$8,$9,$10)`
+
+	t.Run("TestCreateCarePlan: OK", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectQuery(regexp.QuoteMeta(planQuery)).
+			WithArgs(dummyPlan.ID, dummyPlan.ClientID, dummyPlan.ClientID, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+		mockSQL.ExpectExec(regexp.QuoteMeta(taskQuery)).
+			WithArgs(dummyPlan.ID, "Medication reminder", "every_visit", nil, 1, dummyPlan.ID, "Laundry", "weekly", "{\"MO\"}", 2).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mockSQL.ExpectCommit()
+
+		version, err := repo.CreateCarePlan(context.Background(), dummyPlan)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, version)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestCreateCarePlan: Rolls Back On Error", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectQuery(regexp.QuoteMeta(planQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+		mockSQL.ExpectExec(regexp.QuoteMeta(taskQuery)).
+			WillReturnError(sql.ErrConnDone)
+		mockSQL.ExpectRollback()
+
+		version, err := repo.CreateCarePlan(context.Background(), dummyPlan)
+		assert.Equal(t, 0, version)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+}
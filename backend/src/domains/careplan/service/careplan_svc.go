@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context" // Import context
+	"mini-evv-logger-backend/exceptions"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	"mini-evv-logger-backend/src/domains/careplan/model"
+	"mini-evv-logger-backend/src/domains/careplan/repository"
+	clientModel "mini-evv-logger-backend/src/domains/client/model"
+	clientRepo "mini-evv-logger-backend/src/domains/client/repository"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// CarePlanService defines the interface for care plan business logic
+type CarePlanService interface {
+	GetClientOwnership(ctx context.Context, clientID string) (*clientModel.ClientOwnership, error)
+	GetCarePlans(ctx context.Context, clientID string) ([]model.CarePlan, error)
+	GetCarePlanByVersion(ctx context.Context, clientID string, version int) (*model.CarePlan, error)
+	GetActiveCarePlan(ctx context.Context, clientID string) (*model.CarePlan, error)
+	CreateCarePlan(ctx context.Context, req model.CreateCarePlanRequest) (*model.CarePlan, error)
+}
+
+// carePlanServiceImpl implements the CarePlanService interface
+type carePlanServiceImpl struct {
+	repo       repository.CarePlanRepository
+	clientRepo clientRepo.ClientRepository
+}
+
+// NewCarePlanService creates a new CarePlanService (returns interface)
+func NewCarePlanService(repo repository.CarePlanRepository, clientRepo clientRepo.ClientRepository) CarePlanService {
+	return &carePlanServiceImpl{repo: repo, clientRepo: clientRepo}
+}
+
+// GetClientOwnership fetches the branches serving a client, for authorization checks
+func (s *carePlanServiceImpl) GetClientOwnership(ctx context.Context, clientID string) (*clientModel.ClientOwnership, error) {
+	_, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid client ID format")
+	}
+	return s.clientRepo.GetClientOwnership(ctx, clientID)
+}
+
+// GetCarePlans fetches every version of a client's care plan, newest first
+func (s *carePlanServiceImpl) GetCarePlans(ctx context.Context, clientID string) ([]model.CarePlan, error) {
+	log.Info().Str("client_id", clientID).Msg("Fetching care plans for client")
+
+	_, err := uuid.Parse(clientID)
+	if err != nil {
+		log.Error().Err(err).Str("client_id", clientID).Msg("Invalid UUID format for client ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid client ID format")
+	}
+
+	plans, err := s.repo.GetCarePlans(ctx, clientID)
+	if err != nil {
+		log.Error().Err(err).Str("client_id", clientID).Msg("Failed to fetch care plans from repository")
+		return nil, err
+	}
+	return plans, nil
+}
+
+// GetCarePlanByVersion fetches one version of a client's care plan with its tasks
+func (s *carePlanServiceImpl) GetCarePlanByVersion(ctx context.Context, clientID string, version int) (*model.CarePlan, error) {
+	log.Info().Str("client_id", clientID).Int("version", version).Msg("Fetching care plan by version")
+
+	_, err := uuid.Parse(clientID)
+	if err != nil {
+		log.Error().Err(err).Str("client_id", clientID).Msg("Invalid UUID format for client ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid client ID format")
+	}
+	if version < 1 {
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid care plan version")
+	}
+
+	plan, err := s.repo.GetCarePlanByVersion(ctx, clientID, version)
+	if err != nil {
+		log.Error().Err(err).Str("client_id", clientID).Int("version", version).Msg("Failed to fetch care plan from repository")
+		return nil, err
+	}
+	return plan, nil
+}
+
+// GetActiveCarePlan fetches the care plan version that is applied to new visits of a client
+func (s *carePlanServiceImpl) GetActiveCarePlan(ctx context.Context, clientID string) (*model.CarePlan, error) {
+	log.Info().Str("client_id", clientID).Msg("Fetching active care plan for client")
+
+	_, err := uuid.Parse(clientID)
+	if err != nil {
+		log.Error().Err(err).Str("client_id", clientID).Msg("Invalid UUID format for client ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid client ID format")
+	}
+
+	plan, err := s.repo.GetActiveCarePlan(ctx, clientID)
+	if err != nil {
+		log.Error().Err(err).Str("client_id", clientID).Msg("Failed to fetch active care plan from repository")
+		return nil, err
+	}
+	return plan, nil
+}
+
+// CreateCarePlan stores a new version of a client's care plan. It applies to visits created
+// or generated from now on; the tasks of existing visits are left unchanged.
+func (s *carePlanServiceImpl) CreateCarePlan(ctx context.Context, req model.CreateCarePlanRequest) (*model.CarePlan, error) {
+	log.Info().Str("client_id", req.ClientID).Str("user_id", authModel.ActorID(ctx)).Msg("Attempting to create care plan")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for CreateCarePlanRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	// 1. Check that the client exists
+	_, err = s.clientRepo.GetClientByID(ctx, req.ClientID)
+	if err != nil {
+		log.Error().Err(err).Str("client_id", req.ClientID).Msg("Failed to retrieve client before creating care plan")
+		return nil, err
+	}
+
+	// 2. Perform the insert via repository
+	plan := model.CarePlan{
+		ID:       uuid.NewString(),
+		ClientID: req.ClientID,
+	}
+	if actorID := authModel.ActorID(ctx); actorID != "" {
+		plan.CreatedBy = &actorID
+	}
+	for _, task := range req.Tasks {
+		planTask := model.CarePlanTask{Description: task.Description, Frequency: task.Frequency}
+		if task.Frequency == model.FrequencyWeekly {
+			planTask.Days = task.Days
+		}
+		plan.Tasks = append(plan.Tasks, planTask)
+	}
+
+	version, err := s.repo.CreateCarePlan(ctx, plan)
+	if err != nil {
+		log.Error().Err(err).Str("client_id", req.ClientID).Msg("Failed to create care plan in repository")
+		return nil, err
+	}
+
+	return s.repo.GetCarePlanByVersion(ctx, req.ClientID, version)
+}
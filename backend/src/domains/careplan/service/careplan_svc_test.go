@@ -0,0 +1,120 @@
+package service_test
+
+import (
+	"context"
+	"mini-evv-logger-backend/exceptions"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	mocks "mini-evv-logger-backend/src/domains/careplan/mocks/repository"
+	"mini-evv-logger-backend/src/domains/careplan/model"
+	"mini-evv-logger-backend/src/domains/careplan/service"
+	clientMocks "mini-evv-logger-backend/src/domains/client/mocks/repository"
+	clientModel "mini-evv-logger-backend/src/domains/client/model"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	mockCarePlanRepo *mocks.MockCarePlanRepository
+	mockClientRepo   *clientMocks.MockClientRepository
+	ctrl             *gomock.Controller
+	svc              service.CarePlanService
+)
+
+func initMocks(t *testing.T) {
+	ctrl = gomock.NewController(t)
+
+	mockCarePlanRepo = mocks.NewMockCarePlanRepository(ctrl)
+	mockClientRepo = clientMocks.NewMockClientRepository(ctrl)
+
+	svc = service.NewCarePlanService(mockCarePlanRepo, mockClientRepo)
+}
+
+func TestGetActiveCarePlan(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyClientID := uuid.NewString()
+
+	t.Run("TestGetActiveCarePlan: OK", func(t *testing.T) {
+		mockCarePlanRepo.EXPECT().GetActiveCarePlan(gomock.Any(), dummyClientID).Return(&model.CarePlan{ClientID: dummyClientID, Version: 2}, nil).Times(1)
+
+		plan, err := svc.GetActiveCarePlan(context.Background(), dummyClientID)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, plan.Version)
+	})
+
+	t.Run("TestGetActiveCarePlan: Invalid UUID", func(t *testing.T) {
+		plan, err := svc.GetActiveCarePlan(context.Background(), "invalid-uuid")
+		assert.Error(t, err)
+		assert.Nil(t, plan)
+	})
+}
+
+func TestCreateCarePlan(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyClientID, dummyUserID := uuid.NewString(), uuid.NewString()
+	coordinatorCtx := authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: dummyUserID, Role: authModel.RoleCoordinator})
+	dummyRequest := model.CreateCarePlanRequest{ClientID: dummyClientID, Tasks: []model.CreateCarePlanTaskRequest{
+		{Description: "Medication reminder", Frequency: "every_visit"},
+		{Description: "Laundry", Frequency: "weekly", Days: []string{"MO", "WE", "FR"}},
+	}}
+
+	t.Run("TestCreateCarePlan: OK", func(t *testing.T) {
+		mockClientRepo.EXPECT().GetClientByID(gomock.Any(), dummyClientID).Return(&clientModel.Client{ID: dummyClientID}, nil).Times(1)
+		mockCarePlanRepo.EXPECT().CreateCarePlan(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, plan model.CarePlan) (int, error) {
+				assert.NotEmpty(t, plan.ID)
+				assert.Equal(t, dummyUserID, *plan.CreatedBy)
+				assert.Len(t, plan.Tasks, 2)
+				assert.Nil(t, plan.Tasks[0].Days)
+				assert.Equal(t, []string{"MO", "WE", "FR"}, []string(plan.Tasks[1].Days))
+				return 2, nil
+			}).Times(1)
+		mockCarePlanRepo.EXPECT().GetCarePlanByVersion(gomock.Any(), dummyClientID, 2).Return(&model.CarePlan{ClientID: dummyClientID, Version: 2}, nil).Times(1)
+
+		plan, err := svc.CreateCarePlan(coordinatorCtx, dummyRequest)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, plan.Version)
+	})
+
+	t.Run("TestCreateCarePlan: Weekly Task Without Days", func(t *testing.T) {
+		req := model.CreateCarePlanRequest{ClientID: dummyClientID, Tasks: []model.CreateCarePlanTaskRequest{{Description: "Laundry", Frequency: "weekly"}}}
+
+		plan, err := svc.CreateCarePlan(coordinatorCtx, req)
+		assert.Error(t, err)
+		assert.Nil(t, plan)
+		assert.Equal(t, http.StatusBadRequest, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestCreateCarePlan: Days On Every Visit Task", func(t *testing.T) {
+		req := model.CreateCarePlanRequest{ClientID: dummyClientID, Tasks: []model.CreateCarePlanTaskRequest{{Description: "Laundry", Frequency: "every_visit", Days: []string{"MO"}}}}
+
+		plan, err := svc.CreateCarePlan(coordinatorCtx, req)
+		assert.Error(t, err)
+		assert.Nil(t, plan)
+	})
+
+	t.Run("TestCreateCarePlan: Unknown Day", func(t *testing.T) {
+		req := model.CreateCarePlanRequest{ClientID: dummyClientID, Tasks: []model.CreateCarePlanTaskRequest{{Description: "Laundry", Frequency: "weekly", Days: []string{"Monday"}}}}
+
+		plan, err := svc.CreateCarePlan(coordinatorCtx, req)
+		assert.Error(t, err)
+		assert.Nil(t, plan)
+	})
+
+	t.Run("TestCreateCarePlan: Client Not Found", func(t *testing.T) {
+		mockClientRepo.EXPECT().GetClientByID(gomock.Any(), dummyClientID).Return(nil, exceptions.ErrNotFound).Times(1)
+
+		plan, err := svc.CreateCarePlan(coordinatorCtx, dummyRequest)
+		assert.Error(t, err)
+		assert.Nil(t, plan)
+	})
+}
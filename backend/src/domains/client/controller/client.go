@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/policy"
+	"mini-evv-logger-backend/responses"
+	"mini-evv-logger-backend/src/domains/client/model"
+	"mini-evv-logger-backend/src/domains/client/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ClientController handles HTTP requests for clients
+type ClientController struct {
+	svc service.ClientService
+}
+
+// NewClientController creates a new ClientController
+func NewClientController(svc service.ClientService) *ClientController {
+	return &ClientController{svc: svc}
+}
+
+// Routes sets up the API endpoints for clients
+func (cc *ClientController) Routes(app fiber.Router) {
+	clientRoutes := app.Group("/clients")
+	clientRoutes.Get("/", policy.Require(policy.ViewClients, nil), cc.GetClients)
+	clientRoutes.Post("/", policy.Require(policy.ManageClients, nil), cc.CreateClient)
+	clientRoutes.Get("/:id", policy.Require(policy.ViewClients, cc.clientResource), cc.GetClientDetails)
+	clientRoutes.Patch("/:id", policy.Require(policy.ManageClients, cc.clientResource), cc.UpdateClient)
+}
+
+// clientResource resolves the branches serving the client in the :id route parameter
+func (cc *ClientController) clientResource(c *fiber.Ctx) (*policy.Resource, error) {
+	ownership, err := cc.svc.GetClientOwnership(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return nil, err
+	}
+	return &policy.Resource{BranchIDs: ownership.BranchIDs}, nil
+}
+
+// GetClients handles fetching all clients
+func (cc *ClientController) GetClients(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	clients, err := cc.svc.GetAllClients(ctx)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, clients, "Clients retrieved successfully")
+}
+
+// GetClientDetails handles fetching a single client's details
+func (cc *ClientController) GetClientDetails(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Client ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	client, err := cc.svc.GetClientByID(ctx, id)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, client, "Client details retrieved successfully")
+}
+
+// CreateClient handles creating a new client
+func (cc *ClientController) CreateClient(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var req model.CreateClientRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	client, err := cc.svc.CreateClient(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.Created(c, client, "Client created successfully")
+}
+
+// UpdateClient handles updating an existing client
+func (cc *ClientController) UpdateClient(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Client ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	var req model.UpdateClientRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	req.ID = id // Set the ID from the URL parameter
+
+	client, err := cc.svc.UpdateClient(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, client, "Client updated successfully")
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"time"
+	_ "time/tzdata" // The alpine runtime image has no zoneinfo, so the timezone validator needs the embedded copy
+
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+)
+
+// Client represents a person receiving care at home
+type Client struct {
+	ID           string    `json:"id" db:"id"`
+	Name         string    `json:"name" db:"name"`
+	AddressLine1 string    `json:"address_line1" db:"address_line1"`
+	AddressLine2 *string   `json:"address_line2" db:"address_line2"` // Pointer to allow NULL
+	City         string    `json:"city" db:"city"`
+	State        string    `json:"state" db:"state"`
+	PostalCode   *string   `json:"postal_code" db:"postal_code"` // Pointer to allow NULL
+	Latitude     *float64  `json:"latitude" db:"latitude"`       // Home coordinates, NULL until geocoded
+	Longitude    *float64  `json:"longitude" db:"longitude"`     // Home coordinates, NULL until geocoded
+	Phone        *string   `json:"phone" db:"phone"`             // Pointer to allow NULL
+	Timezone     string    `json:"timezone" db:"timezone"`       // IANA name, e.g. "America/Chicago"
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ClientOwnership lists the branches whose caregivers visit a client, for authorization checks
+type ClientOwnership struct {
+	BranchIDs pq.StringArray `db:"branch_ids"`
+}
+
+// CreateClientRequest defines the request body for creating a client
+type CreateClientRequest struct {
+	Name         string   `json:"name" validate:"required,max=255"`
+	AddressLine1 string   `json:"address_line1" validate:"required,max=255"`
+	AddressLine2 *string  `json:"address_line2" validate:"omitempty,max=255"`
+	City         string   `json:"city" validate:"required,max=100"`
+	State        string   `json:"state" validate:"required,max=100"`
+	PostalCode   *string  `json:"postal_code" validate:"omitempty,max=20"`
+	Latitude     *float64 `json:"latitude" validate:"required,latitude"`
+	Longitude    *float64 `json:"longitude" validate:"required,longitude"`
+	Phone        *string  `json:"phone" validate:"omitempty,max=50"`
+	Timezone     string   `json:"timezone" validate:"required,timezone"`
+}
+
+// UpdateClientRequest defines the request body for updating a client; nil fields are left unchanged
+type UpdateClientRequest struct {
+	ID           string   `json:"-" validate:"required,uuid"`
+	Name         *string  `json:"name" validate:"omitempty,min=1,max=255"`
+	AddressLine1 *string  `json:"address_line1" validate:"omitempty,min=1,max=255"`
+	AddressLine2 *string  `json:"address_line2" validate:"omitempty,max=255"`
+	City         *string  `json:"city" validate:"omitempty,min=1,max=100"`
+	State        *string  `json:"state" validate:"omitempty,min=1,max=100"`
+	PostalCode   *string  `json:"postal_code" validate:"omitempty,max=20"`
+	Latitude     *float64 `json:"latitude" validate:"required_with=Longitude,omitempty,latitude"`
+	Longitude    *float64 `json:"longitude" validate:"required_with=Latitude,omitempty,longitude"`
+	Phone        *string  `json:"phone" validate:"omitempty,max=50"`
+	Timezone     *string  `json:"timezone" validate:"omitempty,timezone"`
+}
+
+func (r *CreateClientRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *UpdateClientRequest) Validate() error {
+	return validator.New().Struct(r)
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context" // Import context
+	"database/sql"
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/src/domains/client/model"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=./client_repo.go -destination=../mocks/repository/client_repo.go -package=mocks
+
+var clientColumns = []string{"id", "name", "address_line1", "address_line2", "city", "state", "postal_code",
+	"latitude", "longitude", "phone", "timezone", "created_at", "updated_at"}
+
+// ClientRepository defines the interface for client database operations
+type ClientRepository interface {
+	GetClients(ctx context.Context) ([]model.Client, error)
+	GetClientByID(ctx context.Context, id string) (*model.Client, error)
+	GetClientOwnership(ctx context.Context, id string) (*model.ClientOwnership, error)
+	CreateClient(ctx context.Context, client model.Client) (*model.Client, error)
+	UpdateClient(ctx context.Context, req model.UpdateClientRequest) error
+}
+
+// clientRepositoryImpl implements the ClientRepository interface
+type clientRepositoryImpl struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+// NewClientRepository creates a new ClientRepository (returns interface)
+func NewClientRepository(db *sqlx.DB, logger zerolog.Logger) ClientRepository {
+	return &clientRepositoryImpl{db: db, logger: logger}
+}
+
+// GetClients fetches all clients ordered by name
+func (r *clientRepositoryImpl) GetClients(ctx context.Context) ([]model.Client, error) {
+	var clients []model.Client
+	qb := squirrel.Select(clientColumns...).
+		From("clients").
+		OrderBy("name ASC").
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to build SQL query for GetClients")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.SelectContext(ctx, &clients, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return []model.Client{}, nil
+		}
+		r.logger.Error().Err(err).Msg("Failed to execute SQL query for GetClients")
+		return nil, exceptions.ErrInternalError
+	}
+	return clients, nil
+}
+
+// GetClientByID fetches a single client by ID
+func (r *clientRepositoryImpl) GetClientByID(ctx context.Context, id string) (*model.Client, error) {
+	var client model.Client
+	qb := squirrel.Select(clientColumns...).
+		From("clients").
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("client_id", id).Msg("Failed to build SQL query for GetClientByID")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &client, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.Warn().Str("client_id", id).Msg("Client not found in database")
+			return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Client with ID %s not found", id))
+		}
+		r.logger.Error().Err(err).Str("client_id", id).Msg("Failed to execute SQL query for GetClientByID")
+		return nil, exceptions.ErrInternalError
+	}
+	return &client, nil
+}
+
+// GetClientOwnership fetches the branches of the caregivers scheduled to visit a client
+func (r *clientRepositoryImpl) GetClientOwnership(ctx context.Context, id string) (*model.ClientOwnership, error) {
+	var ownership model.ClientOwnership
+	qb := squirrel.Select("COALESCE(array_agg(DISTINCT c.branch_id) FILTER (WHERE c.branch_id IS NOT NULL), '{}') AS branch_ids").
+		From("clients cl").
+		LeftJoin("schedules s ON s.client_id = cl.id").
+		LeftJoin("caregivers c ON c.id = s.caregiver_id").
+		Where(squirrel.Eq{"cl.id": id}).
+		GroupBy("cl.id").
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("client_id", id).Msg("Failed to build SQL query for GetClientOwnership")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &ownership, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Client with ID %s not found", id))
+		}
+		r.logger.Error().Err(err).Str("client_id", id).Msg("Failed to execute SQL query for GetClientOwnership")
+		return nil, exceptions.ErrInternalError
+	}
+	return &ownership, nil
+}
+
+// CreateClient inserts a new client and returns the stored record
+func (r *clientRepositoryImpl) CreateClient(ctx context.Context, client model.Client) (*model.Client, error) {
+	var created model.Client
+	qb := squirrel.Insert("clients").
+		Columns("id", "name", "address_line1", "address_line2", "city", "state", "postal_code",
+			"latitude", "longitude", "phone", "timezone").
+		Values(client.ID, client.Name, client.AddressLine1, client.AddressLine2, client.City, client.State, client.PostalCode,
+			client.Latitude, client.Longitude, client.Phone, client.Timezone).
+		Suffix("RETURNING " + strings.Join(clientColumns, ", ")).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to build SQL query for CreateClient")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &created, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("client_id", client.ID).Msg("Failed to execute SQL query for CreateClient")
+		return nil, exceptions.ErrInternalError
+	}
+	return &created, nil
+}
+
+// UpdateClient updates the provided fields of a client without pre-checking existence.
+// It relies on the service layer to perform existence checks.
+func (r *clientRepositoryImpl) UpdateClient(ctx context.Context, req model.UpdateClientRequest) error {
+	qb := squirrel.Update("clients").
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": req.ID}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	if req.Name != nil {
+		qb = qb.Set("name", *req.Name)
+	}
+	if req.AddressLine1 != nil {
+		qb = qb.Set("address_line1", *req.AddressLine1)
+	}
+	if req.AddressLine2 != nil {
+		qb = qb.Set("address_line2", *req.AddressLine2)
+	}
+	if req.City != nil {
+		qb = qb.Set("city", *req.City)
+	}
+	if req.State != nil {
+		qb = qb.Set("state", *req.State)
+	}
+	if req.PostalCode != nil {
+		qb = qb.Set("postal_code", *req.PostalCode)
+	}
+	if req.Latitude != nil && req.Longitude != nil {
+		qb = qb.Set("latitude", *req.Latitude).Set("longitude", *req.Longitude)
+	}
+	if req.Phone != nil {
+		qb = qb.Set("phone", *req.Phone)
+	}
+	if req.Timezone != nil {
+		qb = qb.Set("timezone", *req.Timezone)
+	}
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("client_id", req.ID).Msg("Failed to build SQL query for UpdateClient")
+		return exceptions.ErrInternalError
+	}
+
+	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("client_id", req.ID).Msg("Failed to execute SQL query for UpdateClient")
+		return exceptions.ErrInternalError
+	}
+	return nil
+}
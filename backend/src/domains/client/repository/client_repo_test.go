@@ -0,0 +1,188 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	pkgmock "mini-evv-logger-backend/pkg_mock"
+	"mini-evv-logger-backend/src/domains/client/model"
+	"mini-evv-logger-backend/src/domains/client/repository"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	dbMock   *sql.DB
+	sqlxMock *sqlx.DB
+	mockSQL  sqlmock.Sqlmock
+	repo     repository.ClientRepository
+)
+
+func initMocks(t *testing.T) {
+	var err error
+	dbMock, mockSQL, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	// Wrap sqlmock in sqlx.DB
+	sqlxMock = sqlx.NewDb(dbMock, "sqlmock")
+	repo = repository.NewClientRepository(sqlxMock, pkgmock.InitMockLogger())
+}
+
+const clientSelect = `SELECT id, name, address_line1, address_line2, city, state, postal_code, latitude, longitude, phone, timezone, created_at, updated_at FROM clients`
+
+var clientColumns = []string{"id", "name", "address_line1", "address_line2", "city", "state", "postal_code", "latitude", "longitude", "phone", "timezone", "created_at", "updated_at"}
+
+func clientRow(id string) []driver.Value {
+	return []driver.Value{id, "Alice Johnson", "123 Oak Ave", nil, "Austin", "TX", "78701", 30.2672, -97.7431, nil, "America/Chicago", time.Now(), time.Now()}
+}
+
+func TestGetClients(t *testing.T) {
+	initMocks(t)
+
+	query := clientSelect + ` ORDER BY name ASC`
+
+	t.Run("TestGetClients: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WillReturnRows(sqlmock.NewRows(clientColumns).AddRow(clientRow(uuid.NewString())...))
+
+		clients, err := repo.GetClients(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, clients, 1)
+		assert.Equal(t, 30.2672, *clients[0].Latitude)
+	})
+
+	t.Run("TestGetClients: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		clients, err := repo.GetClients(context.Background())
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+		assert.Nil(t, clients)
+	})
+}
+
+func TestGetClientByID(t *testing.T) {
+	initMocks(t)
+
+	dummyID := uuid.NewString()
+	query := clientSelect + ` WHERE id = $1`
+
+	t.Run("TestGetClientByID: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows(clientColumns).AddRow(clientRow(dummyID)...))
+
+		client, err := repo.GetClientByID(context.Background(), dummyID)
+		assert.NoError(t, err)
+		assert.Equal(t, dummyID, client.ID)
+		assert.Equal(t, "America/Chicago", client.Timezone)
+	})
+
+	t.Run("TestGetClientByID: No Rows", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnError(sql.ErrNoRows)
+
+		client, err := repo.GetClientByID(context.Background(), dummyID)
+		assert.NotNil(t, err)
+		assert.Nil(t, client)
+	})
+}
+
+func TestGetClientOwnership(t *testing.T) {
+	initMocks(t)
+
+	dummyID, branchID := uuid.NewString(), uuid.NewString()
+	query := `SELECT COALESCE(array_agg(DISTINCT c.branch_id) FILTER (WHERE c.branch_id IS NOT NULL), '{}') AS branch_ids FROM clients cl LEFT JOIN schedules s ON s.client_id = cl.id LEFT JOIN caregivers c ON c.id = s.caregiver_id WHERE cl.id = $1 GROUP BY cl.id`
+
+	t.Run("TestGetClientOwnership: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows([]string{"branch_ids"}).AddRow("{" + branchID + "}"))
+
+		ownership, err := repo.GetClientOwnership(context.Background(), dummyID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{branchID}, []string(ownership.BranchIDs))
+	})
+
+	t.Run("TestGetClientOwnership: Not Found", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnError(sql.ErrNoRows)
+
+		ownership, err := repo.GetClientOwnership(context.Background(), dummyID)
+		assert.Equal(t, "Error 404: Resource not found - Client with ID "+dummyID+" not found", err.Error())
+		assert.Nil(t, ownership)
+	})
+}
+
+func TestCreateClient(t *testing.T) {
+	initMocks(t)
+
+	dummyID := uuid.NewString()
+	lat, lng := 30.2672, -97.7431
+	dummyClient := model.Client{
+		ID:           dummyID,
+		Name:         "Alice Johnson",
+		AddressLine1: "123 Oak Ave",
+		City:         "Austin",
+		State:        "TX",
+		Latitude:     &lat,
+		Longitude:    &lng,
+		Timezone:     "America/Chicago",
+	}
+	query := `INSERT INTO clients (id,name,address_line1,address_line2,city,state,postal_code,latitude,longitude,phone,timezone) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11) RETURNING id, name`
+
+	t.Run("TestCreateClient: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID, "Alice Johnson", "123 Oak Ave", nil, "Austin", "TX", nil, lat, lng, nil, "America/Chicago").
+			WillReturnRows(sqlmock.NewRows(clientColumns).AddRow(clientRow(dummyID)...))
+
+		client, err := repo.CreateClient(context.Background(), dummyClient)
+		assert.NoError(t, err)
+		assert.Equal(t, dummyID, client.ID)
+	})
+
+	t.Run("TestCreateClient: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		client, err := repo.CreateClient(context.Background(), dummyClient)
+		assert.NotNil(t, err)
+		assert.Nil(t, client)
+	})
+}
+
+func TestUpdateClient(t *testing.T) {
+	initMocks(t)
+
+	dummyID := uuid.NewString()
+	name, lat, lng := "Alice J.", 30.1, -97.1
+
+	t.Run("TestUpdateClient: OK", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(`UPDATE clients SET updated_at = $1, name = $2, latitude = $3, longitude = $4 WHERE id = $5`)).
+			WithArgs(sqlmock.AnyArg(), name, lat, lng, dummyID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := repo.UpdateClient(context.Background(), model.UpdateClientRequest{ID: dummyID, Name: &name, Latitude: &lat, Longitude: &lng})
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestUpdateClient: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(`UPDATE clients SET updated_at = $1, name = $2 WHERE id = $3`)).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.UpdateClient(context.Background(), model.UpdateClientRequest{ID: dummyID, Name: &name})
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+	})
+}
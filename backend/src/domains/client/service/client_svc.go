@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context" // Import context
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/src/domains/client/model"
+	"mini-evv-logger-backend/src/domains/client/repository"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// ClientService defines the interface for client business logic
+type ClientService interface {
+	GetAllClients(ctx context.Context) ([]model.Client, error)
+	GetClientByID(ctx context.Context, id string) (*model.Client, error)
+	GetClientOwnership(ctx context.Context, id string) (*model.ClientOwnership, error)
+	CreateClient(ctx context.Context, req model.CreateClientRequest) (*model.Client, error)
+	UpdateClient(ctx context.Context, req model.UpdateClientRequest) (*model.Client, error)
+}
+
+// clientServiceImpl implements the ClientService interface
+type clientServiceImpl struct {
+	repo repository.ClientRepository
+}
+
+// NewClientService creates a new ClientService (returns interface)
+func NewClientService(repo repository.ClientRepository) ClientService {
+	return &clientServiceImpl{repo: repo}
+}
+
+// GetAllClients fetches all clients
+func (s *clientServiceImpl) GetAllClients(ctx context.Context) ([]model.Client, error) {
+	log.Info().Msg("Fetching all clients")
+	clients, err := s.repo.GetClients(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch clients from repository")
+		return nil, err
+	}
+	return clients, nil
+}
+
+// GetClientByID fetches a client by its ID
+func (s *clientServiceImpl) GetClientByID(ctx context.Context, id string) (*model.Client, error) {
+	log.Info().Str("client_id", id).Msg("Fetching client by ID")
+
+	_, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Str("client_id", id).Msg("Invalid UUID format for client ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid client ID format")
+	}
+
+	client, err := s.repo.GetClientByID(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("client_id", id).Msg("Failed to fetch client by ID from repository")
+		return nil, err
+	}
+	return client, nil
+}
+
+// GetClientOwnership fetches the branches serving a client, for authorization checks
+func (s *clientServiceImpl) GetClientOwnership(ctx context.Context, id string) (*model.ClientOwnership, error) {
+	_, err := uuid.Parse(id)
+	if err != nil {
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid client ID format")
+	}
+	return s.repo.GetClientOwnership(ctx, id)
+}
+
+// CreateClient validates and stores a new client
+func (s *clientServiceImpl) CreateClient(ctx context.Context, req model.CreateClientRequest) (*model.Client, error) {
+	log.Info().Str("name", req.Name).Msg("Attempting to create client")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for CreateClientRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	client, err := s.repo.CreateClient(ctx, model.Client{
+		ID:           uuid.NewString(),
+		Name:         req.Name,
+		AddressLine1: req.AddressLine1,
+		AddressLine2: req.AddressLine2,
+		City:         req.City,
+		State:        req.State,
+		PostalCode:   req.PostalCode,
+		Latitude:     req.Latitude,
+		Longitude:    req.Longitude,
+		Phone:        req.Phone,
+		Timezone:     req.Timezone,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create client in repository")
+		return nil, err
+	}
+	return client, nil
+}
+
+// UpdateClient applies a partial update to an existing client
+func (s *clientServiceImpl) UpdateClient(ctx context.Context, req model.UpdateClientRequest) (*model.Client, error) {
+	log.Info().Str("client_id", req.ID).Msg("Attempting to update client")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for UpdateClientRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	// 1. Check if the client exists
+	_, err = s.repo.GetClientByID(ctx, req.ID)
+	if err != nil {
+		log.Error().Err(err).Str("client_id", req.ID).Msg("Failed to retrieve client before update")
+		return nil, err
+	}
+
+	// 2. Perform the update via repository
+	err = s.repo.UpdateClient(ctx, req)
+	if err != nil {
+		log.Error().Err(err).Str("client_id", req.ID).Msg("Failed to update client in repository")
+		return nil, err
+	}
+
+	return s.repo.GetClientByID(ctx, req.ID)
+}
@@ -0,0 +1,187 @@
+package service_test
+
+import (
+	"context"
+	"mini-evv-logger-backend/exceptions"
+	mocks "mini-evv-logger-backend/src/domains/client/mocks/repository"
+	"mini-evv-logger-backend/src/domains/client/model"
+	"mini-evv-logger-backend/src/domains/client/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	mockClientRepo *mocks.MockClientRepository
+	ctrl           *gomock.Controller
+	svc            service.ClientService
+)
+
+func initMocks(t *testing.T) {
+	ctrl = gomock.NewController(t)
+
+	mockClientRepo = mocks.NewMockClientRepository(ctrl)
+
+	svc = service.NewClientService(mockClientRepo)
+}
+
+func TestGetAllClients(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	t.Run("TestGetAllClients: OK", func(t *testing.T) {
+		mockClientRepo.EXPECT().GetClients(gomock.Any()).Return([]model.Client{{ID: uuid.NewString()}}, nil).Times(1)
+
+		clients, err := svc.GetAllClients(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, clients, 1)
+	})
+
+	t.Run("TestGetAllClients: Repository Error", func(t *testing.T) {
+		mockClientRepo.EXPECT().GetClients(gomock.Any()).Return(nil, assert.AnError).Times(1)
+
+		clients, err := svc.GetAllClients(context.Background())
+		assert.Error(t, err)
+		assert.Nil(t, clients)
+	})
+}
+
+func TestGetClientByID(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyID := uuid.NewString()
+
+	t.Run("TestGetClientByID: OK", func(t *testing.T) {
+		mockClientRepo.EXPECT().GetClientByID(gomock.Any(), dummyID).Return(&model.Client{ID: dummyID}, nil).Times(1)
+
+		client, err := svc.GetClientByID(context.Background(), dummyID)
+		assert.NoError(t, err)
+		assert.Equal(t, dummyID, client.ID)
+	})
+
+	t.Run("TestGetClientByID: Invalid UUID", func(t *testing.T) {
+		client, err := svc.GetClientByID(context.Background(), "invalid-uuid")
+		assert.Error(t, err)
+		assert.Nil(t, client)
+		assert.Equal(t, exceptions.ErrBadRequest.WithDetails("Invalid client ID format").Error(), err.Error())
+	})
+}
+
+func TestGetClientOwnership(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyID := uuid.NewString()
+
+	t.Run("TestGetClientOwnership: OK", func(t *testing.T) {
+		ownership := &model.ClientOwnership{BranchIDs: []string{uuid.NewString()}}
+		mockClientRepo.EXPECT().GetClientOwnership(gomock.Any(), dummyID).Return(ownership, nil).Times(1)
+
+		result, err := svc.GetClientOwnership(context.Background(), dummyID)
+		assert.NoError(t, err)
+		assert.Equal(t, ownership, result)
+	})
+
+	t.Run("TestGetClientOwnership: Invalid UUID", func(t *testing.T) {
+		result, err := svc.GetClientOwnership(context.Background(), "invalid-uuid")
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestCreateClient(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	lat, lng := 30.2672, -97.7431
+	dummyRequest := model.CreateClientRequest{
+		Name:         "Alice Johnson",
+		AddressLine1: "123 Oak Ave",
+		City:         "Austin",
+		State:        "TX",
+		Latitude:     &lat,
+		Longitude:    &lng,
+		Timezone:     "America/Chicago",
+	}
+
+	t.Run("TestCreateClient: OK", func(t *testing.T) {
+		mockClientRepo.EXPECT().CreateClient(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, client model.Client) (*model.Client, error) {
+				assert.NotEmpty(t, client.ID)
+				assert.Equal(t, "America/Chicago", client.Timezone)
+				return &client, nil
+			}).Times(1)
+
+		client, err := svc.CreateClient(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+		assert.Equal(t, "Alice Johnson", client.Name)
+	})
+
+	t.Run("TestCreateClient: Invalid Timezone", func(t *testing.T) {
+		invalid := dummyRequest
+		invalid.Timezone = "Mars/Olympus_Mons"
+
+		client, err := svc.CreateClient(context.Background(), invalid)
+		assert.Error(t, err)
+		assert.Nil(t, client)
+	})
+
+	t.Run("TestCreateClient: Missing Coordinates", func(t *testing.T) {
+		invalid := dummyRequest
+		invalid.Latitude = nil
+
+		client, err := svc.CreateClient(context.Background(), invalid)
+		assert.Error(t, err)
+		assert.Nil(t, client)
+	})
+
+	t.Run("TestCreateClient: Repository Error", func(t *testing.T) {
+		mockClientRepo.EXPECT().CreateClient(gomock.Any(), gomock.Any()).Return(nil, assert.AnError).Times(1)
+
+		client, err := svc.CreateClient(context.Background(), dummyRequest)
+		assert.Error(t, err)
+		assert.Nil(t, client)
+	})
+}
+
+func TestUpdateClient(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyID := uuid.NewString()
+	name := "Alice J."
+
+	t.Run("TestUpdateClient: OK", func(t *testing.T) {
+		req := model.UpdateClientRequest{ID: dummyID, Name: &name}
+		mockClientRepo.EXPECT().GetClientByID(gomock.Any(), dummyID).Return(&model.Client{ID: dummyID}, nil).Times(1)
+		mockClientRepo.EXPECT().UpdateClient(gomock.Any(), req).Return(nil).Times(1)
+		mockClientRepo.EXPECT().GetClientByID(gomock.Any(), dummyID).Return(&model.Client{ID: dummyID, Name: name}, nil).Times(1)
+
+		client, err := svc.UpdateClient(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, name, client.Name)
+	})
+
+	t.Run("TestUpdateClient: Latitude Without Longitude", func(t *testing.T) {
+		lat := 30.0
+		client, err := svc.UpdateClient(context.Background(), model.UpdateClientRequest{ID: dummyID, Latitude: &lat})
+		assert.Error(t, err)
+		assert.Nil(t, client)
+	})
+
+	t.Run("TestUpdateClient: Not Found", func(t *testing.T) {
+		mockClientRepo.EXPECT().GetClientByID(gomock.Any(), dummyID).Return(nil, exceptions.ErrNotFound).Times(1)
+
+		client, err := svc.UpdateClient(context.Background(), model.UpdateClientRequest{ID: dummyID, Name: &name})
+		assert.Error(t, err)
+		assert.Nil(t, client)
+	})
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Record is a request sent with an Idempotency-Key and, once it has finished, the response to replay
+type Record struct {
+	UserID      string     `db:"user_id"`
+	Key         string     `db:"key"`
+	Method      string     `db:"method"`
+	Path        string     `db:"path"`
+	RequestHash string     `db:"request_hash"` // SHA-256 of the request body, hex encoded
+	StatusCode  *int       `db:"status_code"`  // NULL while the original request is still being processed
+	LockedUntil *time.Time `db:"locked_until"` // Lease of the request being processed, NULL once it has finished
+	ContentType *string    `db:"content_type"`
+	ETag        *string    `db:"etag"`
+	Body        []byte     `db:"body"`
+	CreatedAt   time.Time  `db:"created_at"`
+	ExpiresAt   time.Time  `db:"expires_at"`
+}
+
+// Response is the stored response of a finished request
+type Response struct {
+	StatusCode  int
+	ContentType string
+	ETag        string // Empty when the response had no ETag
+	Body        []byte
+}
+
+// BeginRequest identifies a request that carries an Idempotency-Key
+type BeginRequest struct {
+	UserID string `validate:"required"`
+	Key    string `validate:"required,max=255,printascii"`
+	Method string `validate:"required"`
+	Path   string `validate:"required"`
+	Body   []byte
+}
+
+func (r *BeginRequest) Validate() error {
+	return validator.New().Struct(r)
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context" // Import context
+	"database/sql"
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/src/domains/idempotency/model"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=./idempotency_repo.go -destination=../mocks/repository/idempotency_repo.go -package=mocks
+
+// IdempotencyRepository defines the interface for idempotency key database operations
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, record model.Record) (bool, error)
+	GetRecord(ctx context.Context, userID, key string) (*model.Record, error)
+	SaveResponse(ctx context.Context, userID, key string, resp model.Response) error
+	Release(ctx context.Context, userID, key string) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// idempotencyRepositoryImpl implements the IdempotencyRepository interface
+type idempotencyRepositoryImpl struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+// NewIdempotencyRepository creates a new IdempotencyRepository (returns interface)
+func NewIdempotencyRepository(db *sqlx.DB, logger zerolog.Logger) IdempotencyRepository {
+	return &idempotencyRepositoryImpl{db: db, logger: logger}
+}
+
+// Reserve stores a new key without a response. A key that is already stored is only taken
+// over once it has expired, or once the lease of a request that never finished has passed.
+// It reports whether the key was reserved for this request.
+func (r *idempotencyRepositoryImpl) Reserve(ctx context.Context, record model.Record) (bool, error) {
+	qb := squirrel.Insert("idempotency_keys").
+		Columns("user_id", "key", "method", "path", "request_hash", "locked_until", "created_at", "expires_at").
+		Values(record.UserID, record.Key, record.Method, record.Path, record.RequestHash, record.LockedUntil, record.CreatedAt, record.ExpiresAt).
+		Suffix("ON CONFLICT (user_id, key) DO UPDATE SET method = EXCLUDED.method, path = EXCLUDED.path, " +
+			"request_hash = EXCLUDED.request_hash, status_code = NULL, locked_until = EXCLUDED.locked_until, content_type = NULL, etag = NULL, body = NULL, " +
+			"created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at " +
+			"WHERE idempotency_keys.expires_at <= EXCLUDED.created_at " +
+			"OR (idempotency_keys.status_code IS NULL AND idempotency_keys.locked_until <= EXCLUDED.created_at)").
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("user_id", record.UserID).Msg("Failed to build SQL query for Reserve")
+		return false, exceptions.ErrInternalError
+	}
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("user_id", record.UserID).Str("key", record.Key).Msg("Failed to execute SQL query for Reserve")
+		return false, exceptions.ErrInternalError
+	}
+
+	reserved, err := res.RowsAffected()
+	if err != nil {
+		r.logger.Error().Err(err).Str("user_id", record.UserID).Msg("Failed to read affected rows for Reserve")
+		return false, exceptions.ErrInternalError
+	}
+	return reserved == 1, nil
+}
+
+// GetRecord fetches a stored key of a user
+func (r *idempotencyRepositoryImpl) GetRecord(ctx context.Context, userID, key string) (*model.Record, error) {
+	var record model.Record
+	qb := squirrel.Select("user_id", "key", "method", "path", "request_hash", "status_code", "locked_until", "content_type",
+		"etag", "body", "created_at", "expires_at").
+		From("idempotency_keys").
+		Where(squirrel.Eq{"user_id": userID, "key": key}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to build SQL query for GetRecord")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &record, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.Warn().Str("user_id", userID).Str("key", key).Msg("Idempotency key not found in database")
+			return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Idempotency key %s not found", key))
+		}
+		r.logger.Error().Err(err).Str("user_id", userID).Str("key", key).Msg("Failed to execute SQL query for GetRecord")
+		return nil, exceptions.ErrInternalError
+	}
+	return &record, nil
+}
+
+// SaveResponse stores the response of a reserved key so that retries can replay it, and ends its lease
+func (r *idempotencyRepositoryImpl) SaveResponse(ctx context.Context, userID, key string, resp model.Response) error {
+	var etag *string
+	if resp.ETag != "" {
+		etag = &resp.ETag
+	}
+
+	qb := squirrel.Update("idempotency_keys").
+		Set("status_code", resp.StatusCode).
+		Set("locked_until", nil).
+		Set("content_type", resp.ContentType).
+		Set("etag", etag).
+		Set("body", resp.Body).
+		Where(squirrel.Eq{"user_id": userID, "key": key}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to build SQL query for SaveResponse")
+		return exceptions.ErrInternalError
+	}
+
+	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("user_id", userID).Str("key", key).Msg("Failed to execute SQL query for SaveResponse")
+		return exceptions.ErrInternalError
+	}
+	return nil
+}
+
+// Release removes a key whose request failed, so that it can be retried with the same key
+func (r *idempotencyRepositoryImpl) Release(ctx context.Context, userID, key string) error {
+	qb := squirrel.Delete("idempotency_keys").
+		Where(squirrel.Eq{"user_id": userID, "key": key}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to build SQL query for Release")
+		return exceptions.ErrInternalError
+	}
+
+	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("user_id", userID).Str("key", key).Msg("Failed to execute SQL query for Release")
+		return exceptions.ErrInternalError
+	}
+	return nil
+}
+
+// DeleteExpired removes the keys that expired before the given time and returns how many were removed
+func (r *idempotencyRepositoryImpl) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	qb := squirrel.Delete("idempotency_keys").
+		Where(squirrel.LtOrEq{"expires_at": before}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to build SQL query for DeleteExpired")
+		return 0, exceptions.ErrInternalError
+	}
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to execute SQL query for DeleteExpired")
+		return 0, exceptions.ErrInternalError
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to read affected rows for DeleteExpired")
+		return 0, exceptions.ErrInternalError
+	}
+	return deleted, nil
+}
@@ -0,0 +1,187 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	pkgmock "mini-evv-logger-backend/pkg_mock"
+	"mini-evv-logger-backend/src/domains/idempotency/model"
+	"mini-evv-logger-backend/src/domains/idempotency/repository"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	dbMock   *sql.DB
+	sqlxMock *sqlx.DB
+	mockSQL  sqlmock.Sqlmock
+	repo     repository.IdempotencyRepository
+)
+
+func initMocks(t *testing.T) {
+	var err error
+	dbMock, mockSQL, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	// Wrap sqlmock in sqlx.DB
+	sqlxMock = sqlx.NewDb(dbMock, "sqlmock")
+	repo = repository.NewIdempotencyRepository(sqlxMock, pkgmock.InitMockLogger())
+}
+
+func TestReserve(t *testing.T) {
+	initMocks(t)
+
+	now := time.Now()
+	lockedUntil := now.Add(time.Minute)
+	dummyRecord := model.Record{UserID: uuid.NewString(), Key: "retry-1", Method: "POST", Path: "/api/schedules/1/start", RequestHash: "abc", LockedUntil: &lockedUntil, CreatedAt: now, ExpiresAt: now.Add(24 * time.Hour)}
+	query := "INSERT INTO idempotency_keys (user_id,key,method,path,request_hash,locked_until,created_at,expires_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8) " +
+		"ON CONFLICT (user_id, key) DO UPDATE SET method = EXCLUDED.method, path = EXCLUDED.path, " +
+		"request_hash = EXCLUDED.request_hash, status_code = NULL, locked_until = EXCLUDED.locked_until, content_type = NULL, etag = NULL, body = NULL, " +
+		"created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at " +
+		"WHERE idempotency_keys.expires_at <= EXCLUDED.created_at " +
+		"OR (idempotency_keys.status_code IS NULL AND idempotency_keys.locked_until <= EXCLUDED.created_at)"
+
+	t.Run("TestReserve: Reserved", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(dummyRecord.UserID, dummyRecord.Key, dummyRecord.Method, dummyRecord.Path, dummyRecord.RequestHash, &lockedUntil, dummyRecord.CreatedAt, dummyRecord.ExpiresAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		reserved, err := repo.Reserve(context.Background(), dummyRecord)
+		assert.NoError(t, err)
+		assert.True(t, reserved)
+	})
+
+	t.Run("TestReserve: Key In Use", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		reserved, err := repo.Reserve(context.Background(), dummyRecord)
+		assert.NoError(t, err)
+		assert.False(t, reserved)
+	})
+
+	t.Run("TestReserve: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		reserved, err := repo.Reserve(context.Background(), dummyRecord)
+		assert.Error(t, err)
+		assert.False(t, reserved)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+	})
+}
+
+func TestGetRecord(t *testing.T) {
+	initMocks(t)
+
+	userID := uuid.NewString()
+	query := "SELECT user_id, key, method, path, request_hash, status_code, locked_until, content_type, etag, body, created_at, expires_at FROM idempotency_keys WHERE key = $1 AND user_id = $2"
+	columns := []string{"user_id", "key", "method", "path", "request_hash", "status_code", "locked_until", "content_type", "etag", "body", "created_at", "expires_at"}
+
+	t.Run("TestGetRecord: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs("retry-1", userID).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(userID, "retry-1", "POST", "/api/schedules/1/start", "abc", 200, nil, "application/json", nil, []byte(`{"success":true}`), time.Now(), time.Now().Add(time.Hour)))
+
+		record, err := repo.GetRecord(context.Background(), userID, "retry-1")
+		assert.NoError(t, err)
+		assert.Equal(t, 200, *record.StatusCode)
+		assert.Nil(t, record.ETag)
+		assert.Equal(t, `{"success":true}`, string(record.Body))
+	})
+
+	t.Run("TestGetRecord: Not Found", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs("retry-1", userID).
+			WillReturnError(sql.ErrNoRows)
+
+		record, err := repo.GetRecord(context.Background(), userID, "retry-1")
+		assert.Error(t, err)
+		assert.Nil(t, record)
+		assert.Equal(t, "Error 404: Resource not found - Idempotency key retry-1 not found", err.Error())
+	})
+}
+
+func TestSaveResponse(t *testing.T) {
+	initMocks(t)
+
+	userID := uuid.NewString()
+	query := "UPDATE idempotency_keys SET status_code = $1, locked_until = $2, content_type = $3, etag = $4, body = $5 WHERE key = $6 AND user_id = $7"
+
+	t.Run("TestSaveResponse: OK", func(t *testing.T) {
+		etag := `"2"`
+		body := []byte(`{"success":true}`)
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(200, nil, "application/json", &etag, body, "retry-1", userID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.SaveResponse(context.Background(), userID, "retry-1", model.Response{StatusCode: 200, ContentType: "application/json", ETag: etag, Body: body})
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestSaveResponse: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.SaveResponse(context.Background(), userID, "retry-1", model.Response{StatusCode: 200})
+		assert.Error(t, err)
+	})
+}
+
+func TestRelease(t *testing.T) {
+	initMocks(t)
+
+	userID := uuid.NewString()
+	query := "DELETE FROM idempotency_keys WHERE key = $1 AND user_id = $2"
+
+	t.Run("TestRelease: OK", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs("retry-1", userID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.Release(context.Background(), userID, "retry-1")
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestRelease: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.Release(context.Background(), userID, "retry-1")
+		assert.Error(t, err)
+	})
+}
+
+func TestDeleteExpired(t *testing.T) {
+	initMocks(t)
+
+	now := time.Now()
+	query := "DELETE FROM idempotency_keys WHERE expires_at <= $1"
+
+	t.Run("TestDeleteExpired: OK", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(now).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		deleted, err := repo.DeleteExpired(context.Background(), now)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), deleted)
+	})
+
+	t.Run("TestDeleteExpired: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		deleted, err := repo.DeleteExpired(context.Background(), now)
+		assert.Error(t, err)
+		assert.Zero(t, deleted)
+	})
+}
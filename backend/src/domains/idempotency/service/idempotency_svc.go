@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context" // Import context
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/src/domains/idempotency/model"
+	"mini-evv-logger-backend/src/domains/idempotency/repository"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// IdempotencyService defines the interface for replaying retried requests
+type IdempotencyService interface {
+	Begin(ctx context.Context, req model.BeginRequest) (*model.Response, error)
+	Complete(ctx context.Context, userID, key string, resp model.Response) error
+	Abandon(ctx context.Context, userID, key string) error
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+// Settings holds the tunables of the idempotency service
+type Settings struct {
+	TTL     time.Duration // How long a key and its response are kept for replays
+	LockTTL time.Duration // How long a request may run before a retry with its key runs it again
+}
+
+// idempotencyServiceImpl implements the IdempotencyService interface
+type idempotencyServiceImpl struct {
+	repo     repository.IdempotencyRepository
+	settings Settings
+}
+
+// NewIdempotencyService creates a new IdempotencyService (returns interface)
+func NewIdempotencyService(repo repository.IdempotencyRepository, settings Settings) IdempotencyService {
+	return &idempotencyServiceImpl{repo: repo, settings: settings}
+}
+
+// Begin reserves the key of a request. It returns nil when the request is new and must be processed,
+// or the stored response when the request is a retry of one that has already finished.
+func (s *idempotencyServiceImpl) Begin(ctx context.Context, req model.BeginRequest) (*model.Response, error) {
+	log.Info().Str("user_id", req.UserID).Str("key", req.Key).Str("path", req.Path).Msg("Attempting to reserve idempotency key")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for BeginRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	// 1. Reserve the key unless it is already in use
+	// A reservation whose request never finished, e.g. because the process died, expires with its lease
+	now := time.Now()
+	lockedUntil := now.Add(s.settings.LockTTL)
+	requestHash := hashBody(req.Body)
+	reserved, err := s.repo.Reserve(ctx, model.Record{
+		UserID:      req.UserID,
+		Key:         req.Key,
+		Method:      req.Method,
+		Path:        req.Path,
+		RequestHash: requestHash,
+		LockedUntil: &lockedUntil,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.settings.TTL),
+	})
+	if err != nil {
+		log.Error().Err(err).Str("key", req.Key).Msg("Failed to reserve idempotency key in repository")
+		return nil, err
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	// 2. The key is in use: it must have been sent with the same request, which must have finished
+	record, err := s.repo.GetRecord(ctx, req.UserID, req.Key)
+	if err != nil {
+		log.Error().Err(err).Str("key", req.Key).Msg("Failed to retrieve idempotency key after reservation conflict")
+		return nil, err
+	}
+	if record.Method != req.Method || record.Path != req.Path || record.RequestHash != requestHash {
+		return nil, exceptions.ErrUnprocessableEntity.WithDetails(fmt.Sprintf("Idempotency-Key %s was already used for a different request", req.Key))
+	}
+	if record.StatusCode == nil {
+		return nil, exceptions.ErrConflict.WithDetails(fmt.Sprintf("A request with Idempotency-Key %s is still being processed", req.Key))
+	}
+
+	log.Info().Str("user_id", req.UserID).Str("key", req.Key).Int("status_code", *record.StatusCode).Msg("Replaying stored response for idempotency key")
+	resp := &model.Response{StatusCode: *record.StatusCode, Body: record.Body}
+	if record.ContentType != nil {
+		resp.ContentType = *record.ContentType
+	}
+	if record.ETag != nil {
+		resp.ETag = *record.ETag
+	}
+	return resp, nil
+}
+
+// Complete stores the response of a reserved request. Server errors are not stored;
+// the key is released instead so that the retry runs the request again.
+func (s *idempotencyServiceImpl) Complete(ctx context.Context, userID, key string, resp model.Response) error {
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return s.Abandon(ctx, userID, key)
+	}
+
+	err := s.repo.SaveResponse(ctx, userID, key, resp)
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("Failed to store response for idempotency key")
+		return err
+	}
+	return nil
+}
+
+// Abandon releases a reserved key whose request did not finish
+func (s *idempotencyServiceImpl) Abandon(ctx context.Context, userID, key string) error {
+	err := s.repo.Release(ctx, userID, key)
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("Failed to release idempotency key")
+		return err
+	}
+	return nil
+}
+
+// PurgeExpired removes the keys whose replay window has passed. It returns the number of removed keys.
+func (s *idempotencyServiceImpl) PurgeExpired(ctx context.Context) (int64, error) {
+	purged, err := s.repo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if purged > 0 {
+		log.Info().Int64("count", purged).Msg("Purged expired idempotency keys")
+	}
+	return purged, nil
+}
+
+// hashBody fingerprints a request body so that a reused key can be told apart from a retry
+func hashBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
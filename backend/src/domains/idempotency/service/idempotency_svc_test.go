@@ -0,0 +1,186 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"mini-evv-logger-backend/exceptions"
+	mocks "mini-evv-logger-backend/src/domains/idempotency/mocks/repository"
+	"mini-evv-logger-backend/src/domains/idempotency/model"
+	"mini-evv-logger-backend/src/domains/idempotency/service"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	mockIdempotencyRepo *mocks.MockIdempotencyRepository
+	ctrl                *gomock.Controller
+	svc                 service.IdempotencyService
+)
+
+func initMocks(t *testing.T) {
+	ctrl = gomock.NewController(t)
+
+	mockIdempotencyRepo = mocks.NewMockIdempotencyRepository(ctrl)
+
+	svc = service.NewIdempotencyService(mockIdempotencyRepo, service.Settings{TTL: 24 * time.Hour, LockTTL: time.Minute})
+}
+
+func hash(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestBegin(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyUserID := uuid.NewString()
+	dummyBody := `{"latitude":1.3,"longitude":103.8}`
+	dummyRequest := model.BeginRequest{UserID: dummyUserID, Key: "retry-1", Method: "POST", Path: "/api/schedules/1/start", Body: []byte(dummyBody)}
+	statusOK := http.StatusOK
+
+	t.Run("TestBegin: New Request", func(t *testing.T) {
+		mockIdempotencyRepo.EXPECT().Reserve(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, record model.Record) (bool, error) {
+				assert.Equal(t, dummyUserID, record.UserID)
+				assert.Equal(t, "retry-1", record.Key)
+				assert.Equal(t, hash(dummyBody), record.RequestHash)
+				assert.Equal(t, 24*time.Hour, record.ExpiresAt.Sub(record.CreatedAt))
+				assert.Equal(t, time.Minute, record.LockedUntil.Sub(record.CreatedAt))
+				return true, nil
+			}).Times(1)
+
+		stored, err := svc.Begin(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+		assert.Nil(t, stored)
+	})
+
+	t.Run("TestBegin: Replay", func(t *testing.T) {
+		contentType, etag := "application/json", `"2"`
+		mockIdempotencyRepo.EXPECT().Reserve(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+		mockIdempotencyRepo.EXPECT().GetRecord(gomock.Any(), dummyUserID, "retry-1").Return(&model.Record{
+			Method: "POST", Path: "/api/schedules/1/start", RequestHash: hash(dummyBody),
+			StatusCode: &statusOK, ContentType: &contentType, ETag: &etag, Body: []byte(`{"success":true}`),
+		}, nil).Times(1)
+
+		stored, err := svc.Begin(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+		assert.Equal(t, &model.Response{StatusCode: http.StatusOK, ContentType: contentType, ETag: etag, Body: []byte(`{"success":true}`)}, stored)
+	})
+
+	t.Run("TestBegin: Still Processing", func(t *testing.T) {
+		mockIdempotencyRepo.EXPECT().Reserve(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+		mockIdempotencyRepo.EXPECT().GetRecord(gomock.Any(), dummyUserID, "retry-1").Return(&model.Record{
+			Method: "POST", Path: "/api/schedules/1/start", RequestHash: hash(dummyBody),
+		}, nil).Times(1)
+
+		stored, err := svc.Begin(context.Background(), dummyRequest)
+		assert.Error(t, err)
+		assert.Nil(t, stored)
+		assert.Equal(t, exceptions.ErrConflict.WithDetails("A request with Idempotency-Key retry-1 is still being processed").Error(), err.Error())
+	})
+
+	t.Run("TestBegin: Key Reused For Different Body", func(t *testing.T) {
+		mockIdempotencyRepo.EXPECT().Reserve(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+		mockIdempotencyRepo.EXPECT().GetRecord(gomock.Any(), dummyUserID, "retry-1").Return(&model.Record{
+			Method: "POST", Path: "/api/schedules/1/start", RequestHash: hash(`{}`), StatusCode: &statusOK,
+		}, nil).Times(1)
+
+		stored, err := svc.Begin(context.Background(), dummyRequest)
+		assert.Error(t, err)
+		assert.Nil(t, stored)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestBegin: Key Reused For Different Route", func(t *testing.T) {
+		mockIdempotencyRepo.EXPECT().Reserve(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+		mockIdempotencyRepo.EXPECT().GetRecord(gomock.Any(), dummyUserID, "retry-1").Return(&model.Record{
+			Method: "POST", Path: "/api/schedules/1/end", RequestHash: hash(dummyBody), StatusCode: &statusOK,
+		}, nil).Times(1)
+
+		_, err := svc.Begin(context.Background(), dummyRequest)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestBegin: Key Too Long", func(t *testing.T) {
+		req := dummyRequest
+		req.Key = string(make([]byte, 256))
+
+		stored, err := svc.Begin(context.Background(), req)
+		assert.Error(t, err)
+		assert.Nil(t, stored)
+		assert.Equal(t, http.StatusBadRequest, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestBegin: Repository Error", func(t *testing.T) {
+		mockIdempotencyRepo.EXPECT().Reserve(gomock.Any(), gomock.Any()).Return(false, exceptions.ErrInternalError).Times(1)
+
+		stored, err := svc.Begin(context.Background(), dummyRequest)
+		assert.Error(t, err)
+		assert.Nil(t, stored)
+	})
+}
+
+func TestComplete(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyUserID := uuid.NewString()
+
+	t.Run("TestComplete: Stores Response", func(t *testing.T) {
+		resp := model.Response{StatusCode: http.StatusConflict, ContentType: "application/json", Body: []byte(`{"success":false}`)}
+		mockIdempotencyRepo.EXPECT().SaveResponse(gomock.Any(), dummyUserID, "retry-1", resp).Return(nil).Times(1)
+
+		err := svc.Complete(context.Background(), dummyUserID, "retry-1", resp)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestComplete: Server Error Releases Key", func(t *testing.T) {
+		mockIdempotencyRepo.EXPECT().Release(gomock.Any(), dummyUserID, "retry-1").Return(nil).Times(1)
+
+		err := svc.Complete(context.Background(), dummyUserID, "retry-1", model.Response{StatusCode: http.StatusInternalServerError})
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestComplete: Repository Error", func(t *testing.T) {
+		mockIdempotencyRepo.EXPECT().SaveResponse(gomock.Any(), dummyUserID, "retry-1", gomock.Any()).Return(exceptions.ErrInternalError).Times(1)
+
+		err := svc.Complete(context.Background(), dummyUserID, "retry-1", model.Response{StatusCode: http.StatusOK})
+		assert.Error(t, err)
+	})
+}
+
+func TestPurgeExpired(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	t.Run("TestPurgeExpired: OK", func(t *testing.T) {
+		mockIdempotencyRepo.EXPECT().DeleteExpired(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, before time.Time) (int64, error) {
+				assert.WithinDuration(t, time.Now(), before, time.Minute)
+				return 2, nil
+			}).Times(1)
+
+		purged, err := svc.PurgeExpired(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), purged)
+	})
+
+	t.Run("TestPurgeExpired: Repository Error", func(t *testing.T) {
+		mockIdempotencyRepo.EXPECT().DeleteExpired(gomock.Any(), gomock.Any()).Return(int64(0), exceptions.ErrInternalError).Times(1)
+
+		purged, err := svc.PurgeExpired(context.Background())
+		assert.Error(t, err)
+		assert.Zero(t, purged)
+	})
+}
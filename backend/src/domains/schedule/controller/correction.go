@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/responses"
+	"mini-evv-logger-backend/src/domains/schedule/model"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestCorrection handles proposing a correction of a visit's clock-in or clock-out
+func (sc *ScheduleController) RequestCorrection(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Schedule ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	var req model.RequestCorrectionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	req.ScheduleID = id // Set the ID from the URL parameter
+
+	correction, err := sc.svc.RequestCorrection(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, correction, "Correction requested successfully")
+}
+
+// GetScheduleCorrections handles fetching the corrections of a single schedule
+func (sc *ScheduleController) GetScheduleCorrections(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Schedule ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	filter := model.FilterCorrectionsRequest{}
+	err := c.QueryParser(&filter)
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+	}
+	filter.ScheduleID = id // Set the ID from the URL parameter
+
+	corrections, err := sc.svc.GetCorrections(ctx, filter)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, corrections, "Corrections retrieved successfully")
+}
+
+// GetCorrections handles fetching the corrections matching the query, e.g. the pending ones for review
+func (sc *ScheduleController) GetCorrections(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	filter := model.FilterCorrectionsRequest{}
+	err := c.QueryParser(&filter)
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+	}
+
+	corrections, err := sc.svc.GetCorrections(ctx, filter)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, corrections, "Corrections retrieved successfully")
+}
+
+// ApproveCorrection handles a coordinator approving a correction, which applies it to the schedule
+func (sc *ScheduleController) ApproveCorrection(c *fiber.Ctx) error {
+	return sc.resolveCorrection(c, model.CorrectionApproved, "Correction approved successfully")
+}
+
+// RejectCorrection handles a coordinator rejecting a correction
+func (sc *ScheduleController) RejectCorrection(c *fiber.Ctx) error {
+	return sc.resolveCorrection(c, model.CorrectionRejected, "Correction rejected successfully")
+}
+
+// resolveCorrection parses a resolution request and applies the given status
+func (sc *ScheduleController) resolveCorrection(c *fiber.Ctx, status, message string) error {
+	ctx := c.UserContext()
+
+	id, correctionID := c.Params("id"), c.Params("correctionId")
+	if id == "" || correctionID == "" {
+		return responses.Error(c, http.StatusBadRequest, "Schedule ID and correction ID are required", exceptions.ErrBadRequest.Error())
+	}
+
+	var req model.ResolveCorrectionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		}
+	}
+	req.ID = correctionID // Set the IDs from the URL parameters
+	req.ScheduleID = id
+	req.Status = status
+
+	correction, err := sc.svc.ResolveCorrection(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, correction, message)
+}
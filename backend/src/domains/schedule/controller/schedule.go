@@ -2,9 +2,11 @@ package controller
 
 import (
 	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/policy"
 	"mini-evv-logger-backend/responses"
 	"mini-evv-logger-backend/src/domains/schedule/model"
 	"mini-evv-logger-backend/src/domains/schedule/service"
+	"mini-evv-logger-backend/utils"
 	"net/http"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,21 +14,46 @@ import (
 
 // ScheduleController handles HTTP requests for schedules
 type ScheduleController struct {
-	svc service.ScheduleService
+	svc        service.ScheduleService
+	idempotent fiber.Handler // Replays retried clock-in and clock-out requests
 }
 
 // NewScheduleController creates a new ScheduleController
-func NewScheduleController(svc service.ScheduleService) *ScheduleController {
-	return &ScheduleController{svc: svc}
+func NewScheduleController(svc service.ScheduleService, idempotent fiber.Handler) *ScheduleController {
+	return &ScheduleController{svc: svc, idempotent: idempotent}
 }
 
 // Routes sets up the API endpoints for schedules
 func (sc *ScheduleController) Routes(app fiber.Router) {
 	scheduleRoutes := app.Group("/schedules")
-	scheduleRoutes.Get("/", sc.GetSchedules)
-	scheduleRoutes.Get("/:id", sc.GetScheduleDetails)
-	scheduleRoutes.Post("/:id/start", sc.StartVisit)
-	scheduleRoutes.Post("/:id/end", sc.EndVisit)
+	scheduleRoutes.Get("/", policy.Require(policy.ViewSchedule, nil), sc.GetSchedules)
+	scheduleRoutes.Post("/", policy.Require(policy.ManageSchedule, nil), sc.CreateSchedule)
+	scheduleRoutes.Get("/:id", policy.Require(policy.ViewSchedule, sc.scheduleResource), sc.GetScheduleDetails)
+	scheduleRoutes.Patch("/:id", policy.Require(policy.ManageSchedule, sc.scheduleResource), sc.UpdateSchedule)
+	scheduleRoutes.Post("/:id/cancel", policy.Require(policy.ManageSchedule, sc.scheduleResource), sc.CancelSchedule)
+	scheduleRoutes.Post("/:id/start", policy.Require(policy.StartVisit, sc.scheduleResource), sc.idempotent, sc.StartVisit)
+	scheduleRoutes.Post("/:id/end", policy.Require(policy.EndVisit, sc.scheduleResource), sc.idempotent, sc.EndVisit)
+	scheduleRoutes.Post("/:id/no-show", policy.Require(policy.ReportNoShow, sc.scheduleResource), sc.ReportNoShow)
+	scheduleRoutes.Post("/:id/reopen", policy.Require(policy.ManageSchedule, sc.scheduleResource), sc.ReopenSchedule)
+	scheduleRoutes.Get("/:id/status-history", policy.Require(policy.ViewSchedule, sc.scheduleResource), sc.GetStatusHistory)
+	scheduleRoutes.Get("/:id/history", policy.Require(policy.ViewSchedule, sc.scheduleResource), sc.GetHistory)
+	scheduleRoutes.Get("/:id/signature", policy.Require(policy.ViewSchedule, sc.scheduleResource), sc.GetSignature)
+	scheduleRoutes.Get("/:id/corrections", policy.Require(policy.ViewSchedule, sc.scheduleResource), sc.GetScheduleCorrections)
+	scheduleRoutes.Post("/:id/corrections", policy.Require(policy.RequestCorrection, sc.scheduleResource), sc.RequestCorrection)
+	scheduleRoutes.Post("/:id/corrections/:correctionId/approve", policy.Require(policy.ResolveCorrection, sc.scheduleResource), sc.ApproveCorrection)
+	scheduleRoutes.Post("/:id/corrections/:correctionId/reject", policy.Require(policy.ResolveCorrection, sc.scheduleResource), sc.RejectCorrection)
+
+	// Review queue of corrections across the schedules the principal can see
+	app.Get("/visit-corrections", policy.Require(policy.ViewSchedule, nil), sc.GetCorrections)
+}
+
+// scheduleResource resolves the ownership of the schedule in the :id route parameter
+func (sc *ScheduleController) scheduleResource(c *fiber.Ctx) (*policy.Resource, error) {
+	ownership, err := sc.svc.GetScheduleOwnership(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return nil, err
+	}
+	return &policy.Resource{CaregiverID: ownership.CaregiverID, BranchID: ownership.BranchID}, nil
 }
 
 // GetSchedules handles fetching all schedules with pagination
@@ -69,6 +96,7 @@ func (sc *ScheduleController) GetScheduleDetails(c *fiber.Ctx) error {
 	if err != nil {
 		return exceptions.HandleError(c, err)
 	}
+	c.Set(fiber.HeaderETag, utils.ETag(schedule.Version))
 	return responses.OK(c, schedule, "Schedule details retrieved successfully")
 }
 
@@ -86,7 +114,14 @@ func (sc *ScheduleController) StartVisit(c *fiber.Ctx) error {
 		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
 	}
 	req.ID = id // Set the ID from the URL parameter
-	err := sc.svc.StartVisit(ctx, req)
+
+	ifMatch, err := utils.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid If-Match header", err.Error())
+	}
+	req.IfMatch = ifMatch
+
+	err = sc.svc.StartVisit(ctx, req)
 	if err != nil {
 		return exceptions.HandleError(c, err)
 	}
@@ -107,9 +142,198 @@ func (sc *ScheduleController) EndVisit(c *fiber.Ctx) error {
 		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
 	}
 	req.ID = id // Set the ID from the URL parameter
-	err := sc.svc.EndVisit(ctx, req)
+
+	ifMatch, err := utils.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid If-Match header", err.Error())
+	}
+	req.IfMatch = ifMatch
+
+	err = sc.svc.EndVisit(ctx, req)
 	if err != nil {
 		return exceptions.HandleError(c, err)
 	}
 	return responses.OK(c, nil, "Visit ended successfully")
 }
+
+// CreateSchedule handles creating a new schedule
+func (sc *ScheduleController) CreateSchedule(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var req model.CreateScheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	schedule, err := sc.svc.CreateSchedule(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.Created(c, schedule, "Schedule created successfully")
+}
+
+// UpdateSchedule handles editing an upcoming schedule
+func (sc *ScheduleController) UpdateSchedule(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Schedule ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	var req model.UpdateScheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	req.ID = id // Set the ID from the URL parameter
+
+	ifMatch, err := utils.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid If-Match header", err.Error())
+	}
+	req.IfMatch = ifMatch
+
+	schedule, err := sc.svc.UpdateSchedule(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	c.Set(fiber.HeaderETag, utils.ETag(schedule.Version))
+	return responses.OK(c, schedule, "Schedule updated successfully")
+}
+
+// CancelSchedule handles cancelling an upcoming schedule
+func (sc *ScheduleController) CancelSchedule(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Schedule ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	var req model.CancelScheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	req.ID = id // Set the ID from the URL parameter
+
+	ifMatch, err := utils.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid If-Match header", err.Error())
+	}
+	req.IfMatch = ifMatch
+
+	schedule, err := sc.svc.CancelSchedule(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	c.Set(fiber.HeaderETag, utils.ETag(schedule.Version))
+	return responses.OK(c, schedule, "Schedule cancelled successfully")
+}
+
+// ReportNoShow handles reporting that the client was not available for a visit
+func (sc *ScheduleController) ReportNoShow(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Schedule ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	var req model.ReportNoShowRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	req.ID = id // Set the ID from the URL parameter
+
+	ifMatch, err := utils.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid If-Match header", err.Error())
+	}
+	req.IfMatch = ifMatch
+
+	schedule, err := sc.svc.ReportNoShow(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	c.Set(fiber.HeaderETag, utils.ETag(schedule.Version))
+	return responses.OK(c, schedule, "No-show reported successfully")
+}
+
+// ReopenSchedule handles reopening a finished visit
+func (sc *ScheduleController) ReopenSchedule(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Schedule ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	var req model.ReopenScheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	req.ID = id // Set the ID from the URL parameter
+
+	ifMatch, err := utils.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid If-Match header", err.Error())
+	}
+	req.IfMatch = ifMatch
+
+	schedule, err := sc.svc.ReopenSchedule(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	c.Set(fiber.HeaderETag, utils.ETag(schedule.Version))
+	return responses.OK(c, schedule, "Schedule reopened successfully")
+}
+
+// GetStatusHistory handles fetching every status change of a schedule
+func (sc *ScheduleController) GetStatusHistory(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Schedule ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	history, err := sc.svc.GetStatusHistory(ctx, id)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, history, "Status history retrieved successfully")
+}
+
+// GetHistory handles fetching the audit trail of a schedule and its tasks
+func (sc *ScheduleController) GetHistory(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Schedule ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	history, err := sc.svc.GetHistory(ctx, id)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, history, "History retrieved successfully")
+}
+
+// GetSignature handles downloading the signature given at clock-out as a PNG image
+func (sc *ScheduleController) GetSignature(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Schedule ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	signature, err := sc.svc.GetSignature(ctx, id)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	c.Set(fiber.HeaderContentType, "image/png")
+	c.Set(fiber.HeaderContentDisposition, `inline; filename="signature-`+id+`.png"`)
+	return c.Send(signature)
+}
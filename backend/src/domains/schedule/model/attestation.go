@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+)
+
+// Signature formats accepted at clock-out
+const (
+	SignatureFormatPNG     = "png"     // Image captured by the device
+	SignatureFormatStrokes = "strokes" // Pen strokes, rendered to an image by the server
+)
+
+// MaxSignatureBytes is the largest signature image accepted at clock-out
+const MaxSignatureBytes = 512 * 1024
+
+// Limits of a stroke signature, which bound the work of rendering it during clock-out
+const (
+	MaxSignaturePoints     = 5000  // Points across all strokes
+	MaxSignaturePathLength = 50000 // Length of all strokes together, in pixels
+)
+
+// Attestation is the client's or a family member's confirmation of a visit, given at clock-out
+type Attestation struct {
+	ScheduleID         string    `json:"-" db:"schedule_id"`
+	SignerName         *string   `json:"signer_name" db:"signer_name"`
+	SignerRelationship *string   `json:"signer_relationship" db:"signer_relationship"` // e.g., "client", "family", "guardian"
+	SignatureFormat    *string   `json:"signature_format" db:"signature_format"`       // "png" or "strokes", NULL when the client was unable to sign
+	UnableToSignReason *string   `json:"unable_to_sign_reason" db:"unable_to_sign_reason"`
+	SignedAt           time.Time `json:"signed_at" db:"signed_at"` // Device time of the clock-out
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	SignaturePNG       []byte    `json:"-" db:"signature_png"`     // Always PNG, also for strokes; downloaded separately
+	SignatureStrokes   *string   `json:"-" db:"signature_strokes"` // Strokes as sent in JSON, NULL for PNG signatures
+}
+
+// HasSignature reports whether the attestation carries a signature image
+func (a *Attestation) HasSignature() bool {
+	return a.SignatureFormat != nil
+}
+
+// AttestationRequest confirms a visit at clock-out: a signature with the signer's name and relationship,
+// or the reason the client was unable to sign
+type AttestationRequest struct {
+	SignerName         string          `json:"signer_name" validate:"max=200"`
+	SignerRelationship string          `json:"signer_relationship" validate:"omitempty,oneof=client family guardian representative other"`
+	SignaturePNG       []byte          `json:"signature_png" validate:"max=524288"`                                     // Base64 encoded in JSON, at most MaxSignatureBytes
+	SignatureStrokes   [][]StrokePoint `json:"signature_strokes" validate:"omitempty,max=200,dive,min=1,max=2000,dive"` // Each stroke is a list of points
+	UnableToSignReason *string         `json:"unable_to_sign_reason" validate:"omitempty,min=1,max=500"`
+}
+
+// StrokePoint is a point of a signature stroke, in pixels from the top left corner of the signature pad
+type StrokePoint struct {
+	X float64 `json:"x" validate:"min=0,max=2000"`
+	Y float64 `json:"y" validate:"min=0,max=2000"`
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"fmt"
+	taskModel "mini-evv-logger-backend/src/domains/task/model"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Correction statuses
+const (
+	CorrectionPending  = "pending" // Waiting for a coordinator
+	CorrectionApproved = "approved"
+	CorrectionRejected = "rejected"
+)
+
+// Correction is a proposed change to the recorded clock-in or clock-out of a visit.
+// It is applied to the schedule once a coordinator approves it.
+type Correction struct {
+	ID             string     `json:"id" db:"id"`
+	ScheduleID     string     `json:"schedule_id" db:"schedule_id"`
+	Status         string     `json:"status" db:"status"`         // e.g., "pending", "approved", "rejected"
+	StartTime      *time.Time `json:"start_time" db:"start_time"` // Proposed values, NULL leaves the recorded value unchanged
+	StartLatitude  *float64   `json:"start_latitude" db:"start_latitude"`
+	StartLongitude *float64   `json:"start_longitude" db:"start_longitude"`
+	EndTime        *time.Time `json:"end_time" db:"end_time"` // Clocks out a visit that is still in progress
+	EndLatitude    *float64   `json:"end_latitude" db:"end_latitude"`
+	EndLongitude   *float64   `json:"end_longitude" db:"end_longitude"`
+	ReasonCode     string     `json:"reason_code" db:"reason_code"`
+	Comment        *string    `json:"comment" db:"comment"`
+	RequestedBy    *string    `json:"requested_by" db:"requested_by"` // User who proposed the correction
+	ResolvedBy     *string    `json:"resolved_by" db:"resolved_by"`   // Coordinator who approved or rejected
+	ResolvedAt     *time.Time `json:"resolved_at" db:"resolved_at"`
+	ResolutionNote *string    `json:"resolution_note" db:"resolution_note"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+
+	Tasks []CorrectionTask `json:"tasks,omitempty" db:"-"` // Proposed task outcomes, the only way to change tasks of a completed visit
+}
+
+// CorrectionTask is the corrected status of one task of the visit
+type CorrectionTask struct {
+	CorrectionID string  `json:"-" db:"correction_id"`
+	TaskID       string  `json:"task_id" db:"task_id"`
+	Status       string  `json:"status" db:"status"` // e.g., "completed", "not_completed", "cancelled"
+	Reason       *string `json:"reason" db:"reason"` // Required if status is "not_completed"
+}
+
+// IsResolved reports whether a coordinator has already approved or rejected the correction
+func (c *Correction) IsResolved() bool {
+	return c.Status == CorrectionApproved || c.Status == CorrectionRejected
+}
+
+// CorrectedVisit is the clock-in and clock-out of a visit once a correction is applied
+type CorrectedVisit struct {
+	Start      VisitEvent
+	End        *VisitEvent       // nil while the visit stays in progress
+	Transition *StatusTransition // Set when the correction clocks out a visit that is still in progress
+	Version    int               // Version of the schedule after the correction; it applies only to Version-1
+	Tasks      []taskModel.Task  // Tasks with their corrected status and reason, at the version they were read
+}
+
+// RequestCorrectionRequest defines the request body for proposing a correction; nil fields are left unchanged
+type RequestCorrectionRequest struct {
+	ScheduleID     string     `json:"-" validate:"required,uuid"`
+	StartTime      *time.Time `json:"start_time"`
+	StartLatitude  *float64   `json:"start_latitude" validate:"required_with=StartLongitude,omitempty,latitude"`
+	StartLongitude *float64   `json:"start_longitude" validate:"required_with=StartLatitude,omitempty,longitude"`
+	EndTime        *time.Time `json:"end_time"`
+	EndLatitude    *float64   `json:"end_latitude" validate:"required_with=EndLongitude,omitempty,latitude"`
+	EndLongitude   *float64   `json:"end_longitude" validate:"required_with=EndLatitude,omitempty,longitude"`
+	ReasonCode     string     `json:"reason_code" validate:"required,oneof=forgot_to_clock_in forgot_to_clock_out device_issue gps_inaccurate wrong_time other"`
+	Comment        *string    `json:"comment" validate:"required_if=ReasonCode other,omitempty,min=1,max=1000"` // Required if reason_code is "other"
+
+	Tasks []CorrectionTaskRequest `json:"tasks" validate:"omitempty,max=50,unique=TaskID,dive"`
+}
+
+// CorrectionTaskRequest defines the corrected outcome of one task; it must resolve the task
+type CorrectionTaskRequest struct {
+	TaskID string  `json:"task_id" validate:"required,uuid"`
+	Status string  `json:"status" validate:"required,oneof=completed not_completed cancelled"`
+	Reason *string `json:"reason" validate:"required_if=Status not_completed,omitempty,min=1,max=1000"` // Required if status is "not_completed"
+}
+
+// IsEmpty reports whether the request proposes no change at all
+func (r *RequestCorrectionRequest) IsEmpty() bool {
+	return r.StartTime == nil && r.StartLatitude == nil && r.EndTime == nil && r.EndLatitude == nil && len(r.Tasks) == 0
+}
+
+// ResolveCorrectionRequest defines the request body for approving or rejecting a correction
+type ResolveCorrectionRequest struct {
+	ID         string  `json:"-" validate:"required,uuid"`
+	ScheduleID string  `json:"-" validate:"required,uuid"` // Schedule in the route, the correction must belong to it
+	Note       *string `json:"note" validate:"omitempty,min=1,max=1000"`
+	Status     string  `json:"-" validate:"required,oneof=approved rejected"`
+}
+
+// FilterCorrectionsRequest defines the query parameters for listing corrections
+type FilterCorrectionsRequest struct {
+	Status      string `query:"status" validate:"omitempty,oneof=pending approved rejected"`
+	ScheduleID  string `query:"-"` // Set from the route, only corrections of this schedule
+	CaregiverID string `query:"-"` // Set from the principal, only corrections on this caregiver's schedules
+	BranchID    string `query:"-"` // Set from the principal, only corrections on schedules in this branch
+}
+
+func (r *FilterCorrectionsRequest) String() string {
+	return fmt.Sprintf("FilterCorrectionsRequest{Status: %s, ScheduleID: %s, CaregiverID: %s, BranchID: %s}", r.Status, r.ScheduleID, r.CaregiverID, r.BranchID)
+}
+
+func (r *RequestCorrectionRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *ResolveCorrectionRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *FilterCorrectionsRequest) Validate() error {
+	return validator.New().Struct(r)
+}
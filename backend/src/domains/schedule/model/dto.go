@@ -2,30 +2,87 @@ package model
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 )
 
 // StartVisitRequest defines the request body for starting a visit
 type StartVisitRequest struct {
-	ID        string  `json:"id" validate:"required,uuid"` // Schedule ID
-	Latitude  float64 `json:"latitude" validate:"required"`
-	Longitude float64 `json:"longitude" validate:"required"`
+	ID         string     `json:"id" validate:"required,uuid"` // Schedule ID
+	Latitude   float64    `json:"latitude" validate:"required"`
+	Longitude  float64    `json:"longitude" validate:"required"`
+	CapturedAt *time.Time `json:"captured_at"` // Device time when the caregiver clocked in, defaults to the time the request is received
+	GPSFixAt   *time.Time `json:"gps_fix_at"`  // Device time of the location fix
+	IfMatch    *int       `json:"-"`           // Version from the If-Match header, nil to skip the check
+	Offline    bool       `json:"-"`           // Recorded offline and synced later, so the request is expected to arrive after CapturedAt
 }
 
 // EndVisitRequest defines the request body for ending a visit
 type EndVisitRequest struct {
-	ID        string  `json:"id" validate:"required,uuid"` // Schedule ID
-	Latitude  float64 `json:"latitude" validate:"required"`
-	Longitude float64 `json:"longitude" validate:"required"`
+	ID         string     `json:"id" validate:"required,uuid"` // Schedule ID
+	Latitude   float64    `json:"latitude" validate:"required"`
+	Longitude  float64    `json:"longitude" validate:"required"`
+	CapturedAt *time.Time `json:"captured_at"` // Device time when the caregiver clocked out, defaults to the time the request is received
+	GPSFixAt   *time.Time `json:"gps_fix_at"`  // Device time of the location fix
+	IfMatch    *int       `json:"-"`           // Version from the If-Match header, nil to skip the check
+	Offline    bool       `json:"-"`           // Recorded offline and synced later, so the request is expected to arrive after CapturedAt
+
+	Attestation *AttestationRequest `json:"attestation"` // Optional confirmation of the visit by the client or a family member
+}
+
+// CreateScheduleRequest defines the request body for creating a schedule
+type CreateScheduleRequest struct {
+	ClientID        string    `json:"client_id" validate:"required,uuid"`
+	CaregiverID     *string   `json:"caregiver_id" validate:"omitempty,uuid"`               // Optional, the visit stays unassigned when empty
+	ShiftTime       time.Time `json:"shift_time" validate:"required"`                       // RFC 3339, e.g. "2025-06-01T09:00:00Z"
+	DurationMinutes int       `json:"duration_minutes" validate:"required,min=15,max=1440"` // Planned length of the visit
+}
+
+// UpdateScheduleRequest defines the request body for editing an upcoming schedule; nil fields are left unchanged
+type UpdateScheduleRequest struct {
+	ID              string     `json:"-" validate:"required,uuid"`
+	ClientID        *string    `json:"client_id" validate:"omitempty,uuid"`
+	CaregiverID     *string    `json:"caregiver_id" validate:"omitempty,uuid"`
+	ShiftTime       *time.Time `json:"shift_time"`
+	DurationMinutes *int       `json:"duration_minutes" validate:"omitempty,min=15,max=1440"`
+	ShiftEndTime    *time.Time `json:"-"` // Set by the service, keeps the planned duration when only shift_time changes
+	IfMatch         *int       `json:"-"` // Version from the If-Match header, nil to skip the check
+	Version         int        `json:"-"` // Set by the service to the version it read; the update applies only while it is current
+}
+
+// CancelScheduleRequest defines the request body for cancelling a schedule
+type CancelScheduleRequest struct {
+	ID      string `json:"-" validate:"required,uuid"`
+	Reason  string `json:"reason" validate:"required,max=500"`
+	IfMatch *int   `json:"-"` // Version from the If-Match header, nil to skip the check
+}
+
+// ReportNoShowRequest defines the request body for reporting that the client was not available for a visit
+type ReportNoShowRequest struct {
+	ID      string `json:"-" validate:"required,uuid"`
+	Reason  string `json:"reason" validate:"required,max=500"`
+	IfMatch *int   `json:"-"` // Version from the If-Match header, nil to skip the check
+}
+
+// ReopenScheduleRequest defines the request body for reopening a finished visit
+type ReopenScheduleRequest struct {
+	ID      string `json:"-" validate:"required,uuid"`
+	Reason  string `json:"reason" validate:"required,max=500"`
+	IfMatch *int   `json:"-"` // Version from the If-Match header, nil to skip the check
 }
 
 // FilterSchedulesRequest defines the request body for filtering schedules
 type FilterSchedulesRequest struct {
-	Limit  int    `query:"limit" validate:"required,min=1,max=100"`       //
-	Page   int    `query:"page" validate:"required,min=1"`                // Page number for pagination
-	Offset int    `query:"-"`                                             // Offset for pagination, optional
-	Date   string `query:"date" validate:"omitempty,datetime=2006-01-02"` // Date in YYYY-MM-DD format
+	Limit       int    `query:"limit" validate:"required,min=1,max=100"`                             //
+	Page        int    `query:"page" validate:"required,min=1"`                                      // Page number for pagination
+	Offset      int    `query:"-"`                                                                   // Offset for pagination, optional
+	Date        string `query:"date" validate:"omitempty,datetime=2006-01-02,excluded_with=From To"` // Date in YYYY-MM-DD format
+	From        string `query:"from" validate:"omitempty,datetime=2006-01-02"`                       // First day of a date range, inclusive
+	To          string `query:"to" validate:"omitempty,datetime=2006-01-02"`                         // Last day of a date range, inclusive
+	TZ          string `query:"tz" validate:"omitempty,timezone"`                                    // IANA name the days are read in, defaults to each client's timezone
+	CaregiverID string `query:"caregiver_id" validate:"omitempty,uuid"`                              // Only schedules assigned to this caregiver
+	BranchID    string `query:"branch_id" validate:"omitempty,uuid"`                                 // Only schedules of caregivers in this branch
 }
 
 func (r *FilterSchedulesRequest) Validate() error {
@@ -35,7 +92,31 @@ func (r *FilterSchedulesRequest) Validate() error {
 	if r.Limit < 1 {
 		r.Limit = 10 // Default limit if not set or invalid
 	}
-	return validator.New().Struct(r)
+	err := validator.New().Struct(r)
+	if err != nil {
+		return err
+	}
+	// Dates in YYYY-MM-DD format sort the same as the days they name
+	if r.From != "" && r.To != "" && r.From > r.To {
+		return fmt.Errorf("from must not be after to")
+	}
+	return nil
+}
+
+// DayRange returns the requested days as a half-open range [start, end) of local dates in YYYY-MM-DD format,
+// where end is the day after the last requested day. Either side is empty when it is not bounded.
+func (r *FilterSchedulesRequest) DayRange() (start, end string) {
+	first, last := r.From, r.To
+	if r.Date != "" {
+		first, last = r.Date, r.Date
+	}
+	if last != "" {
+		day, err := time.Parse(time.DateOnly, last)
+		if err == nil {
+			end = day.AddDate(0, 0, 1).Format(time.DateOnly)
+		}
+	}
+	return first, end
 }
 
 func (r *FilterSchedulesRequest) SetOffset() {
@@ -43,7 +124,7 @@ func (r *FilterSchedulesRequest) SetOffset() {
 }
 
 func (r *FilterSchedulesRequest) String() string {
-	return fmt.Sprintf("FilterSchedulesRequest{Limit: %d, Page: %d, Date: %s}", r.Limit, r.Page, r.Date)
+	return fmt.Sprintf("FilterSchedulesRequest{Limit: %d, Page: %d, Date: %s, From: %s, To: %s, TZ: %s, CaregiverID: %s, BranchID: %s}", r.Limit, r.Page, r.Date, r.From, r.To, r.TZ, r.CaregiverID, r.BranchID)
 }
 
 // PaginatedSchedulesResponse holds schedules with pagination info (simplified, actual Pagination struct moved to responses)
@@ -73,3 +154,23 @@ func (r *StartVisitRequest) Validate() error {
 func (r *EndVisitRequest) Validate() error {
 	return validator.New().Struct(r)
 }
+
+func (r *CreateScheduleRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *UpdateScheduleRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *CancelScheduleRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *ReportNoShowRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *ReopenScheduleRequest) Validate() error {
+	return validator.New().Struct(r)
+}
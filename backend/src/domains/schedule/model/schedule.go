@@ -2,23 +2,90 @@ package model
 
 import (
 	taskModel "mini-evv-logger-backend/src/domains/task/model"
+	visitNoteModel "mini-evv-logger-backend/src/domains/visitnote/model"
 	"time"
 )
 
 // Schedule represents a caregiver's schedule
 type Schedule struct {
-	ID             string           `json:"id" db:"id"`
-	ClientName     string           `json:"client_name" db:"client_name"`
-	ShiftTime      time.Time        `json:"shift_time" db:"shift_time"`
-	Location       string           `json:"location" db:"location"`
-	Status         string           `json:"status" db:"status"`                   // e.g., "upcoming", "in-progress", "completed", "missed"
-	StartTime      *time.Time       `json:"start_time" db:"start_time"`           // Pointer to allow NULL
-	StartLatitude  *float64         `json:"start_latitude" db:"start_latitude"`   // Pointer to allow NULL
-	StartLongitude *float64         `json:"start_longitude" db:"start_longitude"` // Pointer to allow NULL
-	EndTime        *time.Time       `json:"end_time" db:"end_time"`               // Pointer to allow NULL
-	EndLatitude    *float64         `json:"end_latitude" db:"end_latitude"`       // Pointer to allow NULL
-	EndLongitude   *float64         `json:"end_longitude" db:"end_longitude"`     // Pointer to allow NULL
-	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time        `json:"updated_at" db:"updated_at"`
-	Tasks          []taskModel.Task `json:"tasks,omitempty" db:"-"` // For schedule details, includes associated tasks
+	ID              string           `json:"id" db:"id"`
+	CaregiverID     *string          `json:"caregiver_id" db:"caregiver_id"` // Assigned caregiver, NULL when unassigned
+	ClientID        string           `json:"client_id" db:"client_id"`
+	ClientName      string           `json:"client_name" db:"client_name"` // Joined from clients
+	ShiftTime       time.Time        `json:"shift_time" db:"shift_time"`
+	ShiftEndTime    time.Time        `json:"shift_end_time" db:"shift_end_time"`               // Planned end of the visit
+	DurationMinutes int              `json:"duration_minutes" db:"duration_minutes"`           // Planned length of the visit, from shift_time to shift_end_time
+	Location        string           `json:"location" db:"location"`                           // Client's formatted home address
+	ClientLatitude  *float64         `json:"client_latitude" db:"client_latitude"`             // Client's home coordinates
+	ClientLongitude *float64         `json:"client_longitude" db:"client_longitude"`           // Client's home coordinates
+	Status          Status           `json:"status" db:"status"`                               // See status.go for the allowed transitions
+	StatusReason    *string          `json:"status_reason" db:"status_reason"`                 // Why the status was last changed, e.g. a cancellation reason; NULL for clock-in/out
+	StatusChangedAt *time.Time       `json:"status_changed_at" db:"status_changed_at"`         // When the status was last changed
+	StatusChangedBy *string          `json:"status_changed_by" db:"status_changed_by"`         // Who last changed the status, NULL for the sweeper
+	SeriesID        *string          `json:"series_id" db:"series_id"`                         // Recurring series the visit was generated from, NULL for one-off visits
+	OccurrenceTime  *time.Time       `json:"occurrence_time" db:"occurrence_time"`             // Shift time originally generated by the series
+	Detached        bool             `json:"is_detached" db:"is_detached"`                     // Edited individually, no longer replaced by series changes
+	CarePlanID      *string          `json:"care_plan_id" db:"care_plan_id"`                   // Care plan the initial tasks were filled from, NULL when the client had none
+	CarePlanVersion *int             `json:"care_plan_version" db:"care_plan_version"`         // Joined from care_plans
+	StartTime       *time.Time       `json:"start_time" db:"start_time"`                       // Pointer to allow NULL
+	StartLatitude   *float64         `json:"start_latitude" db:"start_latitude"`               // Pointer to allow NULL
+	StartLongitude  *float64         `json:"start_longitude" db:"start_longitude"`             // Pointer to allow NULL
+	StartDistance   *float64         `json:"start_distance_meters" db:"start_distance_meters"` // Distance from the client's home at clock-in
+	StartOutOfFence bool             `json:"start_out_of_geofence" db:"start_out_of_geofence"` // Clock-in happened outside the geofence
+	StartReceivedAt *time.Time       `json:"start_received_at" db:"start_received_at"`         // Server time the clock-in arrived; start_time is the device time
+	StartGPSFixAt   *time.Time       `json:"start_gps_fix_at" db:"start_gps_fix_at"`           // Device time of the clock-in location fix
+	StartClockSkew  bool             `json:"start_clock_skewed" db:"start_clock_skewed"`       // Device and server time of the clock-in differ by more than the tolerance
+	EndTime         *time.Time       `json:"end_time" db:"end_time"`                           // Pointer to allow NULL
+	EndLatitude     *float64         `json:"end_latitude" db:"end_latitude"`                   // Pointer to allow NULL
+	EndLongitude    *float64         `json:"end_longitude" db:"end_longitude"`                 // Pointer to allow NULL
+	EndDistance     *float64         `json:"end_distance_meters" db:"end_distance_meters"`     // Distance from the client's home at clock-out
+	EndOutOfFence   bool             `json:"end_out_of_geofence" db:"end_out_of_geofence"`     // Clock-out happened outside the geofence
+	EndReceivedAt   *time.Time       `json:"end_received_at" db:"end_received_at"`             // Server time the clock-out arrived; end_time is the device time
+	EndGPSFixAt     *time.Time       `json:"end_gps_fix_at" db:"end_gps_fix_at"`               // Device time of the clock-out location fix
+	EndClockSkew    bool             `json:"end_clock_skewed" db:"end_clock_skewed"`           // Device and server time of the clock-out differ by more than the tolerance
+	ActualMinutes   *int             `json:"actual_minutes" db:"actual_minutes"`               // Minutes from clock-in to clock-out, set on clock-out
+	VarianceMinutes *int             `json:"variance_minutes" db:"variance_minutes"`           // Actual minus planned minutes, negative when the visit ended early
+	Version         int              `json:"version" db:"version"`                             // Incremented by every change, sent as the ETag
+	CreatedAt       time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at" db:"updated_at"`
+	Tasks           []taskModel.Task `json:"tasks,omitempty" db:"-"` // For schedule details, includes associated tasks
+
+	// Clock-in and clock-out as recorded before the first approved correction, NULL when never corrected
+	OriginalStartTime      *time.Time `json:"original_start_time" db:"original_start_time"`
+	OriginalStartLatitude  *float64   `json:"original_start_latitude" db:"original_start_latitude"`
+	OriginalStartLongitude *float64   `json:"original_start_longitude" db:"original_start_longitude"`
+	OriginalEndTime        *time.Time `json:"original_end_time" db:"original_end_time"` // Also NULL when the visit had not been clocked out
+	OriginalEndLatitude    *float64   `json:"original_end_latitude" db:"original_end_latitude"`
+	OriginalEndLongitude   *float64   `json:"original_end_longitude" db:"original_end_longitude"`
+	CorrectedAt            *time.Time `json:"corrected_at" db:"corrected_at"` // When a correction was last applied
+
+	Attestation *Attestation `json:"attestation,omitempty" db:"-"` // For schedule details, the confirmation given at clock-out
+
+	Notes []visitNoteModel.VisitNote `json:"notes,omitempty" db:"-"` // For schedule details, oldest first
+}
+
+// ScheduleOwnership identifies who a schedule belongs to, for authorization checks
+type ScheduleOwnership struct {
+	CaregiverID *string `db:"caregiver_id"`
+	BranchID    *string `db:"branch_id"`
+}
+
+// VisitEvent is a clock-in or clock-out recorded against a schedule
+type VisitEvent struct {
+	Time           time.Time  // Device time of the event
+	ReceivedAt     time.Time  // Server time the event arrived
+	GPSFixAt       *time.Time // Device time of the location fix, nil when not reported
+	ClockSkewed    bool       // Time and ReceivedAt differ by more than the tolerance
+	Latitude       float64
+	Longitude      float64
+	DistanceMeters *float64 // Distance from the client's home, nil when the client has no coordinates
+	OutOfGeofence  bool
+	Minutes        int          // Clock-out only: minutes since clock-in
+	Variance       int          // Clock-out only: Minutes minus the planned duration
+	Attestation    *Attestation // Clock-out only: the client's confirmation, nil when none was given
+}
+
+// UnresolvedTasks is returned with the conflict error of a clock-out while tasks are still open
+type UnresolvedTasks struct {
+	TaskIDs []string `json:"unresolved_task_ids"`
 }
@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+// Status is the state of a visit
+type Status string
+
+const (
+	StatusUpcoming   Status = "upcoming"    // Planned, not started yet
+	StatusInProgress Status = "in-progress" // Clocked in
+	StatusCompleted  Status = "completed"   // Clocked out
+	StatusMissed     Status = "missed"      // Not started within the grace period, set by the sweeper
+	StatusCancelled  Status = "cancelled"   // Called off by a coordinator before it started
+	StatusNoShow     Status = "no-show"     // The client was not available for the visit
+	StatusReopened   Status = "reopened"    // Opened again by a coordinator so the visit can be redone or corrected
+)
+
+// transitions lists the statuses a visit may move to from each status.
+// Every status change of a schedule must be allowed here.
+var transitions = map[Status][]Status{
+	StatusUpcoming:   {StatusInProgress, StatusMissed, StatusCancelled, StatusNoShow},
+	StatusInProgress: {StatusCompleted, StatusNoShow},
+	StatusCompleted:  {StatusReopened},
+	StatusMissed:     {StatusReopened},
+	StatusCancelled:  {StatusReopened},
+	StatusNoShow:     {StatusReopened},
+	StatusReopened:   {StatusInProgress, StatusCancelled, StatusNoShow},
+}
+
+// CanTransition reports whether a visit may move from one status to another
+func CanTransition(from, to Status) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusTransition is one change of a schedule's status, kept as its status history
+type StatusTransition struct {
+	ID         string    `json:"id" db:"id"`
+	ScheduleID string    `json:"schedule_id" db:"schedule_id"`
+	FromStatus Status    `json:"from_status" db:"from_status"`
+	ToStatus   Status    `json:"to_status" db:"to_status"`
+	Reason     *string   `json:"reason" db:"reason"`         // e.g. a cancellation reason, NULL for clock-in/out
+	ChangedBy  *string   `json:"changed_by" db:"changed_by"` // User who made the change, NULL for the sweeper
+	ChangedAt  time.Time `json:"changed_at" db:"changed_at"`
+	Version    int       `json:"version" db:"version"` // Version of the schedule after the change; the change applies only to Version-1
+}
+
+// TransitionConflict is returned with the conflict error of a status change the state machine does not allow
+type TransitionConflict struct {
+	CurrentStatus   Status `json:"current_status"`
+	RequestedStatus Status `json:"requested_status"`
+}
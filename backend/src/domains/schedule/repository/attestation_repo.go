@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context" // Import context
+	"database/sql"
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/src/domains/schedule/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+// GetAttestation fetches the attestation given at the clock-out of a schedule, without the signature itself
+func (r *scheduleRepositoryImpl) GetAttestation(ctx context.Context, scheduleID string) (*model.Attestation, error) {
+	var attestation model.Attestation
+	sqlQuery, args, err := squirrel.Select("schedule_id", "signer_name", "signer_relationship", "signature_format", "unable_to_sign_reason", "signed_at", "created_at").
+		From("visit_attestations").
+		Where(squirrel.Eq{"schedule_id": scheduleID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to build SQL query for GetAttestation")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &attestation, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Attestation for schedule ID %s not found", scheduleID))
+		}
+		r.logger.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to execute SQL query for GetAttestation")
+		return nil, exceptions.ErrInternalError
+	}
+	return &attestation, nil
+}
+
+// GetSignature fetches the PNG signature image given at the clock-out of a schedule
+func (r *scheduleRepositoryImpl) GetSignature(ctx context.Context, scheduleID string) ([]byte, error) {
+	var signature []byte
+	sqlQuery, args, err := squirrel.Select("signature_png").
+		From("visit_attestations").
+		Where(squirrel.Eq{"schedule_id": scheduleID}).
+		Where("signature_png IS NOT NULL").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to build SQL query for GetSignature")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &signature, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.Warn().Str("schedule_id", scheduleID).Msg("Signature not found in database")
+			return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Signature for schedule ID %s not found", scheduleID))
+		}
+		r.logger.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to execute SQL query for GetSignature")
+		return nil, exceptions.ErrInternalError
+	}
+	return signature, nil
+}
+
+// saveAttestation stores attestation within tx, replacing the one of an earlier clock-out of a reopened visit
+func (r *scheduleRepositoryImpl) saveAttestation(ctx context.Context, tx *sqlx.Tx, attestation model.Attestation) error {
+	var signature any // NULL rather than an empty bytea when the client was unable to sign
+	if attestation.HasSignature() {
+		signature = attestation.SignaturePNG
+	}
+
+	sqlQuery, args, err := squirrel.Insert("visit_attestations").
+		Columns("schedule_id", "signer_name", "signer_relationship", "signature_format", "signature_png", "signature_strokes", "unable_to_sign_reason", "signed_at").
+		Values(attestation.ScheduleID, attestation.SignerName, attestation.SignerRelationship, attestation.SignatureFormat,
+			signature, attestation.SignatureStrokes, attestation.UnableToSignReason, attestation.SignedAt).
+		Suffix("ON CONFLICT (schedule_id) DO UPDATE SET signer_name = EXCLUDED.signer_name, signer_relationship = EXCLUDED.signer_relationship, " +
+			"signature_format = EXCLUDED.signature_format, signature_png = EXCLUDED.signature_png, signature_strokes = EXCLUDED.signature_strokes, " +
+			"unable_to_sign_reason = EXCLUDED.unable_to_sign_reason, signed_at = EXCLUDED.signed_at, created_at = NOW()").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", attestation.ScheduleID).Msg("Failed to build SQL query for saveAttestation")
+		return exceptions.ErrInternalError
+	}
+
+	_, err = tx.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", attestation.ScheduleID).Msg("Failed to execute SQL query for saveAttestation")
+		return exceptions.ErrInternalError
+	}
+	return nil
+}
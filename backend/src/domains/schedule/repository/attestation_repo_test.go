@@ -0,0 +1,84 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAttestation(t *testing.T) {
+	initMocks(t)
+
+	dummyScheduleID := uuid.NewString()
+	query := `SELECT schedule_id, signer_name, signer_relationship, signature_format, unable_to_sign_reason, signed_at, created_at FROM visit_attestations WHERE schedule_id = $1`
+	t.Run("TestGetAttestation: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyScheduleID).
+			WillReturnRows(sqlmock.NewRows([]string{"schedule_id", "signer_name", "signer_relationship", "signature_format", "unable_to_sign_reason", "signed_at", "created_at"}).
+				AddRow(dummyScheduleID, "Jane Doe", "family", "strokes", nil, time.Now(), time.Now()))
+
+		attestation, err := repo.GetAttestation(context.Background(), dummyScheduleID)
+		assert.Nil(t, err)
+		assert.Equal(t, "Jane Doe", *attestation.SignerName)
+		assert.True(t, attestation.HasSignature())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestGetAttestation: Not Found", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyScheduleID).
+			WillReturnError(sql.ErrNoRows)
+
+		attestation, err := repo.GetAttestation(context.Background(), dummyScheduleID)
+		assert.Nil(t, attestation)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 404: Resource not found - Attestation for schedule ID "+dummyScheduleID+" not found", err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+}
+
+func TestGetSignature(t *testing.T) {
+	initMocks(t)
+
+	dummyScheduleID := uuid.NewString()
+	query := `SELECT signature_png FROM visit_attestations WHERE schedule_id = $1 AND signature_png IS NOT NULL`
+	t.Run("TestGetSignature: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyScheduleID).
+			WillReturnRows(sqlmock.NewRows([]string{"signature_png"}).AddRow([]byte("\x89PNG")))
+
+		signature, err := repo.GetSignature(context.Background(), dummyScheduleID)
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("\x89PNG"), signature)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestGetSignature: Not Found", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyScheduleID).
+			WillReturnError(sql.ErrNoRows)
+
+		signature, err := repo.GetSignature(context.Background(), dummyScheduleID)
+		assert.Nil(t, signature)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 404: Resource not found - Signature for schedule ID "+dummyScheduleID+" not found", err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestGetSignature: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyScheduleID).
+			WillReturnError(sql.ErrConnDone)
+
+		signature, err := repo.GetSignature(context.Background(), dummyScheduleID)
+		assert.Nil(t, signature)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"context" // Import context
+	"database/sql"
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	auditModel "mini-evv-logger-backend/src/domains/audit/model"
+	"mini-evv-logger-backend/src/domains/schedule/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var correctionColumns = []string{"sc.id", "sc.schedule_id", "sc.status", "sc.start_time", "sc.start_latitude", "sc.start_longitude",
+	"sc.end_time", "sc.end_latitude", "sc.end_longitude", "sc.reason_code", "sc.comment", "sc.requested_by",
+	"sc.resolved_by", "sc.resolved_at", "sc.resolution_note", "sc.created_at", "sc.updated_at"}
+
+// correctedColumns are the schedule columns a correction may change; their values before the
+// first approved correction are kept in the matching original_ columns
+var correctedColumns = []string{"start_time", "start_latitude", "start_longitude", "end_time", "end_latitude", "end_longitude"}
+
+// CreateCorrection stores a new pending correction with its task changes, in one transaction.
+// It returns a conflict if the visit already has a pending correction.
+func (r *scheduleRepositoryImpl) CreateCorrection(ctx context.Context, correction model.Correction) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", correction.ScheduleID).Msg("Failed to begin transaction for CreateCorrection")
+		return exceptions.ErrInternalError
+	}
+	defer tx.Rollback() // No-op once committed
+
+	sqlQuery, args, err := squirrel.Insert("schedule_corrections").
+		Columns("id", "schedule_id", "status", "start_time", "start_latitude", "start_longitude",
+			"end_time", "end_latitude", "end_longitude", "reason_code", "comment", "requested_by").
+		Values(correction.ID, correction.ScheduleID, correction.Status, correction.StartTime, correction.StartLatitude, correction.StartLongitude,
+			correction.EndTime, correction.EndLatitude, correction.EndLongitude, correction.ReasonCode, correction.Comment, correction.RequestedBy).
+		Suffix("ON CONFLICT (schedule_id) WHERE status = 'pending' DO NOTHING").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", correction.ScheduleID).Msg("Failed to build SQL query for CreateCorrection")
+		return exceptions.ErrInternalError
+	}
+
+	result, err := tx.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", correction.ScheduleID).Msg("Failed to execute SQL query for CreateCorrection")
+		return exceptions.ErrInternalError
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", correction.ScheduleID).Msg("Failed to read affected rows for CreateCorrection")
+		return exceptions.ErrInternalError
+	}
+	if affected == 0 {
+		// A concurrent request stored its pending correction after the service layer checked
+		r.logger.Warn().Str("schedule_id", correction.ScheduleID).Msg("Pending correction already exists for CreateCorrection")
+		return exceptions.ErrConflict.WithDetails(fmt.Sprintf("Schedule ID %s already has a pending correction", correction.ScheduleID))
+	}
+
+	if len(correction.Tasks) > 0 {
+		qb := squirrel.Insert("schedule_correction_tasks").
+			Columns("correction_id", "task_id", "status", "reason").
+			PlaceholderFormat(squirrel.Dollar)
+		for _, task := range correction.Tasks {
+			qb = qb.Values(correction.ID, task.TaskID, task.Status, task.Reason)
+		}
+		sqlQuery, args, err = qb.ToSql()
+		if err != nil {
+			r.logger.Error().Err(err).Str("schedule_id", correction.ScheduleID).Msg("Failed to build SQL query for CreateCorrection")
+			return exceptions.ErrInternalError
+		}
+		_, err = tx.ExecContext(ctx, sqlQuery, args...)
+		if err != nil {
+			r.logger.Error().Err(err).Str("schedule_id", correction.ScheduleID).Msg("Failed to execute SQL query for CreateCorrection")
+			return exceptions.ErrInternalError
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", correction.ScheduleID).Msg("Failed to commit transaction for CreateCorrection")
+		return exceptions.ErrInternalError
+	}
+	return nil
+}
+
+// GetCorrections fetches the corrections matching the filter with their task changes, newest first
+func (r *scheduleRepositoryImpl) GetCorrections(ctx context.Context, filter model.FilterCorrectionsRequest) ([]model.Correction, error) {
+	corrections := []model.Correction{}
+	qb := squirrel.Select(correctionColumns...).
+		From("schedule_corrections sc").
+		OrderBy("sc.created_at DESC").
+		PlaceholderFormat(squirrel.Dollar)
+
+	if filter.Status != "" {
+		qb = qb.Where(squirrel.Eq{"sc.status": filter.Status})
+	}
+	if filter.ScheduleID != "" {
+		qb = qb.Where(squirrel.Eq{"sc.schedule_id": filter.ScheduleID})
+	}
+	if filter.CaregiverID != "" {
+		// Only return the corrections on schedules assigned to the requested caregiver
+		qb = qb.Where(squirrel.Expr("sc.schedule_id IN (SELECT id FROM schedules WHERE caregiver_id = ?)", filter.CaregiverID))
+	}
+	if filter.BranchID != "" {
+		// Only return the corrections on schedules assigned to caregivers of the requested branch
+		qb = qb.Where(squirrel.Expr("sc.schedule_id IN (SELECT s.id FROM schedules s JOIN caregivers c ON c.id = s.caregiver_id WHERE c.branch_id = ?)", filter.BranchID))
+	}
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to build SQL query for GetCorrections")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.SelectContext(ctx, &corrections, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to execute SQL query for GetCorrections")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.loadCorrectionTasks(ctx, corrections, "GetCorrections")
+	if err != nil {
+		return nil, err
+	}
+	return corrections, nil
+}
+
+// GetCorrectionByID fetches a single correction by ID with its task changes
+func (r *scheduleRepositoryImpl) GetCorrectionByID(ctx context.Context, id string) (*model.Correction, error) {
+	var correction model.Correction
+	sqlQuery, args, err := squirrel.Select(correctionColumns...).
+		From("schedule_corrections sc").
+		Where(squirrel.Eq{"sc.id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("correction_id", id).Msg("Failed to build SQL query for GetCorrectionByID")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &correction, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.Warn().Str("correction_id", id).Msg("Correction not found in database")
+			return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Correction with ID %s not found", id))
+		}
+		r.logger.Error().Err(err).Str("correction_id", id).Msg("Failed to execute SQL query for GetCorrectionByID")
+		return nil, exceptions.ErrInternalError
+	}
+
+	corrections := []model.Correction{correction}
+	err = r.loadCorrectionTasks(ctx, corrections, "GetCorrectionByID")
+	if err != nil {
+		return nil, err
+	}
+	return &corrections[0], nil
+}
+
+// loadCorrectionTasks fills in the task changes of corrections with a single query
+func (r *scheduleRepositoryImpl) loadCorrectionTasks(ctx context.Context, corrections []model.Correction, method string) error {
+	if len(corrections) == 0 {
+		return nil
+	}
+	ids := make([]string, len(corrections))
+	for i, correction := range corrections {
+		ids[i] = correction.ID
+	}
+
+	sqlQuery, args, err := squirrel.Select("correction_id", "task_id", "status", "reason").
+		From("schedule_correction_tasks").
+		Where(squirrel.Eq{"correction_id": ids}).
+		OrderBy("task_id ASC").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Msgf("Failed to build SQL query for %s", method)
+		return exceptions.ErrInternalError
+	}
+
+	var tasks []model.CorrectionTask
+	err = r.db.SelectContext(ctx, &tasks, sqlQuery, args...)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error().Err(err).Msgf("Failed to execute SQL query for %s", method)
+		return exceptions.ErrInternalError
+	}
+
+	byCorrection := make(map[string][]model.CorrectionTask, len(corrections))
+	for _, task := range tasks {
+		byCorrection[task.CorrectionID] = append(byCorrection[task.CorrectionID], task)
+	}
+	for i := range corrections {
+		corrections[i].Tasks = byCorrection[corrections[i].ID]
+	}
+	return nil
+}
+
+// ApplyCorrection writes the corrected clock-in, clock-out and task statuses of visit and marks
+// correction as approved, in one transaction that is also recorded in the audit trail. The first
+// correction of a schedule keeps the recorded values in the original_ columns. A schedule or
+// correction that was changed in the meantime yields a conflict.
+func (r *scheduleRepositoryImpl) ApplyCorrection(ctx context.Context, correction model.Correction, visit model.CorrectedVisit) error {
+	qb := squirrel.Update("schedules")
+	for _, column := range correctedColumns {
+		qb = qb.Set("original_"+column, squirrel.Expr(fmt.Sprintf("CASE WHEN corrected_at IS NULL THEN %s ELSE original_%s END", column, column)))
+	}
+	qb = qb.Set("corrected_at", correction.ResolvedAt).
+		Set("start_time", visit.Start.Time).
+		Set("start_latitude", visit.Start.Latitude).
+		Set("start_longitude", visit.Start.Longitude).
+		Set("start_distance_meters", visit.Start.DistanceMeters).
+		Set("start_out_of_geofence", visit.Start.OutOfGeofence)
+	if visit.End != nil {
+		qb = qb.Set("end_time", visit.End.Time).
+			Set("end_latitude", visit.End.Latitude).
+			Set("end_longitude", visit.End.Longitude).
+			Set("end_distance_meters", visit.End.DistanceMeters).
+			Set("end_out_of_geofence", visit.End.OutOfGeofence).
+			Set("actual_minutes", visit.End.Minutes).
+			Set("variance_minutes", visit.End.Variance)
+	}
+
+	return r.audited(ctx, correction.ScheduleID, auditModel.ActionVisitCorrected, "ApplyCorrection", func(tx *sqlx.Tx) error {
+		err := r.updateTaskStatuses(ctx, tx, visit.Tasks, *correction.ResolvedAt, "ApplyCorrection")
+		if err != nil {
+			return err
+		}
+
+		// A correction that clocks out a visit in progress also completes it
+		if visit.Transition != nil {
+			err = r.transition(ctx, tx, qb, *visit.Transition, "ApplyCorrection")
+			if err != nil {
+				return err
+			}
+			return r.resolveCorrection(ctx, tx, correction, "ApplyCorrection")
+		}
+
+		sqlQuery, args, err := qb.
+			Set("updated_at", correction.ResolvedAt).
+			Set("version", visit.Version).
+			Where(squirrel.Eq{"id": correction.ScheduleID, "version": visit.Version - 1}).
+			PlaceholderFormat(squirrel.Dollar).
+			ToSql()
+		if err != nil {
+			r.logger.Error().Err(err).Str("schedule_id", correction.ScheduleID).Msg("Failed to build SQL query for ApplyCorrection")
+			return exceptions.ErrInternalError
+		}
+
+		result, err := tx.ExecContext(ctx, sqlQuery, args...)
+		if err != nil {
+			r.logger.Error().Err(err).Str("schedule_id", correction.ScheduleID).Msg("Failed to execute SQL query for ApplyCorrection")
+			return exceptions.ErrInternalError
+		}
+		err = r.checkApplied(result, correction.ScheduleID, "ApplyCorrection")
+		if err != nil {
+			return err
+		}
+		return r.resolveCorrection(ctx, tx, correction, "ApplyCorrection")
+	})
+}
+
+// RejectCorrection marks correction as rejected, leaving the schedule unchanged
+func (r *scheduleRepositoryImpl) RejectCorrection(ctx context.Context, correction model.Correction) error {
+	return r.resolveCorrection(ctx, r.db, correction, "RejectCorrection")
+}
+
+// resolveCorrection records the coordinator's decision on a correction that is still pending.
+// A correction resolved in the meantime yields a conflict.
+func (r *scheduleRepositoryImpl) resolveCorrection(ctx context.Context, db sqlx.ExecerContext, correction model.Correction, method string) error {
+	sqlQuery, args, err := squirrel.Update("schedule_corrections").
+		Set("status", correction.Status).
+		Set("resolved_by", correction.ResolvedBy).
+		Set("resolved_at", correction.ResolvedAt).
+		Set("resolution_note", correction.ResolutionNote).
+		Set("updated_at", correction.ResolvedAt).
+		Where(squirrel.Eq{"id": correction.ID, "status": model.CorrectionPending}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("correction_id", correction.ID).Msgf("Failed to build SQL query for %s", method)
+		return exceptions.ErrInternalError
+	}
+
+	result, err := db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("correction_id", correction.ID).Msgf("Failed to execute SQL query for %s", method)
+		return exceptions.ErrInternalError
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error().Err(err).Str("correction_id", correction.ID).Msgf("Failed to read affected rows for %s", method)
+		return exceptions.ErrInternalError
+	}
+	if affected == 0 {
+		r.logger.Warn().Str("correction_id", correction.ID).Msgf("Conditional update for %s matched no row", method)
+		return exceptions.ErrConflict.WithDetails(fmt.Sprintf("Correction %s was already resolved by another request", correction.ID))
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"mini-evv-logger-backend/src/domains/schedule/model"
+	taskModel "mini-evv-logger-backend/src/domains/task/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	originalColumnsQuery = `original_start_time = CASE WHEN corrected_at IS NULL THEN start_time ELSE original_start_time END, ` +
+		`original_start_latitude = CASE WHEN corrected_at IS NULL THEN start_latitude ELSE original_start_latitude END, ` +
+		`original_start_longitude = CASE WHEN corrected_at IS NULL THEN start_longitude ELSE original_start_longitude END, ` +
+		`original_end_time = CASE WHEN corrected_at IS NULL THEN end_time ELSE original_end_time END, ` +
+		`original_end_latitude = CASE WHEN corrected_at IS NULL THEN end_latitude ELSE original_end_latitude END, ` +
+		`original_end_longitude = CASE WHEN corrected_at IS NULL THEN end_longitude ELSE original_end_longitude END`
+	correctionTasksQuery   = `SELECT correction_id, task_id, status, reason FROM schedule_correction_tasks WHERE correction_id IN ($1) ORDER BY task_id ASC`
+	resolveCorrectionQuery = `UPDATE schedule_corrections SET status = $1, resolved_by = $2, resolved_at = $3, resolution_note = $4, updated_at = $5 WHERE id = $6 AND status = $7`
+)
+
+var (
+	correctionRows = []string{"id", "schedule_id", "status", "start_time", "start_latitude", "start_longitude", "end_time", "end_latitude", "end_longitude",
+		"reason_code", "comment", "requested_by", "resolved_by", "resolved_at", "resolution_note", "created_at", "updated_at"}
+	correctionTaskRows = []string{"correction_id", "task_id", "status", "reason"}
+)
+
+func TestCreateCorrection(t *testing.T) {
+	initMocks(t)
+
+	dummyEndTime := time.Now()
+	dummyLatitude, dummyLongitude := 37.7749, -122.4194
+	dummyRequestedBy := uuid.NewString()
+	dummyCorrection := model.Correction{ID: uuid.NewString(), ScheduleID: uuid.NewString(), Status: model.CorrectionPending, EndTime: &dummyEndTime, EndLatitude: &dummyLatitude, EndLongitude: &dummyLongitude, ReasonCode: "forgot_to_clock_out", RequestedBy: &dummyRequestedBy}
+	query := `INSERT INTO schedule_corrections (id,schedule_id,status,start_time,start_latitude,start_longitude,end_time,end_latitude,end_longitude,reason_code,comment,requested_by) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) ON CONFLICT (schedule_id) WHERE status = 'pending' DO NOTHING`
+	t.Run("TestCreateCorrection: OK", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(dummyCorrection.ID, dummyCorrection.ScheduleID, "pending", nil, nil, nil, &dummyEndTime, &dummyLatitude, &dummyLongitude, "forgot_to_clock_out", nil, &dummyRequestedBy).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectCommit()
+
+		err := repo.CreateCorrection(context.Background(), dummyCorrection)
+		assert.Nil(t, err)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestCreateCorrection: With Tasks", func(t *testing.T) {
+		dummyReason := "Client declined the walk"
+		dummyTaskIDs := []string{uuid.NewString(), uuid.NewString()}
+		correction := dummyCorrection
+		correction.Tasks = []model.CorrectionTask{
+			{CorrectionID: correction.ID, TaskID: dummyTaskIDs[0], Status: "completed"},
+			{CorrectionID: correction.ID, TaskID: dummyTaskIDs[1], Status: "not_completed", Reason: &dummyReason},
+		}
+		tasksQuery := `INSERT INTO schedule_correction_tasks (correction_id,task_id,status,reason) VALUES ($1,$2,$3,$4),($5,$6,$7,$8)`
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectExec(regexp.QuoteMeta(tasksQuery)).
+			WithArgs(correction.ID, dummyTaskIDs[0], "completed", nil, correction.ID, dummyTaskIDs[1], "not_completed", &dummyReason).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mockSQL.ExpectCommit()
+
+		err := repo.CreateCorrection(context.Background(), correction)
+		assert.Nil(t, err)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestCreateCorrection: Pending Correction Exists", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mockSQL.ExpectRollback()
+
+		err := repo.CreateCorrection(context.Background(), dummyCorrection)
+		assert.NotNil(t, err)
+		assert.Equal(t, fmt.Sprintf("Error 409: Conflict - Schedule ID %s already has a pending correction", dummyCorrection.ScheduleID), err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestCreateCorrection: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+		mockSQL.ExpectRollback()
+
+		err := repo.CreateCorrection(context.Background(), dummyCorrection)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+}
+
+func TestGetCorrections(t *testing.T) {
+	initMocks(t)
+
+	dummyID, dummyScheduleID, dummyBranchID := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	t.Run("TestGetCorrections: OK", func(t *testing.T) {
+		query := `SELECT sc.id, sc.schedule_id, sc.status, sc.start_time, sc.start_latitude, sc.start_longitude, sc.end_time, sc.end_latitude, sc.end_longitude, sc.reason_code, sc.comment, sc.requested_by, sc.resolved_by, sc.resolved_at, sc.resolution_note, sc.created_at, sc.updated_at FROM schedule_corrections sc WHERE sc.status = $1 AND sc.schedule_id IN (SELECT s.id FROM schedules s JOIN caregivers c ON c.id = s.caregiver_id WHERE c.branch_id = $2) ORDER BY sc.created_at DESC`
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs("pending", dummyBranchID).
+			WillReturnRows(sqlmock.NewRows(correctionRows).
+				AddRow(dummyID, dummyScheduleID, "pending", time.Now(), nil, nil, nil, nil, nil, "wrong_time", nil, nil, nil, nil, nil, time.Now(), time.Now()))
+		dummyTaskID := uuid.NewString()
+		mockSQL.ExpectQuery(regexp.QuoteMeta(correctionTasksQuery)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows(correctionTaskRows).AddRow(dummyID, dummyTaskID, "completed", nil))
+
+		corrections, err := repo.GetCorrections(context.Background(), model.FilterCorrectionsRequest{Status: model.CorrectionPending, BranchID: dummyBranchID})
+		assert.Nil(t, err)
+		assert.Len(t, corrections, 1)
+		assert.Equal(t, dummyID, corrections[0].ID)
+		assert.Equal(t, "wrong_time", corrections[0].ReasonCode)
+		assert.Len(t, corrections[0].Tasks, 1)
+		assert.Equal(t, dummyTaskID, corrections[0].Tasks[0].TaskID)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestGetCorrections: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(`FROM schedule_corrections sc WHERE sc.schedule_id = $1`)).
+			WithArgs(dummyScheduleID).
+			WillReturnError(sql.ErrConnDone)
+
+		corrections, err := repo.GetCorrections(context.Background(), model.FilterCorrectionsRequest{ScheduleID: dummyScheduleID})
+		assert.Nil(t, corrections)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+}
+
+func TestGetCorrectionByID(t *testing.T) {
+	initMocks(t)
+
+	dummyID := uuid.NewString()
+	query := `SELECT sc.id, sc.schedule_id, sc.status, sc.start_time, sc.start_latitude, sc.start_longitude, sc.end_time, sc.end_latitude, sc.end_longitude, sc.reason_code, sc.comment, sc.requested_by, sc.resolved_by, sc.resolved_at, sc.resolution_note, sc.created_at, sc.updated_at FROM schedule_corrections sc WHERE sc.id = $1`
+	t.Run("TestGetCorrectionByID: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows(correctionRows).
+				AddRow(dummyID, uuid.NewString(), "approved", nil, nil, nil, time.Now(), 37.7749, -122.4194, "forgot_to_clock_out", nil, nil, nil, time.Now(), nil, time.Now(), time.Now()))
+		mockSQL.ExpectQuery(regexp.QuoteMeta(correctionTasksQuery)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows(correctionTaskRows))
+
+		correction, err := repo.GetCorrectionByID(context.Background(), dummyID)
+		assert.Nil(t, err)
+		assert.Equal(t, dummyID, correction.ID)
+		assert.True(t, correction.IsResolved())
+		assert.Empty(t, correction.Tasks)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestGetCorrectionByID: Not Found", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnError(sql.ErrNoRows)
+
+		correction, err := repo.GetCorrectionByID(context.Background(), dummyID)
+		assert.Nil(t, correction)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 404: Resource not found - Correction with ID "+dummyID+" not found", err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+}
+
+func TestApplyCorrection(t *testing.T) {
+	initMocks(t)
+
+	dummyScheduleID, dummyUserID := uuid.NewString(), uuid.NewString()
+	dummyResolvedAt := time.Now()
+	dummyCorrection := model.Correction{ID: uuid.NewString(), ScheduleID: dummyScheduleID, Status: model.CorrectionApproved, ResolvedBy: &dummyUserID, ResolvedAt: &dummyResolvedAt}
+	dummyDistance := 12.5
+	dummyStart := model.VisitEvent{Time: time.Now().Add(-time.Hour), Latitude: 37.7749, Longitude: -122.4194, DistanceMeters: &dummyDistance}
+	t.Run("TestApplyCorrection: OK", func(t *testing.T) {
+		query := `UPDATE schedules SET ` + originalColumnsQuery + `, corrected_at = $1, start_time = $2, start_latitude = $3, start_longitude = $4, start_distance_meters = $5, start_out_of_geofence = $6, updated_at = $7, version = $8 WHERE id = $9 AND version = $10`
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyScheduleID, `{"id":"`+dummyScheduleID+`"}`)
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(&dummyResolvedAt, dummyStart.Time, dummyStart.Latitude, dummyStart.Longitude, &dummyDistance, false, &dummyResolvedAt, 4, dummyScheduleID, 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectExec(regexp.QuoteMeta(resolveCorrectionQuery)).
+			WithArgs("approved", &dummyUserID, &dummyResolvedAt, nil, &dummyResolvedAt, dummyCorrection.ID, "pending").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectSnapshot(dummyScheduleID, `{"id":"`+dummyScheduleID+`"}`)
+		expectAuditEvent(dummyScheduleID, "visit_corrected")
+		mockSQL.ExpectCommit()
+
+		err := repo.ApplyCorrection(context.Background(), dummyCorrection, model.CorrectedVisit{Start: dummyStart, Version: 4})
+		assert.Nil(t, err)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestApplyCorrection: Clock-Out Completes Visit", func(t *testing.T) {
+		dummyReason := "Clock-out added by an approved correction"
+		dummyEnd := model.VisitEvent{Time: time.Now().Add(-10 * time.Minute), Latitude: 37.7749, Longitude: -122.4194, DistanceMeters: &dummyDistance, Minutes: 50, Variance: -10}
+		dummyTransition := model.StatusTransition{ID: uuid.NewString(), ScheduleID: dummyScheduleID, FromStatus: model.StatusInProgress, ToStatus: model.StatusCompleted, Reason: &dummyReason, ChangedBy: &dummyUserID, ChangedAt: dummyResolvedAt, Version: 4}
+		query := `UPDATE schedules SET ` + originalColumnsQuery + `, corrected_at = $1, start_time = $2, start_latitude = $3, start_longitude = $4, start_distance_meters = $5, start_out_of_geofence = $6, end_time = $7, end_latitude = $8, end_longitude = $9, end_distance_meters = $10, end_out_of_geofence = $11, actual_minutes = $12, variance_minutes = $13, status = $14, status_reason = $15, status_changed_at = $16, status_changed_by = $17, updated_at = $18, version = $19 WHERE id = $20 AND status = $21 AND version = $22`
+		historyQuery := `INSERT INTO schedule_status_transitions (id,schedule_id,from_status,to_status,reason,changed_by,changed_at,version) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyScheduleID, `{"id":"`+dummyScheduleID+`"}`)
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(&dummyResolvedAt, dummyStart.Time, dummyStart.Latitude, dummyStart.Longitude, &dummyDistance, false, dummyEnd.Time, dummyEnd.Latitude, dummyEnd.Longitude, &dummyDistance, false, 50, -10,
+				"completed", &dummyReason, dummyResolvedAt, &dummyUserID, dummyResolvedAt, 4, dummyScheduleID, "in-progress", 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectExec(regexp.QuoteMeta(historyQuery)).
+			WithArgs(dummyTransition.ID, dummyScheduleID, "in-progress", "completed", &dummyReason, &dummyUserID, dummyResolvedAt, 4).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectExec(regexp.QuoteMeta(resolveCorrectionQuery)).
+			WithArgs("approved", &dummyUserID, &dummyResolvedAt, nil, &dummyResolvedAt, dummyCorrection.ID, "pending").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectSnapshot(dummyScheduleID, `{"id":"`+dummyScheduleID+`"}`)
+		expectAuditEvent(dummyScheduleID, "visit_corrected")
+		mockSQL.ExpectCommit()
+
+		err := repo.ApplyCorrection(context.Background(), dummyCorrection, model.CorrectedVisit{Start: dummyStart, End: &dummyEnd, Transition: &dummyTransition, Version: 4})
+		assert.Nil(t, err)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestApplyCorrection: Corrects Tasks", func(t *testing.T) {
+		dummyTask := taskModel.Task{ID: uuid.NewString(), ScheduleID: dummyScheduleID, Status: "completed", Version: 2}
+		taskSnapshotQuery := `SELECT to_jsonb(t) FROM tasks t WHERE t.id = $1 FOR UPDATE`
+		taskQuery := `UPDATE tasks SET status = $1, reason = $2, updated_at = $3, version = $4 WHERE id = $5 AND version = $6`
+		taskRow := `{"id":"` + dummyTask.ID + `","schedule_id":"` + dummyScheduleID + `"}`
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyScheduleID, `{"id":"`+dummyScheduleID+`"}`)
+		mockSQL.ExpectQuery(regexp.QuoteMeta(taskSnapshotQuery)).WithArgs(dummyTask.ID).WillReturnRows(sqlmock.NewRows([]string{"to_jsonb"}).AddRow([]byte(taskRow)))
+		mockSQL.ExpectExec(regexp.QuoteMeta(taskQuery)).
+			WithArgs("completed", nil, dummyResolvedAt, 3, dummyTask.ID, 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectQuery(regexp.QuoteMeta(taskSnapshotQuery)).WithArgs(dummyTask.ID).WillReturnRows(sqlmock.NewRows([]string{"to_jsonb"}).AddRow([]byte(taskRow)))
+		mockSQL.ExpectExec(regexp.QuoteMeta(auditQuery)).
+			WithArgs(dummyScheduleID, "task", dummyTask.ID, "task_status_updated", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mockSQL.ExpectExec(regexp.QuoteMeta(`UPDATE schedules SET`)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectExec(regexp.QuoteMeta(resolveCorrectionQuery)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectSnapshot(dummyScheduleID, `{"id":"`+dummyScheduleID+`"}`)
+		expectAuditEvent(dummyScheduleID, "visit_corrected")
+		mockSQL.ExpectCommit()
+
+		err := repo.ApplyCorrection(context.Background(), dummyCorrection, model.CorrectedVisit{Start: dummyStart, Version: 4, Tasks: []taskModel.Task{dummyTask}})
+		assert.Nil(t, err)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestApplyCorrection: Already Resolved", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyScheduleID, `{"id":"`+dummyScheduleID+`"}`)
+		mockSQL.ExpectExec(regexp.QuoteMeta(`UPDATE schedules SET`)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectExec(regexp.QuoteMeta(resolveCorrectionQuery)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mockSQL.ExpectRollback()
+
+		err := repo.ApplyCorrection(context.Background(), dummyCorrection, model.CorrectedVisit{Start: dummyStart, Version: 4})
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 409: Conflict - Correction "+dummyCorrection.ID+" was already resolved by another request", err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestApplyCorrection: Schedule Changed By Another Request", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyScheduleID, `{"id":"`+dummyScheduleID+`"}`)
+		mockSQL.ExpectExec(regexp.QuoteMeta(`UPDATE schedules SET`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mockSQL.ExpectRollback()
+
+		err := repo.ApplyCorrection(context.Background(), dummyCorrection, model.CorrectedVisit{Start: dummyStart, Version: 4})
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 409: Conflict - Schedule ID "+dummyScheduleID+" was changed by another request. Reload it and try again.", err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+}
+
+func TestRejectCorrection(t *testing.T) {
+	initMocks(t)
+
+	dummyUserID, dummyNote := uuid.NewString(), "The visit log matches the client's account"
+	dummyResolvedAt := time.Now()
+	dummyCorrection := model.Correction{ID: uuid.NewString(), ScheduleID: uuid.NewString(), Status: model.CorrectionRejected, ResolvedBy: &dummyUserID, ResolvedAt: &dummyResolvedAt, ResolutionNote: &dummyNote}
+	t.Run("TestRejectCorrection: OK", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(resolveCorrectionQuery)).
+			WithArgs("rejected", &dummyUserID, &dummyResolvedAt, &dummyNote, &dummyResolvedAt, dummyCorrection.ID, "pending").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.RejectCorrection(context.Background(), dummyCorrection)
+		assert.Nil(t, err)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestRejectCorrection: Already Resolved", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(resolveCorrectionQuery)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.RejectCorrection(context.Background(), dummyCorrection)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 409: Conflict - Correction "+dummyCorrection.ID+" was already resolved by another request", err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+}
@@ -5,7 +5,10 @@ import (
 	"database/sql"
 	"fmt"
 	"mini-evv-logger-backend/exceptions"
+	auditModel "mini-evv-logger-backend/src/domains/audit/model"
+	auditRepo "mini-evv-logger-backend/src/domains/audit/repository"
 	"mini-evv-logger-backend/src/domains/schedule/model"
+	taskModel "mini-evv-logger-backend/src/domains/task/model"
 	"time" // Imported for time.Now()
 
 	"github.com/Masterminds/squirrel"
@@ -15,13 +18,38 @@ import (
 
 //go:generate go run go.uber.org/mock/mockgen -source=./schedule_repo.go -destination=../mocks/repository/schedule_repo.go -package=mocks
 
+// scheduleColumns selects a schedule together with the client details it is displayed with
+var scheduleColumns = []string{"s.id", "s.caregiver_id", "s.client_id", "cl.name AS client_name", "s.shift_time", "s.shift_end_time",
+	"(EXTRACT(EPOCH FROM s.shift_end_time - s.shift_time) / 60)::int AS duration_minutes",
+	"concat_ws(', ', cl.address_line1, cl.address_line2, cl.city, cl.state, cl.postal_code) AS location",
+	"cl.latitude AS client_latitude", "cl.longitude AS client_longitude", "s.status", "s.status_reason", "s.status_changed_at",
+	"s.status_changed_by", "s.series_id", "s.occurrence_time", "s.is_detached", "s.care_plan_id", "cp.version AS care_plan_version",
+	"s.start_time", "s.start_latitude", "s.start_longitude", "s.start_distance_meters", "s.start_out_of_geofence",
+	"s.start_received_at", "s.start_gps_fix_at", "s.start_clock_skewed",
+	"s.end_time", "s.end_latitude", "s.end_longitude", "s.end_distance_meters", "s.end_out_of_geofence",
+	"s.end_received_at", "s.end_gps_fix_at", "s.end_clock_skewed",
+	"s.actual_minutes", "s.variance_minutes", "s.original_start_time", "s.original_start_latitude", "s.original_start_longitude",
+	"s.original_end_time", "s.original_end_latitude", "s.original_end_longitude", "s.corrected_at", "s.version", "s.created_at", "s.updated_at"}
+
 // ScheduleRepository defines the interface for schedule database operations
 type ScheduleRepository interface {
 	GetSchedules(ctx context.Context, filter model.FilterSchedulesRequest) ([]model.Schedule, int, error)
 	GetScheduleByID(ctx context.Context, id string) (*model.Schedule, error)
-	UpdateScheduleStatus(ctx context.Context, id, status string) error
-	LogVisitStart(ctx context.Context, id string, startTime time.Time, latitude, longitude float64) error
-	LogVisitEnd(ctx context.Context, id string, endTime time.Time, latitude, longitude float64) error
+	GetScheduleOwnership(ctx context.Context, id string) (*model.ScheduleOwnership, error)
+	CreateSchedule(ctx context.Context, schedule model.Schedule) error
+	UpdateSchedule(ctx context.Context, req model.UpdateScheduleRequest) error
+	GetOverdueSchedules(ctx context.Context, shiftBefore time.Time) ([]model.Schedule, error)
+	TransitionStatus(ctx context.Context, transition model.StatusTransition) error
+	LogVisitStart(ctx context.Context, transition model.StatusTransition, event model.VisitEvent) error
+	LogVisitEnd(ctx context.Context, transition model.StatusTransition, event model.VisitEvent, closedTasks []taskModel.Task) error
+	GetStatusHistory(ctx context.Context, id string) ([]model.StatusTransition, error)
+	GetAttestation(ctx context.Context, scheduleID string) (*model.Attestation, error)
+	GetSignature(ctx context.Context, scheduleID string) ([]byte, error)
+	CreateCorrection(ctx context.Context, correction model.Correction) error
+	GetCorrections(ctx context.Context, filter model.FilterCorrectionsRequest) ([]model.Correction, error)
+	GetCorrectionByID(ctx context.Context, id string) (*model.Correction, error)
+	ApplyCorrection(ctx context.Context, correction model.Correction, visit model.CorrectedVisit) error
+	RejectCorrection(ctx context.Context, correction model.Correction) error
 }
 
 // scheduleRepositoryImpl implements the ScheduleRepository interface
@@ -35,24 +63,57 @@ func NewScheduleRepository(db *sqlx.DB, logger zerolog.Logger) ScheduleRepositor
 	return &scheduleRepositoryImpl{db: db, logger: logger}
 }
 
+// localMidnight returns the start of the day, given in YYYY-MM-DD format, in loc as a UTC time.
+// The day has already been validated, so a parse error cannot happen here.
+func localMidnight(day string, loc *time.Location) time.Time {
+	t, _ := time.ParseInLocation(time.DateOnly, day, loc)
+	return t.UTC()
+}
+
 // GetSchedules fetches all schedules from the database with pagination
 func (r *scheduleRepositoryImpl) GetSchedules(ctx context.Context, filter model.FilterSchedulesRequest) ([]model.Schedule, int, error) {
 	var schedules []model.Schedule
 	qb := squirrel.Select().
-		From("schedules").
+		From("schedules s").
+		Join("clients cl ON cl.id = s.client_id").
+		LeftJoin("care_plans cp ON cp.id = s.care_plan_id").
 		PlaceholderFormat(squirrel.Dollar)
 
-	if filter.Date != "" {
-		// If a specific date is provided, filter by shift_time
-		qb = qb.Where(
-			squirrel.And{
-				squirrel.GtOrEq{"shift_time": filter.Date + " 00:00:00"},
-				squirrel.LtOrEq{"shift_time": filter.Date + " 23:59:59"},
-			},
-		)
+	start, end := filter.DayRange()
+	if filter.TZ != "" {
+		// Days start at local midnight in the requested timezone, so the UTC bounds follow its DST changes
+		loc, err := time.LoadLocation(filter.TZ)
+		if err != nil {
+			r.logger.Error().Err(err).Str("timezone", filter.TZ).Msg("Failed to load timezone for GetSchedules")
+			return nil, 0, exceptions.ErrInternalError
+		}
+		if start != "" {
+			qb = qb.Where(squirrel.GtOrEq{"s.shift_time": localMidnight(start, loc)})
+		}
+		if end != "" {
+			qb = qb.Where(squirrel.Lt{"s.shift_time": localMidnight(end, loc)})
+		}
+	} else {
+		// Without a timezone each visit is matched against the days of its client's timezone
+		if start != "" {
+			qb = qb.Where(squirrel.Expr("s.shift_time >= (CAST(? AS timestamp) AT TIME ZONE cl.timezone)", start))
+		}
+		if end != "" {
+			qb = qb.Where(squirrel.Expr("s.shift_time < (CAST(? AS timestamp) AT TIME ZONE cl.timezone)", end))
+		}
 	}
 
-	countq := qb.Column("COUNT(id)")
+	if filter.CaregiverID != "" {
+		// Only return the schedules assigned to the requested caregiver
+		qb = qb.Where(squirrel.Eq{"s.caregiver_id": filter.CaregiverID})
+	}
+
+	if filter.BranchID != "" {
+		// Only return the schedules assigned to caregivers of the requested branch
+		qb = qb.Where(squirrel.Expr("s.caregiver_id IN (SELECT id FROM caregivers WHERE branch_id = ?)", filter.BranchID))
+	}
+
+	countq := qb.Column("COUNT(s.id)")
 	countQuery, countArgs, err := countq.ToSql()
 	if err != nil {
 		r.logger.Error().Err(err).Msg("Failed to build SQL query for CountTotalSchedules")
@@ -72,10 +133,8 @@ func (r *scheduleRepositoryImpl) GetSchedules(ctx context.Context, filter model.
 		return nil, 0, exceptions.ErrInternalError
 	}
 
-	qb = qb.Columns("id", "client_name", "shift_time", "location", "status",
-		"start_time", "start_latitude", "start_longitude", "end_time", "end_latitude", "end_longitude",
-		"created_at", "updated_at").
-		OrderBy("shift_time ASC").
+	qb = qb.Columns(scheduleColumns...).
+		OrderBy("s.shift_time ASC").
 		Limit(uint64(filter.Limit)).
 		Offset(uint64(filter.Offset))
 
@@ -99,11 +158,11 @@ func (r *scheduleRepositoryImpl) GetSchedules(ctx context.Context, filter model.
 // GetScheduleByID fetches a single schedule by ID
 func (r *scheduleRepositoryImpl) GetScheduleByID(ctx context.Context, id string) (*model.Schedule, error) {
 	var schedule model.Schedule
-	qb := squirrel.Select("id", "client_name", "shift_time", "location", "status",
-		"start_time", "start_latitude", "start_longitude", "end_time", "end_latitude", "end_longitude",
-		"created_at", "updated_at").
-		From("schedules").
-		Where(squirrel.Eq{"id": id}).
+	qb := squirrel.Select(scheduleColumns...).
+		From("schedules s").
+		Join("clients cl ON cl.id = s.client_id").
+		LeftJoin("care_plans cp ON cp.id = s.care_plan_id").
+		Where(squirrel.Eq{"s.id": id}).
 		PlaceholderFormat(squirrel.Dollar)
 
 	sqlQuery, args, err := qb.ToSql()
@@ -124,81 +183,374 @@ func (r *scheduleRepositoryImpl) GetScheduleByID(ctx context.Context, id string)
 	return &schedule, nil
 }
 
-// UpdateScheduleStatus updates the status of a schedule without pre-checking existence.
-// It relies on the service layer to perform existence checks.
-func (r *scheduleRepositoryImpl) UpdateScheduleStatus(ctx context.Context, id, status string) error {
-	qb := squirrel.Update("schedules").
-		Set("status", status).
-		Set("updated_at", time.Now()).
-		Where(squirrel.Eq{"id": id}).
+// GetScheduleOwnership fetches the assigned caregiver and their branch for a schedule
+func (r *scheduleRepositoryImpl) GetScheduleOwnership(ctx context.Context, id string) (*model.ScheduleOwnership, error) {
+	var ownership model.ScheduleOwnership
+	qb := squirrel.Select("s.caregiver_id", "c.branch_id").
+		From("schedules s").
+		LeftJoin("caregivers c ON c.id = s.caregiver_id").
+		Where(squirrel.Eq{"s.id": id}).
 		PlaceholderFormat(squirrel.Dollar)
 
 	sqlQuery, args, err := qb.ToSql()
 	if err != nil {
-		r.logger.Error().Err(err).Str("schedule_id", id).Str("status", status).Msg("Failed to build SQL query for UpdateScheduleStatus")
+		r.logger.Error().Err(err).Str("schedule_id", id).Msg("Failed to build SQL query for GetScheduleOwnership")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &ownership, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.Warn().Str("schedule_id", id).Msg("Schedule not found in database")
+			return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Schedule with ID %s not found", id))
+		}
+		r.logger.Error().Err(err).Str("schedule_id", id).Msg("Failed to execute SQL query for GetScheduleOwnership")
+		return nil, exceptions.ErrInternalError
+	}
+	return &ownership, nil
+}
+
+// CreateSchedule inserts a new schedule together with its tasks and audit event, in one transaction.
+// Only the descriptions of schedule.Tasks are used; tasks are numbered in slice order.
+func (r *scheduleRepositoryImpl) CreateSchedule(ctx context.Context, schedule model.Schedule) error {
+	return r.audited(ctx, schedule.ID, auditModel.ActionScheduleCreated, "CreateSchedule", func(tx *sqlx.Tx) error {
+		return r.insertSchedule(ctx, tx, schedule)
+	})
+}
+
+// insertSchedule runs the INSERT statements of CreateSchedule within tx
+func (r *scheduleRepositoryImpl) insertSchedule(ctx context.Context, tx *sqlx.Tx, schedule model.Schedule) error {
+	sqlQuery, args, err := squirrel.Insert("schedules").
+		Columns("id", "caregiver_id", "client_id", "shift_time", "shift_end_time", "status", "care_plan_id").
+		Values(schedule.ID, schedule.CaregiverID, schedule.ClientID, schedule.ShiftTime, schedule.ShiftEndTime, schedule.Status, schedule.CarePlanID).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", schedule.ID).Msg("Failed to build SQL query for CreateSchedule")
 		return exceptions.ErrInternalError
 	}
 
-	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
+	_, err = tx.ExecContext(ctx, sqlQuery, args...)
 	if err != nil {
-		r.logger.Error().Err(err).Str("schedule_id", id).Str("status", status).Msg("Failed to execute SQL query for UpdateScheduleStatus")
+		r.logger.Error().Err(err).Str("schedule_id", schedule.ID).Msg("Failed to execute SQL query for CreateSchedule")
 		return exceptions.ErrInternalError
 	}
 
+	if len(schedule.Tasks) > 0 {
+		qb := squirrel.Insert("tasks").
+			Columns("schedule_id", "description", "position").
+			PlaceholderFormat(squirrel.Dollar)
+		for i, task := range schedule.Tasks {
+			qb = qb.Values(schedule.ID, task.Description, i+1)
+		}
+		sqlQuery, args, err = qb.ToSql()
+		if err != nil {
+			r.logger.Error().Err(err).Str("schedule_id", schedule.ID).Msg("Failed to build SQL query for CreateSchedule")
+			return exceptions.ErrInternalError
+		}
+		_, err = tx.ExecContext(ctx, sqlQuery, args...)
+		if err != nil {
+			r.logger.Error().Err(err).Str("schedule_id", schedule.ID).Msg("Failed to execute SQL query for CreateSchedule")
+			return exceptions.ErrInternalError
+		}
+	}
 	return nil
 }
 
-// LogVisitStart logs the start time and geolocation for a visit.
-// It updates the record by ID and sets status to 'in-progress'.
-// The service layer is responsible for pre-validating the 'upcoming' status.
-func (r *scheduleRepositoryImpl) LogVisitStart(ctx context.Context, id string, startTime time.Time, latitude, longitude float64) error {
+// UpdateSchedule updates the provided fields of a schedule while it is still upcoming and at req.Version.
+// Occurrences of a series are detached so that later changes to the series leave them alone.
+// A schedule that was changed in the meantime yields a conflict.
+func (r *scheduleRepositoryImpl) UpdateSchedule(ctx context.Context, req model.UpdateScheduleRequest) error {
 	qb := squirrel.Update("schedules").
-		Set("start_time", startTime).
-		Set("start_latitude", latitude).
-		Set("start_longitude", longitude).
-		Set("status", "in-progress").
 		Set("updated_at", time.Now()).
-		Where(squirrel.Eq{"id": id}).
+		Set("is_detached", squirrel.Expr("series_id IS NOT NULL")).
+		Set("version", req.Version+1).
+		Where(squirrel.Eq{"id": req.ID, "status": model.StatusUpcoming, "version": req.Version}).
 		PlaceholderFormat(squirrel.Dollar)
 
+	if req.ClientID != nil {
+		qb = qb.Set("client_id", *req.ClientID)
+	}
+	if req.CaregiverID != nil {
+		qb = qb.Set("caregiver_id", *req.CaregiverID)
+	}
+	if req.ShiftTime != nil {
+		qb = qb.Set("shift_time", *req.ShiftTime)
+	}
+	if req.ShiftEndTime != nil {
+		qb = qb.Set("shift_end_time", *req.ShiftEndTime)
+	}
+
 	sqlQuery, args, err := qb.ToSql()
 	if err != nil {
-		r.logger.Error().Err(err).Str("schedule_id", id).Msg("Failed to build SQL query for LogVisitStart")
+		r.logger.Error().Err(err).Str("schedule_id", req.ID).Msg("Failed to build SQL query for UpdateSchedule")
+		return exceptions.ErrInternalError
+	}
+
+	return r.audited(ctx, req.ID, auditModel.ActionScheduleUpdated, "UpdateSchedule", func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, sqlQuery, args...)
+		if err != nil {
+			r.logger.Error().Err(err).Str("schedule_id", req.ID).Msg("Failed to execute SQL query for UpdateSchedule")
+			return exceptions.ErrInternalError
+		}
+		return r.checkApplied(result, req.ID, "UpdateSchedule")
+	})
+}
+
+// GetOverdueSchedules fetches the ID, status and version of upcoming schedules whose shift started before shiftBefore
+func (r *scheduleRepositoryImpl) GetOverdueSchedules(ctx context.Context, shiftBefore time.Time) ([]model.Schedule, error) {
+	schedules := []model.Schedule{}
+	qb := squirrel.Select("id", "status", "version").
+		From("schedules").
+		Where(squirrel.Eq{"status": model.StatusUpcoming}).
+		Where(squirrel.Lt{"shift_time": shiftBefore}).
+		OrderBy("shift_time ASC").
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to build SQL query for GetOverdueSchedules")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.SelectContext(ctx, &schedules, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to execute SQL query for GetOverdueSchedules")
+		return nil, exceptions.ErrInternalError
+	}
+	return schedules, nil
+}
+
+// TransitionStatus moves a schedule to another status and records the change in its history.
+// The service layer is responsible for checking the transition against the state machine.
+func (r *scheduleRepositoryImpl) TransitionStatus(ctx context.Context, transition model.StatusTransition) error {
+	return r.applyTransition(ctx, squirrel.Update("schedules"), transition, auditModel.ActionStatusChanged, "TransitionStatus")
+}
+
+// LogVisitStart logs the start time, receive time, geolocation, geofence and clock skew result for a visit
+// and applies transition, normally to 'in-progress'.
+func (r *scheduleRepositoryImpl) LogVisitStart(ctx context.Context, transition model.StatusTransition, event model.VisitEvent) error {
+	qb := squirrel.Update("schedules").
+		Set("start_time", event.Time).
+		Set("start_latitude", event.Latitude).
+		Set("start_longitude", event.Longitude).
+		Set("start_distance_meters", event.DistanceMeters).
+		Set("start_out_of_geofence", event.OutOfGeofence).
+		Set("start_received_at", event.ReceivedAt).
+		Set("start_gps_fix_at", event.GPSFixAt).
+		Set("start_clock_skewed", event.ClockSkewed)
+
+	return r.applyTransition(ctx, qb, transition, auditModel.ActionVisitStarted, "LogVisitStart")
+}
+
+// LogVisitEnd logs the end time, receive time, geolocation, geofence and clock skew result and length of a visit
+// and applies transition, normally to 'completed'. Tasks left open are written as closedTasks and the client's
+// attestation, if any, is stored in the same transaction.
+func (r *scheduleRepositoryImpl) LogVisitEnd(ctx context.Context, transition model.StatusTransition, event model.VisitEvent, closedTasks []taskModel.Task) error {
+	qb := squirrel.Update("schedules").
+		Set("end_time", event.Time).
+		Set("end_latitude", event.Latitude).
+		Set("end_longitude", event.Longitude).
+		Set("end_distance_meters", event.DistanceMeters).
+		Set("end_out_of_geofence", event.OutOfGeofence).
+		Set("end_received_at", event.ReceivedAt).
+		Set("end_gps_fix_at", event.GPSFixAt).
+		Set("end_clock_skewed", event.ClockSkewed).
+		Set("actual_minutes", event.Minutes).
+		Set("variance_minutes", event.Variance)
+
+	return r.audited(ctx, transition.ScheduleID, auditModel.ActionVisitEnded, "LogVisitEnd", func(tx *sqlx.Tx) error {
+		err := r.updateTaskStatuses(ctx, tx, closedTasks, transition.ChangedAt, "LogVisitEnd")
+		if err != nil {
+			return err
+		}
+		err = r.transition(ctx, tx, qb, transition, "LogVisitEnd")
+		if err != nil || event.Attestation == nil {
+			return err
+		}
+		return r.saveAttestation(ctx, tx, *event.Attestation)
+	})
+}
+
+// updateTaskStatuses writes the status and reason of tasks within tx, each while it is still at its
+// version and with its own audit event. A task changed in the meantime yields a conflict.
+func (r *scheduleRepositoryImpl) updateTaskStatuses(ctx context.Context, tx *sqlx.Tx, tasks []taskModel.Task, changedAt time.Time, method string) error {
+	for _, task := range tasks {
+		before, err := auditRepo.Snapshot(ctx, tx, "tasks", task.ID)
+		if err != nil {
+			r.logger.Error().Err(err).Str("task_id", task.ID).Msgf("Failed to read task before %s", method)
+			return exceptions.ErrInternalError
+		}
+
+		sqlQuery, args, err := squirrel.Update("tasks").
+			Set("status", task.Status).
+			Set("reason", task.Reason).
+			Set("updated_at", changedAt).
+			Set("version", task.Version+1).
+			Where(squirrel.Eq{"id": task.ID, "version": task.Version}).
+			PlaceholderFormat(squirrel.Dollar).
+			ToSql()
+		if err != nil {
+			r.logger.Error().Err(err).Str("task_id", task.ID).Msgf("Failed to build SQL query for %s", method)
+			return exceptions.ErrInternalError
+		}
+
+		result, err := tx.ExecContext(ctx, sqlQuery, args...)
+		if err != nil {
+			r.logger.Error().Err(err).Str("task_id", task.ID).Msgf("Failed to execute SQL query for %s", method)
+			return exceptions.ErrInternalError
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			r.logger.Error().Err(err).Str("task_id", task.ID).Msgf("Failed to read affected rows for %s", method)
+			return exceptions.ErrInternalError
+		}
+		if affected == 0 {
+			r.logger.Warn().Str("task_id", task.ID).Msgf("Conditional update for %s matched no row", method)
+			return exceptions.ErrConflict.WithDetails(fmt.Sprintf("Task ID %s was changed by another request. Reload it and try again.", task.ID))
+		}
+
+		after, err := auditRepo.Snapshot(ctx, tx, "tasks", task.ID)
+		if err != nil {
+			r.logger.Error().Err(err).Str("task_id", task.ID).Msgf("Failed to read task after %s", method)
+			return exceptions.ErrInternalError
+		}
+		err = auditRepo.Append(ctx, tx, auditModel.Event{ScheduleID: task.ScheduleID, EntityType: auditModel.EntityTask, EntityID: task.ID, Action: auditModel.ActionTaskStatusUpdated, Before: before, After: after})
+		if err != nil {
+			r.logger.Error().Err(err).Str("task_id", task.ID).Msgf("Failed to append audit event for %s", method)
+			return exceptions.ErrInternalError
+		}
+	}
+	return nil
+}
+
+// GetStatusHistory fetches every status change of a schedule, oldest first
+func (r *scheduleRepositoryImpl) GetStatusHistory(ctx context.Context, id string) ([]model.StatusTransition, error) {
+	history := []model.StatusTransition{}
+	sqlQuery, args, err := squirrel.Select("id", "schedule_id", "from_status", "to_status", "reason", "changed_by", "changed_at", "version").
+		From("schedule_status_transitions").
+		Where(squirrel.Eq{"schedule_id": id}).
+		OrderBy("changed_at ASC").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", id).Msg("Failed to build SQL query for GetStatusHistory")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.SelectContext(ctx, &history, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", id).Msg("Failed to execute SQL query for GetStatusHistory")
+		return nil, exceptions.ErrInternalError
+	}
+	return history, nil
+}
+
+// applyTransition runs qb with the status columns of transition set, only if the schedule is still
+// in transition.FromStatus at the version before transition.Version, and appends transition to the
+// status history and the audit trail in the same transaction. A schedule that was changed in the
+// meantime yields a conflict.
+func (r *scheduleRepositoryImpl) applyTransition(ctx context.Context, qb squirrel.UpdateBuilder, transition model.StatusTransition, action, method string) error {
+	return r.audited(ctx, transition.ScheduleID, action, method, func(tx *sqlx.Tx) error {
+		return r.transition(ctx, tx, qb, transition, method)
+	})
+}
+
+// transition runs the statements of applyTransition within tx
+func (r *scheduleRepositoryImpl) transition(ctx context.Context, tx *sqlx.Tx, qb squirrel.UpdateBuilder, transition model.StatusTransition, method string) error {
+	sqlQuery, args, err := qb.
+		Set("status", transition.ToStatus).
+		Set("status_reason", transition.Reason).
+		Set("status_changed_at", transition.ChangedAt).
+		Set("status_changed_by", transition.ChangedBy).
+		Set("updated_at", transition.ChangedAt).
+		Set("version", transition.Version).
+		Where(squirrel.Eq{"id": transition.ScheduleID, "status": transition.FromStatus, "version": transition.Version - 1}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", transition.ScheduleID).Msgf("Failed to build SQL query for %s", method)
 		return exceptions.ErrInternalError
 	}
 
-	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
+	result, err := tx.ExecContext(ctx, sqlQuery, args...)
 	if err != nil {
-		r.logger.Error().Err(err).Str("schedule_id", id).Msg("Failed to execute SQL query for LogVisitStart")
+		r.logger.Error().Err(err).Str("schedule_id", transition.ScheduleID).Msgf("Failed to execute SQL query for %s", method)
+		return exceptions.ErrInternalError
+	}
+	err = r.checkApplied(result, transition.ScheduleID, method)
+	if err != nil {
+		return err
+	}
+
+	sqlQuery, args, err = squirrel.Insert("schedule_status_transitions").
+		Columns("id", "schedule_id", "from_status", "to_status", "reason", "changed_by", "changed_at", "version").
+		Values(transition.ID, transition.ScheduleID, transition.FromStatus, transition.ToStatus, transition.Reason, transition.ChangedBy, transition.ChangedAt, transition.Version).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", transition.ScheduleID).Msgf("Failed to build SQL query for %s", method)
+		return exceptions.ErrInternalError
+	}
+
+	_, err = tx.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", transition.ScheduleID).Msgf("Failed to execute SQL query for %s", method)
 		return exceptions.ErrInternalError
 	}
 	return nil
 }
 
-// LogVisitEnd logs the end time and geolocation for a visit.
-// It updates the record by ID and sets status to 'completed'.
-// The service layer is responsible for pre-validating the 'in-progress' status.
-func (r *scheduleRepositoryImpl) LogVisitEnd(ctx context.Context, id string, endTime time.Time, latitude, longitude float64) error {
-	qb := squirrel.Update("schedules").
-		Set("end_time", endTime).
-		Set("end_latitude", latitude).
-		Set("end_longitude", longitude).
-		Set("status", "completed").
-		Set("updated_at", time.Now()).
-		Where(squirrel.Eq{"id": id}).
-		PlaceholderFormat(squirrel.Dollar)
+// audited runs change in a transaction and appends an audit event with the schedule's row before
+// and after it. The row is locked first, so that the event shows exactly what change did.
+func (r *scheduleRepositoryImpl) audited(ctx context.Context, id, action, method string, change func(tx *sqlx.Tx) error) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", id).Msgf("Failed to begin transaction for %s", method)
+		return exceptions.ErrInternalError
+	}
+	defer tx.Rollback() // No-op once committed
 
-	sqlQuery, args, err := qb.ToSql()
+	before, err := auditRepo.Snapshot(ctx, tx, "schedules", id)
 	if err != nil {
-		r.logger.Error().Err(err).Str("schedule_id", id).Msg("Failed to build SQL query for LogVisitEnd")
+		r.logger.Error().Err(err).Str("schedule_id", id).Msgf("Failed to read schedule before %s", method)
 		return exceptions.ErrInternalError
 	}
 
-	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
+	err = change(tx)
+	if err != nil {
+		return err
+	}
+
+	after, err := auditRepo.Snapshot(ctx, tx, "schedules", id)
 	if err != nil {
-		r.logger.Error().Err(err).Str("schedule_id", id).Msg("Failed to execute SQL query for LogVisitEnd")
+		r.logger.Error().Err(err).Str("schedule_id", id).Msgf("Failed to read schedule after %s", method)
+		return exceptions.ErrInternalError
+	}
+	err = auditRepo.Append(ctx, tx, auditModel.Event{ScheduleID: id, EntityType: auditModel.EntitySchedule, EntityID: id, Action: action, Before: before, After: after})
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", id).Msgf("Failed to append audit event for %s", method)
+		return exceptions.ErrInternalError
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", id).Msgf("Failed to commit transaction for %s", method)
 		return exceptions.ErrInternalError
 	}
+	return nil
+}
 
+// checkApplied turns a conditional update that matched no row into a conflict.
+// The service layer has already found the schedule, so no row means another request changed it first.
+func (r *scheduleRepositoryImpl) checkApplied(result sql.Result, id, method string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", id).Msgf("Failed to read affected rows for %s", method)
+		return exceptions.ErrInternalError
+	}
+	if affected == 0 {
+		r.logger.Warn().Str("schedule_id", id).Msgf("Conditional update for %s matched no row", method)
+		return exceptions.ErrConflict.WithDetails(fmt.Sprintf("Schedule ID %s was changed by another request. Reload it and try again.", id))
+	}
 	return nil
 }
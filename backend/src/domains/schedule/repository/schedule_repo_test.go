@@ -3,9 +3,11 @@ package repository_test
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	pkgmock "mini-evv-logger-backend/pkg_mock"
 	"mini-evv-logger-backend/src/domains/schedule/model"
 	"mini-evv-logger-backend/src/domains/schedule/repository"
+	taskModel "mini-evv-logger-backend/src/domains/task/model"
 	"regexp"
 	"testing"
 	"time"
@@ -35,16 +37,38 @@ func initMocks(t *testing.T) {
 	repo = repository.NewScheduleRepository(sqlxMock, pkgmock.InitMockLogger())
 }
 
+const (
+	snapshotQuery = `SELECT to_jsonb(t) FROM schedules t WHERE t.id = $1 FOR UPDATE`
+	auditQuery    = `INSERT INTO audit_events (schedule_id,entity_type,entity_id,action,actor_id,before,after,ip_address,user_agent) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`
+)
+
+// expectSnapshot expects the audit trail to read the schedule row; an empty row means it does not exist
+func expectSnapshot(id, row string) {
+	rows := sqlmock.NewRows([]string{"to_jsonb"})
+	if row != "" {
+		rows.AddRow([]byte(row))
+	}
+	mockSQL.ExpectQuery(regexp.QuoteMeta(snapshotQuery)).WithArgs(id).WillReturnRows(rows)
+}
+
+// expectAuditEvent expects an audit event for the schedule
+func expectAuditEvent(id, action string) {
+	mockSQL.ExpectExec(regexp.QuoteMeta(auditQuery)).
+		WithArgs(id, "schedule", id, action, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
 func TestGetSchedules(t *testing.T) {
 	initMocks(t)
 
 	dummyLimit, dummyOffset := 10, 0
 
-	countQuery := `SELECT COUNT(id) FROM schedules`
-	query := `SELECT id, client_name, shift_time, location, status, start_time, start_latitude, start_longitude, end_time, end_latitude, end_longitude, created_at, updated_at FROM schedules ORDER BY shift_time ASC LIMIT 10 OFFSET 0`
+	countQuery := `SELECT COUNT(s.id) FROM schedules s JOIN clients cl ON cl.id = s.client_id LEFT JOIN care_plans cp ON cp.id = s.care_plan_id`
+	query := `SELECT s.id, s.caregiver_id, s.client_id, cl.name AS client_name, s.shift_time, s.shift_end_time, (EXTRACT(EPOCH FROM s.shift_end_time - s.shift_time) / 60)::int AS duration_minutes, concat_ws(', ', cl.address_line1, cl.address_line2, cl.city, cl.state, cl.postal_code) AS location, cl.latitude AS client_latitude, cl.longitude AS client_longitude, s.status, s.status_reason, s.status_changed_at, s.status_changed_by, s.series_id, s.occurrence_time, s.is_detached, s.care_plan_id, cp.version AS care_plan_version, s.start_time, s.start_latitude, s.start_longitude, s.start_distance_meters, s.start_out_of_geofence, s.start_received_at, s.start_gps_fix_at, s.start_clock_skewed, s.end_time, s.end_latitude, s.end_longitude, s.end_distance_meters, s.end_out_of_geofence, s.end_received_at, s.end_gps_fix_at, s.end_clock_skewed, s.actual_minutes, s.variance_minutes, s.original_start_time, s.original_start_latitude, s.original_start_longitude, s.original_end_time, s.original_end_latitude, s.original_end_longitude, s.corrected_at, s.version, s.created_at, s.updated_at FROM schedules s JOIN clients cl ON cl.id = s.client_id LEFT JOIN care_plans cp ON cp.id = s.care_plan_id ORDER BY s.shift_time ASC LIMIT 10 OFFSET 0`
 	dummySchedules := []model.Schedule{
 		{
 			ID:             uuid.NewString(),
+			ClientID:       uuid.NewString(),
 			ClientName:     "Test Client",
 			ShiftTime:      time.Now(),
 			Location:       "Test Location",
@@ -71,8 +95,8 @@ func TestGetSchedules(t *testing.T) {
 		mockSQL.ExpectQuery(regexp.QuoteMeta(countQuery)).
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(len(dummySchedules)))
 		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "client_name", "shift_time", "location", "status", "start_time", "start_latitude", "start_longitude", "end_time", "end_latitude", "end_longitude", "created_at", "updated_at"}).
-				AddRow(dummySchedules[0].ID, dummySchedules[0].ClientName, dummySchedules[0].ShiftTime, dummySchedules[0].Location, dummySchedules[0].Status, dummySchedules[0].StartTime, dummySchedules[0].StartLatitude, dummySchedules[0].StartLongitude, dummySchedules[0].EndTime, dummySchedules[0].EndLatitude, dummySchedules[0].EndLongitude, dummySchedules[0].CreatedAt, dummySchedules[0].UpdatedAt))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "caregiver_id", "client_id", "client_name", "shift_time", "location", "client_latitude", "client_longitude", "status", "status_reason", "status_changed_at", "start_time", "start_latitude", "start_longitude", "start_distance_meters", "start_out_of_geofence", "end_time", "end_latitude", "end_longitude", "end_distance_meters", "end_out_of_geofence", "created_at", "updated_at"}).
+				AddRow(dummySchedules[0].ID, dummySchedules[0].CaregiverID, dummySchedules[0].ClientID, dummySchedules[0].ClientName, dummySchedules[0].ShiftTime, dummySchedules[0].Location, dummySchedules[0].ClientLatitude, dummySchedules[0].ClientLongitude, dummySchedules[0].Status, dummySchedules[0].StatusReason, dummySchedules[0].StatusChangedAt, dummySchedules[0].StartTime, dummySchedules[0].StartLatitude, dummySchedules[0].StartLongitude, dummySchedules[0].StartDistance, dummySchedules[0].StartOutOfFence, dummySchedules[0].EndTime, dummySchedules[0].EndLatitude, dummySchedules[0].EndLongitude, dummySchedules[0].EndDistance, dummySchedules[0].EndOutOfFence, dummySchedules[0].CreatedAt, dummySchedules[0].UpdatedAt))
 
 		schedules, total, err := repo.GetSchedules(context.Background(), dummyFilter)
 		assert.Nil(t, err)
@@ -103,13 +127,121 @@ func TestGetSchedules(t *testing.T) {
 
 }
 
+func TestGetSchedulesByCaregiver(t *testing.T) {
+	initMocks(t)
+
+	dummyCaregiverID := uuid.NewString()
+	dummyFilter := model.FilterSchedulesRequest{
+		Limit:       10,
+		Offset:      0,
+		CaregiverID: dummyCaregiverID,
+	}
+
+	t.Run("TestGetSchedulesByCaregiver: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(s.id) FROM schedules s JOIN clients cl ON cl.id = s.client_id LEFT JOIN care_plans cp ON cp.id = s.care_plan_id WHERE s.caregiver_id = $1`)).
+			WithArgs(dummyCaregiverID).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mockSQL.ExpectQuery(regexp.QuoteMeta(`FROM schedules s JOIN clients cl ON cl.id = s.client_id LEFT JOIN care_plans cp ON cp.id = s.care_plan_id WHERE s.caregiver_id = $1 ORDER BY s.shift_time ASC LIMIT 10 OFFSET 0`)).
+			WithArgs(dummyCaregiverID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "caregiver_id"}).AddRow(uuid.NewString(), dummyCaregiverID))
+
+		schedules, total, err := repo.GetSchedules(context.Background(), dummyFilter)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, total)
+		assert.Len(t, schedules, 1)
+		assert.Equal(t, dummyCaregiverID, *schedules[0].CaregiverID)
+	})
+}
+
+func TestGetSchedulesByDate(t *testing.T) {
+	initMocks(t)
+
+	from := `FROM schedules s JOIN clients cl ON cl.id = s.client_id LEFT JOIN care_plans cp ON cp.id = s.care_plan_id`
+	utc := func(s string) time.Time {
+		t, _ := time.Parse(time.RFC3339, s)
+		return t.UTC()
+	}
+
+	tests := []struct {
+		name   string
+		filter model.FilterSchedulesRequest
+		where  string
+		args   []driver.Value
+	}{
+		{
+			// Clocks spring forward, so the day lasts 23 hours
+			name:   "DST start in New York",
+			filter: model.FilterSchedulesRequest{Date: "2026-03-08", TZ: "America/New_York"},
+			where:  `WHERE s.shift_time >= $1 AND s.shift_time < $2`,
+			args:   []driver.Value{utc("2026-03-08T05:00:00Z"), utc("2026-03-09T04:00:00Z")},
+		},
+		{
+			// Clocks fall back, so the day lasts 25 hours
+			name:   "DST end in New York",
+			filter: model.FilterSchedulesRequest{Date: "2026-11-01", TZ: "America/New_York"},
+			where:  `WHERE s.shift_time >= $1 AND s.shift_time < $2`,
+			args:   []driver.Value{utc("2026-11-01T04:00:00Z"), utc("2026-11-02T05:00:00Z")},
+		},
+		{
+			name:   "DST start in Berlin",
+			filter: model.FilterSchedulesRequest{Date: "2026-03-29", TZ: "Europe/Berlin"},
+			where:  `WHERE s.shift_time >= $1 AND s.shift_time < $2`,
+			args:   []driver.Value{utc("2026-03-28T23:00:00Z"), utc("2026-03-29T22:00:00Z")},
+		},
+		{
+			name:   "Range across DST",
+			filter: model.FilterSchedulesRequest{From: "2026-10-31", To: "2026-11-02", TZ: "America/Chicago"},
+			where:  `WHERE s.shift_time >= $1 AND s.shift_time < $2`,
+			args:   []driver.Value{utc("2026-10-31T05:00:00Z"), utc("2026-11-03T06:00:00Z")},
+		},
+		{
+			name:   "Open range",
+			filter: model.FilterSchedulesRequest{From: "2026-03-08", TZ: "America/New_York"},
+			where:  `WHERE s.shift_time >= $1`,
+			args:   []driver.Value{utc("2026-03-08T05:00:00Z")},
+		},
+		{
+			name:   "Client timezone",
+			filter: model.FilterSchedulesRequest{Date: "2026-03-08"},
+			where:  `WHERE s.shift_time >= (CAST($1 AS timestamp) AT TIME ZONE cl.timezone) AND s.shift_time < (CAST($2 AS timestamp) AT TIME ZONE cl.timezone)`,
+			args:   []driver.Value{"2026-03-08", "2026-03-09"},
+		},
+		{
+			name:   "Client timezone with range",
+			filter: model.FilterSchedulesRequest{From: "2026-12-31", To: "2027-01-01"},
+			where:  `WHERE s.shift_time >= (CAST($1 AS timestamp) AT TIME ZONE cl.timezone) AND s.shift_time < (CAST($2 AS timestamp) AT TIME ZONE cl.timezone)`,
+			args:   []driver.Value{"2026-12-31", "2027-01-02"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestGetSchedulesByDate: "+tt.name, func(t *testing.T) {
+			tt.filter.Limit = 10
+
+			mockSQL.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(s.id) ` + from + ` ` + tt.where)).
+				WithArgs(tt.args...).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			mockSQL.ExpectQuery(regexp.QuoteMeta(from + ` ` + tt.where + ` ORDER BY s.shift_time ASC LIMIT 10 OFFSET 0`)).
+				WithArgs(tt.args...).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewString()))
+
+			schedules, total, err := repo.GetSchedules(context.Background(), tt.filter)
+			assert.Nil(t, err)
+			assert.Equal(t, 1, total)
+			assert.Len(t, schedules, 1)
+			assert.Nil(t, mockSQL.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestGetScheduleByID(t *testing.T) {
 	initMocks(t)
 
 	dummyID := uuid.NewString()
-	query := `SELECT id, client_name, shift_time, location, status, start_time, start_latitude, start_longitude, end_time, end_latitude, end_longitude, created_at, updated_at FROM schedules WHERE id = $1`
+	query := `SELECT s.id, s.caregiver_id, s.client_id, cl.name AS client_name, s.shift_time, s.shift_end_time, (EXTRACT(EPOCH FROM s.shift_end_time - s.shift_time) / 60)::int AS duration_minutes, concat_ws(', ', cl.address_line1, cl.address_line2, cl.city, cl.state, cl.postal_code) AS location, cl.latitude AS client_latitude, cl.longitude AS client_longitude, s.status, s.status_reason, s.status_changed_at, s.status_changed_by, s.series_id, s.occurrence_time, s.is_detached, s.care_plan_id, cp.version AS care_plan_version, s.start_time, s.start_latitude, s.start_longitude, s.start_distance_meters, s.start_out_of_geofence, s.start_received_at, s.start_gps_fix_at, s.start_clock_skewed, s.end_time, s.end_latitude, s.end_longitude, s.end_distance_meters, s.end_out_of_geofence, s.end_received_at, s.end_gps_fix_at, s.end_clock_skewed, s.actual_minutes, s.variance_minutes, s.original_start_time, s.original_start_latitude, s.original_start_longitude, s.original_end_time, s.original_end_latitude, s.original_end_longitude, s.corrected_at, s.version, s.created_at, s.updated_at FROM schedules s JOIN clients cl ON cl.id = s.client_id LEFT JOIN care_plans cp ON cp.id = s.care_plan_id WHERE s.id = $1`
 	dummySchedule := model.Schedule{
 		ID:             dummyID,
+		ClientID:       uuid.NewString(),
 		ClientName:     "Test Client",
 		ShiftTime:      time.Now(),
 		Location:       "Test Location",
@@ -126,8 +258,8 @@ func TestGetScheduleByID(t *testing.T) {
 	t.Run("TestGetScheduleByID: OK", func(t *testing.T) {
 		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
 			WithArgs(dummyID).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "client_name", "shift_time", "location", "status", "start_time", "start_latitude", "start_longitude", "end_time", "end_latitude", "end_longitude", "created_at", "updated_at"}).
-				AddRow(dummySchedule.ID, dummySchedule.ClientName, dummySchedule.ShiftTime, dummySchedule.Location, dummySchedule.Status, dummySchedule.StartTime, dummySchedule.StartLatitude, dummySchedule.StartLongitude, dummySchedule.EndTime, dummySchedule.EndLatitude, dummySchedule.EndLongitude, dummySchedule.CreatedAt, dummySchedule.UpdatedAt))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "caregiver_id", "client_id", "client_name", "shift_time", "location", "client_latitude", "client_longitude", "status", "status_reason", "status_changed_at", "start_time", "start_latitude", "start_longitude", "start_distance_meters", "start_out_of_geofence", "end_time", "end_latitude", "end_longitude", "end_distance_meters", "end_out_of_geofence", "created_at", "updated_at"}).
+				AddRow(dummySchedule.ID, dummySchedule.CaregiverID, dummySchedule.ClientID, dummySchedule.ClientName, dummySchedule.ShiftTime, dummySchedule.Location, dummySchedule.ClientLatitude, dummySchedule.ClientLongitude, dummySchedule.Status, dummySchedule.StatusReason, dummySchedule.StatusChangedAt, dummySchedule.StartTime, dummySchedule.StartLatitude, dummySchedule.StartLongitude, dummySchedule.StartDistance, dummySchedule.StartOutOfFence, dummySchedule.EndTime, dummySchedule.EndLatitude, dummySchedule.EndLongitude, dummySchedule.EndDistance, dummySchedule.EndOutOfFence, dummySchedule.CreatedAt, dummySchedule.UpdatedAt))
 
 		schedule, err := repo.GetScheduleByID(context.Background(), dummyID)
 		assert.Nil(t, err)
@@ -156,79 +288,400 @@ func TestGetScheduleByID(t *testing.T) {
 	})
 }
 
-func TestUpdateScheduleStatus(t *testing.T) {
+func TestGetScheduleOwnership(t *testing.T) {
 	initMocks(t)
 
 	dummyID := uuid.NewString()
-	dummyStatus := "completed"
-	query := `UPDATE schedules SET status = $1, updated_at = $2 WHERE id
-    = $3`
-	t.Run("TestUpdateScheduleStatus: OK", func(t *testing.T) {
+	query := `SELECT s.caregiver_id, c.branch_id FROM schedules s LEFT JOIN caregivers c ON c.id = s.caregiver_id WHERE s.id = $1`
+
+	t.Run("TestGetScheduleOwnership: OK", func(t *testing.T) {
+		dummyCaregiverID, dummyBranchID := uuid.NewString(), uuid.NewString()
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows([]string{"caregiver_id", "branch_id"}).AddRow(dummyCaregiverID, dummyBranchID))
+
+		ownership, err := repo.GetScheduleOwnership(context.Background(), dummyID)
+		assert.Nil(t, err)
+		assert.Equal(t, dummyCaregiverID, *ownership.CaregiverID)
+		assert.Equal(t, dummyBranchID, *ownership.BranchID)
+	})
+
+	t.Run("TestGetScheduleOwnership: No Rows", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnError(sql.ErrNoRows)
+
+		ownership, err := repo.GetScheduleOwnership(context.Background(), dummyID)
+		assert.NotNil(t, err)
+		assert.Nil(t, ownership)
+	})
+}
+
+func TestCreateSchedule(t *testing.T) {
+	initMocks(t)
+
+	dummyCaregiverID := uuid.NewString()
+	shiftTime := time.Now().Add(24 * time.Hour)
+	dummySchedule := model.Schedule{ID: uuid.NewString(), CaregiverID: &dummyCaregiverID, ClientID: uuid.NewString(), ShiftTime: shiftTime, ShiftEndTime: shiftTime.Add(time.Hour), Status: "upcoming"}
+	query := `INSERT INTO schedules (id,caregiver_id,client_id,shift_time,shift_end_time,status,care_plan_id) VALUES ($1,$2,$3,$4,$5,$6,$7)`
+	taskQuery := `INSERT INTO tasks (schedule_id,description,position) VALUES ($1,$2,$3),($4,$5,$6)`
+	t.Run("TestCreateSchedule: OK", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummySchedule.ID, "")
 		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
-			WithArgs(dummyStatus, sqlmock.AnyArg(), dummyID).
+			WithArgs(dummySchedule.ID, dummyCaregiverID, dummySchedule.ClientID, dummySchedule.ShiftTime, dummySchedule.ShiftEndTime, "upcoming", nil).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectSnapshot(dummySchedule.ID, `{"id":"`+dummySchedule.ID+`","status":"upcoming"}`)
+		expectAuditEvent(dummySchedule.ID, "schedule_created")
+		mockSQL.ExpectCommit()
+
+		err := repo.CreateSchedule(context.Background(), dummySchedule)
+		assert.Nil(t, err)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestCreateSchedule: With Care Plan Tasks", func(t *testing.T) {
+		carePlanID := uuid.NewString()
+		withTasks := dummySchedule
+		withTasks.CarePlanID = &carePlanID
+		withTasks.Tasks = []taskModel.Task{{Description: "Medication reminder"}, {Description: "Laundry"}}
 
-		err := repo.UpdateScheduleStatus(context.Background(), dummyID, dummyStatus)
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummySchedule.ID, "")
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(dummySchedule.ID, dummyCaregiverID, dummySchedule.ClientID, dummySchedule.ShiftTime, dummySchedule.ShiftEndTime, "upcoming", carePlanID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mockSQL.ExpectExec(regexp.QuoteMeta(taskQuery)).
+			WithArgs(dummySchedule.ID, "Medication reminder", 1, dummySchedule.ID, "Laundry", 2).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		expectSnapshot(dummySchedule.ID, `{"id":"`+dummySchedule.ID+`","status":"upcoming"}`)
+		expectAuditEvent(dummySchedule.ID, "schedule_created")
+		mockSQL.ExpectCommit()
+
+		err := repo.CreateSchedule(context.Background(), withTasks)
 		assert.Nil(t, err)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
 	})
 
-	t.Run("TestUpdateScheduleStatus: SQL Error", func(t *testing.T) {
+	t.Run("TestCreateSchedule: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummySchedule.ID, "")
 		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
 			WillReturnError(sql.ErrConnDone)
+		mockSQL.ExpectRollback()
 
-		err := repo.UpdateScheduleStatus(context.Background(), dummyID, dummyStatus)
+		err := repo.CreateSchedule(context.Background(), dummySchedule)
 		assert.NotNil(t, err)
 		assert.Equal(t, "Error 500: Internal server error", err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
 	})
+}
+
+func TestUpdateSchedule(t *testing.T) {
+	initMocks(t)
 
+	dummyID, dummyCaregiverID := uuid.NewString(), uuid.NewString()
+	dummyShiftTime := time.Now().Add(48 * time.Hour)
+	dummyShiftEndTime := dummyShiftTime.Add(time.Hour)
+	dummyRequest := model.UpdateScheduleRequest{ID: dummyID, CaregiverID: &dummyCaregiverID, ShiftTime: &dummyShiftTime, ShiftEndTime: &dummyShiftEndTime, Version: 3}
+	query := `UPDATE schedules SET updated_at = $1, is_detached = series_id IS NOT NULL, version = $2, caregiver_id = $3, shift_time = $4, shift_end_time = $5 WHERE id = $6 AND status = $7 AND version = $8`
+	t.Run("TestUpdateSchedule: OK", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyID, `{"id":"`+dummyID+`","version":3}`)
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(sqlmock.AnyArg(), 4, dummyCaregiverID, dummyShiftTime, dummyShiftEndTime, dummyID, "upcoming", 3).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectSnapshot(dummyID, `{"id":"`+dummyID+`","version":4}`)
+		expectAuditEvent(dummyID, "schedule_updated")
+		mockSQL.ExpectCommit()
+
+		err := repo.UpdateSchedule(context.Background(), dummyRequest)
+		assert.Nil(t, err)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestUpdateSchedule: Changed By Another Request", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyID, `{"id":"`+dummyID+`","version":4}`)
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mockSQL.ExpectRollback()
+
+		err := repo.UpdateSchedule(context.Background(), dummyRequest)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 409: Conflict - Schedule ID "+dummyID+" was changed by another request. Reload it and try again.", err.Error())
+	})
+
+	t.Run("TestUpdateSchedule: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyID, `{"id":"`+dummyID+`","version":3}`)
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+		mockSQL.ExpectRollback()
+
+		err := repo.UpdateSchedule(context.Background(), dummyRequest)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+	})
+}
+
+func TestGetOverdueSchedules(t *testing.T) {
+	initMocks(t)
+
+	cutoff := time.Now().Add(-time.Hour)
+	query := `SELECT id, status, version FROM schedules WHERE status = $1 AND shift_time < $2 ORDER BY shift_time ASC`
+	t.Run("TestGetOverdueSchedules: OK", func(t *testing.T) {
+		dummyIDs := []string{uuid.NewString(), uuid.NewString()}
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs("upcoming", cutoff).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status", "version"}).AddRow(dummyIDs[0], "upcoming", 1).AddRow(dummyIDs[1], "upcoming", 3))
+
+		schedules, err := repo.GetOverdueSchedules(context.Background(), cutoff)
+		assert.Nil(t, err)
+		assert.Len(t, schedules, 2)
+		assert.Equal(t, dummyIDs[1], schedules[1].ID)
+		assert.Equal(t, 3, schedules[1].Version)
+	})
+
+	t.Run("TestGetOverdueSchedules: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		schedules, err := repo.GetOverdueSchedules(context.Background(), cutoff)
+		assert.NotNil(t, err)
+		assert.Nil(t, schedules)
+	})
+}
+
+func TestTransitionStatus(t *testing.T) {
+	initMocks(t)
+
+	dummyReason := "No clock-in within 1h0m0s of the shift start"
+	dummyTransition := model.StatusTransition{ID: uuid.NewString(), ScheduleID: uuid.NewString(), FromStatus: model.StatusUpcoming, ToStatus: model.StatusMissed, Reason: &dummyReason, ChangedAt: time.Now(), Version: 2}
+	query := `UPDATE schedules SET status = $1, status_reason = $2, status_changed_at = $3, status_changed_by = $4, updated_at = $5, version = $6 WHERE id = $7 AND status = $8 AND version = $9`
+	historyQuery := `INSERT INTO schedule_status_transitions (id,schedule_id,from_status,to_status,reason,changed_by,changed_at,version) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`
+	t.Run("TestTransitionStatus: OK", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyTransition.ScheduleID, `{"id":"`+dummyTransition.ScheduleID+`"}`)
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs("missed", dummyReason, dummyTransition.ChangedAt, nil, dummyTransition.ChangedAt, 2, dummyTransition.ScheduleID, "upcoming", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectExec(regexp.QuoteMeta(historyQuery)).
+			WithArgs(dummyTransition.ID, dummyTransition.ScheduleID, "upcoming", "missed", dummyReason, nil, dummyTransition.ChangedAt, 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectSnapshot(dummyTransition.ScheduleID, `{"id":"`+dummyTransition.ScheduleID+`"}`)
+		expectAuditEvent(dummyTransition.ScheduleID, "status_changed")
+		mockSQL.ExpectCommit()
+
+		err := repo.TransitionStatus(context.Background(), dummyTransition)
+		assert.Nil(t, err)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestTransitionStatus: Changed By Another Request", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyTransition.ScheduleID, `{"id":"`+dummyTransition.ScheduleID+`"}`)
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mockSQL.ExpectRollback()
+
+		err := repo.TransitionStatus(context.Background(), dummyTransition)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 409: Conflict - Schedule ID "+dummyTransition.ScheduleID+" was changed by another request. Reload it and try again.", err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestTransitionStatus: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyTransition.ScheduleID, `{"id":"`+dummyTransition.ScheduleID+`"}`)
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectExec(regexp.QuoteMeta(historyQuery)).
+			WillReturnError(sql.ErrConnDone)
+		mockSQL.ExpectRollback()
+
+		err := repo.TransitionStatus(context.Background(), dummyTransition)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
 }
 
 func TestLogVisitStart(t *testing.T) {
 	initMocks(t)
 
-	dummyID := uuid.NewString()
-	dummyStartTime := time.Now()
-	dummyLatitude, dummyLongitude := 37.7749, -122.4194
-	query := `UPDATE schedules SET start_time = $1, start_latitude = $2, start_longitude = $3, status = $4, updated_at = $5 WHERE id = $6`
+	dummyUserID := uuid.NewString()
+	dummyTransition := model.StatusTransition{ID: uuid.NewString(), ScheduleID: uuid.NewString(), FromStatus: model.StatusUpcoming, ToStatus: model.StatusInProgress, ChangedBy: &dummyUserID, ChangedAt: time.Now(), Version: 5}
+	dummyDistance := 42.5
+	dummyGPSFixAt := time.Now().Add(-20 * time.Second)
+	dummyEvent := model.VisitEvent{Time: time.Now(), ReceivedAt: time.Now().Add(3 * time.Second), GPSFixAt: &dummyGPSFixAt, Latitude: 37.7749, Longitude: -122.4194, DistanceMeters: &dummyDistance}
+	query := `UPDATE schedules SET start_time = $1, start_latitude = $2, start_longitude = $3, start_distance_meters = $4, start_out_of_geofence = $5, start_received_at = $6, start_gps_fix_at = $7, start_clock_skewed = $8, status = $9, status_reason = $10, status_changed_at = $11, status_changed_by = $12, updated_at = $13, version = $14 WHERE id = $15 AND status = $16 AND version = $17`
+	historyQuery := `INSERT INTO schedule_status_transitions (id,schedule_id,from_status,to_status,reason,changed_by,changed_at,version) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`
 	t.Run("TestLogVisitStart: OK", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyTransition.ScheduleID, `{"id":"`+dummyTransition.ScheduleID+`"}`)
 		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
-			WithArgs(dummyStartTime, dummyLatitude, dummyLongitude, sqlmock.AnyArg(), sqlmock.AnyArg(), dummyID).
-			WillReturnResult(sqlmock.NewResult(1, 1))
-
-		err := repo.LogVisitStart(context.Background(), dummyID, dummyStartTime, dummyLatitude, dummyLongitude)
+			WithArgs(dummyEvent.Time, dummyEvent.Latitude, dummyEvent.Longitude, dummyEvent.DistanceMeters, false, dummyEvent.ReceivedAt, &dummyGPSFixAt, false, "in-progress", nil, dummyTransition.ChangedAt, dummyUserID, dummyTransition.ChangedAt, 5, dummyTransition.ScheduleID, "upcoming", 4).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectExec(regexp.QuoteMeta(historyQuery)).
+			WithArgs(dummyTransition.ID, dummyTransition.ScheduleID, "upcoming", "in-progress", nil, dummyUserID, dummyTransition.ChangedAt, 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectSnapshot(dummyTransition.ScheduleID, `{"id":"`+dummyTransition.ScheduleID+`"}`)
+		expectAuditEvent(dummyTransition.ScheduleID, "visit_started")
+		mockSQL.ExpectCommit()
+
+		err := repo.LogVisitStart(context.Background(), dummyTransition, dummyEvent)
 		assert.Nil(t, err)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
 	})
 
 	t.Run("TestLogVisitStart: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyTransition.ScheduleID, `{"id":"`+dummyTransition.ScheduleID+`"}`)
 		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
 			WillReturnError(sql.ErrConnDone)
-		err := repo.LogVisitStart(context.Background(), dummyID, dummyStartTime, dummyLatitude, dummyLongitude)
+		mockSQL.ExpectRollback()
+
+		err := repo.LogVisitStart(context.Background(), dummyTransition, dummyEvent)
 		assert.NotNil(t, err)
 		assert.Equal(t, "Error 500: Internal server error", err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
 	})
 }
 
 func TestLogVisitEnd(t *testing.T) {
 	initMocks(t)
 
-	dummyID := uuid.NewString()
-	dummyEndTime := time.Now()
-	dummyLatitude, dummyLongitude := 37.7749, -122.4194
-	query := `UPDATE schedules SET end_time = $1, end_latitude = $2, end_longitude = $3, status = $4, updated_at = $5 WHERE id = $6`
+	dummyTransition := model.StatusTransition{ID: uuid.NewString(), ScheduleID: uuid.NewString(), FromStatus: model.StatusInProgress, ToStatus: model.StatusCompleted, ChangedAt: time.Now(), Version: 2}
+	dummyDistance := 1250.0
+	dummyEvent := model.VisitEvent{Time: time.Now(), ReceivedAt: time.Now().Add(15 * time.Minute), Latitude: 37.7749, Longitude: -122.4194, DistanceMeters: &dummyDistance, OutOfGeofence: true, ClockSkewed: true, Minutes: 52, Variance: -8}
+	query := `UPDATE schedules SET end_time = $1, end_latitude = $2, end_longitude = $3, end_distance_meters = $4, end_out_of_geofence = $5, end_received_at = $6, end_gps_fix_at = $7, end_clock_skewed = $8, actual_minutes = $9, variance_minutes = $10, status = $11, status_reason = $12, status_changed_at = $13, status_changed_by = $14, updated_at = $15, version = $16 WHERE id = $17 AND status = $18 AND version = $19`
+	historyQuery := `INSERT INTO schedule_status_transitions (id,schedule_id,from_status,to_status,reason,changed_by,changed_at,version) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`
 	t.Run("TestLogVisitEnd: OK", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyTransition.ScheduleID, `{"id":"`+dummyTransition.ScheduleID+`"}`)
 		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
-			WithArgs(dummyEndTime, dummyLatitude, dummyLongitude, "completed", sqlmock.AnyArg(), dummyID).
-			WillReturnResult(sqlmock.NewResult(1, 1))
+			WithArgs(dummyEvent.Time, dummyEvent.Latitude, dummyEvent.Longitude, dummyEvent.DistanceMeters, true, dummyEvent.ReceivedAt, nil, true, 52, -8, "completed", nil, sqlmock.AnyArg(), nil, sqlmock.AnyArg(), 2, dummyTransition.ScheduleID, "in-progress", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectExec(regexp.QuoteMeta(historyQuery)).
+			WithArgs(dummyTransition.ID, dummyTransition.ScheduleID, "in-progress", "completed", nil, nil, sqlmock.AnyArg(), 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectSnapshot(dummyTransition.ScheduleID, `{"id":"`+dummyTransition.ScheduleID+`"}`)
+		expectAuditEvent(dummyTransition.ScheduleID, "visit_ended")
+		mockSQL.ExpectCommit()
+
+		err := repo.LogVisitEnd(context.Background(), dummyTransition, dummyEvent, nil)
+		assert.Nil(t, err)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
 
-		err := repo.LogVisitEnd(context.Background(), dummyID, dummyEndTime, dummyLatitude, dummyLongitude)
+	t.Run("TestLogVisitEnd: Stores Attestation", func(t *testing.T) {
+		dummySigner, dummyRelationship, dummyFormat := "Jane Doe", "family", model.SignatureFormatPNG
+		dummySignature := []byte("\x89PNG")
+		event := dummyEvent
+		event.Attestation = &model.Attestation{ScheduleID: dummyTransition.ScheduleID, SignerName: &dummySigner, SignerRelationship: &dummyRelationship,
+			SignatureFormat: &dummyFormat, SignaturePNG: dummySignature, SignedAt: dummyTransition.ChangedAt}
+		attestationQuery := `INSERT INTO visit_attestations (schedule_id,signer_name,signer_relationship,signature_format,signature_png,signature_strokes,unable_to_sign_reason,signed_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8) ON CONFLICT (schedule_id) DO UPDATE SET`
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyTransition.ScheduleID, `{"id":"`+dummyTransition.ScheduleID+`"}`)
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectExec(regexp.QuoteMeta(historyQuery)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectExec(regexp.QuoteMeta(attestationQuery)).
+			WithArgs(dummyTransition.ScheduleID, &dummySigner, &dummyRelationship, &dummyFormat, dummySignature, nil, nil, dummyTransition.ChangedAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectSnapshot(dummyTransition.ScheduleID, `{"id":"`+dummyTransition.ScheduleID+`"}`)
+		expectAuditEvent(dummyTransition.ScheduleID, "visit_ended")
+		mockSQL.ExpectCommit()
+
+		err := repo.LogVisitEnd(context.Background(), dummyTransition, event, nil)
 		assert.Nil(t, err)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestLogVisitEnd: Closes Tasks", func(t *testing.T) {
+		dummyReason := "visit ended"
+		dummyTask := taskModel.Task{ID: uuid.NewString(), ScheduleID: dummyTransition.ScheduleID, Status: "not_completed", Reason: &dummyReason, Version: 3}
+		taskSnapshotQuery := `SELECT to_jsonb(t) FROM tasks t WHERE t.id = $1 FOR UPDATE`
+		taskQuery := `UPDATE tasks SET status = $1, reason = $2, updated_at = $3, version = $4 WHERE id = $5 AND version = $6`
+		taskRow := `{"id":"` + dummyTask.ID + `","schedule_id":"` + dummyTransition.ScheduleID + `"}`
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyTransition.ScheduleID, `{"id":"`+dummyTransition.ScheduleID+`"}`)
+		mockSQL.ExpectQuery(regexp.QuoteMeta(taskSnapshotQuery)).WithArgs(dummyTask.ID).WillReturnRows(sqlmock.NewRows([]string{"to_jsonb"}).AddRow([]byte(taskRow)))
+		mockSQL.ExpectExec(regexp.QuoteMeta(taskQuery)).
+			WithArgs("not_completed", &dummyReason, dummyTransition.ChangedAt, 4, dummyTask.ID, 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectQuery(regexp.QuoteMeta(taskSnapshotQuery)).WithArgs(dummyTask.ID).WillReturnRows(sqlmock.NewRows([]string{"to_jsonb"}).AddRow([]byte(taskRow)))
+		mockSQL.ExpectExec(regexp.QuoteMeta(auditQuery)).
+			WithArgs(dummyTransition.ScheduleID, "task", dummyTask.ID, "task_status_updated", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectExec(regexp.QuoteMeta(historyQuery)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectSnapshot(dummyTransition.ScheduleID, `{"id":"`+dummyTransition.ScheduleID+`"}`)
+		expectAuditEvent(dummyTransition.ScheduleID, "visit_ended")
+		mockSQL.ExpectCommit()
+
+		err := repo.LogVisitEnd(context.Background(), dummyTransition, dummyEvent, []taskModel.Task{dummyTask})
+		assert.Nil(t, err)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestLogVisitEnd: Task Changed By Another Request", func(t *testing.T) {
+		dummyTask := taskModel.Task{ID: uuid.NewString(), ScheduleID: dummyTransition.ScheduleID, Status: "not_completed", Version: 3}
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyTransition.ScheduleID, `{"id":"`+dummyTransition.ScheduleID+`"}`)
+		mockSQL.ExpectQuery(regexp.QuoteMeta(`SELECT to_jsonb(t) FROM tasks t WHERE t.id = $1 FOR UPDATE`)).WithArgs(dummyTask.ID).WillReturnRows(sqlmock.NewRows([]string{"to_jsonb"}).AddRow([]byte(`{}`)))
+		mockSQL.ExpectExec(regexp.QuoteMeta(`UPDATE tasks SET`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mockSQL.ExpectRollback()
+
+		err := repo.LogVisitEnd(context.Background(), dummyTransition, dummyEvent, []taskModel.Task{dummyTask})
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 409: Conflict - Task ID "+dummyTask.ID+" was changed by another request. Reload it and try again.", err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
 	})
 
 	t.Run("TestLogVisitEnd: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyTransition.ScheduleID, `{"id":"`+dummyTransition.ScheduleID+`"}`)
 		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
 			WillReturnError(sql.ErrConnDone)
-		err := repo.LogVisitEnd(context.Background(), dummyID, dummyEndTime, dummyLatitude, dummyLongitude)
+		mockSQL.ExpectRollback()
+
+		err := repo.LogVisitEnd(context.Background(), dummyTransition, dummyEvent, nil)
 		assert.NotNil(t, err)
 		assert.Equal(t, "Error 500: Internal server error", err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+}
+
+func TestGetStatusHistory(t *testing.T) {
+	initMocks(t)
+
+	dummyID := uuid.NewString()
+	query := `SELECT id, schedule_id, from_status, to_status, reason, changed_by, changed_at, version FROM schedule_status_transitions WHERE schedule_id = $1 ORDER BY changed_at ASC`
+	t.Run("TestGetStatusHistory: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "schedule_id", "from_status", "to_status", "reason", "changed_by", "changed_at", "version"}).
+				AddRow(uuid.NewString(), dummyID, "upcoming", "in-progress", nil, uuid.NewString(), time.Now(), 2).
+				AddRow(uuid.NewString(), dummyID, "in-progress", "completed", nil, uuid.NewString(), time.Now(), 3))
+
+		history, err := repo.GetStatusHistory(context.Background(), dummyID)
+		assert.Nil(t, err)
+		assert.Len(t, history, 2)
+		assert.Equal(t, model.StatusCompleted, history[1].ToStatus)
+	})
+
+	t.Run("TestGetStatusHistory: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		history, err := repo.GetStatusHistory(context.Background(), dummyID)
+		assert.Nil(t, history)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
 	})
 }
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/src/domains/schedule/model"
+	"time"
+)
+
+const (
+	maxSignatureSide = 2000 // Largest width and height of a signature image, in pixels
+	strokePadding    = 8    // Margin around rendered strokes, in pixels
+	strokeRadius     = 1.5  // Half the width of a rendered stroke, in pixels
+)
+
+// newAttestation checks the confirmation sent with a clock-out and prepares it for storage. A signature
+// needs the signer's name and relationship; strokes are rendered so that every signature can be downloaded as PNG.
+// Uploaded PNGs are decoded and encoded again, so that only the image itself is stored and served.
+func newAttestation(scheduleID string, signedAt time.Time, req *model.AttestationRequest) (*model.Attestation, error) {
+	if req == nil {
+		return nil, nil
+	}
+
+	attestation := &model.Attestation{ScheduleID: scheduleID, SignedAt: signedAt, UnableToSignReason: req.UnableToSignReason}
+	if req.SignerName != "" {
+		attestation.SignerName = &req.SignerName
+	}
+	if req.SignerRelationship != "" {
+		attestation.SignerRelationship = &req.SignerRelationship
+	}
+
+	signed := len(req.SignaturePNG) > 0 || len(req.SignatureStrokes) > 0
+	switch {
+	case len(req.SignaturePNG) > 0 && len(req.SignatureStrokes) > 0:
+		return nil, exceptions.ErrBadRequest.WithDetails("Send either signature_png or signature_strokes, not both")
+	case req.UnableToSignReason != nil && signed:
+		return nil, exceptions.ErrBadRequest.WithDetails("A signature cannot be sent together with unable_to_sign_reason")
+	case req.UnableToSignReason != nil:
+		return attestation, nil
+	case !signed:
+		return nil, exceptions.ErrBadRequest.WithDetails("An attestation needs signature_png or signature_strokes, or unable_to_sign_reason")
+	case req.SignerName == "" || req.SignerRelationship == "":
+		return nil, exceptions.ErrBadRequest.WithDetails("signer_name and signer_relationship are required with a signature")
+	}
+
+	format := model.SignatureFormatPNG
+	if len(req.SignatureStrokes) > 0 {
+		format = model.SignatureFormatStrokes
+		points, length := measureStrokes(req.SignatureStrokes)
+		if points > model.MaxSignaturePoints {
+			return nil, exceptions.ErrBadRequest.WithDetails(fmt.Sprintf("signature_strokes must have at most %d points in total", model.MaxSignaturePoints))
+		}
+		if length > model.MaxSignaturePathLength {
+			return nil, exceptions.ErrBadRequest.WithDetails(fmt.Sprintf("signature_strokes must be at most %d pixels long in total", model.MaxSignaturePathLength))
+		}
+		strokes, err := json.Marshal(req.SignatureStrokes)
+		if err != nil {
+			return nil, exceptions.ErrInternalError
+		}
+		encoded := string(strokes)
+		attestation.SignatureStrokes = &encoded
+		attestation.SignaturePNG, err = renderStrokes(req.SignatureStrokes)
+		if err != nil {
+			return nil, exceptions.ErrInternalError
+		}
+	} else {
+		config, err := png.DecodeConfig(bytes.NewReader(req.SignaturePNG))
+		if err != nil {
+			return nil, exceptions.ErrBadRequest.WithDetails("signature_png is not a valid PNG image")
+		}
+		if config.Width > maxSignatureSide || config.Height > maxSignatureSide {
+			return nil, exceptions.ErrBadRequest.WithDetails(fmt.Sprintf("signature_png must be at most %d x %d pixels", maxSignatureSide, maxSignatureSide))
+		}
+		// Decode the whole image rather than trusting the header, and drop anything but its pixels
+		img, err := png.Decode(bytes.NewReader(req.SignaturePNG))
+		if err != nil {
+			return nil, exceptions.ErrBadRequest.WithDetails("signature_png is not a valid PNG image")
+		}
+		attestation.SignaturePNG, err = encodePNG(img)
+		if err != nil {
+			return nil, exceptions.ErrInternalError
+		}
+	}
+	attestation.SignatureFormat = &format
+	return attestation, nil
+}
+
+// measureStrokes counts the points of signature strokes and adds up the length of their segments
+func measureStrokes(strokes [][]model.StrokePoint) (points int, length float64) {
+	for _, stroke := range strokes {
+		points += len(stroke)
+		for i := 1; i < len(stroke); i++ {
+			length += math.Hypot(stroke[i].X-stroke[i-1].X, stroke[i].Y-stroke[i-1].Y)
+		}
+	}
+	return points, length
+}
+
+// renderStrokes draws signature strokes in black on a transparent PNG just large enough to hold them
+func renderStrokes(strokes [][]model.StrokePoint) ([]byte, error) {
+	var width, height float64
+	for _, stroke := range strokes {
+		for _, point := range stroke {
+			width, height = math.Max(width, point.X), math.Max(height, point.Y)
+		}
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, int(math.Ceil(width))+2*strokePadding, int(math.Ceil(height))+2*strokePadding))
+
+	for _, stroke := range strokes {
+		prev := stroke[0]
+		drawDot(img, prev.X, prev.Y)
+		for _, point := range stroke[1:] {
+			// Stamp dots at most half a pixel apart along the segment
+			steps := int(math.Ceil(2 * math.Max(math.Abs(point.X-prev.X), math.Abs(point.Y-prev.Y))))
+			for i := 1; i <= steps; i++ {
+				t := float64(i) / float64(steps)
+				drawDot(img, prev.X+t*(point.X-prev.X), prev.Y+t*(point.Y-prev.Y))
+			}
+			prev = point
+		}
+	}
+
+	return encodePNG(img)
+}
+
+// encodePNG encodes an image as PNG
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawDot fills the pen around the point x, y of the signature pad
+func drawDot(img *image.NRGBA, x, y float64) {
+	x, y = x+strokePadding, y+strokePadding
+	for py := int(math.Floor(y - strokeRadius)); py <= int(math.Ceil(y+strokeRadius)); py++ {
+		for px := int(math.Floor(x - strokeRadius)); px <= int(math.Ceil(x+strokeRadius)); px++ {
+			if math.Hypot(float64(px)-x, float64(py)-y) <= strokeRadius {
+				img.SetNRGBA(px, py, color.NRGBA{A: 255})
+			}
+		}
+	}
+}
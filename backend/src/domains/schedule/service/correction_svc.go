@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context" // Import context
+	"fmt"
+	"math"
+	"mini-evv-logger-backend/exceptions"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	"mini-evv-logger-backend/src/domains/schedule/model"
+	taskModel "mini-evv-logger-backend/src/domains/task/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// correctionReason is stored as the status reason when an approved correction clocks out a visit
+const correctionReason = "Clock-out added by an approved correction"
+
+// RequestCorrection proposes new clock-in or clock-out times or locations, or new task outcomes, for a visit
+// that was clocked in. The schedule is only changed once a coordinator approves the correction.
+func (s *scheduleServiceImpl) RequestCorrection(ctx context.Context, req model.RequestCorrectionRequest) (*model.Correction, error) {
+	log.Info().Str("schedule_id", req.ScheduleID).Str("user_id", authModel.ActorID(ctx)).Str("reason_code", req.ReasonCode).Msg("Attempting to request visit correction")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for RequestCorrectionRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+	if req.IsEmpty() {
+		return nil, exceptions.ErrBadRequest.WithDetails("At least one of start_time, start_latitude/start_longitude, end_time, end_latitude/end_longitude or tasks is required")
+	}
+
+	// 1. Check if the schedule exists and its current status
+	schedule, err := s.scheduleRepo.GetScheduleByID(ctx, req.ScheduleID)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", req.ScheduleID).Msg("Failed to retrieve schedule before requesting correction")
+		return nil, err
+	}
+
+	// 2. Apply business logic: only recorded visits can be corrected, and the result must still be a valid visit
+	err = checkCorrection(schedule, req.StartTime, req.EndTime, req.EndLatitude != nil, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Corrected tasks must belong to the visit
+	if len(req.Tasks) > 0 {
+		tasks, err := s.taskRepo.GetTasksByScheduleID(ctx, req.ScheduleID)
+		if err != nil {
+			log.Error().Err(err).Str("schedule_id", req.ScheduleID).Msg("Failed to retrieve tasks before requesting correction")
+			return nil, err
+		}
+		for _, task := range req.Tasks {
+			if findTask(tasks, task.TaskID) == nil {
+				return nil, exceptions.ErrUnprocessableEntity.WithDetails(fmt.Sprintf("Task ID %s does not belong to schedule ID %s", task.TaskID, req.ScheduleID))
+			}
+		}
+	}
+
+	// 4. A visit has at most one pending correction, so that approvals cannot overwrite each other
+	pending, err := s.scheduleRepo.GetCorrections(ctx, model.FilterCorrectionsRequest{ScheduleID: req.ScheduleID, Status: model.CorrectionPending})
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", req.ScheduleID).Msg("Failed to fetch pending corrections from repository")
+		return nil, err
+	}
+	if len(pending) > 0 {
+		return nil, exceptions.ErrConflict.WithDetails(fmt.Sprintf("Schedule ID %s already has a pending correction %s", req.ScheduleID, pending[0].ID))
+	}
+
+	// 5. Store the correction via repository
+	correction := model.Correction{
+		ID:             uuid.NewString(),
+		ScheduleID:     req.ScheduleID,
+		Status:         model.CorrectionPending,
+		StartTime:      req.StartTime,
+		StartLatitude:  req.StartLatitude,
+		StartLongitude: req.StartLongitude,
+		EndTime:        req.EndTime,
+		EndLatitude:    req.EndLatitude,
+		EndLongitude:   req.EndLongitude,
+		ReasonCode:     req.ReasonCode,
+		Comment:        req.Comment,
+	}
+	for _, task := range req.Tasks {
+		correction.Tasks = append(correction.Tasks, model.CorrectionTask{CorrectionID: correction.ID, TaskID: task.TaskID, Status: task.Status, Reason: task.Reason})
+	}
+	if actorID := authModel.ActorID(ctx); actorID != "" {
+		correction.RequestedBy = &actorID
+	}
+	err = s.scheduleRepo.CreateCorrection(ctx, correction)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", req.ScheduleID).Msg("Failed to create correction in repository")
+		return nil, err
+	}
+
+	return s.scheduleRepo.GetCorrectionByID(ctx, correction.ID)
+}
+
+// GetCorrections fetches the corrections visible to the principal
+func (s *scheduleServiceImpl) GetCorrections(ctx context.Context, filter model.FilterCorrectionsRequest) ([]model.Correction, error) {
+	log.Info().Msgf("Fetching visit corrections with filter %s", filter.String())
+
+	err := filter.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for FilterCorrectionsRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	// Restrict the listing to what the principal is allowed to see
+	if principal, ok := authModel.PrincipalFromContext(ctx); ok {
+		switch principal.Role {
+		case authModel.RoleCaregiver:
+			if principal.CaregiverID == nil {
+				return nil, exceptions.ErrForbidden.WithDetails("User is not linked to a caregiver")
+			}
+			filter.CaregiverID = *principal.CaregiverID
+		case authModel.RoleCoordinator:
+			if principal.BranchID == nil {
+				return nil, exceptions.ErrForbidden.WithDetails("Coordinator is not assigned to a branch")
+			}
+			filter.BranchID = *principal.BranchID
+		}
+	}
+
+	corrections, err := s.scheduleRepo.GetCorrections(ctx, filter)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch visit corrections from repository")
+		return nil, err
+	}
+	return corrections, nil
+}
+
+// ResolveCorrection approves or rejects a pending correction. An approved correction is applied to
+// the schedule and its tasks, keeping the values it replaces as the original clock-in and clock-out.
+func (s *scheduleServiceImpl) ResolveCorrection(ctx context.Context, req model.ResolveCorrectionRequest) (*model.Correction, error) {
+	log.Info().Str("correction_id", req.ID).Str("schedule_id", req.ScheduleID).Str("user_id", authModel.ActorID(ctx)).Str("status", req.Status).Msg("Attempting to resolve visit correction")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for ResolveCorrectionRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+	if req.Status == model.CorrectionRejected && req.Note == nil {
+		return nil, exceptions.ErrBadRequest.WithDetails("A note is required when rejecting a correction")
+	}
+
+	// 1. Check if the correction exists on this schedule and is still pending
+	correction, err := s.scheduleRepo.GetCorrectionByID(ctx, req.ID)
+	if err != nil {
+		log.Error().Err(err).Str("correction_id", req.ID).Msg("Failed to retrieve correction before resolving")
+		return nil, err
+	}
+	if correction.ScheduleID != req.ScheduleID {
+		return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Correction with ID %s not found", req.ID))
+	}
+	if correction.IsResolved() {
+		return nil, exceptions.ErrConflict.WithDetails(fmt.Sprintf("Correction %s is already %s", req.ID, correction.Status))
+	}
+
+	// 2. Apply business logic: a correction is approved by someone other than the person who asked for it
+	actorID := authModel.ActorID(ctx)
+	if req.Status == model.CorrectionApproved && correction.RequestedBy != nil && *correction.RequestedBy == actorID {
+		return nil, exceptions.ErrForbidden.WithDetails("A correction must be approved by someone other than the requester")
+	}
+	now := time.Now()
+	correction.Status = req.Status
+	correction.ResolvedAt = &now
+	correction.ResolutionNote = req.Note
+	if actorID != "" {
+		correction.ResolvedBy = &actorID
+	}
+
+	// 3. Perform the update via repository
+	if req.Status == model.CorrectionRejected {
+		err = s.scheduleRepo.RejectCorrection(ctx, *correction)
+		if err != nil {
+			log.Error().Err(err).Str("correction_id", req.ID).Msg("Failed to reject correction in repository")
+			return nil, err
+		}
+		return s.scheduleRepo.GetCorrectionByID(ctx, req.ID)
+	}
+
+	schedule, err := s.scheduleRepo.GetScheduleByID(ctx, correction.ScheduleID)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", correction.ScheduleID).Msg("Failed to retrieve schedule before applying correction")
+		return nil, err
+	}
+	visit, err := s.correctVisit(ctx, schedule, correction, now)
+	if err != nil {
+		return nil, err
+	}
+	err = s.scheduleRepo.ApplyCorrection(ctx, *correction, visit)
+	if err != nil {
+		log.Error().Err(err).Str("correction_id", req.ID).Msg("Failed to apply correction in repository")
+		return nil, err
+	}
+
+	return s.scheduleRepo.GetCorrectionByID(ctx, req.ID)
+}
+
+// correctVisit works out the clock-in, clock-out and tasks of schedule once correction is applied.
+// Corrected locations are measured against the geofence again and the visit length is recalculated.
+func (s *scheduleServiceImpl) correctVisit(ctx context.Context, schedule *model.Schedule, correction *model.Correction, now time.Time) (model.CorrectedVisit, error) {
+	// The schedule may have changed since the correction was requested
+	err := checkCorrection(schedule, correction.StartTime, correction.EndTime, correction.EndLatitude != nil, now)
+	if err != nil {
+		return model.CorrectedVisit{}, err
+	}
+	tasks, err := s.taskRepo.GetTasksByScheduleID(ctx, schedule.ID)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", schedule.ID).Msg("Failed to retrieve tasks before applying correction")
+		return model.CorrectedVisit{}, err
+	}
+
+	visit := model.CorrectedVisit{Version: schedule.Version + 1}
+	for _, corrected := range correction.Tasks {
+		task := findTask(tasks, corrected.TaskID)
+		if task == nil {
+			return model.CorrectedVisit{}, exceptions.ErrConflict.WithDetails(fmt.Sprintf("Task ID %s no longer belongs to schedule ID %s", corrected.TaskID, schedule.ID))
+		}
+		task.Status = corrected.Status
+		task.Reason = corrected.Reason
+		visit.Tasks = append(visit.Tasks, *task)
+	}
+
+	visit.Start, err = s.correctEvent(schedule, recordedEvent(schedule.StartTime, schedule.StartLatitude, schedule.StartLongitude, schedule.StartDistance, schedule.StartOutOfFence),
+		correction.StartTime, correction.StartLatitude, correction.StartLongitude)
+	if err != nil {
+		return model.CorrectedVisit{}, err
+	}
+
+	if schedule.EndTime == nil && correction.EndTime == nil {
+		return visit, nil // Still in progress, only the clock-in changes
+	}
+	end, err := s.correctEvent(schedule, recordedEvent(schedule.EndTime, schedule.EndLatitude, schedule.EndLongitude, schedule.EndDistance, schedule.EndOutOfFence),
+		correction.EndTime, correction.EndLatitude, correction.EndLongitude)
+	if err != nil {
+		return model.CorrectedVisit{}, err
+	}
+	end.Minutes = int(math.Round(end.Time.Sub(visit.Start.Time).Minutes()))
+	end.Variance = end.Minutes - int(math.Round(schedule.ShiftEndTime.Sub(schedule.ShiftTime).Minutes()))
+	visit.End = &end
+
+	// A visit that was never clocked out is completed by the correction, with the same rule for its tasks as at clock-out
+	if schedule.Status == model.StatusInProgress {
+		reason := correctionReason
+		transition, err := newTransition(ctx, schedule, model.StatusCompleted, &reason)
+		if err != nil {
+			return model.CorrectedVisit{}, err
+		}
+		visit.Transition = &transition
+
+		closedTasks, err := s.closeUnresolvedTasks(schedule.ID, tasks)
+		if err != nil {
+			return model.CorrectedVisit{}, err
+		}
+		visit.Tasks = append(visit.Tasks, closedTasks...)
+	}
+	return visit, nil
+}
+
+// findTask returns the task with the given ID, or nil if tasks does not contain it
+func findTask(tasks []taskModel.Task, id string) *taskModel.Task {
+	for i := range tasks {
+		if tasks[i].ID == id {
+			return &tasks[i]
+		}
+	}
+	return nil
+}
+
+// correctEvent applies the corrected time and location to a recorded clock-in or clock-out
+func (s *scheduleServiceImpl) correctEvent(schedule *model.Schedule, event model.VisitEvent, at *time.Time, latitude, longitude *float64) (model.VisitEvent, error) {
+	if at != nil {
+		event.Time = *at
+	}
+	if latitude == nil || longitude == nil {
+		return event, nil
+	}
+	return s.checkGeofence(schedule, event.Time, *latitude, *longitude)
+}
+
+// recordedEvent describes a clock-in or clock-out as it is stored on the schedule
+func recordedEvent(at *time.Time, latitude, longitude, distance *float64, outOfFence bool) model.VisitEvent {
+	event := model.VisitEvent{DistanceMeters: distance, OutOfGeofence: outOfFence}
+	if at != nil {
+		event.Time = *at
+	}
+	if latitude != nil && longitude != nil {
+		event.Latitude, event.Longitude = *latitude, *longitude
+	}
+	return event
+}
+
+// checkCorrection verifies that schedule can be corrected and that the corrected times make a valid visit
+func checkCorrection(schedule *model.Schedule, startTime, endTime *time.Time, endLocation bool, now time.Time) error {
+	if (schedule.Status != model.StatusInProgress && schedule.Status != model.StatusCompleted) || schedule.StartTime == nil {
+		return exceptions.ErrConflict.WithDetails(fmt.Sprintf("Schedule ID %s is %s; only visits that are in progress or completed can be corrected", schedule.ID, schedule.Status))
+	}
+
+	// A visit without a clock-out can only get a complete one
+	if schedule.EndTime == nil && (endTime != nil || endLocation) && (endTime == nil || !endLocation) {
+		return exceptions.ErrUnprocessableEntity.WithDetails(fmt.Sprintf("Schedule ID %s was not clocked out; end_time, end_latitude and end_longitude are all required", schedule.ID))
+	}
+
+	start, end := schedule.StartTime, schedule.EndTime
+	if startTime != nil {
+		start = startTime
+	}
+	if endTime != nil {
+		end = endTime
+	}
+	if start.After(now) || (end != nil && end.After(now)) {
+		return exceptions.ErrUnprocessableEntity.WithDetails("Corrected times cannot be in the future")
+	}
+	if end != nil && !end.After(*start) {
+		return exceptions.ErrUnprocessableEntity.WithDetails(fmt.Sprintf("The corrected clock-out at %s must be after the clock-in at %s", end.Format(time.RFC3339), start.Format(time.RFC3339)))
+	}
+	return nil
+}
@@ -0,0 +1,382 @@
+package service_test
+
+import (
+	"context"
+	"mini-evv-logger-backend/exceptions"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	"mini-evv-logger-backend/src/domains/schedule/model"
+	taskModel "mini-evv-logger-backend/src/domains/task/model"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRequestCorrection(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyID, dummyUserID := uuid.NewString(), uuid.NewString()
+	caregiverCtx := authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: dummyUserID, Role: authModel.RoleCaregiver})
+	startTime := time.Now().Add(-2 * time.Hour)
+	endTime := time.Now().Add(-time.Hour)
+	latitude, longitude := 12.345678, 98.765432
+
+	t.Run("TestRequestCorrection: OK", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusInProgress, StartTime: &startTime}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetCorrections(gomock.Any(), model.FilterCorrectionsRequest{ScheduleID: dummyID, Status: model.CorrectionPending}).Return([]model.Correction{}, nil).Times(1)
+		var correctionID string
+		mockScheduleRepo.EXPECT().CreateCorrection(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, correction model.Correction) error {
+				correctionID = correction.ID
+				assert.Equal(t, dummyID, correction.ScheduleID)
+				assert.Equal(t, model.CorrectionPending, correction.Status)
+				assert.Equal(t, &endTime, correction.EndTime)
+				assert.Equal(t, dummyUserID, *correction.RequestedBy)
+				return nil
+			}).Times(1)
+		mockScheduleRepo.EXPECT().GetCorrectionByID(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, id string) (*model.Correction, error) {
+				assert.Equal(t, correctionID, id)
+				return &model.Correction{ID: id, ScheduleID: dummyID, Status: model.CorrectionPending}, nil
+			}).Times(1)
+
+		req := model.RequestCorrectionRequest{ScheduleID: dummyID, EndTime: &endTime, EndLatitude: &latitude, EndLongitude: &longitude, ReasonCode: "forgot_to_clock_out"}
+		correction, err := svc.RequestCorrection(caregiverCtx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, model.CorrectionPending, correction.Status)
+	})
+
+	t.Run("TestRequestCorrection: Task Outcomes", func(t *testing.T) {
+		taskID, reason := uuid.NewString(), "Client declined the walk"
+		recordedEnd := time.Now().Add(-30 * time.Minute)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusCompleted, StartTime: &startTime, EndTime: &recordedEnd}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return([]taskModel.Task{{ID: taskID, ScheduleID: dummyID, Status: taskModel.TaskCompleted}}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetCorrections(gomock.Any(), gomock.Any()).Return([]model.Correction{}, nil).Times(1)
+		mockScheduleRepo.EXPECT().CreateCorrection(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, correction model.Correction) error {
+				if assert.Len(t, correction.Tasks, 1) {
+					assert.Equal(t, correction.ID, correction.Tasks[0].CorrectionID)
+					assert.Equal(t, taskID, correction.Tasks[0].TaskID)
+					assert.Equal(t, taskModel.TaskNotCompleted, correction.Tasks[0].Status)
+					assert.Equal(t, &reason, correction.Tasks[0].Reason)
+				}
+				return nil
+			}).Times(1)
+		mockScheduleRepo.EXPECT().GetCorrectionByID(gomock.Any(), gomock.Any()).Return(&model.Correction{ID: uuid.NewString(), Status: model.CorrectionPending}, nil).Times(1)
+
+		req := model.RequestCorrectionRequest{ScheduleID: dummyID, ReasonCode: "wrong_time",
+			Tasks: []model.CorrectionTaskRequest{{TaskID: taskID, Status: taskModel.TaskNotCompleted, Reason: &reason}}}
+		_, err := svc.RequestCorrection(caregiverCtx, req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestRequestCorrection: Task Of Another Schedule", func(t *testing.T) {
+		taskID := uuid.NewString()
+		recordedEnd := time.Now().Add(-30 * time.Minute)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusCompleted, StartTime: &startTime, EndTime: &recordedEnd}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return([]taskModel.Task{{ID: uuid.NewString(), ScheduleID: dummyID}}, nil).Times(1)
+
+		req := model.RequestCorrectionRequest{ScheduleID: dummyID, ReasonCode: "wrong_time",
+			Tasks: []model.CorrectionTaskRequest{{TaskID: taskID, Status: taskModel.TaskCompleted}}}
+		_, err := svc.RequestCorrection(caregiverCtx, req)
+		assert.Error(t, err)
+		assert.Equal(t, exceptions.ErrUnprocessableEntity.WithDetails("Task ID "+taskID+" does not belong to schedule ID "+dummyID).Error(), err.Error())
+	})
+
+	t.Run("TestRequestCorrection: Not Completed Without Reason", func(t *testing.T) {
+		req := model.RequestCorrectionRequest{ScheduleID: dummyID, ReasonCode: "wrong_time",
+			Tasks: []model.CorrectionTaskRequest{{TaskID: uuid.NewString(), Status: taskModel.TaskNotCompleted}}}
+		_, err := svc.RequestCorrection(caregiverCtx, req)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestRequestCorrection: Duplicate Task", func(t *testing.T) {
+		taskID := uuid.NewString()
+		req := model.RequestCorrectionRequest{ScheduleID: dummyID, ReasonCode: "wrong_time",
+			Tasks: []model.CorrectionTaskRequest{{TaskID: taskID, Status: taskModel.TaskCompleted}, {TaskID: taskID, Status: taskModel.TaskCancelled}}}
+		_, err := svc.RequestCorrection(caregiverCtx, req)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestRequestCorrection: Nothing To Correct", func(t *testing.T) {
+		_, err := svc.RequestCorrection(caregiverCtx, model.RequestCorrectionRequest{ScheduleID: dummyID, ReasonCode: "wrong_time"})
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestRequestCorrection: Comment Required For Other", func(t *testing.T) {
+		_, err := svc.RequestCorrection(caregiverCtx, model.RequestCorrectionRequest{ScheduleID: dummyID, StartTime: &startTime, ReasonCode: "other"})
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestRequestCorrection: Visit Not Started", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusUpcoming}, nil).Times(1)
+
+		_, err := svc.RequestCorrection(caregiverCtx, model.RequestCorrectionRequest{ScheduleID: dummyID, StartTime: &startTime, ReasonCode: "forgot_to_clock_in"})
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusConflict, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestRequestCorrection: Incomplete Clock-Out", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusInProgress, StartTime: &startTime}, nil).Times(1)
+
+		_, err := svc.RequestCorrection(caregiverCtx, model.RequestCorrectionRequest{ScheduleID: dummyID, EndTime: &endTime, ReasonCode: "forgot_to_clock_out"})
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestRequestCorrection: Clock-Out Before Clock-In", func(t *testing.T) {
+		recordedEnd := time.Now().Add(-30 * time.Minute)
+		lateStart := time.Now().Add(-10 * time.Minute)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusCompleted, StartTime: &startTime, EndTime: &recordedEnd}, nil).Times(1)
+
+		_, err := svc.RequestCorrection(caregiverCtx, model.RequestCorrectionRequest{ScheduleID: dummyID, StartTime: &lateStart, ReasonCode: "wrong_time"})
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestRequestCorrection: In The Future", func(t *testing.T) {
+		future := time.Now().Add(time.Hour)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusInProgress, StartTime: &startTime}, nil).Times(1)
+
+		_, err := svc.RequestCorrection(caregiverCtx, model.RequestCorrectionRequest{ScheduleID: dummyID, StartTime: &future, ReasonCode: "wrong_time"})
+		assert.Error(t, err)
+		assert.Equal(t, exceptions.ErrUnprocessableEntity.WithDetails("Corrected times cannot be in the future").Error(), err.Error())
+	})
+
+	t.Run("TestRequestCorrection: Pending Correction Exists", func(t *testing.T) {
+		pendingID := uuid.NewString()
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusInProgress, StartTime: &startTime}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetCorrections(gomock.Any(), gomock.Any()).Return([]model.Correction{{ID: pendingID}}, nil).Times(1)
+
+		_, err := svc.RequestCorrection(caregiverCtx, model.RequestCorrectionRequest{ScheduleID: dummyID, StartTime: &startTime, ReasonCode: "wrong_time"})
+		assert.Error(t, err)
+		assert.Equal(t, exceptions.ErrConflict.WithDetails("Schedule ID "+dummyID+" already has a pending correction "+pendingID).Error(), err.Error())
+	})
+}
+
+func TestGetCorrections(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	t.Run("TestGetCorrections: Coordinator Branch", func(t *testing.T) {
+		branchID := uuid.NewString()
+		ctx := authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: uuid.NewString(), Role: authModel.RoleCoordinator, BranchID: &branchID})
+		mockScheduleRepo.EXPECT().GetCorrections(gomock.Any(), model.FilterCorrectionsRequest{Status: model.CorrectionPending, BranchID: branchID}).Return([]model.Correction{{ID: uuid.NewString()}}, nil).Times(1)
+
+		corrections, err := svc.GetCorrections(ctx, model.FilterCorrectionsRequest{Status: model.CorrectionPending})
+		assert.NoError(t, err)
+		assert.Len(t, corrections, 1)
+	})
+
+	t.Run("TestGetCorrections: Invalid Status", func(t *testing.T) {
+		_, err := svc.GetCorrections(context.Background(), model.FilterCorrectionsRequest{Status: "open"})
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, err.(*exceptions.CustomError).Code)
+	})
+}
+
+func TestResolveCorrection(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyScheduleID, dummyCorrectionID := uuid.NewString(), uuid.NewString()
+	requesterID, coordinatorID := uuid.NewString(), uuid.NewString()
+	coordinatorCtx := authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: coordinatorID, Role: authModel.RoleCoordinator})
+	shiftTime := time.Now().Add(-3 * time.Hour)
+	startTime := shiftTime.Add(10 * time.Minute)
+	clientLatitude, clientLongitude := 12.345678, 98.765432
+	note := "Confirmed with the client"
+
+	pending := func(correction model.Correction) *model.Correction {
+		correction.ID = dummyCorrectionID
+		correction.ScheduleID = dummyScheduleID
+		correction.Status = model.CorrectionPending
+		correction.RequestedBy = &requesterID
+		return &correction
+	}
+	approve := model.ResolveCorrectionRequest{ID: dummyCorrectionID, ScheduleID: dummyScheduleID, Status: model.CorrectionApproved}
+
+	t.Run("TestResolveCorrection: Approve Completes Visit", func(t *testing.T) {
+		endTime := shiftTime.Add(time.Hour)
+		farLatitude := clientLatitude + 0.01 // About 1.1 km north of the client's home
+		mockScheduleRepo.EXPECT().GetCorrectionByID(gomock.Any(), dummyCorrectionID).
+			Return(pending(model.Correction{EndTime: &endTime, EndLatitude: &farLatitude, EndLongitude: &clientLongitude}), nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).
+			Return(&model.Schedule{ID: dummyScheduleID, Status: model.StatusInProgress, ShiftTime: shiftTime, ShiftEndTime: shiftTime.Add(time.Hour), StartTime: &startTime, StartLatitude: &clientLatitude, StartLongitude: &clientLongitude, ClientLatitude: &clientLatitude, ClientLongitude: &clientLongitude, Version: 3}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyScheduleID).Return([]taskModel.Task{{ID: uuid.NewString(), Status: taskModel.TaskCompleted}}, nil).Times(1)
+		mockScheduleRepo.EXPECT().ApplyCorrection(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, correction model.Correction, visit model.CorrectedVisit) error {
+				assert.Equal(t, model.CorrectionApproved, correction.Status)
+				assert.Equal(t, coordinatorID, *correction.ResolvedBy)
+				assert.NotNil(t, correction.ResolvedAt)
+				assert.Equal(t, 4, visit.Version)
+				assert.Equal(t, startTime, visit.Start.Time)
+				if assert.NotNil(t, visit.End) {
+					assert.Equal(t, endTime, visit.End.Time)
+					assert.Equal(t, 50, visit.End.Minutes)
+					assert.Equal(t, -10, visit.End.Variance)
+					assert.True(t, visit.End.OutOfGeofence)
+				}
+				if assert.NotNil(t, visit.Transition) {
+					assert.Equal(t, model.StatusInProgress, visit.Transition.FromStatus)
+					assert.Equal(t, model.StatusCompleted, visit.Transition.ToStatus)
+					assert.Equal(t, 4, visit.Transition.Version)
+				}
+				assert.Empty(t, visit.Tasks)
+				return nil
+			}).Times(1)
+		mockScheduleRepo.EXPECT().GetCorrectionByID(gomock.Any(), dummyCorrectionID).Return(&model.Correction{ID: dummyCorrectionID, Status: model.CorrectionApproved}, nil).Times(1)
+
+		correction, err := svc.ResolveCorrection(coordinatorCtx, approve)
+		assert.NoError(t, err)
+		assert.Equal(t, model.CorrectionApproved, correction.Status)
+	})
+
+	t.Run("TestResolveCorrection: Approve Completed Visit", func(t *testing.T) {
+		endTime := shiftTime.Add(70 * time.Minute)
+		correctedStart := shiftTime
+		mockScheduleRepo.EXPECT().GetCorrectionByID(gomock.Any(), dummyCorrectionID).
+			Return(pending(model.Correction{StartTime: &correctedStart}), nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).
+			Return(&model.Schedule{ID: dummyScheduleID, Status: model.StatusCompleted, ShiftTime: shiftTime, ShiftEndTime: shiftTime.Add(time.Hour), StartTime: &startTime, EndTime: &endTime, Version: 5}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyScheduleID).Return(nil, nil).Times(1)
+		mockScheduleRepo.EXPECT().ApplyCorrection(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ model.Correction, visit model.CorrectedVisit) error {
+				assert.Equal(t, 6, visit.Version)
+				assert.Equal(t, correctedStart, visit.Start.Time)
+				assert.Equal(t, 70, visit.End.Minutes)
+				assert.Equal(t, 10, visit.End.Variance)
+				assert.Nil(t, visit.Transition)
+				return nil
+			}).Times(1)
+		mockScheduleRepo.EXPECT().GetCorrectionByID(gomock.Any(), dummyCorrectionID).Return(&model.Correction{ID: dummyCorrectionID, Status: model.CorrectionApproved}, nil).Times(1)
+
+		_, err := svc.ResolveCorrection(coordinatorCtx, approve)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestResolveCorrection: Approve Task Outcomes", func(t *testing.T) {
+		endTime := shiftTime.Add(time.Hour)
+		taskID, reason := uuid.NewString(), "Client declined the walk"
+		mockScheduleRepo.EXPECT().GetCorrectionByID(gomock.Any(), dummyCorrectionID).
+			Return(pending(model.Correction{Tasks: []model.CorrectionTask{{TaskID: taskID, Status: taskModel.TaskNotCompleted, Reason: &reason}}}), nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).
+			Return(&model.Schedule{ID: dummyScheduleID, Status: model.StatusCompleted, ShiftTime: shiftTime, ShiftEndTime: shiftTime.Add(time.Hour), StartTime: &startTime, EndTime: &endTime, Version: 5}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyScheduleID).
+			Return([]taskModel.Task{{ID: taskID, ScheduleID: dummyScheduleID, Status: taskModel.TaskCompleted, Version: 2}, {ID: uuid.NewString(), Status: taskModel.TaskCompleted}}, nil).Times(1)
+		mockScheduleRepo.EXPECT().ApplyCorrection(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ model.Correction, visit model.CorrectedVisit) error {
+				if assert.Len(t, visit.Tasks, 1) {
+					assert.Equal(t, taskID, visit.Tasks[0].ID)
+					assert.Equal(t, taskModel.TaskNotCompleted, visit.Tasks[0].Status)
+					assert.Equal(t, &reason, visit.Tasks[0].Reason)
+					assert.Equal(t, 2, visit.Tasks[0].Version)
+				}
+				return nil
+			}).Times(1)
+		mockScheduleRepo.EXPECT().GetCorrectionByID(gomock.Any(), dummyCorrectionID).Return(&model.Correction{ID: dummyCorrectionID, Status: model.CorrectionApproved}, nil).Times(1)
+
+		_, err := svc.ResolveCorrection(coordinatorCtx, approve)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestResolveCorrection: Task Removed Since Request", func(t *testing.T) {
+		endTime := shiftTime.Add(time.Hour)
+		taskID := uuid.NewString()
+		mockScheduleRepo.EXPECT().GetCorrectionByID(gomock.Any(), dummyCorrectionID).
+			Return(pending(model.Correction{Tasks: []model.CorrectionTask{{TaskID: taskID, Status: taskModel.TaskCompleted}}}), nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).
+			Return(&model.Schedule{ID: dummyScheduleID, Status: model.StatusCompleted, StartTime: &startTime, EndTime: &endTime, Version: 5}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyScheduleID).Return([]taskModel.Task{}, nil).Times(1)
+
+		_, err := svc.ResolveCorrection(coordinatorCtx, approve)
+		assert.Error(t, err)
+		assert.Equal(t, exceptions.ErrConflict.WithDetails("Task ID "+taskID+" no longer belongs to schedule ID "+dummyScheduleID).Error(), err.Error())
+	})
+
+	t.Run("TestResolveCorrection: Clock-Out With Unresolved Tasks", func(t *testing.T) {
+		endTime := shiftTime.Add(time.Hour)
+		taskID := uuid.NewString()
+		mockScheduleRepo.EXPECT().GetCorrectionByID(gomock.Any(), dummyCorrectionID).
+			Return(pending(model.Correction{EndTime: &endTime, EndLatitude: &clientLatitude, EndLongitude: &clientLongitude}), nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).
+			Return(&model.Schedule{ID: dummyScheduleID, Status: model.StatusInProgress, ShiftTime: shiftTime, ShiftEndTime: shiftTime.Add(time.Hour), StartTime: &startTime, ClientLatitude: &clientLatitude, ClientLongitude: &clientLongitude, Version: 3}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyScheduleID).Return([]taskModel.Task{{ID: taskID, Status: taskModel.TaskPending}}, nil).Times(1)
+
+		_, err := svc.ResolveCorrection(coordinatorCtx, approve)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusConflict, err.(*exceptions.CustomError).Code)
+		assert.Equal(t, model.UnresolvedTasks{TaskIDs: []string{taskID}}, err.(*exceptions.CustomError).Data)
+	})
+
+	t.Run("TestResolveCorrection: Reject", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetCorrectionByID(gomock.Any(), dummyCorrectionID).Return(pending(model.Correction{StartTime: &startTime}), nil).Times(1)
+		mockScheduleRepo.EXPECT().RejectCorrection(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, correction model.Correction) error {
+				assert.Equal(t, model.CorrectionRejected, correction.Status)
+				assert.Equal(t, note, *correction.ResolutionNote)
+				return nil
+			}).Times(1)
+		mockScheduleRepo.EXPECT().GetCorrectionByID(gomock.Any(), dummyCorrectionID).Return(&model.Correction{ID: dummyCorrectionID, Status: model.CorrectionRejected}, nil).Times(1)
+
+		req := model.ResolveCorrectionRequest{ID: dummyCorrectionID, ScheduleID: dummyScheduleID, Status: model.CorrectionRejected, Note: &note}
+		_, err := svc.ResolveCorrection(coordinatorCtx, req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestResolveCorrection: Reject Without Note", func(t *testing.T) {
+		req := model.ResolveCorrectionRequest{ID: dummyCorrectionID, ScheduleID: dummyScheduleID, Status: model.CorrectionRejected}
+		_, err := svc.ResolveCorrection(coordinatorCtx, req)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestResolveCorrection: Approved By Requester", func(t *testing.T) {
+		requesterCtx := authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: requesterID, Role: authModel.RoleCoordinator})
+		mockScheduleRepo.EXPECT().GetCorrectionByID(gomock.Any(), dummyCorrectionID).Return(pending(model.Correction{StartTime: &startTime}), nil).Times(1)
+
+		_, err := svc.ResolveCorrection(requesterCtx, approve)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestResolveCorrection: Other Schedule", func(t *testing.T) {
+		correction := pending(model.Correction{StartTime: &startTime})
+		correction.ScheduleID = uuid.NewString()
+		mockScheduleRepo.EXPECT().GetCorrectionByID(gomock.Any(), dummyCorrectionID).Return(correction, nil).Times(1)
+
+		_, err := svc.ResolveCorrection(coordinatorCtx, approve)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestResolveCorrection: Already Resolved", func(t *testing.T) {
+		correction := pending(model.Correction{StartTime: &startTime})
+		correction.Status = model.CorrectionRejected
+		mockScheduleRepo.EXPECT().GetCorrectionByID(gomock.Any(), dummyCorrectionID).Return(correction, nil).Times(1)
+
+		_, err := svc.ResolveCorrection(coordinatorCtx, approve)
+		assert.Error(t, err)
+		assert.Equal(t, exceptions.ErrConflict.WithDetails("Correction "+dummyCorrectionID+" is already rejected").Error(), err.Error())
+	})
+
+	t.Run("TestResolveCorrection: Visit Changed Since Request", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetCorrectionByID(gomock.Any(), dummyCorrectionID).Return(pending(model.Correction{StartTime: &startTime}), nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(&model.Schedule{ID: dummyScheduleID, Status: model.StatusCancelled, StartTime: &startTime}, nil).Times(1)
+
+		_, err := svc.ResolveCorrection(coordinatorCtx, approve)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusConflict, err.(*exceptions.CustomError).Code)
+	})
+}
@@ -3,11 +3,25 @@ package service
 import (
 	"context" // Import context
 	"fmt"
+	"math"
 	"mini-evv-logger-backend/exceptions"
+	auditModel "mini-evv-logger-backend/src/domains/audit/model"
+	auditRepo "mini-evv-logger-backend/src/domains/audit/repository"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	caregiverRepo "mini-evv-logger-backend/src/domains/caregiver/repository"
+	carePlanRepo "mini-evv-logger-backend/src/domains/careplan/repository"
+	clientRepo "mini-evv-logger-backend/src/domains/client/repository"
 	"mini-evv-logger-backend/src/domains/schedule/model"
 	"mini-evv-logger-backend/src/domains/schedule/repository"
+	taskModel "mini-evv-logger-backend/src/domains/task/model"
 	taskRepo "mini-evv-logger-backend/src/domains/task/repository"
+	exceptionModel "mini-evv-logger-backend/src/domains/visitexception/model"
+	exceptionRepo "mini-evv-logger-backend/src/domains/visitexception/repository"
+	visitNoteRepo "mini-evv-logger-backend/src/domains/visitnote/repository"
+	"mini-evv-logger-backend/utils"
+	"net/http"
 	"time"
+	_ "time/tzdata" // Care plan weekdays are read in the client's timezone, also on hosts without zoneinfo
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
@@ -17,19 +31,83 @@ import (
 type ScheduleService interface {
 	GetAllSchedules(ctx context.Context, filter model.FilterSchedulesRequest) (*model.PaginatedSchedulesResponse, error)
 	GetScheduleByID(ctx context.Context, id string) (*model.Schedule, error)
+	GetScheduleOwnership(ctx context.Context, id string) (*model.ScheduleOwnership, error)
+	GetSignature(ctx context.Context, id string) ([]byte, error)
 	StartVisit(ctx context.Context, req model.StartVisitRequest) error
 	EndVisit(ctx context.Context, req model.EndVisitRequest) error
+	CreateSchedule(ctx context.Context, req model.CreateScheduleRequest) (*model.Schedule, error)
+	UpdateSchedule(ctx context.Context, req model.UpdateScheduleRequest) (*model.Schedule, error)
+	CancelSchedule(ctx context.Context, req model.CancelScheduleRequest) (*model.Schedule, error)
+	ReportNoShow(ctx context.Context, req model.ReportNoShowRequest) (*model.Schedule, error)
+	ReopenSchedule(ctx context.Context, req model.ReopenScheduleRequest) (*model.Schedule, error)
+	GetStatusHistory(ctx context.Context, id string) ([]model.StatusTransition, error)
+	GetHistory(ctx context.Context, id string) ([]auditModel.Event, error)
+	RequestCorrection(ctx context.Context, req model.RequestCorrectionRequest) (*model.Correction, error)
+	GetCorrections(ctx context.Context, filter model.FilterCorrectionsRequest) ([]model.Correction, error)
+	ResolveCorrection(ctx context.Context, req model.ResolveCorrectionRequest) (*model.Correction, error)
+	MarkOverdueSchedulesMissed(ctx context.Context) (int, error)
+	RaiseMissingClockOuts(ctx context.Context) (int64, error)
+}
+
+// GeofencePolicy decides what happens to a visit logged outside the client's geofence
+type GeofencePolicy string
+
+const (
+	GeofencePolicyFlag   GeofencePolicy = "flag"   // Record the visit and mark it as out of geofence
+	GeofencePolicyReject GeofencePolicy = "reject" // Refuse to record the visit
+)
+
+// UnresolvedTaskPolicy decides what happens to a clock-out while tasks of the visit are still open
+type UnresolvedTaskPolicy string
+
+const (
+	UnresolvedTaskPolicyReject           UnresolvedTaskPolicy = "reject"             // Refuse the clock-out and list the open tasks
+	UnresolvedTaskPolicyMarkNotCompleted UnresolvedTaskPolicy = "mark_not_completed" // Clock out and mark the open tasks as not completed
+)
+
+// visitEndedReason is stored on tasks marked as not completed by a clock-out
+const visitEndedReason = "visit ended"
+
+// Settings holds the configurable rules applied by the schedule service
+type Settings struct {
+	GeofenceRadiusMeters float64
+	GeofencePolicy       GeofencePolicy
+	LateClockInGrace     time.Duration // Clock-ins later than shift_time plus this raise an exception
+	MissingClockOutAfter time.Duration // Visits in progress for longer than this raise an exception
+	MissedVisitGrace     time.Duration // Upcoming visits not started this long after shift_time are marked missed
+	ClockSkewTolerance   time.Duration // Clock-ins and clock-outs whose device time differs more from the server time are flagged
+	OfflineEventMaxAge   time.Duration // Offline clock-ins and clock-outs synced longer than this after their device time are flagged, zero for no limit
+	UnresolvedTaskPolicy UnresolvedTaskPolicy
 }
 
 // scheduleServiceImpl implements the ScheduleService interface
 type scheduleServiceImpl struct {
-	scheduleRepo repository.ScheduleRepository
-	taskRepo     taskRepo.TaskRepository
+	scheduleRepo  repository.ScheduleRepository
+	taskRepo      taskRepo.TaskRepository
+	exceptionRepo exceptionRepo.VisitExceptionRepository
+	caregiverRepo caregiverRepo.CaregiverRepository
+	clientRepo    clientRepo.ClientRepository
+	carePlanRepo  carePlanRepo.CarePlanRepository
+	auditRepo     auditRepo.AuditRepository
+	noteRepo      visitNoteRepo.VisitNoteRepository
+	settings      Settings
 }
 
 // NewScheduleService creates a new ScheduleService (returns interface)
-func NewScheduleService(scheduleRepo repository.ScheduleRepository, taskRepo taskRepo.TaskRepository) ScheduleService {
-	return &scheduleServiceImpl{scheduleRepo: scheduleRepo, taskRepo: taskRepo}
+func NewScheduleService(scheduleRepo repository.ScheduleRepository, taskRepo taskRepo.TaskRepository, exceptionRepo exceptionRepo.VisitExceptionRepository,
+	caregiverRepo caregiverRepo.CaregiverRepository, clientRepo clientRepo.ClientRepository, carePlanRepo carePlanRepo.CarePlanRepository,
+	auditRepo auditRepo.AuditRepository, noteRepo visitNoteRepo.VisitNoteRepository, settings Settings) ScheduleService {
+	return &scheduleServiceImpl{
+		scheduleRepo:  scheduleRepo,
+		taskRepo:      taskRepo,
+		exceptionRepo: exceptionRepo,
+		caregiverRepo: caregiverRepo,
+		clientRepo:    clientRepo,
+		carePlanRepo:  carePlanRepo,
+		auditRepo:     auditRepo,
+		noteRepo:      noteRepo,
+		settings:      settings,
+	}
 }
 
 // GetAllSchedules fetches all schedules with pagination
@@ -42,6 +120,22 @@ func (s *scheduleServiceImpl) GetAllSchedules(ctx context.Context, filter model.
 		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
 	}
 
+	// Restrict the listing to what the principal is allowed to see
+	if principal, ok := authModel.PrincipalFromContext(ctx); ok {
+		switch principal.Role {
+		case authModel.RoleCaregiver:
+			if principal.CaregiverID == nil {
+				return nil, exceptions.ErrForbidden.WithDetails("User is not linked to a caregiver")
+			}
+			filter.CaregiverID = *principal.CaregiverID
+		case authModel.RoleCoordinator:
+			if principal.BranchID == nil {
+				return nil, exceptions.ErrForbidden.WithDetails("Coordinator is not assigned to a branch")
+			}
+			filter.BranchID = *principal.BranchID
+		}
+	}
+
 	filter.SetOffset()
 
 	schedules, total, err := s.scheduleRepo.GetSchedules(ctx, filter)
@@ -61,7 +155,7 @@ func (s *scheduleServiceImpl) GetAllSchedules(ctx context.Context, filter model.
 	return &res, nil
 }
 
-// GetScheduleByID fetches a schedule by its ID, including its associated tasks
+// GetScheduleByID fetches a schedule by its ID, including its associated tasks and the attestation given at clock-out
 func (s *scheduleServiceImpl) GetScheduleByID(ctx context.Context, id string) (*model.Schedule, error) {
 	log.Info().Str("schedule_id", id).Msg("Fetching schedule by ID")
 
@@ -82,12 +176,65 @@ func (s *scheduleServiceImpl) GetScheduleByID(ctx context.Context, id string) (*
 		return schedule, nil
 	}
 	schedule.Tasks = tasks
+
+	notes, err := s.noteRepo.GetNotesByScheduleID(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", id).Msg("Failed to fetch visit notes for schedule")
+		return schedule, nil
+	}
+	schedule.Notes = notes
+
+	if schedule.EndTime != nil {
+		attestation, err := s.scheduleRepo.GetAttestation(ctx, id)
+		if err != nil {
+			if customErr, ok := err.(*exceptions.CustomError); !ok || customErr.Code != http.StatusNotFound {
+				log.Error().Err(err).Str("schedule_id", id).Msg("Failed to fetch attestation for schedule")
+			}
+			return schedule, nil
+		}
+		schedule.Attestation = attestation
+	}
 	return schedule, nil
 }
 
+// GetSignature fetches the signature image given at the clock-out of a schedule, as PNG
+func (s *scheduleServiceImpl) GetSignature(ctx context.Context, id string) ([]byte, error) {
+	log.Info().Str("schedule_id", id).Msg("Fetching signature of schedule")
+
+	_, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", id).Msg("Invalid UUID format for schedule ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid schedule ID format")
+	}
+
+	signature, err := s.scheduleRepo.GetSignature(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", id).Msg("Failed to fetch signature from repository")
+		return nil, err
+	}
+	return signature, nil
+}
+
+// GetScheduleOwnership fetches who a schedule belongs to, for authorization checks
+func (s *scheduleServiceImpl) GetScheduleOwnership(ctx context.Context, id string) (*model.ScheduleOwnership, error) {
+	_, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", id).Msg("Invalid UUID format for schedule ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid schedule ID format")
+	}
+
+	ownership, err := s.scheduleRepo.GetScheduleOwnership(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", id).Msg("Failed to fetch schedule ownership from repository")
+		return nil, err
+	}
+	return ownership, nil
+}
+
 // StartVisit updates the schedule with start time and geolocation
 func (s *scheduleServiceImpl) StartVisit(ctx context.Context, req model.StartVisitRequest) error {
-	log.Info().Str("schedule_id", req.ID).Float64("latitude", req.Latitude).Float64("longitude", req.Longitude).Msg("Attempting to start visit")
+	receivedAt := time.Now()
+	log.Info().Str("schedule_id", req.ID).Str("user_id", authModel.ActorID(ctx)).Float64("latitude", req.Latitude).Float64("longitude", req.Longitude).Msg("Attempting to start visit")
 
 	err := req.Validate()
 	if err != nil {
@@ -102,23 +249,53 @@ func (s *scheduleServiceImpl) StartVisit(ctx context.Context, req model.StartVis
 		return err
 	}
 
-	// 2. Apply business logic: only "upcoming" schedules can be started
-	if schedule.Status != "upcoming" {
-		return exceptions.ErrConflict.WithDetails(fmt.Sprintf("Visit for schedule ID %s is already %s. Cannot start.", req.ID, schedule.Status))
+	// 2. Apply business logic: the client's copy must be current and the visit allowed to move to "in-progress"
+	err = checkIfMatch(schedule, req.IfMatch)
+	if err != nil {
+		return err
+	}
+	transition, err := newTransition(ctx, schedule, model.StatusInProgress, nil)
+	if err != nil {
+		return err
 	}
+	transition.ChangedAt = capturedAt(req.CapturedAt, receivedAt)
 
-	// 3. Perform the update via repository
-	err = s.scheduleRepo.LogVisitStart(ctx, req.ID, time.Now(), req.Latitude, req.Longitude)
+	// 3. Verify the location against the client's home and the device clock against the server's
+	event, err := s.checkGeofence(schedule, transition.ChangedAt, req.Latitude, req.Longitude)
+	if err != nil {
+		return err
+	}
+	s.checkClockSkew(schedule, &event, receivedAt, req.GPSFixAt, req.Offline)
+
+	// 4. Perform the update via repository
+	err = s.scheduleRepo.LogVisitStart(ctx, transition, event)
 	if err != nil {
 		log.Error().Err(err).Str("schedule_id", req.ID).Msg("Failed to log visit start in repository")
 		return err
 	}
+
+	// 5. Queue any compliance issues for review. A flagged device time cannot be trusted,
+	// so lateness is then measured from when the clock-in reached the server
+	clockedInAt := event.Time
+	if event.ClockSkewed {
+		clockedInAt = event.ReceivedAt
+	}
+	if late := clockedInAt.Sub(schedule.ShiftTime); late > s.settings.LateClockInGrace {
+		s.raiseException(ctx, req.ID, exceptionModel.TypeLateClockIn, fmt.Sprintf("Clocked in %d minutes late", int(late.Minutes())))
+	}
+	if event.OutOfGeofence {
+		s.raiseException(ctx, req.ID, exceptionModel.TypeClockInOutOfGeofence, fmt.Sprintf("Clocked in %.0f m from the client's home", *event.DistanceMeters))
+	}
+	if event.ClockSkewed {
+		s.raiseException(ctx, req.ID, exceptionModel.TypeClockInClockSkew, "Device clock "+describeSkew(event)+" at clock-in")
+	}
 	return nil
 }
 
-// EndVisit updates the schedule with end time and geolocation
+// EndVisit updates the schedule with end time and geolocation, and stores the client's attestation if one was given
 func (s *scheduleServiceImpl) EndVisit(ctx context.Context, req model.EndVisitRequest) error {
-	log.Info().Str("schedule_id", req.ID).Float64("latitude", req.Latitude).Float64("longitude", req.Longitude).Msg("Attempting to end visit")
+	receivedAt := time.Now()
+	log.Info().Str("schedule_id", req.ID).Str("user_id", authModel.ActorID(ctx)).Float64("latitude", req.Latitude).Float64("longitude", req.Longitude).Msg("Attempting to end visit")
 
 	err := req.Validate()
 	if err != nil {
@@ -133,36 +310,573 @@ func (s *scheduleServiceImpl) EndVisit(ctx context.Context, req model.EndVisitRe
 		return err
 	}
 
-	// 2. Apply business logic: only "in-progress" schedules can be ended
-	if schedule.Status != "in-progress" {
-		return exceptions.ErrConflict.WithDetails(fmt.Sprintf("Visit for schedule ID %s is currently %s. Cannot end.", req.ID, schedule.Status))
+	// 2. Apply business logic: the client's copy must be current and the visit allowed to move to "completed"
+	err = checkIfMatch(schedule, req.IfMatch)
+	if err != nil {
+		return err
+	}
+	transition, err := newTransition(ctx, schedule, model.StatusCompleted, nil)
+	if err != nil {
+		return err
+	}
+	transition.ChangedAt = capturedAt(req.CapturedAt, receivedAt)
+	if schedule.StartTime != nil && transition.ChangedAt.Before(*schedule.StartTime) {
+		return exceptions.ErrUnprocessableEntity.WithDetails(fmt.Sprintf("Schedule ID %s cannot be clocked out before it was clocked in at %s", req.ID, schedule.StartTime.Format(time.RFC3339)))
+	}
+	attestation, err := newAttestation(req.ID, transition.ChangedAt, req.Attestation)
+	if err != nil {
+		return err
 	}
 
-	// 3. Perform the update via repository
-	err = s.scheduleRepo.LogVisitEnd(ctx, req.ID, time.Now(), req.Latitude, req.Longitude)
+	// 3. Every task must be completed or explained, unless the agency lets clock-out close the rest
+	tasks, err := s.taskRepo.GetTasksByScheduleID(ctx, req.ID)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", req.ID).Msg("Failed to retrieve tasks before ending visit")
+		return err
+	}
+	closedTasks, err := s.closeUnresolvedTasks(req.ID, tasks)
+	if err != nil {
+		return err
+	}
+
+	// 4. Verify the location against the client's home and the device clock against the server's
+	event, err := s.checkGeofence(schedule, transition.ChangedAt, req.Latitude, req.Longitude)
+	if err != nil {
+		return err
+	}
+	s.checkClockSkew(schedule, &event, receivedAt, req.GPSFixAt, req.Offline)
+	event.Attestation = attestation
+
+	// 5. Compare the length of the visit with the plan, for payroll and billing
+	if schedule.StartTime != nil {
+		event.Minutes = int(math.Round(event.Time.Sub(*schedule.StartTime).Minutes()))
+	}
+	event.Variance = event.Minutes - int(math.Round(schedule.ShiftEndTime.Sub(schedule.ShiftTime).Minutes()))
+
+	// 6. Perform the update via repository
+	err = s.scheduleRepo.LogVisitEnd(ctx, transition, event, closedTasks)
 	if err != nil {
 		log.Error().Err(err).Str("schedule_id", req.ID).Msg("Failed to log visit end in repository")
 		return err
 	}
+
+	// 7. Queue any compliance issues for review
+	if event.OutOfGeofence {
+		s.raiseException(ctx, req.ID, exceptionModel.TypeClockOutOutOfGeofence, fmt.Sprintf("Clocked out %.0f m from the client's home", *event.DistanceMeters))
+	}
+	if event.ClockSkewed {
+		s.raiseException(ctx, req.ID, exceptionModel.TypeClockOutClockSkew, "Device clock "+describeSkew(event)+" at clock-out")
+	}
 	return nil
 }
 
-// UpdateScheduleStatus handles updating the status of a schedule.
-func (s *scheduleServiceImpl) UpdateScheduleStatus(ctx context.Context, id, status string) error {
-	log.Info().Str("schedule_id", id).Str("status", status).Msg("Attempting to update schedule status")
+// closeUnresolvedTasks checks the tasks of a visit before clock-out. Tasks that are neither completed nor
+// not completed with a reason yield a conflict listing their IDs, or with UnresolvedTaskPolicyMarkNotCompleted
+// are returned marked as not completed, to be written together with the clock-out.
+func (s *scheduleServiceImpl) closeUnresolvedTasks(scheduleID string, tasks []taskModel.Task) ([]taskModel.Task, error) {
+	var unresolved []taskModel.Task
+	for _, task := range tasks {
+		if !task.IsResolved() {
+			unresolved = append(unresolved, task)
+		}
+	}
+	if len(unresolved) == 0 {
+		return nil, nil
+	}
+
+	if s.settings.UnresolvedTaskPolicy != UnresolvedTaskPolicyMarkNotCompleted {
+		ids := make([]string, len(unresolved))
+		for i, task := range unresolved {
+			ids[i] = task.ID
+		}
+		log.Warn().Str("schedule_id", scheduleID).Strs("task_ids", ids).Msg("Rejected clock-out with unresolved tasks")
+		return nil, exceptions.ErrConflict.
+			WithDetails(fmt.Sprintf("Schedule ID %s has %d unresolved task(s); complete them or mark them as not completed with a reason before clocking out", scheduleID, len(ids))).
+			WithData(model.UnresolvedTasks{TaskIDs: ids})
+	}
+
+	reason := visitEndedReason
+	for i := range unresolved {
+		unresolved[i].Status = taskModel.TaskNotCompleted
+		unresolved[i].Reason = &reason
+	}
+	log.Info().Str("schedule_id", scheduleID).Int("tasks", len(unresolved)).Msg("Marking unresolved tasks as not completed at clock-out")
+	return unresolved, nil
+}
+
+// CreateSchedule creates a new upcoming schedule for a client
+func (s *scheduleServiceImpl) CreateSchedule(ctx context.Context, req model.CreateScheduleRequest) (*model.Schedule, error) {
+	log.Info().Str("client_id", req.ClientID).Str("user_id", authModel.ActorID(ctx)).Time("shift_time", req.ShiftTime).Msg("Attempting to create schedule")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for CreateScheduleRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
 
-	// 1. Check if the schedule exists
-	_, err := s.scheduleRepo.GetScheduleByID(ctx, id)
+	// 1. Check that the client exists and the caregiver may be assigned
+	client, err := s.clientRepo.GetClientByID(ctx, req.ClientID)
+	if err != nil {
+		log.Error().Err(err).Str("client_id", req.ClientID).Msg("Failed to retrieve client before creating schedule")
+		return nil, err
+	}
+	err = s.checkAssignment(ctx, req.CaregiverID)
 	if err != nil {
-		log.Error().Err(err).Str("schedule_id", id).Msg("Schedule not found for status update")
+		return nil, err
+	}
+
+	schedule := model.Schedule{
+		ID:           uuid.NewString(),
+		CaregiverID:  req.CaregiverID,
+		ClientID:     req.ClientID,
+		ShiftTime:    req.ShiftTime,
+		ShiftEndTime: req.ShiftTime.Add(time.Duration(req.DurationMinutes) * time.Minute),
+		Status:       model.StatusUpcoming,
+	}
+
+	// 2. Fill the tasks from the client's active care plan
+	err = s.fillCarePlanTasks(ctx, &schedule, client.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Perform the insert via repository
+	err = s.scheduleRepo.CreateSchedule(ctx, schedule)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", schedule.ID).Msg("Failed to create schedule in repository")
+		return nil, err
+	}
+
+	return s.scheduleRepo.GetScheduleByID(ctx, schedule.ID)
+}
+
+// fillCarePlanTasks sets the tasks of a new schedule from the client's active care plan and records
+// the plan version they came from. Clients without a care plan get a schedule without tasks.
+func (s *scheduleServiceImpl) fillCarePlanTasks(ctx context.Context, schedule *model.Schedule, timezone string) error {
+	plan, err := s.carePlanRepo.GetActiveCarePlan(ctx, schedule.ClientID)
+	if err != nil {
+		if customErr, ok := err.(*exceptions.CustomError); ok && customErr.Code == http.StatusNotFound {
+			return nil
+		}
+		log.Error().Err(err).Str("client_id", schedule.ClientID).Msg("Failed to retrieve care plan before creating schedule")
 		return err
 	}
 
-	// 2. Perform the update
-	err = s.scheduleRepo.UpdateScheduleStatus(ctx, id, status)
+	// Weekly tasks follow the weekday of the visit in the client's timezone
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		log.Error().Err(err).Str("client_id", schedule.ClientID).Str("timezone", timezone).Msg("Invalid client timezone")
+		return exceptions.ErrInternalError
+	}
+
+	schedule.CarePlanID = &plan.ID
+	for _, description := range plan.TasksFor(schedule.ShiftTime.In(loc)) {
+		schedule.Tasks = append(schedule.Tasks, taskModel.Task{ScheduleID: schedule.ID, Description: description, Status: "pending"})
+	}
+	return nil
+}
+
+// UpdateSchedule edits the client, caregiver, shift time or duration of an upcoming schedule
+func (s *scheduleServiceImpl) UpdateSchedule(ctx context.Context, req model.UpdateScheduleRequest) (*model.Schedule, error) {
+	log.Info().Str("schedule_id", req.ID).Str("user_id", authModel.ActorID(ctx)).Msg("Attempting to update schedule")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for UpdateScheduleRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	// 1. Check if the schedule exists and its current status
+	schedule, err := s.scheduleRepo.GetScheduleByID(ctx, req.ID)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", req.ID).Msg("Failed to retrieve schedule before update")
+		return nil, err
+	}
+
+	// 2. Apply business logic: only "upcoming" schedules can be edited, from a current copy
+	if schedule.Status != model.StatusUpcoming {
+		return nil, exceptions.ErrConflict.WithDetails(fmt.Sprintf("Schedule ID %s is %s. Cannot edit.", req.ID, schedule.Status))
+	}
+	err = checkIfMatch(schedule, req.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	req.Version = schedule.Version
+
+	// 3. Check that the new client exists and the new caregiver may be assigned
+	if req.ClientID != nil {
+		_, err = s.clientRepo.GetClientByID(ctx, *req.ClientID)
+		if err != nil {
+			log.Error().Err(err).Str("client_id", *req.ClientID).Msg("Failed to retrieve client before updating schedule")
+			return nil, err
+		}
+	}
+	if req.CaregiverID != nil {
+		err = s.checkAssignment(ctx, req.CaregiverID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 4. Move the planned end with the shift, keeping the duration unless a new one is given
+	if req.ShiftTime != nil || req.DurationMinutes != nil {
+		start, duration := schedule.ShiftTime, schedule.ShiftEndTime.Sub(schedule.ShiftTime)
+		if req.ShiftTime != nil {
+			start = *req.ShiftTime
+		}
+		if req.DurationMinutes != nil {
+			duration = time.Duration(*req.DurationMinutes) * time.Minute
+		}
+		end := start.Add(duration)
+		req.ShiftEndTime = &end
+	}
+
+	// 5. Perform the update via repository
+	err = s.scheduleRepo.UpdateSchedule(ctx, req)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", req.ID).Msg("Failed to update schedule in repository")
+		return nil, err
+	}
+
+	return s.scheduleRepo.GetScheduleByID(ctx, req.ID)
+}
+
+// CancelSchedule cancels an upcoming or reopened schedule and records why
+func (s *scheduleServiceImpl) CancelSchedule(ctx context.Context, req model.CancelScheduleRequest) (*model.Schedule, error) {
+	log.Info().Str("schedule_id", req.ID).Str("user_id", authModel.ActorID(ctx)).Msg("Attempting to cancel schedule")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for CancelScheduleRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	// 1. Check if the schedule exists and its current status
+	schedule, err := s.scheduleRepo.GetScheduleByID(ctx, req.ID)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", req.ID).Msg("Failed to retrieve schedule before cancelling")
+		return nil, err
+	}
+
+	// 2. Apply business logic: the client's copy must be current and the visit allowed to move to "cancelled"
+	err = checkIfMatch(schedule, req.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	transition, err := newTransition(ctx, schedule, model.StatusCancelled, &req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Perform the update via repository
+	err = s.scheduleRepo.TransitionStatus(ctx, transition)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", req.ID).Msg("Failed to cancel schedule in repository")
+		return nil, err
+	}
+
+	return s.scheduleRepo.GetScheduleByID(ctx, req.ID)
+}
+
+// ReportNoShow records that the client was not available for a visit, before or after clock-in
+func (s *scheduleServiceImpl) ReportNoShow(ctx context.Context, req model.ReportNoShowRequest) (*model.Schedule, error) {
+	log.Info().Str("schedule_id", req.ID).Str("user_id", authModel.ActorID(ctx)).Msg("Attempting to report client no-show")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for ReportNoShowRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	// 1. Check if the schedule exists and its current status
+	schedule, err := s.scheduleRepo.GetScheduleByID(ctx, req.ID)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", req.ID).Msg("Failed to retrieve schedule before reporting no-show")
+		return nil, err
+	}
+
+	// 2. Apply business logic: the client's copy must be current and the visit allowed to move to "no-show"
+	err = checkIfMatch(schedule, req.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	transition, err := newTransition(ctx, schedule, model.StatusNoShow, &req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Perform the update via repository
+	err = s.scheduleRepo.TransitionStatus(ctx, transition)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", req.ID).Msg("Failed to report no-show in repository")
+		return nil, err
+	}
+
+	return s.scheduleRepo.GetScheduleByID(ctx, req.ID)
+}
+
+// ReopenSchedule opens a completed, missed, cancelled or no-show visit again so that it can be
+// redone or corrected. Clock-in and clock-out details are kept until the visit is started again.
+func (s *scheduleServiceImpl) ReopenSchedule(ctx context.Context, req model.ReopenScheduleRequest) (*model.Schedule, error) {
+	log.Info().Str("schedule_id", req.ID).Str("user_id", authModel.ActorID(ctx)).Msg("Attempting to reopen schedule")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for ReopenScheduleRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	// 1. Check if the schedule exists and its current status
+	schedule, err := s.scheduleRepo.GetScheduleByID(ctx, req.ID)
 	if err != nil {
-		log.Error().Err(err).Str("schedule_id", id).Str("status", status).Msg("Failed to update schedule status in repository")
+		log.Error().Err(err).Str("schedule_id", req.ID).Msg("Failed to retrieve schedule before reopening")
+		return nil, err
+	}
+
+	// 2. Apply business logic: the client's copy must be current and the visit allowed to move to "reopened"
+	err = checkIfMatch(schedule, req.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	transition, err := newTransition(ctx, schedule, model.StatusReopened, &req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Perform the update via repository
+	err = s.scheduleRepo.TransitionStatus(ctx, transition)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", req.ID).Msg("Failed to reopen schedule in repository")
+		return nil, err
+	}
+
+	return s.scheduleRepo.GetScheduleByID(ctx, req.ID)
+}
+
+// GetStatusHistory fetches every status change of a schedule, oldest first
+func (s *scheduleServiceImpl) GetStatusHistory(ctx context.Context, id string) ([]model.StatusTransition, error) {
+	log.Info().Str("schedule_id", id).Msg("Fetching schedule status history")
+
+	_, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", id).Msg("Invalid UUID format for schedule ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid schedule ID format")
+	}
+
+	// 1. Check that the schedule exists, so that an unknown ID is not reported as an empty history
+	_, err = s.scheduleRepo.GetScheduleByID(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", id).Msg("Failed to retrieve schedule before fetching status history")
+		return nil, err
+	}
+
+	history, err := s.scheduleRepo.GetStatusHistory(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", id).Msg("Failed to fetch status history from repository")
+		return nil, err
+	}
+	return history, nil
+}
+
+// GetHistory fetches the audit trail of a schedule and its tasks, oldest first
+func (s *scheduleServiceImpl) GetHistory(ctx context.Context, id string) ([]auditModel.Event, error) {
+	log.Info().Str("schedule_id", id).Str("user_id", authModel.ActorID(ctx)).Msg("Fetching schedule audit history")
+
+	_, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", id).Msg("Invalid UUID format for schedule ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid schedule ID format")
+	}
+
+	// 1. Check that the schedule exists, so that an unknown ID is not reported as an empty history
+	_, err = s.scheduleRepo.GetScheduleByID(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", id).Msg("Failed to retrieve schedule before fetching audit history")
+		return nil, err
+	}
+
+	history, err := s.auditRepo.GetScheduleHistory(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", id).Msg("Failed to fetch audit history from repository")
+		return nil, err
+	}
+	return history, nil
+}
+
+// checkIfMatch refuses a change made from an outdated copy of the schedule.
+// A nil ifMatch means the client did not send If-Match and skips the check.
+func checkIfMatch(schedule *model.Schedule, ifMatch *int) error {
+	if ifMatch != nil && *ifMatch != schedule.Version {
+		return exceptions.ErrPreconditionFailed.WithDetails(fmt.Sprintf("Schedule ID %s is at version %d, not %d", schedule.ID, schedule.Version, *ifMatch))
+	}
+	return nil
+}
+
+// newTransition checks a status change of schedule against the state machine and describes it
+// for the repository, which applies it only while the schedule is unchanged since it was read.
+// The change is attributed to the principal in ctx, or to no one for the sweeper.
+func newTransition(ctx context.Context, schedule *model.Schedule, to model.Status, reason *string) (model.StatusTransition, error) {
+	if !model.CanTransition(schedule.Status, to) {
+		return model.StatusTransition{}, exceptions.ErrConflict.
+			WithDetails(fmt.Sprintf("Schedule ID %s cannot move from %s to %s", schedule.ID, schedule.Status, to)).
+			WithData(model.TransitionConflict{CurrentStatus: schedule.Status, RequestedStatus: to})
+	}
+
+	transition := model.StatusTransition{
+		ID:         uuid.NewString(),
+		ScheduleID: schedule.ID,
+		FromStatus: schedule.Status,
+		ToStatus:   to,
+		Reason:     reason,
+		ChangedAt:  time.Now(),
+		Version:    schedule.Version + 1,
+	}
+	if actorID := authModel.ActorID(ctx); actorID != "" {
+		transition.ChangedBy = &actorID
+	}
+	return transition, nil
+}
+
+// capturedAt returns the device time of a clock-in or clock-out, or the receive time when the device did not send one
+func capturedAt(deviceTime *time.Time, receivedAt time.Time) time.Time {
+	if deviceTime != nil {
+		return *deviceTime
+	}
+	return receivedAt
+}
+
+// checkAssignment verifies that the caregiver exists and that the principal may assign visits to them.
+// Coordinators must assign a caregiver from their own branch so that they can still see the visit.
+func (s *scheduleServiceImpl) checkAssignment(ctx context.Context, caregiverID *string) error {
+	principal, hasPrincipal := authModel.PrincipalFromContext(ctx)
+	isCoordinator := hasPrincipal && principal.Role == authModel.RoleCoordinator
+
+	if caregiverID == nil {
+		if isCoordinator {
+			return exceptions.ErrBadRequest.WithDetails("Coordinators must assign a caregiver")
+		}
+		return nil
+	}
+
+	caregiver, err := s.caregiverRepo.GetCaregiverByID(ctx, *caregiverID)
+	if err != nil {
+		log.Error().Err(err).Str("caregiver_id", *caregiverID).Msg("Failed to retrieve caregiver before assignment")
 		return err
 	}
+
+	if isCoordinator && (principal.BranchID == nil || caregiver.BranchID == nil || *principal.BranchID != *caregiver.BranchID) {
+		return exceptions.ErrForbidden.WithDetails("Coordinators may only assign caregivers in their branch")
+	}
 	return nil
 }
+
+// MarkOverdueSchedulesMissed moves upcoming schedules that were never started within the
+// grace period after their shift time to "missed". It returns the number of schedules updated.
+func (s *scheduleServiceImpl) MarkOverdueSchedulesMissed(ctx context.Context) (int, error) {
+	overdue, err := s.scheduleRepo.GetOverdueSchedules(ctx, time.Now().Add(-s.settings.MissedVisitGrace))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch overdue schedules from repository")
+		return 0, err
+	}
+
+	reason := fmt.Sprintf("No clock-in within %s of the shift start", s.settings.MissedVisitGrace)
+	marked := 0
+	for i := range overdue {
+		// Keep going so that one bad row, or a visit started meanwhile, does not block the rest of the sweep
+		transition, err := newTransition(ctx, &overdue[i], model.StatusMissed, &reason)
+		if err == nil {
+			err = s.scheduleRepo.TransitionStatus(ctx, transition)
+		}
+		if err != nil {
+			log.Error().Err(err).Str("schedule_id", overdue[i].ID).Msg("Failed to mark overdue schedule as missed")
+			continue
+		}
+		marked++
+	}
+
+	if marked > 0 {
+		log.Info().Int("marked", marked).Msg("Marked overdue schedules as missed")
+	}
+	return marked, nil
+}
+
+// RaiseMissingClockOuts opens an exception for every visit that has been in progress
+// for longer than the configured limit. It returns the number of new exceptions.
+func (s *scheduleServiceImpl) RaiseMissingClockOuts(ctx context.Context) (int64, error) {
+	raised, err := s.exceptionRepo.RaiseMissingClockOuts(ctx, time.Now().Add(-s.settings.MissingClockOutAfter))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to raise missing clock-out exceptions in repository")
+		return 0, err
+	}
+	if raised > 0 {
+		log.Info().Int64("raised", raised).Msg("Raised missing clock-out exceptions")
+	}
+	return raised, nil
+}
+
+// raiseException queues an exception on a visit that has already been recorded.
+// Failures are logged rather than returned so that the caregiver's clock-in/out is not lost.
+func (s *scheduleServiceImpl) raiseException(ctx context.Context, scheduleID, excType, details string) {
+	err := s.exceptionRepo.CreateException(ctx, exceptionModel.VisitException{
+		ID:         uuid.NewString(),
+		ScheduleID: scheduleID,
+		Type:       excType,
+		Status:     exceptionModel.StatusOpen,
+		Details:    &details,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", scheduleID).Str("type", excType).Msg("Failed to raise visit exception")
+		return
+	}
+	log.Warn().Str("schedule_id", scheduleID).Str("type", excType).Msg("Raised visit exception")
+}
+
+// checkGeofence measures the distance between the reported location and the client's home
+// and applies the geofence policy. Visits for clients without coordinates are not checked.
+func (s *scheduleServiceImpl) checkGeofence(schedule *model.Schedule, at time.Time, latitude, longitude float64) (model.VisitEvent, error) {
+	event := model.VisitEvent{Time: at, Latitude: latitude, Longitude: longitude}
+
+	if schedule.ClientLatitude == nil || schedule.ClientLongitude == nil {
+		log.Warn().Str("schedule_id", schedule.ID).Str("client_id", schedule.ClientID).Msg("Client has no home coordinates, skipping geofence check")
+		return event, nil
+	}
+
+	distance := utils.HaversineMeters(*schedule.ClientLatitude, *schedule.ClientLongitude, latitude, longitude)
+	event.DistanceMeters = &distance
+	if distance <= s.settings.GeofenceRadiusMeters {
+		return event, nil
+	}
+
+	if s.settings.GeofencePolicy == GeofencePolicyReject {
+		log.Warn().Str("schedule_id", schedule.ID).Float64("distance_meters", distance).Msg("Rejected visit outside the geofence")
+		return event, exceptions.ErrUnprocessableEntity.WithDetails(fmt.Sprintf("Location is %.0f m from the client's home, outside the %.0f m geofence", distance, s.settings.GeofenceRadiusMeters))
+	}
+
+	log.Warn().Str("schedule_id", schedule.ID).Float64("distance_meters", distance).Msg("Flagged visit outside the geofence")
+	event.OutOfGeofence = true
+	return event, nil
+}
+
+// checkClockSkew records when the event arrived and flags it when the device time differs from
+// the server time by more than the tolerance. Offline events legitimately arrive late, so for them
+// only device clocks ahead of the server and events older than the offline limit are flagged.
+func (s *scheduleServiceImpl) checkClockSkew(schedule *model.Schedule, event *model.VisitEvent, receivedAt time.Time, gpsFixAt *time.Time, offline bool) {
+	event.ReceivedAt = receivedAt
+	event.GPSFixAt = gpsFixAt
+
+	skew := event.Time.Sub(receivedAt)
+	tooOld := offline && s.settings.OfflineEventMaxAge > 0 && -skew > s.settings.OfflineEventMaxAge
+	if skew > s.settings.ClockSkewTolerance || (!offline && -skew > s.settings.ClockSkewTolerance) || tooOld {
+		log.Warn().Str("schedule_id", schedule.ID).Dur("skew", skew).Msg("Flagged visit with a skewed device clock")
+		event.ClockSkewed = true
+	}
+}
+
+// describeSkew tells how far the device clock of a flagged event was off, e.g. "was 12 minutes behind the server"
+func describeSkew(event model.VisitEvent) string {
+	skew := event.Time.Sub(event.ReceivedAt)
+	if skew > 0 {
+		return fmt.Sprintf("was %d minutes ahead of the server", int(skew.Minutes()))
+	}
+	return fmt.Sprintf("was %d minutes behind the server", int(-skew.Minutes()))
+}
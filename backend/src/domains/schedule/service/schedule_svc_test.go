@@ -1,15 +1,33 @@
 package service_test
 
 import (
+	"bytes"
 	"context"
+	"image"
+	"image/png"
 	"mini-evv-logger-backend/exceptions"
+	auditMocks "mini-evv-logger-backend/src/domains/audit/mocks/repository"
+	auditModel "mini-evv-logger-backend/src/domains/audit/model"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	caregiverMocks "mini-evv-logger-backend/src/domains/caregiver/mocks/repository"
+	caregiverModel "mini-evv-logger-backend/src/domains/caregiver/model"
+	carePlanMocks "mini-evv-logger-backend/src/domains/careplan/mocks/repository"
+	carePlanModel "mini-evv-logger-backend/src/domains/careplan/model"
+	clientMocks "mini-evv-logger-backend/src/domains/client/mocks/repository"
+	clientModel "mini-evv-logger-backend/src/domains/client/model"
 	mocks "mini-evv-logger-backend/src/domains/schedule/mocks/repository"
 	"mini-evv-logger-backend/src/domains/schedule/model"
 	"mini-evv-logger-backend/src/domains/schedule/service"
 
 	taskMocks "mini-evv-logger-backend/src/domains/task/mocks/repository"
 	taskModel "mini-evv-logger-backend/src/domains/task/model"
+	exceptionMocks "mini-evv-logger-backend/src/domains/visitexception/mocks/repository"
+	exceptionModel "mini-evv-logger-backend/src/domains/visitexception/model"
+	noteMocks "mini-evv-logger-backend/src/domains/visitnote/mocks/repository"
+	noteModel "mini-evv-logger-backend/src/domains/visitnote/model"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -17,19 +35,34 @@ import (
 )
 
 var (
-	mockScheduleRepo *mocks.MockScheduleRepository
-	mockTaskRepo     *taskMocks.MockTaskRepository
-	ctrl             *gomock.Controller
-	svc              service.ScheduleService
+	mockScheduleRepo  *mocks.MockScheduleRepository
+	mockTaskRepo      *taskMocks.MockTaskRepository
+	mockExceptionRepo *exceptionMocks.MockVisitExceptionRepository
+	mockCaregiverRepo *caregiverMocks.MockCaregiverRepository
+	mockClientRepo    *clientMocks.MockClientRepository
+	mockCarePlanRepo  *carePlanMocks.MockCarePlanRepository
+	mockAuditRepo     *auditMocks.MockAuditRepository
+	mockNoteRepo      *noteMocks.MockVisitNoteRepository
+	ctrl              *gomock.Controller
+	svc               service.ScheduleService
 )
 
+// dummySettings flags visits further than 150 m from the client's home or more than 15 minutes late
+var dummySettings = service.Settings{GeofenceRadiusMeters: 150, GeofencePolicy: service.GeofencePolicyFlag, LateClockInGrace: 15 * time.Minute, MissingClockOutAfter: 12 * time.Hour, MissedVisitGrace: time.Hour, ClockSkewTolerance: 2 * time.Minute, OfflineEventMaxAge: 24 * time.Hour}
+
 func initMocks(t *testing.T) {
 	ctrl = gomock.NewController(t)
 
 	mockScheduleRepo = mocks.NewMockScheduleRepository(ctrl)
 	mockTaskRepo = taskMocks.NewMockTaskRepository(ctrl)
+	mockExceptionRepo = exceptionMocks.NewMockVisitExceptionRepository(ctrl)
+	mockCaregiverRepo = caregiverMocks.NewMockCaregiverRepository(ctrl)
+	mockClientRepo = clientMocks.NewMockClientRepository(ctrl)
+	mockCarePlanRepo = carePlanMocks.NewMockCarePlanRepository(ctrl)
+	mockAuditRepo = auditMocks.NewMockAuditRepository(ctrl)
+	mockNoteRepo = noteMocks.NewMockVisitNoteRepository(ctrl)
 
-	svc = service.NewScheduleService(mockScheduleRepo, mockTaskRepo)
+	svc = service.NewScheduleService(mockScheduleRepo, mockTaskRepo, mockExceptionRepo, mockCaregiverRepo, mockClientRepo, mockCarePlanRepo, mockAuditRepo, mockNoteRepo, dummySettings)
 }
 
 func TestGetAllSchedules(t *testing.T) {
@@ -53,6 +86,44 @@ func TestGetAllSchedules(t *testing.T) {
 		assert.Equal(t, 100, paginatedSchedules.TotalData)
 	})
 
+	t.Run("TestGetAllSchedules: Scoped To Caregiver Principal", func(t *testing.T) {
+		caregiverID := uuid.NewString()
+		ctx := authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: uuid.NewString(), Role: authModel.RoleCaregiver, CaregiverID: &caregiverID})
+
+		mockScheduleRepo.EXPECT().GetSchedules(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, filter model.FilterSchedulesRequest) ([]model.Schedule, int, error) {
+				assert.Equal(t, caregiverID, filter.CaregiverID)
+				return []model.Schedule{}, 0, nil
+			}).Times(1)
+
+		otherFilter := dummyFilter
+		otherFilter.CaregiverID = uuid.NewString() // Must be overridden by the principal
+		_, err := svc.GetAllSchedules(ctx, otherFilter)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestGetAllSchedules: Scoped To Coordinator Branch", func(t *testing.T) {
+		branchID := uuid.NewString()
+		ctx := authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: uuid.NewString(), Role: authModel.RoleCoordinator, BranchID: &branchID})
+
+		mockScheduleRepo.EXPECT().GetSchedules(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, filter model.FilterSchedulesRequest) ([]model.Schedule, int, error) {
+				assert.Equal(t, branchID, filter.BranchID)
+				return []model.Schedule{}, 0, nil
+			}).Times(1)
+
+		_, err := svc.GetAllSchedules(ctx, dummyFilter)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestGetAllSchedules: Caregiver Without Link", func(t *testing.T) {
+		ctx := authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: uuid.NewString(), Role: authModel.RoleCaregiver})
+
+		paginatedSchedules, err := svc.GetAllSchedules(ctx, dummyFilter)
+		assert.Error(t, err)
+		assert.Nil(t, paginatedSchedules)
+	})
+
 	t.Run("TestGetAllSchedules: Validation error", func(t *testing.T) {
 		invalidFilter := model.FilterSchedulesRequest{
 			Limit: 1,
@@ -63,6 +134,23 @@ func TestGetAllSchedules(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("TestGetAllSchedules: Invalid date filters", func(t *testing.T) {
+		invalidFilters := map[string]model.FilterSchedulesRequest{
+			"unknown timezone":  {Date: "2026-03-08", TZ: "Mars/Olympus_Mons"},
+			"date with range":   {Date: "2026-03-08", From: "2026-03-01"},
+			"from after to":     {From: "2026-03-09", To: "2026-03-08"},
+			"invalid range day": {From: "2026-02-30"},
+		}
+		for name, filter := range invalidFilters {
+			_, err := svc.GetAllSchedules(context.Background(), filter)
+			assert.Error(t, err, name)
+			customErr, ok := err.(*exceptions.CustomError)
+			if assert.True(t, ok, name) {
+				assert.Equal(t, http.StatusBadRequest, customErr.Code, name)
+			}
+		}
+	})
+
 	t.Run("TestGetAllSchedules: Error get schedules", func(t *testing.T) {
 		mockScheduleRepo.EXPECT().GetSchedules(gomock.Any(), gomock.Any()).Return(nil, 0, assert.AnError).Times(1)
 		paginatedSchedules, err := svc.GetAllSchedules(context.Background(), dummyFilter)
@@ -81,6 +169,7 @@ func TestGetScheduleByID(t *testing.T) {
 	t.Run("TestGetScheduleByID: OK", func(t *testing.T) {
 		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID}, nil).Times(1)
 		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return([]taskModel.Task{}, nil).Times(1)
+		mockNoteRepo.EXPECT().GetNotesByScheduleID(gomock.Any(), dummyID).Return([]noteModel.VisitNote{}, nil).Times(1)
 		schedule, err := svc.GetScheduleByID(context.Background(), dummyID)
 		assert.NoError(t, err)
 		assert.NotNil(t, schedule)
@@ -111,9 +200,50 @@ func TestGetScheduleByID(t *testing.T) {
 		assert.Equal(t, exceptions.ErrBadRequest.WithDetails("Invalid schedule ID format").Error(), err.Error())
 	})
 
+	t.Run("TestGetScheduleByID: With Notes", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return([]taskModel.Task{}, nil).Times(1)
+		mockNoteRepo.EXPECT().GetNotesByScheduleID(gomock.Any(), dummyID).
+			Return([]noteModel.VisitNote{{ID: uuid.NewString(), ScheduleID: dummyID, Category: noteModel.CategoryObservation, Text: "Client was in good spirits"}}, nil).Times(1)
+
+		schedule, err := svc.GetScheduleByID(context.Background(), dummyID)
+		assert.NoError(t, err)
+		if assert.Len(t, schedule.Notes, 1) {
+			assert.Equal(t, noteModel.CategoryObservation, schedule.Notes[0].Category)
+		}
+	})
+
+	t.Run("TestGetScheduleByID: With Attestation", func(t *testing.T) {
+		endTime := time.Now()
+		format, signer := model.SignatureFormatPNG, "Jane Doe"
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusCompleted, EndTime: &endTime}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return([]taskModel.Task{}, nil).Times(1)
+		mockNoteRepo.EXPECT().GetNotesByScheduleID(gomock.Any(), dummyID).Return([]noteModel.VisitNote{}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetAttestation(gomock.Any(), dummyID).Return(&model.Attestation{ScheduleID: dummyID, SignerName: &signer, SignatureFormat: &format}, nil).Times(1)
+
+		schedule, err := svc.GetScheduleByID(context.Background(), dummyID)
+		assert.NoError(t, err)
+		if assert.NotNil(t, schedule.Attestation) {
+			assert.Equal(t, &signer, schedule.Attestation.SignerName)
+		}
+	})
+
+	t.Run("TestGetScheduleByID: Without Attestation", func(t *testing.T) {
+		endTime := time.Now()
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusCompleted, EndTime: &endTime}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return([]taskModel.Task{}, nil).Times(1)
+		mockNoteRepo.EXPECT().GetNotesByScheduleID(gomock.Any(), dummyID).Return([]noteModel.VisitNote{}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetAttestation(gomock.Any(), dummyID).Return(nil, exceptions.ErrNotFound).Times(1)
+
+		schedule, err := svc.GetScheduleByID(context.Background(), dummyID)
+		assert.NoError(t, err)
+		assert.Nil(t, schedule.Attestation)
+	})
+
 	t.Run("TestGetScheduleByID: No Tasks Found", func(t *testing.T) {
 		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID}, nil).Times(1)
 		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return(nil, nil).Times(1)
+		mockNoteRepo.EXPECT().GetNotesByScheduleID(gomock.Any(), dummyID).Return([]noteModel.VisitNote{}, nil).Times(1)
 		schedule, err := svc.GetScheduleByID(context.Background(), dummyID)
 		assert.NoError(t, err)
 		assert.NotNil(t, schedule)
@@ -122,6 +252,67 @@ func TestGetScheduleByID(t *testing.T) {
 	})
 }
 
+func TestGetSignature(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyID := uuid.NewString()
+
+	t.Run("TestGetSignature: OK", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetSignature(gomock.Any(), dummyID).Return([]byte("\x89PNG"), nil).Times(1)
+
+		signature, err := svc.GetSignature(context.Background(), dummyID)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("\x89PNG"), signature)
+	})
+
+	t.Run("TestGetSignature: Not Found", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetSignature(gomock.Any(), dummyID).Return(nil, exceptions.ErrNotFound).Times(1)
+
+		signature, err := svc.GetSignature(context.Background(), dummyID)
+		assert.Error(t, err)
+		assert.Nil(t, signature)
+	})
+
+	t.Run("TestGetSignature: Invalid UUID", func(t *testing.T) {
+		_, err := svc.GetSignature(context.Background(), "invalid-uuid")
+		assert.Error(t, err)
+		assert.Equal(t, exceptions.ErrBadRequest.WithDetails("Invalid schedule ID format").Error(), err.Error())
+	})
+}
+
+func TestGetScheduleOwnership(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyID := uuid.NewString()
+
+	t.Run("TestGetScheduleOwnership: OK", func(t *testing.T) {
+		caregiverID := uuid.NewString()
+		mockScheduleRepo.EXPECT().GetScheduleOwnership(gomock.Any(), dummyID).Return(&model.ScheduleOwnership{CaregiverID: &caregiverID}, nil).Times(1)
+
+		ownership, err := svc.GetScheduleOwnership(context.Background(), dummyID)
+		assert.NoError(t, err)
+		assert.Equal(t, caregiverID, *ownership.CaregiverID)
+	})
+
+	t.Run("TestGetScheduleOwnership: Invalid UUID", func(t *testing.T) {
+		ownership, err := svc.GetScheduleOwnership(context.Background(), "invalid-uuid")
+		assert.Error(t, err)
+		assert.Nil(t, ownership)
+	})
+
+	t.Run("TestGetScheduleOwnership: Not Found", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleOwnership(gomock.Any(), dummyID).Return(nil, exceptions.ErrNotFound).Times(1)
+
+		ownership, err := svc.GetScheduleOwnership(context.Background(), dummyID)
+		assert.Error(t, err)
+		assert.Nil(t, ownership)
+	})
+}
+
 func TestStartVisit(t *testing.T) {
 	initMocks(t)
 
@@ -135,8 +326,26 @@ func TestStartVisit(t *testing.T) {
 	}
 
 	t.Run("TestStartVisit: OK", func(t *testing.T) {
-		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming"}, nil).Times(1)
-		mockScheduleRepo.EXPECT().LogVisitStart(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		dummyUserID := uuid.NewString()
+		caregiverCtx := authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: dummyUserID, Role: authModel.RoleCaregiver})
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusUpcoming, ShiftTime: time.Now(), Version: 1}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitStart(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, transition model.StatusTransition, _ model.VisitEvent) error {
+				assert.Equal(t, dummyID, transition.ScheduleID)
+				assert.Equal(t, 2, transition.Version)
+				assert.Equal(t, model.StatusUpcoming, transition.FromStatus)
+				assert.Equal(t, model.StatusInProgress, transition.ToStatus)
+				assert.Equal(t, dummyUserID, *transition.ChangedBy)
+				return nil
+			}).Times(1)
+
+		err := svc.StartVisit(caregiverCtx, dummyRequest)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestStartVisit: Reopened Visit", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusReopened, ShiftTime: time.Now()}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitStart(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
 		err := svc.StartVisit(context.Background(), dummyRequest)
 		assert.NoError(t, err)
@@ -150,18 +359,249 @@ func TestStartVisit(t *testing.T) {
 	})
 
 	t.Run("TestStartVisit: Schedule Already Started", func(t *testing.T) {
-		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "started"}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusInProgress}, nil).Times(1)
 		err := svc.StartVisit(context.Background(), dummyRequest)
 		assert.Error(t, err)
-		assert.Equal(t, exceptions.ErrConflict.WithDetails("Visit for schedule ID "+dummyID+" is already started. Cannot start.").Error(), err.Error())
+		assert.Equal(t, exceptions.ErrConflict.WithDetails("Schedule ID "+dummyID+" cannot move from in-progress to in-progress").Error(), err.Error())
+		assert.Equal(t, model.TransitionConflict{CurrentStatus: model.StatusInProgress, RequestedStatus: model.StatusInProgress}, err.(*exceptions.CustomError).Data)
+	})
+
+	t.Run("TestStartVisit: Stale If-Match", func(t *testing.T) {
+		staleVersion := 1
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusUpcoming, ShiftTime: time.Now(), Version: 2}, nil).Times(1)
+
+		req := dummyRequest
+		req.IfMatch = &staleVersion
+		err := svc.StartVisit(context.Background(), req)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestStartVisit: Double Tap", func(t *testing.T) {
+		// Both taps read the upcoming visit; the repository lets only the first one through
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusUpcoming, ShiftTime: time.Now(), Version: 1}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitStart(gomock.Any(), gomock.Any(), gomock.Any()).Return(exceptions.ErrConflict.WithDetails("Schedule ID " + dummyID + " was changed by another request. Reload it and try again.")).Times(1)
+
+		err := svc.StartVisit(context.Background(), dummyRequest)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusConflict, err.(*exceptions.CustomError).Code)
 	})
 
 	t.Run("TestStartVisit: Failed Log Visit Start", func(t *testing.T) {
-		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming"}, nil).Times(1)
-		mockScheduleRepo.EXPECT().LogVisitStart(gomock.Any(), dummyID, gomock.Any(), dummyRequest.Latitude, dummyRequest.Longitude).Return(assert.AnError).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming", ShiftTime: time.Now()}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitStart(gomock.Any(), gomock.Any(), gomock.Any()).Return(assert.AnError).Times(1)
+
+		err := svc.StartVisit(context.Background(), dummyRequest)
+		assert.Error(t, err)
+	})
+
+	t.Run("TestStartVisit: Inside Geofence", func(t *testing.T) {
+		// About 11 m from the reported location
+		homeLat, homeLng := dummyRequest.Latitude+0.0001, dummyRequest.Longitude
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming", ShiftTime: time.Now(), ClientLatitude: &homeLat, ClientLongitude: &homeLng}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitStart(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ model.StatusTransition, event model.VisitEvent) error {
+				assert.Equal(t, dummyRequest.Latitude, event.Latitude)
+				assert.NotNil(t, event.DistanceMeters)
+				assert.InDelta(t, 11.1, *event.DistanceMeters, 0.5)
+				assert.False(t, event.OutOfGeofence)
+				return nil
+			}).Times(1)
 
 		err := svc.StartVisit(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestStartVisit: Outside Geofence Flagged", func(t *testing.T) {
+		// About 1.1 km from the reported location
+		homeLat, homeLng := dummyRequest.Latitude+0.01, dummyRequest.Longitude
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming", ShiftTime: time.Now(), ClientLatitude: &homeLat, ClientLongitude: &homeLng}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitStart(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ model.StatusTransition, event model.VisitEvent) error {
+				assert.Greater(t, *event.DistanceMeters, 1000.0)
+				assert.True(t, event.OutOfGeofence)
+				return nil
+			}).Times(1)
+		mockExceptionRepo.EXPECT().CreateException(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, exc exceptionModel.VisitException) error {
+				assert.Equal(t, dummyID, exc.ScheduleID)
+				assert.Equal(t, exceptionModel.TypeClockInOutOfGeofence, exc.Type)
+				assert.Equal(t, exceptionModel.StatusOpen, exc.Status)
+				return nil
+			}).Times(1)
+
+		err := svc.StartVisit(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestStartVisit: Outside Geofence Rejected", func(t *testing.T) {
+		rejectSvc := service.NewScheduleService(mockScheduleRepo, mockTaskRepo, mockExceptionRepo, mockCaregiverRepo, mockClientRepo, mockCarePlanRepo, mockAuditRepo, mockNoteRepo, service.Settings{GeofenceRadiusMeters: 150, GeofencePolicy: service.GeofencePolicyReject})
+		homeLat, homeLng := dummyRequest.Latitude+0.01, dummyRequest.Longitude
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming", ShiftTime: time.Now(), ClientLatitude: &homeLat, ClientLongitude: &homeLng}, nil).Times(1)
+
+		err := rejectSvc.StartVisit(context.Background(), dummyRequest)
 		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestStartVisit: Late Clock In Raises Exception", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming", ShiftTime: time.Now().Add(-time.Hour)}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitStart(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		mockExceptionRepo.EXPECT().CreateException(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, exc exceptionModel.VisitException) error {
+				assert.Equal(t, exceptionModel.TypeLateClockIn, exc.Type)
+				assert.Equal(t, "Clocked in 60 minutes late", *exc.Details)
+				return nil
+			}).Times(1)
+
+		err := svc.StartVisit(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestStartVisit: Within Grace Period", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming", ShiftTime: time.Now().Add(-10 * time.Minute)}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitStart(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		err := svc.StartVisit(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestStartVisit: Failed Raise Exception", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming", ShiftTime: time.Now().Add(-time.Hour)}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitStart(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		mockExceptionRepo.EXPECT().CreateException(gomock.Any(), gomock.Any()).Return(assert.AnError).Times(1)
+
+		// The visit is already recorded, so the caregiver still gets a success
+		err := svc.StartVisit(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestStartVisit: Device Time", func(t *testing.T) {
+		// Recorded offline on time, synced an hour later: no late clock-in
+		shiftTime := time.Now().Add(-time.Hour)
+		deviceTime := shiftTime.Add(5 * time.Minute)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusUpcoming, ShiftTime: shiftTime}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitStart(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, transition model.StatusTransition, event model.VisitEvent) error {
+				assert.Equal(t, deviceTime, event.Time)
+				assert.Equal(t, deviceTime, transition.ChangedAt)
+				assert.WithinDuration(t, time.Now(), event.ReceivedAt, time.Second)
+				assert.False(t, event.ClockSkewed)
+				return nil
+			}).Times(1)
+
+		req := dummyRequest
+		req.CapturedAt = &deviceTime
+		req.Offline = true
+		err := svc.StartVisit(context.Background(), req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestStartVisit: Device Clock Within Tolerance", func(t *testing.T) {
+		deviceTime := time.Now().Add(-time.Minute)
+		gpsFixAt := deviceTime.Add(-10 * time.Second)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusUpcoming, ShiftTime: time.Now()}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitStart(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ model.StatusTransition, event model.VisitEvent) error {
+				assert.Equal(t, deviceTime, event.Time)
+				assert.Equal(t, &gpsFixAt, event.GPSFixAt)
+				assert.False(t, event.ClockSkewed)
+				return nil
+			}).Times(1)
+
+		req := dummyRequest
+		req.CapturedAt = &deviceTime
+		req.GPSFixAt = &gpsFixAt
+		err := svc.StartVisit(context.Background(), req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestStartVisit: Device Clock Behind", func(t *testing.T) {
+		// A live request should arrive within the tolerance of its capture time
+		deviceTime := time.Now().Add(-10 * time.Minute)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusUpcoming, ShiftTime: time.Now()}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitStart(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ model.StatusTransition, event model.VisitEvent) error {
+				assert.Equal(t, deviceTime, event.Time)
+				assert.True(t, event.ClockSkewed)
+				return nil
+			}).Times(1)
+		mockExceptionRepo.EXPECT().CreateException(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, exc exceptionModel.VisitException) error {
+				assert.Equal(t, exceptionModel.TypeClockInClockSkew, exc.Type)
+				assert.Equal(t, "Device clock was 10 minutes behind the server at clock-in", *exc.Details)
+				return nil
+			}).Times(1)
+
+		req := dummyRequest
+		req.CapturedAt = &deviceTime
+		err := svc.StartVisit(context.Background(), req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestStartVisit: Backdated Device Time", func(t *testing.T) {
+		// Claiming to have clocked in on time does not hide a live clock-in that arrives an hour late
+		shiftTime := time.Now().Add(-time.Hour)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusUpcoming, ShiftTime: shiftTime}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitStart(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		var raised []string
+		mockExceptionRepo.EXPECT().CreateException(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, exc exceptionModel.VisitException) error {
+				raised = append(raised, exc.Type)
+				return nil
+			}).Times(2)
+
+		req := dummyRequest
+		req.CapturedAt = &shiftTime
+		err := svc.StartVisit(context.Background(), req)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{exceptionModel.TypeLateClockIn, exceptionModel.TypeClockInClockSkew}, raised)
+	})
+
+	t.Run("TestStartVisit: Offline Event Too Old", func(t *testing.T) {
+		shiftTime := time.Now().Add(-48 * time.Hour)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusUpcoming, ShiftTime: shiftTime}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitStart(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ model.StatusTransition, event model.VisitEvent) error {
+				assert.Equal(t, shiftTime, event.Time)
+				assert.True(t, event.ClockSkewed)
+				return nil
+			}).Times(1)
+		var raised []string
+		mockExceptionRepo.EXPECT().CreateException(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, exc exceptionModel.VisitException) error {
+				raised = append(raised, exc.Type)
+				return nil
+			}).Times(2)
+
+		req := dummyRequest
+		req.CapturedAt = &shiftTime
+		req.Offline = true
+		err := svc.StartVisit(context.Background(), req)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{exceptionModel.TypeLateClockIn, exceptionModel.TypeClockInClockSkew}, raised)
+	})
+
+	t.Run("TestStartVisit: Offline Device Clock Ahead", func(t *testing.T) {
+		deviceTime := time.Now().Add(4 * time.Minute)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusUpcoming, ShiftTime: time.Now().Add(5 * time.Minute)}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitStart(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ model.StatusTransition, event model.VisitEvent) error {
+				assert.True(t, event.ClockSkewed)
+				return nil
+			}).Times(1)
+		mockExceptionRepo.EXPECT().CreateException(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, exc exceptionModel.VisitException) error {
+				assert.Equal(t, exceptionModel.TypeClockInClockSkew, exc.Type)
+				assert.Equal(t, "Device clock was 3 minutes ahead of the server at clock-in", *exc.Details)
+				return nil
+			}).Times(1)
+
+		req := dummyRequest
+		req.CapturedAt = &deviceTime
+		req.Offline = true
+		err := svc.StartVisit(context.Background(), req)
+		assert.NoError(t, err)
 	})
 }
 
@@ -179,12 +619,195 @@ func TestEndVisit(t *testing.T) {
 
 	t.Run("TestEndVisit: OK", func(t *testing.T) {
 		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "in-progress"}, nil).Times(1)
-		mockScheduleRepo.EXPECT().LogVisitEnd(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return([]taskModel.Task{}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitEnd(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
 		err := svc.EndVisit(context.Background(), dummyRequest)
 		assert.NoError(t, err)
 	})
 
+	t.Run("TestEndVisit: Signature Strokes", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusInProgress}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return([]taskModel.Task{}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitEnd(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, transition model.StatusTransition, event model.VisitEvent, _ []taskModel.Task) error {
+				if assert.NotNil(t, event.Attestation) {
+					assert.Equal(t, dummyID, event.Attestation.ScheduleID)
+					assert.Equal(t, "Jane Doe", *event.Attestation.SignerName)
+					assert.Equal(t, model.SignatureFormatStrokes, *event.Attestation.SignatureFormat)
+					assert.Equal(t, transition.ChangedAt, event.Attestation.SignedAt)
+					assert.JSONEq(t, `[[{"x":10,"y":20},{"x":60,"y":25}]]`, *event.Attestation.SignatureStrokes)
+
+					img, err := png.Decode(bytes.NewReader(event.Attestation.SignaturePNG))
+					if assert.NoError(t, err) {
+						assert.Equal(t, image.Rect(0, 0, 76, 41), img.Bounds()) // Strokes plus the margin
+						_, _, _, alpha := img.At(18, 28).RGBA()                 // First point, shifted by the margin
+						assert.NotZero(t, alpha)
+					}
+				}
+				return nil
+			}).Times(1)
+
+		req := dummyRequest
+		req.Attestation = &model.AttestationRequest{SignerName: "Jane Doe", SignerRelationship: "family",
+			SignatureStrokes: [][]model.StrokePoint{{{X: 10, Y: 20}, {X: 60, Y: 25}}}}
+		err := svc.EndVisit(context.Background(), req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestEndVisit: Signature PNG", func(t *testing.T) {
+		trailer := []byte("<script>alert(1)</script>")
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusInProgress}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return([]taskModel.Task{}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitEnd(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ model.StatusTransition, event model.VisitEvent, _ []taskModel.Task) error {
+				if assert.NotNil(t, event.Attestation) {
+					assert.Equal(t, model.SignatureFormatPNG, *event.Attestation.SignatureFormat)
+					assert.False(t, bytes.Contains(event.Attestation.SignaturePNG, trailer)) // Only the image is stored
+					img, err := png.Decode(bytes.NewReader(event.Attestation.SignaturePNG))
+					if assert.NoError(t, err) {
+						assert.Equal(t, image.Rect(0, 0, 40, 20), img.Bounds())
+					}
+				}
+				return nil
+			}).Times(1)
+
+		req := dummyRequest
+		req.Attestation = &model.AttestationRequest{SignerName: "Jane Doe", SignerRelationship: "family",
+			SignaturePNG: append(signaturePNG(t, 40, 20), trailer...)}
+		err := svc.EndVisit(context.Background(), req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestEndVisit: Client Unable To Sign", func(t *testing.T) {
+		reason := "Client is visually impaired"
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusInProgress}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return([]taskModel.Task{}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitEnd(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ model.StatusTransition, event model.VisitEvent, _ []taskModel.Task) error {
+				if assert.NotNil(t, event.Attestation) {
+					assert.Equal(t, &reason, event.Attestation.UnableToSignReason)
+					assert.False(t, event.Attestation.HasSignature())
+					assert.Nil(t, event.Attestation.SignaturePNG)
+				}
+				return nil
+			}).Times(1)
+
+		req := dummyRequest
+		req.Attestation = &model.AttestationRequest{UnableToSignReason: &reason}
+		err := svc.EndVisit(context.Background(), req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestEndVisit: Invalid Attestation", func(t *testing.T) {
+		reason := "Client is asleep"
+		for name, attestation := range map[string]model.AttestationRequest{
+			"Not A PNG":            {SignerName: "Jane Doe", SignerRelationship: "family", SignaturePNG: []byte("not an image")},
+			"Truncated PNG":        {SignerName: "Jane Doe", SignerRelationship: "family", SignaturePNG: signaturePNG(t, 40, 20)[:40]},
+			"Without Signer":       {SignatureStrokes: [][]model.StrokePoint{{{X: 1, Y: 1}}}},
+			"Signature And Reason": {SignerName: "Jane Doe", SignerRelationship: "family", SignatureStrokes: [][]model.StrokePoint{{{X: 1, Y: 1}}}, UnableToSignReason: &reason},
+			"Empty":                {},
+			"Too Many Points":      {SignerName: "Jane Doe", SignerRelationship: "family", SignatureStrokes: strokes(3, 2000, 0)},
+			"Too Long":             {SignerName: "Jane Doe", SignerRelationship: "family", SignatureStrokes: strokes(1, 100, 2000)},
+		} {
+			mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusInProgress}, nil).Times(1)
+
+			req := dummyRequest
+			req.Attestation = &attestation
+			err := svc.EndVisit(context.Background(), req)
+			assert.Error(t, err, name)
+			assert.Equal(t, http.StatusBadRequest, err.(*exceptions.CustomError).Code, name)
+		}
+	})
+
+	t.Run("TestEndVisit: Unknown Signer Relationship", func(t *testing.T) {
+		req := dummyRequest
+		req.Attestation = &model.AttestationRequest{SignerName: "Jane Doe", SignerRelationship: "neighbour", SignatureStrokes: [][]model.StrokePoint{{{X: 1, Y: 1}}}}
+		err := svc.EndVisit(context.Background(), req)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestEndVisit: Records Variance Against Plan", func(t *testing.T) {
+		// A two hour visit that started 90 minutes ago ends 30 minutes early
+		shiftTime := time.Now().Add(-90 * time.Minute)
+		startTime := shiftTime
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).
+			Return(&model.Schedule{ID: dummyID, Status: "in-progress", ShiftTime: shiftTime, ShiftEndTime: shiftTime.Add(2 * time.Hour), StartTime: &startTime}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return([]taskModel.Task{}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitEnd(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ model.StatusTransition, event model.VisitEvent, _ []taskModel.Task) error {
+				assert.Equal(t, 90, event.Minutes)
+				assert.Equal(t, -30, event.Variance)
+				return nil
+			}).Times(1)
+
+		err := svc.EndVisit(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestEndVisit: Device Time", func(t *testing.T) {
+		shiftTime := time.Now().Add(-3 * time.Hour)
+		startTime := shiftTime
+		deviceTime := shiftTime.Add(time.Hour)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).
+			Return(&model.Schedule{ID: dummyID, Status: model.StatusInProgress, ShiftTime: shiftTime, ShiftEndTime: shiftTime.Add(time.Hour), StartTime: &startTime}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return([]taskModel.Task{}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitEnd(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, transition model.StatusTransition, event model.VisitEvent, _ []taskModel.Task) error {
+				assert.Equal(t, deviceTime, event.Time)
+				assert.Equal(t, deviceTime, transition.ChangedAt)
+				assert.Equal(t, 60, event.Minutes)
+				assert.Equal(t, 0, event.Variance)
+				assert.False(t, event.ClockSkewed)
+				return nil
+			}).Times(1)
+
+		req := dummyRequest
+		req.CapturedAt = &deviceTime
+		req.Offline = true
+		err := svc.EndVisit(context.Background(), req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestEndVisit: Device Clock Ahead", func(t *testing.T) {
+		startTime := time.Now().Add(-time.Hour)
+		deviceTime := time.Now().Add(30 * time.Minute)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).
+			Return(&model.Schedule{ID: dummyID, Status: model.StatusInProgress, ShiftTime: startTime, ShiftEndTime: startTime.Add(time.Hour), StartTime: &startTime}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return([]taskModel.Task{}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitEnd(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ model.StatusTransition, event model.VisitEvent, _ []taskModel.Task) error {
+				assert.Equal(t, deviceTime, event.Time)
+				assert.True(t, event.ClockSkewed)
+				return nil
+			}).Times(1)
+		mockExceptionRepo.EXPECT().CreateException(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, exc exceptionModel.VisitException) error {
+				assert.Equal(t, exceptionModel.TypeClockOutClockSkew, exc.Type)
+				return nil
+			}).Times(1)
+
+		req := dummyRequest
+		req.CapturedAt = &deviceTime
+		err := svc.EndVisit(context.Background(), req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestEndVisit: Device Time Before Clock In", func(t *testing.T) {
+		startTime := time.Now().Add(-time.Hour)
+		deviceTime := startTime.Add(-time.Minute)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).
+			Return(&model.Schedule{ID: dummyID, Status: model.StatusInProgress, StartTime: &startTime}, nil).Times(1)
+
+		req := dummyRequest
+		req.CapturedAt = &deviceTime
+		req.Offline = true
+		err := svc.EndVisit(context.Background(), req)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*exceptions.CustomError).Code)
+	})
+
 	t.Run("TestEndVisit: Schedule Not Found", func(t *testing.T) {
 		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(nil, exceptions.ErrNotFound).Times(1)
 		err := svc.EndVisit(context.Background(), dummyRequest)
@@ -193,18 +816,559 @@ func TestEndVisit(t *testing.T) {
 	})
 
 	t.Run("TestEndVisit: Schedule Not In Progress", func(t *testing.T) {
-		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming"}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming", ShiftTime: time.Now()}, nil).Times(1)
 		err := svc.EndVisit(context.Background(), dummyRequest)
 		assert.Error(t, err)
-		assert.Equal(t, exceptions.ErrConflict.WithDetails("Visit for schedule ID "+dummyID+" is currently upcoming. Cannot end.").Error(), err.Error())
+		assert.Equal(t, exceptions.ErrConflict.WithDetails("Schedule ID "+dummyID+" cannot move from upcoming to completed").Error(), err.Error())
 	})
 
 	t.Run("TestEndVisit: Failed Log Visit End", func(t *testing.T) {
 		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "in-progress"}, nil).Times(1)
-		mockScheduleRepo.EXPECT().LogVisitEnd(gomock.Any(), dummyID, gomock.Any(), dummyRequest.Latitude, dummyRequest.Longitude).Return(assert.AnError).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return([]taskModel.Task{}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitEnd(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(assert.AnError).Times(1)
+
+		err := svc.EndVisit(context.Background(), dummyRequest)
+		assert.Error(t, err)
+	})
+
+	t.Run("TestEndVisit: Outside Geofence Flagged", func(t *testing.T) {
+		homeLat, homeLng := dummyRequest.Latitude, dummyRequest.Longitude+0.05
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "in-progress", ClientLatitude: &homeLat, ClientLongitude: &homeLng}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return([]taskModel.Task{}, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitEnd(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ model.StatusTransition, event model.VisitEvent, _ []taskModel.Task) error {
+				assert.True(t, event.OutOfGeofence)
+				return nil
+			}).Times(1)
+		mockExceptionRepo.EXPECT().CreateException(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, exc exceptionModel.VisitException) error {
+				assert.Equal(t, exceptionModel.TypeClockOutOutOfGeofence, exc.Type)
+				return nil
+			}).Times(1)
+
+		err := svc.EndVisit(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestEndVisit: Outside Geofence Rejected", func(t *testing.T) {
+		rejectSvc := service.NewScheduleService(mockScheduleRepo, mockTaskRepo, mockExceptionRepo, mockCaregiverRepo, mockClientRepo, mockCarePlanRepo, mockAuditRepo, mockNoteRepo, service.Settings{GeofenceRadiusMeters: 150, GeofencePolicy: service.GeofencePolicyReject})
+		homeLat, homeLng := dummyRequest.Latitude, dummyRequest.Longitude+0.05
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "in-progress", ClientLatitude: &homeLat, ClientLongitude: &homeLng}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return([]taskModel.Task{}, nil).Times(1)
+
+		err := rejectSvc.EndVisit(context.Background(), dummyRequest)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*exceptions.CustomError).Code)
+	})
+
+	reason := "Client declined"
+	dummyTasks := []taskModel.Task{
+		{ID: uuid.NewString(), ScheduleID: dummyID, Status: taskModel.TaskCompleted, Version: 2},
+		{ID: uuid.NewString(), ScheduleID: dummyID, Status: taskModel.TaskNotCompleted, Reason: &reason, Version: 2},
+		{ID: uuid.NewString(), ScheduleID: dummyID, Status: taskModel.TaskPending, Version: 1},
+		{ID: uuid.NewString(), ScheduleID: dummyID, Status: taskModel.TaskNotCompleted, Version: 3}, // Recorded without a reason
+	}
+
+	t.Run("TestEndVisit: Unresolved Tasks", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusInProgress}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return(dummyTasks, nil).Times(1)
 
 		err := svc.EndVisit(context.Background(), dummyRequest)
 		assert.Error(t, err)
+		assert.Equal(t, exceptions.ErrConflict.WithDetails("Schedule ID "+dummyID+" has 2 unresolved task(s); complete them or mark them as not completed with a reason before clocking out").Error(), err.Error())
+		assert.Equal(t, model.UnresolvedTasks{TaskIDs: []string{dummyTasks[2].ID, dummyTasks[3].ID}}, err.(*exceptions.CustomError).Data)
 	})
 
+	t.Run("TestEndVisit: Failed Get Tasks", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusInProgress}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return(nil, exceptions.ErrInternalError).Times(1)
+
+		err := svc.EndVisit(context.Background(), dummyRequest)
+		assert.Error(t, err)
+		assert.Equal(t, exceptions.ErrInternalError.Error(), err.Error())
+	})
+
+	t.Run("TestEndVisit: Unresolved Tasks Marked Not Completed", func(t *testing.T) {
+		settings := dummySettings
+		settings.UnresolvedTaskPolicy = service.UnresolvedTaskPolicyMarkNotCompleted
+		closeSvc := service.NewScheduleService(mockScheduleRepo, mockTaskRepo, mockExceptionRepo, mockCaregiverRepo, mockClientRepo, mockCarePlanRepo, mockAuditRepo, mockNoteRepo, settings)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusInProgress}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyID).Return(dummyTasks, nil).Times(1)
+		mockScheduleRepo.EXPECT().LogVisitEnd(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ model.StatusTransition, _ model.VisitEvent, closedTasks []taskModel.Task) error {
+				if assert.Len(t, closedTasks, 2) {
+					assert.Equal(t, dummyTasks[2].ID, closedTasks[0].ID)
+					assert.Equal(t, dummyTasks[3].ID, closedTasks[1].ID)
+					for _, task := range closedTasks {
+						assert.Equal(t, taskModel.TaskNotCompleted, task.Status)
+						assert.Equal(t, "visit ended", *task.Reason)
+					}
+					assert.Equal(t, 1, closedTasks[0].Version) // Still the version that was read
+				}
+				return nil
+			}).Times(1)
+
+		err := closeSvc.EndVisit(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+		assert.Equal(t, taskModel.TaskPending, dummyTasks[2].Status)
+	})
+}
+
+func TestCreateSchedule(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyClientID, dummyCaregiverID, dummyBranchID := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	dummyRequest := model.CreateScheduleRequest{ClientID: dummyClientID, CaregiverID: &dummyCaregiverID, ShiftTime: time.Now().Add(24 * time.Hour), DurationMinutes: 90}
+	coordinatorCtx := authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: uuid.NewString(), Role: authModel.RoleCoordinator, BranchID: &dummyBranchID})
+
+	t.Run("TestCreateSchedule: OK", func(t *testing.T) {
+		mockClientRepo.EXPECT().GetClientByID(gomock.Any(), dummyClientID).Return(&clientModel.Client{ID: dummyClientID}, nil).Times(1)
+		mockCaregiverRepo.EXPECT().GetCaregiverByID(gomock.Any(), dummyCaregiverID).Return(&caregiverModel.Caregiver{ID: dummyCaregiverID, BranchID: &dummyBranchID}, nil).Times(1)
+		mockCarePlanRepo.EXPECT().GetActiveCarePlan(gomock.Any(), dummyClientID).Return(nil, exceptions.ErrNotFound).Times(1)
+		mockScheduleRepo.EXPECT().CreateSchedule(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, schedule model.Schedule) error {
+				assert.NotEmpty(t, schedule.ID)
+				assert.Equal(t, model.StatusUpcoming, schedule.Status)
+				assert.Equal(t, dummyClientID, schedule.ClientID)
+				assert.Equal(t, 90*time.Minute, schedule.ShiftEndTime.Sub(schedule.ShiftTime))
+				assert.Nil(t, schedule.CarePlanID)
+				assert.Empty(t, schedule.Tasks)
+				return nil
+			}).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), gomock.Any()).Return(&model.Schedule{ClientID: dummyClientID, Status: "upcoming"}, nil).Times(1)
+
+		schedule, err := svc.CreateSchedule(coordinatorCtx, dummyRequest)
+		assert.NoError(t, err)
+		assert.Equal(t, model.StatusUpcoming, schedule.Status)
+	})
+
+	t.Run("TestCreateSchedule: Tasks From Care Plan", func(t *testing.T) {
+		// 23:30 on a Tuesday in Chicago is already Wednesday in UTC
+		loc, err := time.LoadLocation("America/Chicago")
+		if err != nil {
+			t.Skipf("timezone data not available: %v", err)
+		}
+		tuesdayEvening := time.Date(2030, time.January, 1, 23, 30, 0, 0, loc)
+		plan := &carePlanModel.CarePlan{ID: uuid.NewString(), ClientID: dummyClientID, Version: 3, Tasks: []carePlanModel.CarePlanTask{
+			{Description: "Medication reminder", Frequency: carePlanModel.FrequencyEveryVisit},
+			{Description: "Laundry", Frequency: carePlanModel.FrequencyWeekly, Days: []string{"TU"}},
+			{Description: "Grocery run", Frequency: carePlanModel.FrequencyWeekly, Days: []string{"WE"}},
+		}}
+
+		mockClientRepo.EXPECT().GetClientByID(gomock.Any(), dummyClientID).Return(&clientModel.Client{ID: dummyClientID, Timezone: "America/Chicago"}, nil).Times(1)
+		mockCaregiverRepo.EXPECT().GetCaregiverByID(gomock.Any(), dummyCaregiverID).Return(&caregiverModel.Caregiver{ID: dummyCaregiverID, BranchID: &dummyBranchID}, nil).Times(1)
+		mockCarePlanRepo.EXPECT().GetActiveCarePlan(gomock.Any(), dummyClientID).Return(plan, nil).Times(1)
+		mockScheduleRepo.EXPECT().CreateSchedule(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, schedule model.Schedule) error {
+				assert.Equal(t, &plan.ID, schedule.CarePlanID)
+				assert.Len(t, schedule.Tasks, 2)
+				assert.Equal(t, "Medication reminder", schedule.Tasks[0].Description)
+				assert.Equal(t, "Laundry", schedule.Tasks[1].Description)
+				return nil
+			}).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), gomock.Any()).Return(&model.Schedule{ClientID: dummyClientID, CarePlanID: &plan.ID, CarePlanVersion: &plan.Version}, nil).Times(1)
+
+		schedule, err := svc.CreateSchedule(coordinatorCtx, model.CreateScheduleRequest{ClientID: dummyClientID, CaregiverID: &dummyCaregiverID, ShiftTime: tuesdayEvening.UTC(), DurationMinutes: 60})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, *schedule.CarePlanVersion)
+	})
+
+	t.Run("TestCreateSchedule: Validation Error", func(t *testing.T) {
+		schedule, err := svc.CreateSchedule(context.Background(), model.CreateScheduleRequest{ClientID: "not-a-uuid"})
+		assert.Error(t, err)
+		assert.Nil(t, schedule)
+	})
+
+	t.Run("TestCreateSchedule: Client Not Found", func(t *testing.T) {
+		mockClientRepo.EXPECT().GetClientByID(gomock.Any(), dummyClientID).Return(nil, exceptions.ErrNotFound).Times(1)
+
+		schedule, err := svc.CreateSchedule(coordinatorCtx, dummyRequest)
+		assert.Error(t, err)
+		assert.Nil(t, schedule)
+	})
+
+	t.Run("TestCreateSchedule: Caregiver In Other Branch", func(t *testing.T) {
+		otherBranchID := uuid.NewString()
+		mockClientRepo.EXPECT().GetClientByID(gomock.Any(), dummyClientID).Return(&clientModel.Client{ID: dummyClientID}, nil).Times(1)
+		mockCaregiverRepo.EXPECT().GetCaregiverByID(gomock.Any(), dummyCaregiverID).Return(&caregiverModel.Caregiver{ID: dummyCaregiverID, BranchID: &otherBranchID}, nil).Times(1)
+
+		schedule, err := svc.CreateSchedule(coordinatorCtx, dummyRequest)
+		assert.Error(t, err)
+		assert.Nil(t, schedule)
+		assert.Equal(t, http.StatusForbidden, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestCreateSchedule: Coordinator Without Caregiver", func(t *testing.T) {
+		mockClientRepo.EXPECT().GetClientByID(gomock.Any(), dummyClientID).Return(&clientModel.Client{ID: dummyClientID}, nil).Times(1)
+
+		schedule, err := svc.CreateSchedule(coordinatorCtx, model.CreateScheduleRequest{ClientID: dummyClientID, ShiftTime: dummyRequest.ShiftTime, DurationMinutes: 60})
+		assert.Error(t, err)
+		assert.Nil(t, schedule)
+		assert.Equal(t, http.StatusBadRequest, err.(*exceptions.CustomError).Code)
+	})
+}
+
+func TestUpdateSchedule(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyID := uuid.NewString()
+	dummyShiftTime := time.Now().Add(48 * time.Hour)
+	dummyRequest := model.UpdateScheduleRequest{ID: dummyID, ShiftTime: &dummyShiftTime}
+
+	t.Run("TestUpdateSchedule: OK", func(t *testing.T) {
+		// Moving the shift keeps its planned duration of 90 minutes
+		current := time.Now().Add(24 * time.Hour)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming", ShiftTime: current, ShiftEndTime: current.Add(90 * time.Minute), Version: 2}, nil).Times(1)
+		mockScheduleRepo.EXPECT().UpdateSchedule(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req model.UpdateScheduleRequest) error {
+				assert.Equal(t, 2, req.Version)
+				assert.Equal(t, dummyShiftTime, *req.ShiftTime)
+				assert.Equal(t, dummyShiftTime.Add(90*time.Minute), *req.ShiftEndTime)
+				return nil
+			}).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming", ShiftTime: dummyShiftTime}, nil).Times(1)
+
+		schedule, err := svc.UpdateSchedule(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+		assert.Equal(t, dummyShiftTime, schedule.ShiftTime)
+	})
+
+	t.Run("TestUpdateSchedule: New Duration", func(t *testing.T) {
+		current := time.Now().Add(24 * time.Hour)
+		duration := 240
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming", ShiftTime: current, ShiftEndTime: current.Add(time.Hour)}, nil).Times(1)
+		mockScheduleRepo.EXPECT().UpdateSchedule(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req model.UpdateScheduleRequest) error {
+				assert.Equal(t, current.Add(4*time.Hour), *req.ShiftEndTime)
+				return nil
+			}).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming", DurationMinutes: duration}, nil).Times(1)
+
+		schedule, err := svc.UpdateSchedule(context.Background(), model.UpdateScheduleRequest{ID: dummyID, DurationMinutes: &duration})
+		assert.NoError(t, err)
+		assert.Equal(t, duration, schedule.DurationMinutes)
+	})
+
+	t.Run("TestUpdateSchedule: Stale If-Match", func(t *testing.T) {
+		staleVersion := 1
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming", Version: 2}, nil).Times(1)
+
+		schedule, err := svc.UpdateSchedule(context.Background(), model.UpdateScheduleRequest{ID: dummyID, ShiftTime: &dummyShiftTime, IfMatch: &staleVersion})
+		assert.Error(t, err)
+		assert.Nil(t, schedule)
+		assert.Equal(t, exceptions.ErrPreconditionFailed.WithDetails("Schedule ID "+dummyID+" is at version 2, not 1").Error(), err.Error())
+	})
+
+	t.Run("TestUpdateSchedule: Changed By Another Request", func(t *testing.T) {
+		current := time.Now().Add(24 * time.Hour)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming", ShiftTime: current, ShiftEndTime: current.Add(time.Hour)}, nil).Times(1)
+		mockScheduleRepo.EXPECT().UpdateSchedule(gomock.Any(), gomock.Any()).Return(exceptions.ErrConflict).Times(1)
+
+		schedule, err := svc.UpdateSchedule(context.Background(), dummyRequest)
+		assert.Error(t, err)
+		assert.Nil(t, schedule)
+		assert.Equal(t, http.StatusConflict, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestUpdateSchedule: Visit In Progress", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "in-progress"}, nil).Times(1)
+
+		schedule, err := svc.UpdateSchedule(context.Background(), dummyRequest)
+		assert.Error(t, err)
+		assert.Nil(t, schedule)
+		assert.Equal(t, exceptions.ErrConflict.WithDetails("Schedule ID "+dummyID+" is in-progress. Cannot edit.").Error(), err.Error())
+	})
+
+	t.Run("TestUpdateSchedule: Visit Completed", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "completed"}, nil).Times(1)
+
+		schedule, err := svc.UpdateSchedule(context.Background(), dummyRequest)
+		assert.Error(t, err)
+		assert.Nil(t, schedule)
+	})
+
+	t.Run("TestUpdateSchedule: Unknown Caregiver", func(t *testing.T) {
+		caregiverID := uuid.NewString()
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming"}, nil).Times(1)
+		mockCaregiverRepo.EXPECT().GetCaregiverByID(gomock.Any(), caregiverID).Return(nil, exceptions.ErrNotFound).Times(1)
+
+		schedule, err := svc.UpdateSchedule(context.Background(), model.UpdateScheduleRequest{ID: dummyID, CaregiverID: &caregiverID})
+		assert.Error(t, err)
+		assert.Nil(t, schedule)
+	})
+}
+
+func TestCancelSchedule(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyID := uuid.NewString()
+	dummyRequest := model.CancelScheduleRequest{ID: dummyID, Reason: "Client is in hospital"}
+
+	t.Run("TestCancelSchedule: OK", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "upcoming"}, nil).Times(1)
+		mockScheduleRepo.EXPECT().TransitionStatus(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, transition model.StatusTransition) error {
+				assert.Equal(t, model.StatusUpcoming, transition.FromStatus)
+				assert.Equal(t, model.StatusCancelled, transition.ToStatus)
+				assert.Equal(t, "Client is in hospital", *transition.Reason)
+				return nil
+			}).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusCancelled}, nil).Times(1)
+
+		schedule, err := svc.CancelSchedule(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+		assert.Equal(t, model.StatusCancelled, schedule.Status)
+	})
+
+	t.Run("TestCancelSchedule: Missing Reason", func(t *testing.T) {
+		schedule, err := svc.CancelSchedule(context.Background(), model.CancelScheduleRequest{ID: dummyID})
+		assert.Error(t, err)
+		assert.Nil(t, schedule)
+	})
+
+	t.Run("TestCancelSchedule: Already Completed", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: "completed"}, nil).Times(1)
+
+		schedule, err := svc.CancelSchedule(context.Background(), dummyRequest)
+		assert.Error(t, err)
+		assert.Nil(t, schedule)
+		assert.Equal(t, exceptions.ErrConflict.WithDetails("Schedule ID "+dummyID+" cannot move from completed to cancelled").Error(), err.Error())
+	})
+}
+
+func TestReportNoShow(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyID := uuid.NewString()
+	dummyRequest := model.ReportNoShowRequest{ID: dummyID, Reason: "Nobody answered the door"}
+
+	t.Run("TestReportNoShow: OK", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusUpcoming}, nil).Times(1)
+		mockScheduleRepo.EXPECT().TransitionStatus(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, transition model.StatusTransition) error {
+				assert.Equal(t, model.StatusNoShow, transition.ToStatus)
+				assert.Equal(t, "Nobody answered the door", *transition.Reason)
+				return nil
+			}).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusNoShow}, nil).Times(1)
+
+		schedule, err := svc.ReportNoShow(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+		assert.Equal(t, model.StatusNoShow, schedule.Status)
+	})
+
+	t.Run("TestReportNoShow: Already Completed", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusCompleted}, nil).Times(1)
+
+		schedule, err := svc.ReportNoShow(context.Background(), dummyRequest)
+		assert.Error(t, err)
+		assert.Nil(t, schedule)
+		assert.Equal(t, http.StatusConflict, err.(*exceptions.CustomError).Code)
+	})
+}
+
+func TestReopenSchedule(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyID := uuid.NewString()
+	dummyRequest := model.ReopenScheduleRequest{ID: dummyID, Reason: "Clock-out was recorded by mistake"}
+
+	t.Run("TestReopenSchedule: OK", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusCompleted}, nil).Times(1)
+		mockScheduleRepo.EXPECT().TransitionStatus(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, transition model.StatusTransition) error {
+				assert.Equal(t, model.StatusCompleted, transition.FromStatus)
+				assert.Equal(t, model.StatusReopened, transition.ToStatus)
+				return nil
+			}).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusReopened}, nil).Times(1)
+
+		schedule, err := svc.ReopenSchedule(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+		assert.Equal(t, model.StatusReopened, schedule.Status)
+	})
+
+	t.Run("TestReopenSchedule: Upcoming Visit", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID, Status: model.StatusUpcoming}, nil).Times(1)
+
+		schedule, err := svc.ReopenSchedule(context.Background(), dummyRequest)
+		assert.Error(t, err)
+		assert.Nil(t, schedule)
+		assert.Equal(t, model.TransitionConflict{CurrentStatus: model.StatusUpcoming, RequestedStatus: model.StatusReopened}, err.(*exceptions.CustomError).Data)
+	})
+
+	t.Run("TestReopenSchedule: Missing Reason", func(t *testing.T) {
+		schedule, err := svc.ReopenSchedule(context.Background(), model.ReopenScheduleRequest{ID: dummyID})
+		assert.Error(t, err)
+		assert.Nil(t, schedule)
+	})
+}
+
+func TestGetStatusHistory(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyID := uuid.NewString()
+
+	t.Run("TestGetStatusHistory: OK", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetStatusHistory(gomock.Any(), dummyID).Return([]model.StatusTransition{{ScheduleID: dummyID, FromStatus: model.StatusUpcoming, ToStatus: model.StatusInProgress}}, nil).Times(1)
+
+		history, err := svc.GetStatusHistory(context.Background(), dummyID)
+		assert.NoError(t, err)
+		assert.Len(t, history, 1)
+	})
+
+	t.Run("TestGetStatusHistory: Schedule Not Found", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(nil, exceptions.ErrNotFound).Times(1)
+
+		history, err := svc.GetStatusHistory(context.Background(), dummyID)
+		assert.Error(t, err)
+		assert.Nil(t, history)
+	})
+}
+
+func TestGetHistory(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyID := uuid.NewString()
+
+	t.Run("TestGetHistory: OK", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(&model.Schedule{ID: dummyID}, nil).Times(1)
+		mockAuditRepo.EXPECT().GetScheduleHistory(gomock.Any(), dummyID).Return([]auditModel.Event{
+			{ScheduleID: dummyID, EntityType: auditModel.EntitySchedule, EntityID: dummyID, Action: auditModel.ActionScheduleCreated},
+			{ScheduleID: dummyID, EntityType: auditModel.EntitySchedule, EntityID: dummyID, Action: auditModel.ActionVisitStarted},
+		}, nil).Times(1)
+
+		history, err := svc.GetHistory(context.Background(), dummyID)
+		assert.NoError(t, err)
+		assert.Len(t, history, 2)
+		assert.Equal(t, auditModel.ActionVisitStarted, history[1].Action)
+	})
+
+	t.Run("TestGetHistory: Schedule Not Found", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyID).Return(nil, exceptions.ErrNotFound).Times(1)
+
+		history, err := svc.GetHistory(context.Background(), dummyID)
+		assert.Error(t, err)
+		assert.Nil(t, history)
+	})
+
+	t.Run("TestGetHistory: Invalid ID", func(t *testing.T) {
+		history, err := svc.GetHistory(context.Background(), "not-a-uuid")
+		assert.Error(t, err)
+		assert.Nil(t, history)
+		assert.Equal(t, http.StatusBadRequest, err.(*exceptions.CustomError).Code)
+	})
+}
+
+func TestMarkOverdueSchedulesMissed(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	t.Run("TestMarkOverdueSchedulesMissed: OK", func(t *testing.T) {
+		dummySchedules := []model.Schedule{{ID: uuid.NewString(), Status: model.StatusUpcoming, Version: 1}, {ID: uuid.NewString(), Status: model.StatusUpcoming, Version: 3}}
+		mockScheduleRepo.EXPECT().GetOverdueSchedules(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, shiftBefore time.Time) ([]model.Schedule, error) {
+				assert.WithinDuration(t, time.Now().Add(-time.Hour), shiftBefore, time.Minute)
+				return dummySchedules, nil
+			}).Times(1)
+		for _, schedule := range dummySchedules {
+			isForID := gomock.Cond(func(transition model.StatusTransition) bool { return transition.ScheduleID == schedule.ID })
+			mockScheduleRepo.EXPECT().TransitionStatus(gomock.Any(), isForID).
+				DoAndReturn(func(_ context.Context, transition model.StatusTransition) error {
+					assert.Equal(t, model.StatusMissed, transition.ToStatus)
+					assert.Equal(t, schedule.Version+1, transition.Version)
+					assert.Equal(t, "No clock-in within 1h0m0s of the shift start", *transition.Reason)
+					assert.Nil(t, transition.ChangedBy)
+					return nil
+				}).Times(1)
+		}
+
+		marked, err := svc.MarkOverdueSchedulesMissed(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, marked)
+	})
+
+	t.Run("TestMarkOverdueSchedulesMissed: Continues After Update Error", func(t *testing.T) {
+		dummySchedules := []model.Schedule{{ID: uuid.NewString(), Status: model.StatusUpcoming, Version: 1}, {ID: uuid.NewString(), Status: model.StatusUpcoming, Version: 1}}
+		mockScheduleRepo.EXPECT().GetOverdueSchedules(gomock.Any(), gomock.Any()).Return(dummySchedules, nil).Times(1)
+		gomock.InOrder(
+			mockScheduleRepo.EXPECT().TransitionStatus(gomock.Any(), gomock.Any()).Return(assert.AnError).Times(1),
+			mockScheduleRepo.EXPECT().TransitionStatus(gomock.Any(), gomock.Any()).Return(nil).Times(1),
+		)
+
+		marked, err := svc.MarkOverdueSchedulesMissed(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, marked)
+	})
+
+	t.Run("TestMarkOverdueSchedulesMissed: Repository Error", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetOverdueSchedules(gomock.Any(), gomock.Any()).Return(nil, assert.AnError).Times(1)
+
+		marked, err := svc.MarkOverdueSchedulesMissed(context.Background())
+		assert.Error(t, err)
+		assert.Zero(t, marked)
+	})
+}
+
+func TestRaiseMissingClockOuts(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	t.Run("TestRaiseMissingClockOuts: OK", func(t *testing.T) {
+		mockExceptionRepo.EXPECT().RaiseMissingClockOuts(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, startedBefore time.Time) (int64, error) {
+				assert.WithinDuration(t, time.Now().Add(-12*time.Hour), startedBefore, time.Minute)
+				return 2, nil
+			}).Times(1)
+
+		raised, err := svc.RaiseMissingClockOuts(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), raised)
+	})
+
+	t.Run("TestRaiseMissingClockOuts: Repository Error", func(t *testing.T) {
+		mockExceptionRepo.EXPECT().RaiseMissingClockOuts(gomock.Any(), gomock.Any()).Return(int64(0), assert.AnError).Times(1)
+
+		raised, err := svc.RaiseMissingClockOuts(context.Background())
+		assert.Error(t, err)
+		assert.Zero(t, raised)
+	})
+}
+
+// strokes returns n signature strokes of the given number of points, zigzagging across width pixels
+// signaturePNG encodes a blank image of the given size as PNG
+func signaturePNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, width, height)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func strokes(n, points int, width float64) [][]model.StrokePoint {
+	result := make([][]model.StrokePoint, n)
+	for i := range result {
+		for j := 0; j < points; j++ {
+			result[i] = append(result[i], model.StrokePoint{X: float64(j%2) * width, Y: 10})
+		}
+	}
+	return result
 }
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/policy"
+	"mini-evv-logger-backend/responses"
+	"mini-evv-logger-backend/src/domains/series/model"
+	"mini-evv-logger-backend/src/domains/series/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SeriesController handles HTTP requests for recurring schedule series
+type SeriesController struct {
+	svc service.SeriesService
+}
+
+// NewSeriesController creates a new SeriesController
+func NewSeriesController(svc service.SeriesService) *SeriesController {
+	return &SeriesController{svc: svc}
+}
+
+// Routes sets up the API endpoints for schedule series
+func (sc *SeriesController) Routes(app fiber.Router) {
+	seriesRoutes := app.Group("/schedule-series")
+	seriesRoutes.Get("/", policy.Require(policy.ManageSchedule, nil), sc.GetSeries)
+	seriesRoutes.Post("/", policy.Require(policy.ManageSchedule, nil), sc.CreateSeries)
+	seriesRoutes.Get("/:id", policy.Require(policy.ManageSchedule, sc.seriesResource), sc.GetSeriesDetails)
+	seriesRoutes.Post("/:id/split", policy.Require(policy.ManageSchedule, sc.seriesResource), sc.SplitSeries)
+}
+
+// seriesResource resolves the ownership of the series in the :id route parameter
+func (sc *SeriesController) seriesResource(c *fiber.Ctx) (*policy.Resource, error) {
+	ownership, err := sc.svc.GetSeriesOwnership(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return nil, err
+	}
+	return &policy.Resource{CaregiverID: ownership.CaregiverID, BranchID: ownership.BranchID}, nil
+}
+
+// GetSeries handles fetching all schedule series
+func (sc *SeriesController) GetSeries(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	filter := model.FilterSeriesRequest{}
+
+	err := c.QueryParser(&filter)
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+	}
+
+	series, err := sc.svc.GetAllSeries(ctx, filter)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, series, "Schedule series retrieved successfully")
+}
+
+// GetSeriesDetails handles fetching a single series
+func (sc *SeriesController) GetSeriesDetails(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Series ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	series, err := sc.svc.GetSeriesByID(ctx, id)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, series, "Schedule series retrieved successfully")
+}
+
+// CreateSeries handles creating a new recurring visit
+func (sc *SeriesController) CreateSeries(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var req model.CreateSeriesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	series, err := sc.svc.CreateSeries(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.Created(c, series, "Schedule series created successfully")
+}
+
+// SplitSeries handles changing a series from one occurrence onwards
+func (sc *SeriesController) SplitSeries(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Series ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	var req model.SplitSeriesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	req.ID = id // Set the ID from the URL parameter
+
+	series, err := sc.svc.SplitSeries(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.Created(c, series, "Schedule series split successfully")
+}
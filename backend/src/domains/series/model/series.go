@@ -0,0 +1,82 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+)
+
+// ScheduleSeries is a recurring visit that is expanded into individual schedules
+type ScheduleSeries struct {
+	ID               string         `json:"id" db:"id"`
+	ClientID         string         `json:"client_id" db:"client_id"`
+	CaregiverID      *string        `json:"caregiver_id" db:"caregiver_id"`           // Caregiver assigned to every occurrence, NULL when unassigned
+	RRule            string         `json:"rrule" db:"rrule"`                         // iCalendar recurrence rule, e.g. "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"
+	StartsAt         time.Time      `json:"starts_at" db:"starts_at"`                 // First occurrence; later ones keep its local time in the client's timezone
+	DurationMinutes  int            `json:"duration_minutes" db:"duration_minutes"`   // Length of each visit
+	TaskTemplate     pq.StringArray `json:"task_template" db:"task_template"`         // Task descriptions copied onto every occurrence
+	EndsAt           *time.Time     `json:"ends_at" db:"ends_at"`                     // No occurrences at or after this time, NULL while open-ended
+	GeneratedThrough *time.Time     `json:"generated_through" db:"generated_through"` // Occurrences up to this time have been created
+	Timezone         string         `json:"timezone" db:"timezone"`                   // Joined from clients
+	CreatedAt        time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// Occurrence is a visit of a series that is about to be created
+type Occurrence struct {
+	Time       time.Time // Shift time, also stored as occurrence_time
+	Tasks      []string  // Task descriptions the visit starts with, in order
+	CarePlanID *string   // Care plan some of the tasks were filled from, nil when the client has none
+}
+
+// SeriesOwnership identifies who a series is assigned to, for authorization checks
+type SeriesOwnership struct {
+	CaregiverID *string `db:"caregiver_id"`
+	BranchID    *string `db:"branch_id"`
+}
+
+// FilterSeriesRequest defines the query parameters for listing series
+type FilterSeriesRequest struct {
+	ClientID string `query:"client_id" validate:"omitempty,uuid"`
+	BranchID string `query:"-"` // Set from the principal, only series assigned to caregivers of this branch
+}
+
+func (r *FilterSeriesRequest) String() string {
+	return fmt.Sprintf("FilterSeriesRequest{ClientID: %s, BranchID: %s}", r.ClientID, r.BranchID)
+}
+
+// CreateSeriesRequest defines the request body for creating a recurring visit
+type CreateSeriesRequest struct {
+	ClientID        string    `json:"client_id" validate:"required,uuid"`
+	CaregiverID     *string   `json:"caregiver_id" validate:"omitempty,uuid"`
+	RRule           string    `json:"rrule" validate:"required,max=500"`
+	StartsAt        time.Time `json:"starts_at" validate:"required"`
+	DurationMinutes int       `json:"duration_minutes" validate:"required,min=15,max=1440"`
+	TaskTemplate    []string  `json:"task_template" validate:"max=50,dive,required,max=1000"`
+}
+
+// SplitSeriesRequest changes a series from one occurrence onwards ("this and following").
+// Nil fields keep the value of the current series.
+type SplitSeriesRequest struct {
+	ID              string     `json:"-" validate:"required,uuid"`
+	From            time.Time  `json:"from" validate:"required"` // Occurrence time of the first occurrence to change
+	CaregiverID     *string    `json:"caregiver_id" validate:"omitempty,uuid"`
+	RRule           *string    `json:"rrule" validate:"omitempty,min=1,max=500"`
+	StartsAt        *time.Time `json:"starts_at"` // First occurrence of the new series, defaults to from
+	DurationMinutes *int       `json:"duration_minutes" validate:"omitempty,min=15,max=1440"`
+	TaskTemplate    []string   `json:"task_template" validate:"omitempty,max=50,dive,required,max=1000"`
+}
+
+func (r *FilterSeriesRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *CreateSeriesRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *SplitSeriesRequest) Validate() error {
+	return validator.New().Struct(r)
+}
@@ -0,0 +1,365 @@
+package repository
+
+import (
+	"context" // Import context
+	"database/sql"
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	auditModel "mini-evv-logger-backend/src/domains/audit/model"
+	auditRepo "mini-evv-logger-backend/src/domains/audit/repository"
+	"mini-evv-logger-backend/src/domains/series/model"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=./series_repo.go -destination=../mocks/repository/series_repo.go -package=mocks
+
+// seriesColumns selects a series together with the timezone its rule is expanded in
+var seriesColumns = []string{"ss.id", "ss.client_id", "ss.caregiver_id", "ss.rrule", "ss.starts_at", "ss.duration_minutes",
+	"ss.task_template", "ss.ends_at", "ss.generated_through", "cl.timezone", "ss.created_at", "ss.updated_at"}
+
+// SeriesRepository defines the interface for schedule series database operations
+type SeriesRepository interface {
+	GetSeries(ctx context.Context, filter model.FilterSeriesRequest) ([]model.ScheduleSeries, error)
+	GetSeriesByID(ctx context.Context, id string) (*model.ScheduleSeries, error)
+	GetSeriesOwnership(ctx context.Context, id string) (*model.SeriesOwnership, error)
+	GetSeriesDueForGeneration(ctx context.Context, horizon time.Time) ([]model.ScheduleSeries, error)
+	CreateSeries(ctx context.Context, series model.ScheduleSeries) error
+	SplitSeries(ctx context.Context, id string, from time.Time, next model.ScheduleSeries) (int64, error)
+	InsertOccurrences(ctx context.Context, series model.ScheduleSeries, occurrences []model.Occurrence, generatedThrough time.Time) (int, error)
+}
+
+// seriesRepositoryImpl implements the SeriesRepository interface
+type seriesRepositoryImpl struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+// NewSeriesRepository creates a new SeriesRepository (returns interface)
+func NewSeriesRepository(db *sqlx.DB, logger zerolog.Logger) SeriesRepository {
+	return &seriesRepositoryImpl{db: db, logger: logger}
+}
+
+// GetSeries fetches the series matching the filter, ordered by start
+func (r *seriesRepositoryImpl) GetSeries(ctx context.Context, filter model.FilterSeriesRequest) ([]model.ScheduleSeries, error) {
+	var series []model.ScheduleSeries
+	qb := squirrel.Select(seriesColumns...).
+		From("schedule_series ss").
+		Join("clients cl ON cl.id = ss.client_id").
+		OrderBy("ss.starts_at ASC").
+		PlaceholderFormat(squirrel.Dollar)
+
+	if filter.ClientID != "" {
+		qb = qb.Where(squirrel.Eq{"ss.client_id": filter.ClientID})
+	}
+	if filter.BranchID != "" {
+		// Only return the series assigned to caregivers of the requested branch
+		qb = qb.Where(squirrel.Expr("ss.caregiver_id IN (SELECT id FROM caregivers WHERE branch_id = ?)", filter.BranchID))
+	}
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to build SQL query for GetSeries")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.SelectContext(ctx, &series, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return []model.ScheduleSeries{}, nil
+		}
+		r.logger.Error().Err(err).Msg("Failed to execute SQL query for GetSeries")
+		return nil, exceptions.ErrInternalError
+	}
+	return series, nil
+}
+
+// GetSeriesByID fetches a single series by ID
+func (r *seriesRepositoryImpl) GetSeriesByID(ctx context.Context, id string) (*model.ScheduleSeries, error) {
+	var series model.ScheduleSeries
+	qb := squirrel.Select(seriesColumns...).
+		From("schedule_series ss").
+		Join("clients cl ON cl.id = ss.client_id").
+		Where(squirrel.Eq{"ss.id": id}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("series_id", id).Msg("Failed to build SQL query for GetSeriesByID")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &series, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.Warn().Str("series_id", id).Msg("Schedule series not found in database")
+			return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Schedule series with ID %s not found", id))
+		}
+		r.logger.Error().Err(err).Str("series_id", id).Msg("Failed to execute SQL query for GetSeriesByID")
+		return nil, exceptions.ErrInternalError
+	}
+	return &series, nil
+}
+
+// GetSeriesOwnership fetches the assigned caregiver and their branch for a series
+func (r *seriesRepositoryImpl) GetSeriesOwnership(ctx context.Context, id string) (*model.SeriesOwnership, error) {
+	var ownership model.SeriesOwnership
+	qb := squirrel.Select("ss.caregiver_id", "c.branch_id").
+		From("schedule_series ss").
+		LeftJoin("caregivers c ON c.id = ss.caregiver_id").
+		Where(squirrel.Eq{"ss.id": id}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("series_id", id).Msg("Failed to build SQL query for GetSeriesOwnership")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &ownership, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.Warn().Str("series_id", id).Msg("Schedule series not found in database")
+			return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Schedule series with ID %s not found", id))
+		}
+		r.logger.Error().Err(err).Str("series_id", id).Msg("Failed to execute SQL query for GetSeriesOwnership")
+		return nil, exceptions.ErrInternalError
+	}
+	return &ownership, nil
+}
+
+// GetSeriesDueForGeneration fetches the series that have not been expanded up to horizon
+// and have not been generated past their end yet
+func (r *seriesRepositoryImpl) GetSeriesDueForGeneration(ctx context.Context, horizon time.Time) ([]model.ScheduleSeries, error) {
+	var series []model.ScheduleSeries
+	qb := squirrel.Select(seriesColumns...).
+		From("schedule_series ss").
+		Join("clients cl ON cl.id = ss.client_id").
+		Where(squirrel.Or{squirrel.Eq{"ss.generated_through": nil}, squirrel.Lt{"ss.generated_through": horizon}}).
+		Where(squirrel.Or{squirrel.Eq{"ss.ends_at": nil}, squirrel.Eq{"ss.generated_through": nil}, squirrel.Expr("ss.generated_through < ss.ends_at")}).
+		OrderBy("ss.starts_at ASC").
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to build SQL query for GetSeriesDueForGeneration")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.SelectContext(ctx, &series, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return []model.ScheduleSeries{}, nil
+		}
+		r.logger.Error().Err(err).Msg("Failed to execute SQL query for GetSeriesDueForGeneration")
+		return nil, exceptions.ErrInternalError
+	}
+	return series, nil
+}
+
+// CreateSeries inserts a new series. Occurrences are created separately by InsertOccurrences.
+func (r *seriesRepositoryImpl) CreateSeries(ctx context.Context, series model.ScheduleSeries) error {
+	sqlQuery, args, err := insertSeriesQuery(series).ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("series_id", series.ID).Msg("Failed to build SQL query for CreateSeries")
+		return exceptions.ErrInternalError
+	}
+
+	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("series_id", series.ID).Msg("Failed to execute SQL query for CreateSeries")
+		return exceptions.ErrInternalError
+	}
+	return nil
+}
+
+// SplitSeries ends the series at from and inserts next to take over from there, in one transaction.
+// Upcoming occurrences at or after from are removed unless they were edited individually; visits
+// that already started, were cancelled or were edited stay on the original series. Removed
+// occurrences are recorded in the audit trail. It returns the number of occurrences removed.
+func (r *seriesRepositoryImpl) SplitSeries(ctx context.Context, id string, from time.Time, next model.ScheduleSeries) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.Error().Err(err).Str("series_id", id).Msg("Failed to begin transaction for SplitSeries")
+		return 0, exceptions.ErrInternalError
+	}
+	defer tx.Rollback() // No-op once committed
+
+	// 1. End the current series, unless a concurrent split or end already did at or before from
+	sqlQuery, args, err := squirrel.Update("schedule_series").
+		Set("ends_at", from).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Or{squirrel.Eq{"ends_at": nil}, squirrel.Gt{"ends_at": from}}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("series_id", id).Msg("Failed to build SQL query for SplitSeries")
+		return 0, exceptions.ErrInternalError
+	}
+	result, err := tx.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("series_id", id).Msg("Failed to execute SQL query for SplitSeries")
+		return 0, exceptions.ErrInternalError
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error().Err(err).Str("series_id", id).Msg("Failed to read affected rows for SplitSeries")
+		return 0, exceptions.ErrInternalError
+	}
+	if affected == 0 {
+		r.logger.Warn().Str("series_id", id).Msg("Conditional update for SplitSeries matched no row")
+		return 0, exceptions.ErrConflict.WithDetails(fmt.Sprintf("Schedule series ID %s was changed by another request. Reload it and try again.", id))
+	}
+
+	// 2. Remove the occurrences the new series replaces, keeping their rows in the audit trail
+	sqlQuery, args, err = squirrel.Delete("schedules").
+		Where(squirrel.Eq{"series_id": id, "status": "upcoming", "is_detached": false}).
+		Where(squirrel.GtOrEq{"occurrence_time": from}).
+		Suffix("RETURNING id, to_jsonb(schedules) AS row").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("series_id", id).Msg("Failed to build SQL query for SplitSeries")
+		return 0, exceptions.ErrInternalError
+	}
+	var deleted []struct {
+		ID  string `db:"id"`
+		Row []byte `db:"row"`
+	}
+	err = tx.SelectContext(ctx, &deleted, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("series_id", id).Msg("Failed to execute SQL query for SplitSeries")
+		return 0, exceptions.ErrInternalError
+	}
+	for _, schedule := range deleted {
+		err = auditRepo.Append(ctx, tx, auditModel.Event{ScheduleID: schedule.ID, EntityType: auditModel.EntitySchedule, EntityID: schedule.ID,
+			Action: auditModel.ActionScheduleDeleted, Before: schedule.Row})
+		if err != nil {
+			r.logger.Error().Err(err).Str("schedule_id", schedule.ID).Msg("Failed to append audit event for SplitSeries")
+			return 0, exceptions.ErrInternalError
+		}
+	}
+
+	// 3. Insert the series that takes over
+	sqlQuery, args, err = insertSeriesQuery(next).ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("series_id", next.ID).Msg("Failed to build SQL query for SplitSeries")
+		return 0, exceptions.ErrInternalError
+	}
+	_, err = tx.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("series_id", next.ID).Msg("Failed to execute SQL query for SplitSeries")
+		return 0, exceptions.ErrInternalError
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error().Err(err).Str("series_id", id).Msg("Failed to commit transaction for SplitSeries")
+		return 0, exceptions.ErrInternalError
+	}
+	return int64(len(deleted)), nil
+}
+
+// InsertOccurrences creates an upcoming schedule, with the tasks of the occurrence, for every
+// occurrence that does not exist yet and records how far the series has been generated, in one
+// transaction, and records each created schedule in the audit trail. Occurrences that already
+// exist, even if cancelled or edited, are left untouched.
+// It returns the number of schedules created.
+func (r *seriesRepositoryImpl) InsertOccurrences(ctx context.Context, series model.ScheduleSeries, occurrences []model.Occurrence, generatedThrough time.Time) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.Error().Err(err).Str("series_id", series.ID).Msg("Failed to begin transaction for InsertOccurrences")
+		return 0, exceptions.ErrInternalError
+	}
+	defer tx.Rollback() // No-op once committed
+
+	created := 0
+	for _, occurrence := range occurrences {
+		sqlQuery, args, err := squirrel.Insert("schedules").
+			Columns("caregiver_id", "client_id", "shift_time", "shift_end_time", "status", "series_id", "occurrence_time", "care_plan_id").
+			Values(series.CaregiverID, series.ClientID, occurrence.Time, occurrence.Time.Add(time.Duration(series.DurationMinutes)*time.Minute),
+				"upcoming", series.ID, occurrence.Time, occurrence.CarePlanID).
+			Suffix("ON CONFLICT (series_id, occurrence_time) DO NOTHING RETURNING id").
+			PlaceholderFormat(squirrel.Dollar).
+			ToSql()
+		if err != nil {
+			r.logger.Error().Err(err).Str("series_id", series.ID).Msg("Failed to build SQL query for InsertOccurrences")
+			return 0, exceptions.ErrInternalError
+		}
+
+		var scheduleID string
+		err = tx.QueryRowxContext(ctx, sqlQuery, args...).Scan(&scheduleID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				continue // Already generated
+			}
+			r.logger.Error().Err(err).Str("series_id", series.ID).Time("occurrence_time", occurrence.Time).Msg("Failed to execute SQL query for InsertOccurrences")
+			return 0, exceptions.ErrInternalError
+		}
+		created++
+
+		if len(occurrence.Tasks) > 0 {
+			qb := squirrel.Insert("tasks").
+				Columns("schedule_id", "description", "position").
+				PlaceholderFormat(squirrel.Dollar)
+			for i, description := range occurrence.Tasks {
+				qb = qb.Values(scheduleID, description, i+1)
+			}
+			sqlQuery, args, err = qb.ToSql()
+			if err != nil {
+				r.logger.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to build SQL query for InsertOccurrences")
+				return 0, exceptions.ErrInternalError
+			}
+			_, err = tx.ExecContext(ctx, sqlQuery, args...)
+			if err != nil {
+				r.logger.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to execute SQL query for InsertOccurrences")
+				return 0, exceptions.ErrInternalError
+			}
+		}
+
+		after, err := auditRepo.Snapshot(ctx, tx, "schedules", scheduleID)
+		if err != nil {
+			r.logger.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to read schedule after InsertOccurrences")
+			return 0, exceptions.ErrInternalError
+		}
+		err = auditRepo.Append(ctx, tx, auditModel.Event{ScheduleID: scheduleID, EntityType: auditModel.EntitySchedule, EntityID: scheduleID,
+			Action: auditModel.ActionScheduleCreated, After: after})
+		if err != nil {
+			r.logger.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to append audit event for InsertOccurrences")
+			return 0, exceptions.ErrInternalError
+		}
+	}
+
+	sqlQuery, args, err := squirrel.Update("schedule_series").
+		Set("generated_through", generatedThrough).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": series.ID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("series_id", series.ID).Msg("Failed to build SQL query for InsertOccurrences")
+		return 0, exceptions.ErrInternalError
+	}
+	_, err = tx.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("series_id", series.ID).Msg("Failed to execute SQL query for InsertOccurrences")
+		return 0, exceptions.ErrInternalError
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error().Err(err).Str("series_id", series.ID).Msg("Failed to commit transaction for InsertOccurrences")
+		return 0, exceptions.ErrInternalError
+	}
+	return created, nil
+}
+
+// insertSeriesQuery builds the INSERT statement for a new series
+func insertSeriesQuery(series model.ScheduleSeries) squirrel.InsertBuilder {
+	return squirrel.Insert("schedule_series").
+		Columns("id", "client_id", "caregiver_id", "rrule", "starts_at", "duration_minutes", "task_template").
+		Values(series.ID, series.ClientID, series.CaregiverID, series.RRule, series.StartsAt, series.DurationMinutes, series.TaskTemplate).
+		PlaceholderFormat(squirrel.Dollar)
+}
@@ -0,0 +1,261 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	pkgmock "mini-evv-logger-backend/pkg_mock"
+	"mini-evv-logger-backend/src/domains/series/model"
+	"mini-evv-logger-backend/src/domains/series/repository"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	dbMock   *sql.DB
+	sqlxMock *sqlx.DB
+	mockSQL  sqlmock.Sqlmock
+	repo     repository.SeriesRepository
+)
+
+func initMocks(t *testing.T) {
+	var err error
+	dbMock, mockSQL, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	// Wrap sqlmock in sqlx.DB
+	sqlxMock = sqlx.NewDb(dbMock, "sqlmock")
+	repo = repository.NewSeriesRepository(sqlxMock, pkgmock.InitMockLogger())
+}
+
+func TestGetSeriesByID(t *testing.T) {
+	initMocks(t)
+
+	dummyID := uuid.NewString()
+	query := `SELECT ss.id, ss.client_id, ss.caregiver_id, ss.rrule, ss.starts_at, ss.duration_minutes, ss.task_template, ss.ends_at, ss.generated_through, cl.timezone, ss.created_at, ss.updated_at FROM schedule_series ss JOIN clients cl ON cl.id = ss.client_id WHERE ss.id = $1`
+
+	t.Run("TestGetSeriesByID: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "client_id", "rrule", "starts_at", "duration_minutes", "task_template", "timezone"}).
+				AddRow(dummyID, uuid.NewString(), "FREQ=DAILY", time.Now(), 60, "{\"Prepare lunch\",\"Medication reminder\"}", "America/Chicago"))
+
+		series, err := repo.GetSeriesByID(context.Background(), dummyID)
+		assert.Nil(t, err)
+		assert.Equal(t, dummyID, series.ID)
+		assert.Equal(t, []string{"Prepare lunch", "Medication reminder"}, []string(series.TaskTemplate))
+	})
+
+	t.Run("TestGetSeriesByID: No Rows", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnError(sql.ErrNoRows)
+
+		series, err := repo.GetSeriesByID(context.Background(), dummyID)
+		assert.Nil(t, series)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 404: Resource not found - Schedule series with ID "+dummyID+" not found", err.Error())
+	})
+
+	t.Run("TestGetSeriesByID: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnError(sql.ErrConnDone)
+
+		series, err := repo.GetSeriesByID(context.Background(), dummyID)
+		assert.Nil(t, series)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+	})
+}
+
+func TestGetSeriesDueForGeneration(t *testing.T) {
+	initMocks(t)
+
+	horizon := time.Now().Add(28 * 24 * time.Hour)
+	query := `FROM schedule_series ss JOIN clients cl ON cl.id = ss.client_id WHERE (ss.generated_through IS NULL OR ss.generated_through < $1) AND (ss.ends_at IS NULL OR ss.generated_through IS NULL OR ss.generated_through < ss.ends_at) ORDER BY ss.starts_at ASC`
+
+	t.Run("TestGetSeriesDueForGeneration: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(horizon).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.NewString()).AddRow(uuid.NewString()))
+
+		series, err := repo.GetSeriesDueForGeneration(context.Background(), horizon)
+		assert.Nil(t, err)
+		assert.Len(t, series, 2)
+	})
+
+	t.Run("TestGetSeriesDueForGeneration: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		series, err := repo.GetSeriesDueForGeneration(context.Background(), horizon)
+		assert.Nil(t, series)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+	})
+}
+
+func TestCreateSeries(t *testing.T) {
+	initMocks(t)
+
+	dummySeries := model.ScheduleSeries{ID: uuid.NewString(), ClientID: uuid.NewString(), RRule: "FREQ=WEEKLY;BYDAY=MO,WE,FR", StartsAt: time.Now(), DurationMinutes: 90}
+	query := `INSERT INTO schedule_series (id,client_id,caregiver_id,rrule,starts_at,duration_minutes,task_template) VALUES ($1,$2,$3,$4,$5,$6,$7)`
+
+	t.Run("TestCreateSeries: OK", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := repo.CreateSeries(context.Background(), dummySeries)
+		assert.Nil(t, err)
+	})
+
+	t.Run("TestCreateSeries: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.CreateSeries(context.Background(), dummySeries)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+	})
+}
+
+func TestSplitSeries(t *testing.T) {
+	initMocks(t)
+
+	dummyID := uuid.NewString()
+	from := time.Now().Add(7 * 24 * time.Hour)
+	next := model.ScheduleSeries{ID: uuid.NewString(), ClientID: uuid.NewString(), RRule: "FREQ=DAILY", StartsAt: from, DurationMinutes: 60}
+
+	endQuery := `UPDATE schedule_series SET ends_at = $1, updated_at = $2 WHERE id = $3 AND (ends_at IS NULL OR ends_at > $4)`
+	deleteQuery := `DELETE FROM schedules WHERE is_detached = $1 AND series_id = $2 AND status = $3 AND occurrence_time >= $4 RETURNING id, to_jsonb(schedules) AS row`
+	insertQuery := `INSERT INTO schedule_series`
+	auditQuery := `INSERT INTO audit_events (schedule_id,entity_type,entity_id,action,actor_id,before,after,ip_address,user_agent) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`
+
+	t.Run("TestSplitSeries: OK", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectExec(regexp.QuoteMeta(endQuery)).
+			WithArgs(from, sqlmock.AnyArg(), dummyID, from).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		deletedIDs := []string{uuid.NewString(), uuid.NewString(), uuid.NewString()}
+		rows := sqlmock.NewRows([]string{"id", "row"})
+		for _, deletedID := range deletedIDs {
+			rows.AddRow(deletedID, []byte(`{"id":"`+deletedID+`","status":"upcoming"}`))
+		}
+		mockSQL.ExpectQuery(regexp.QuoteMeta(deleteQuery)).
+			WithArgs(false, dummyID, "upcoming", from).
+			WillReturnRows(rows)
+		// Each removed occurrence keeps its last state in the audit trail
+		for _, deletedID := range deletedIDs {
+			beforeJSON := `{"id":"` + deletedID + `","status":"upcoming"}`
+			mockSQL.ExpectExec(regexp.QuoteMeta(auditQuery)).
+				WithArgs(deletedID, "schedule", deletedID, "schedule_deleted", nil, &beforeJSON, nil, nil, nil).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+		}
+		mockSQL.ExpectExec(regexp.QuoteMeta(insertQuery)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mockSQL.ExpectCommit()
+
+		removed, err := repo.SplitSeries(context.Background(), dummyID, from, next)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), removed)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestSplitSeries: Already Ended", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectExec(regexp.QuoteMeta(endQuery)).
+			WithArgs(from, sqlmock.AnyArg(), dummyID, from).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mockSQL.ExpectRollback()
+
+		removed, err := repo.SplitSeries(context.Background(), dummyID, from, next)
+		assert.NotNil(t, err)
+		assert.Equal(t, int64(0), removed)
+		assert.Equal(t, fmt.Sprintf("Error 409: Conflict - Schedule series ID %s was changed by another request. Reload it and try again.", dummyID), err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestSplitSeries: Rolls Back On Error", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectExec(regexp.QuoteMeta(endQuery)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectQuery(regexp.QuoteMeta(deleteQuery)).
+			WillReturnError(sql.ErrConnDone)
+		mockSQL.ExpectRollback()
+
+		removed, err := repo.SplitSeries(context.Background(), dummyID, from, next)
+		assert.NotNil(t, err)
+		assert.Equal(t, int64(0), removed)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+}
+
+func TestInsertOccurrences(t *testing.T) {
+	initMocks(t)
+
+	dummySeries := model.ScheduleSeries{ID: uuid.NewString(), ClientID: uuid.NewString(), DurationMinutes: 90}
+	carePlanID := uuid.NewString()
+	first := model.Occurrence{Time: time.Now().Add(24 * time.Hour), Tasks: []string{"Prepare lunch", "Light housekeeping"}, CarePlanID: &carePlanID}
+	second := model.Occurrence{Time: time.Now().Add(48 * time.Hour)}
+	generatedThrough := time.Now().Add(28 * 24 * time.Hour)
+
+	scheduleQuery := `INSERT INTO schedules (caregiver_id,client_id,shift_time,shift_end_time,status,series_id,occurrence_time,care_plan_id) VALUES ($1,$2,$3,$4,$5,$6,$7,$8) ON CONFLICT (series_id, occurrence_time) DO NOTHING RETURNING id`
+	taskQuery := `INSERT INTO tasks (schedule_id,description,position) VALUES ($1,$2,$3),($4,$5,$6)`
+	generatedQuery := `UPDATE schedule_series SET generated_through = $1, updated_at = $2 WHERE id = $3`
+	snapshotQuery := `SELECT to_jsonb(t) FROM schedules t WHERE t.id = $1 FOR UPDATE`
+	auditQuery := `INSERT INTO audit_events (schedule_id,entity_type,entity_id,action,actor_id,before,after,ip_address,user_agent) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`
+
+	t.Run("TestInsertOccurrences: OK", func(t *testing.T) {
+		scheduleID := uuid.NewString()
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectQuery(regexp.QuoteMeta(scheduleQuery)).
+			WithArgs(nil, dummySeries.ClientID, first.Time, first.Time.Add(90*time.Minute), "upcoming", dummySeries.ID, first.Time, &carePlanID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(scheduleID))
+		mockSQL.ExpectExec(regexp.QuoteMeta(taskQuery)).
+			WithArgs(scheduleID, "Prepare lunch", 1, scheduleID, "Light housekeeping", 2).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		afterJSON := `{"id":"` + scheduleID + `","status":"upcoming"}`
+		mockSQL.ExpectQuery(regexp.QuoteMeta(snapshotQuery)).
+			WithArgs(scheduleID).
+			WillReturnRows(sqlmock.NewRows([]string{"to_jsonb"}).AddRow([]byte(afterJSON)))
+		mockSQL.ExpectExec(regexp.QuoteMeta(auditQuery)).
+			WithArgs(scheduleID, "schedule", scheduleID, "schedule_created", nil, nil, &afterJSON, nil, nil).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		// The second occurrence already exists
+		mockSQL.ExpectQuery(regexp.QuoteMeta(scheduleQuery)).
+			WithArgs(nil, dummySeries.ClientID, second.Time, second.Time.Add(90*time.Minute), "upcoming", dummySeries.ID, second.Time, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mockSQL.ExpectExec(regexp.QuoteMeta(generatedQuery)).
+			WithArgs(generatedThrough, sqlmock.AnyArg(), dummySeries.ID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockSQL.ExpectCommit()
+
+		created, err := repo.InsertOccurrences(context.Background(), dummySeries, []model.Occurrence{first, second}, generatedThrough)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, created)
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestInsertOccurrences: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectQuery(regexp.QuoteMeta(scheduleQuery)).
+			WillReturnError(sql.ErrConnDone)
+		mockSQL.ExpectRollback()
+
+		created, err := repo.InsertOccurrences(context.Background(), dummySeries, []model.Occurrence{first}, generatedThrough)
+		assert.NotNil(t, err)
+		assert.Equal(t, 0, created)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+		assert.Nil(t, mockSQL.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,408 @@
+package service
+
+import (
+	"context" // Import context
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	caregiverRepo "mini-evv-logger-backend/src/domains/caregiver/repository"
+	carePlanModel "mini-evv-logger-backend/src/domains/careplan/model"
+	carePlanRepo "mini-evv-logger-backend/src/domains/careplan/repository"
+	clientRepo "mini-evv-logger-backend/src/domains/client/repository"
+	"mini-evv-logger-backend/src/domains/series/model"
+	"mini-evv-logger-backend/src/domains/series/repository"
+	"net/http"
+	"strings"
+	"time"
+	_ "time/tzdata" // Series are expanded in the client's timezone, which must load without system zoneinfo
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/teambition/rrule-go"
+)
+
+// SeriesService defines the interface for schedule series business logic
+type SeriesService interface {
+	GetAllSeries(ctx context.Context, filter model.FilterSeriesRequest) ([]model.ScheduleSeries, error)
+	GetSeriesByID(ctx context.Context, id string) (*model.ScheduleSeries, error)
+	GetSeriesOwnership(ctx context.Context, id string) (*model.SeriesOwnership, error)
+	CreateSeries(ctx context.Context, req model.CreateSeriesRequest) (*model.ScheduleSeries, error)
+	SplitSeries(ctx context.Context, req model.SplitSeriesRequest) (*model.ScheduleSeries, error)
+	GenerateOccurrences(ctx context.Context) (int, error)
+}
+
+// Settings holds the configurable rules applied by the series service
+type Settings struct {
+	Horizon time.Duration // How far ahead occurrences are created
+}
+
+// seriesServiceImpl implements the SeriesService interface
+type seriesServiceImpl struct {
+	repo          repository.SeriesRepository
+	caregiverRepo caregiverRepo.CaregiverRepository
+	clientRepo    clientRepo.ClientRepository
+	carePlanRepo  carePlanRepo.CarePlanRepository
+	settings      Settings
+}
+
+// NewSeriesService creates a new SeriesService (returns interface)
+func NewSeriesService(repo repository.SeriesRepository, caregiverRepo caregiverRepo.CaregiverRepository, clientRepo clientRepo.ClientRepository,
+	carePlanRepo carePlanRepo.CarePlanRepository, settings Settings) SeriesService {
+	return &seriesServiceImpl{
+		repo:          repo,
+		caregiverRepo: caregiverRepo,
+		clientRepo:    clientRepo,
+		carePlanRepo:  carePlanRepo,
+		settings:      settings,
+	}
+}
+
+// GetAllSeries fetches the series visible to the principal
+func (s *seriesServiceImpl) GetAllSeries(ctx context.Context, filter model.FilterSeriesRequest) ([]model.ScheduleSeries, error) {
+	log.Info().Msgf("Fetching schedule series with filter %s", filter.String())
+
+	err := filter.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for FilterSeriesRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	// Coordinators only see the series assigned to their branch
+	if principal, ok := authModel.PrincipalFromContext(ctx); ok && principal.Role == authModel.RoleCoordinator {
+		if principal.BranchID == nil {
+			return nil, exceptions.ErrForbidden.WithDetails("Coordinator is not assigned to a branch")
+		}
+		filter.BranchID = *principal.BranchID
+	}
+
+	series, err := s.repo.GetSeries(ctx, filter)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch schedule series from repository")
+		return nil, err
+	}
+	return series, nil
+}
+
+// GetSeriesByID fetches a series by its ID
+func (s *seriesServiceImpl) GetSeriesByID(ctx context.Context, id string) (*model.ScheduleSeries, error) {
+	log.Info().Str("series_id", id).Msg("Fetching schedule series by ID")
+
+	_, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Str("series_id", id).Msg("Invalid UUID format for series ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid series ID format")
+	}
+
+	series, err := s.repo.GetSeriesByID(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("series_id", id).Msg("Failed to fetch schedule series by ID from repository")
+		return nil, err
+	}
+	return series, nil
+}
+
+// GetSeriesOwnership fetches who a series is assigned to, for authorization checks
+func (s *seriesServiceImpl) GetSeriesOwnership(ctx context.Context, id string) (*model.SeriesOwnership, error) {
+	_, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Str("series_id", id).Msg("Invalid UUID format for series ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid series ID format")
+	}
+
+	ownership, err := s.repo.GetSeriesOwnership(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("series_id", id).Msg("Failed to fetch schedule series ownership from repository")
+		return nil, err
+	}
+	return ownership, nil
+}
+
+// CreateSeries stores a new recurring visit and creates its occurrences up to the horizon
+func (s *seriesServiceImpl) CreateSeries(ctx context.Context, req model.CreateSeriesRequest) (*model.ScheduleSeries, error) {
+	log.Info().Str("client_id", req.ClientID).Str("user_id", authModel.ActorID(ctx)).Str("rrule", req.RRule).Msg("Attempting to create schedule series")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for CreateSeriesRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+	_, err = parseRule(req.RRule, time.UTC)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Check that the client exists and the caregiver may be assigned
+	_, err = s.clientRepo.GetClientByID(ctx, req.ClientID)
+	if err != nil {
+		log.Error().Err(err).Str("client_id", req.ClientID).Msg("Failed to retrieve client before creating schedule series")
+		return nil, err
+	}
+	err = s.checkAssignment(ctx, req.CaregiverID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Perform the insert via repository
+	series := model.ScheduleSeries{
+		ID:              uuid.NewString(),
+		ClientID:        req.ClientID,
+		CaregiverID:     req.CaregiverID,
+		RRule:           req.RRule,
+		StartsAt:        req.StartsAt,
+		DurationMinutes: req.DurationMinutes,
+		TaskTemplate:    req.TaskTemplate,
+	}
+	err = s.repo.CreateSeries(ctx, series)
+	if err != nil {
+		log.Error().Err(err).Str("series_id", series.ID).Msg("Failed to create schedule series in repository")
+		return nil, err
+	}
+
+	// 3. Create the first occurrences right away instead of waiting for the sweeper
+	return s.generateNow(ctx, series.ID)
+}
+
+// SplitSeries applies changes from one occurrence onwards ("this and following").
+// The current series ends at req.From and a new series with the changes takes over;
+// visits that already happened, were cancelled or were edited individually are kept.
+func (s *seriesServiceImpl) SplitSeries(ctx context.Context, req model.SplitSeriesRequest) (*model.ScheduleSeries, error) {
+	log.Info().Str("series_id", req.ID).Str("user_id", authModel.ActorID(ctx)).Time("from", req.From).Msg("Attempting to split schedule series")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for SplitSeriesRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	// 1. Check if the series exists and still runs at req.From
+	current, err := s.repo.GetSeriesByID(ctx, req.ID)
+	if err != nil {
+		log.Error().Err(err).Str("series_id", req.ID).Msg("Failed to retrieve schedule series before split")
+		return nil, err
+	}
+	if req.From.Before(current.StartsAt) {
+		return nil, exceptions.ErrBadRequest.WithDetails("from must not be before the start of the series")
+	}
+	if current.EndsAt != nil && !req.From.Before(*current.EndsAt) {
+		return nil, exceptions.ErrConflict.WithDetails(fmt.Sprintf("Schedule series ID %s already ends at %s. Cannot split.", req.ID, current.EndsAt.Format(time.RFC3339)))
+	}
+
+	// 2. Build the series that takes over, keeping everything that was not changed
+	next := model.ScheduleSeries{
+		ID:              uuid.NewString(),
+		ClientID:        current.ClientID,
+		CaregiverID:     current.CaregiverID,
+		RRule:           current.RRule,
+		StartsAt:        req.From,
+		DurationMinutes: current.DurationMinutes,
+		TaskTemplate:    current.TaskTemplate,
+	}
+	if req.CaregiverID != nil {
+		err = s.checkAssignment(ctx, req.CaregiverID)
+		if err != nil {
+			return nil, err
+		}
+		next.CaregiverID = req.CaregiverID
+	}
+	if req.RRule != nil {
+		_, err = parseRule(*req.RRule, time.UTC)
+		if err != nil {
+			return nil, err
+		}
+		next.RRule = *req.RRule
+	}
+	if req.StartsAt != nil {
+		if req.StartsAt.Before(req.From) {
+			return nil, exceptions.ErrBadRequest.WithDetails("starts_at must not be before from")
+		}
+		next.StartsAt = *req.StartsAt
+	}
+	if req.DurationMinutes != nil {
+		next.DurationMinutes = *req.DurationMinutes
+	}
+	if req.TaskTemplate != nil {
+		next.TaskTemplate = req.TaskTemplate
+	}
+
+	// 3. Perform the split via repository
+	removed, err := s.repo.SplitSeries(ctx, req.ID, req.From, next)
+	if err != nil {
+		log.Error().Err(err).Str("series_id", req.ID).Msg("Failed to split schedule series in repository")
+		return nil, err
+	}
+	log.Info().Str("series_id", req.ID).Str("next_series_id", next.ID).Int64("removed", removed).Msg("Split schedule series")
+
+	// 4. Create the new series' occurrences right away instead of waiting for the sweeper
+	return s.generateNow(ctx, next.ID)
+}
+
+// GenerateOccurrences creates the schedules of every series up to the horizon.
+// It returns the number of schedules created.
+func (s *seriesServiceImpl) GenerateOccurrences(ctx context.Context) (int, error) {
+	now := time.Now()
+	horizon := now.Add(s.settings.Horizon)
+
+	due, err := s.repo.GetSeriesDueForGeneration(ctx, horizon)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch schedule series due for generation from repository")
+		return 0, err
+	}
+
+	total := 0
+	for _, series := range due {
+		// Keep going so that one bad series does not block the rest of the sweep
+		created, err := s.generate(ctx, series, now, horizon)
+		if err != nil {
+			log.Error().Err(err).Str("series_id", series.ID).Msg("Failed to generate schedule series occurrences")
+			continue
+		}
+		total += created
+	}
+
+	if total > 0 {
+		log.Info().Int("created", total).Msg("Generated schedules from series")
+	}
+	return total, nil
+}
+
+// generateNow reloads a series and creates its occurrences up to the horizon.
+// Generation failures are logged rather than returned; the sweeper retries them.
+func (s *seriesServiceImpl) generateNow(ctx context.Context, id string) (*model.ScheduleSeries, error) {
+	series, err := s.repo.GetSeriesByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	_, err = s.generate(ctx, *series, now, now.Add(s.settings.Horizon))
+	if err != nil {
+		log.Error().Err(err).Str("series_id", id).Msg("Failed to generate schedule series occurrences")
+		return series, nil
+	}
+	return s.repo.GetSeriesByID(ctx, id)
+}
+
+// generate creates the occurrences of a series between the later of now and what was already
+// generated, and the earlier of horizon and the end of the series. Past occurrences are never created.
+func (s *seriesServiceImpl) generate(ctx context.Context, series model.ScheduleSeries, now, horizon time.Time) (int, error) {
+	after := now
+	if series.GeneratedThrough != nil && series.GeneratedThrough.After(after) {
+		after = *series.GeneratedThrough
+	}
+	through := horizon
+	if series.EndsAt != nil && series.EndsAt.Before(through) {
+		through = *series.EndsAt
+	}
+	if !through.After(after) {
+		return 0, nil
+	}
+
+	times, err := occurrencesBetween(series, after, through)
+	if err != nil {
+		return 0, err
+	}
+
+	// Every occurrence starts with the tasks of the client's active care plan, followed by the series' own template
+	plan, err := s.activeCarePlan(ctx, series.ClientID)
+	if err != nil {
+		return 0, err
+	}
+	occurrences := make([]model.Occurrence, 0, len(times))
+	for _, t := range times {
+		occurrence := model.Occurrence{Time: t}
+		if plan != nil {
+			occurrence.CarePlanID = &plan.ID
+			occurrence.Tasks = plan.TasksFor(t)
+		}
+		occurrence.Tasks = append(occurrence.Tasks, series.TaskTemplate...)
+		occurrences = append(occurrences, occurrence)
+	}
+	return s.repo.InsertOccurrences(ctx, series, occurrences, through)
+}
+
+// activeCarePlan fetches the active care plan of a client, or nil when the client has none
+func (s *seriesServiceImpl) activeCarePlan(ctx context.Context, clientID string) (*carePlanModel.CarePlan, error) {
+	plan, err := s.carePlanRepo.GetActiveCarePlan(ctx, clientID)
+	if err != nil {
+		if customErr, ok := err.(*exceptions.CustomError); ok && customErr.Code == http.StatusNotFound {
+			return nil, nil
+		}
+		log.Error().Err(err).Str("client_id", clientID).Msg("Failed to retrieve care plan before generating occurrences")
+		return nil, err
+	}
+	return plan, nil
+}
+
+// checkAssignment verifies that the caregiver exists and that the principal may assign visits to them.
+// Coordinators must assign a caregiver from their own branch so that they can still see the series.
+func (s *seriesServiceImpl) checkAssignment(ctx context.Context, caregiverID *string) error {
+	principal, hasPrincipal := authModel.PrincipalFromContext(ctx)
+	isCoordinator := hasPrincipal && principal.Role == authModel.RoleCoordinator
+
+	if caregiverID == nil {
+		if isCoordinator {
+			return exceptions.ErrBadRequest.WithDetails("Coordinators must assign a caregiver")
+		}
+		return nil
+	}
+
+	caregiver, err := s.caregiverRepo.GetCaregiverByID(ctx, *caregiverID)
+	if err != nil {
+		log.Error().Err(err).Str("caregiver_id", *caregiverID).Msg("Failed to retrieve caregiver before assignment")
+		return err
+	}
+
+	if isCoordinator && (principal.BranchID == nil || caregiver.BranchID == nil || *principal.BranchID != *caregiver.BranchID) {
+		return exceptions.ErrForbidden.WithDetails("Coordinators may only assign caregivers in their branch")
+	}
+	return nil
+}
+
+// occurrencesBetween expands the rule of a series into the shift times in [after, before],
+// excluding anything at or after the end of the series. The rule is anchored at starts_at
+// in the client's timezone so that visits keep their local time across DST changes.
+func occurrencesBetween(series model.ScheduleSeries, after, before time.Time) ([]time.Time, error) {
+	// Expanding in any other timezone would create visits at the wrong local time, and
+	// occurrences are never regenerated once inserted, so leave the series for the next sweep
+	loc, err := time.LoadLocation(series.Timezone)
+	if err != nil {
+		log.Error().Err(err).Str("series_id", series.ID).Str("timezone", series.Timezone).Msg("Failed to load client timezone for schedule series")
+		return nil, exceptions.ErrInternalError
+	}
+
+	option, err := parseRule(series.RRule, loc)
+	if err != nil {
+		return nil, err
+	}
+	option.Dtstart = series.StartsAt.In(loc)
+
+	rule, err := rrule.NewRRule(*option)
+	if err != nil {
+		return nil, exceptions.ErrBadRequest.WithDetails(fmt.Sprintf("Invalid rrule: %v", err))
+	}
+
+	occurrences := rule.Between(after, before, true)
+	if series.EndsAt != nil {
+		kept := occurrences[:0]
+		for _, occurrence := range occurrences {
+			if occurrence.Before(*series.EndsAt) {
+				kept = append(kept, occurrence)
+			}
+		}
+		occurrences = kept
+	}
+	return occurrences, nil
+}
+
+// parseRule parses an iCalendar RRULE, with or without the "RRULE:" prefix.
+// Visits recur at most daily, so sub-daily frequencies are rejected.
+func parseRule(rule string, loc *time.Location) (*rrule.ROption, error) {
+	option, err := rrule.StrToROptionInLocation(strings.TrimPrefix(rule, "RRULE:"), loc)
+	if err != nil {
+		return nil, exceptions.ErrBadRequest.WithDetails(fmt.Sprintf("Invalid rrule: %v", err))
+	}
+	if option.Freq != rrule.YEARLY && option.Freq != rrule.MONTHLY && option.Freq != rrule.WEEKLY && option.Freq != rrule.DAILY {
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid rrule: FREQ must be DAILY, WEEKLY, MONTHLY or YEARLY")
+	}
+	return option, nil
+}
@@ -0,0 +1,310 @@
+package service_test
+
+import (
+	"context"
+	"mini-evv-logger-backend/exceptions"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	caregiverMocks "mini-evv-logger-backend/src/domains/caregiver/mocks/repository"
+	caregiverModel "mini-evv-logger-backend/src/domains/caregiver/model"
+	carePlanMocks "mini-evv-logger-backend/src/domains/careplan/mocks/repository"
+	carePlanModel "mini-evv-logger-backend/src/domains/careplan/model"
+	clientMocks "mini-evv-logger-backend/src/domains/client/mocks/repository"
+	clientModel "mini-evv-logger-backend/src/domains/client/model"
+	mocks "mini-evv-logger-backend/src/domains/series/mocks/repository"
+	"mini-evv-logger-backend/src/domains/series/model"
+	"mini-evv-logger-backend/src/domains/series/service"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	mockSeriesRepo    *mocks.MockSeriesRepository
+	mockCaregiverRepo *caregiverMocks.MockCaregiverRepository
+	mockClientRepo    *clientMocks.MockClientRepository
+	mockCarePlanRepo  *carePlanMocks.MockCarePlanRepository
+	ctrl              *gomock.Controller
+	svc               service.SeriesService
+)
+
+func initMocks(t *testing.T) {
+	ctrl = gomock.NewController(t)
+
+	mockSeriesRepo = mocks.NewMockSeriesRepository(ctrl)
+	mockCaregiverRepo = caregiverMocks.NewMockCaregiverRepository(ctrl)
+	mockClientRepo = clientMocks.NewMockClientRepository(ctrl)
+	mockCarePlanRepo = carePlanMocks.NewMockCarePlanRepository(ctrl)
+
+	svc = service.NewSeriesService(mockSeriesRepo, mockCaregiverRepo, mockClientRepo, mockCarePlanRepo, service.Settings{Horizon: 28 * 24 * time.Hour})
+}
+
+func TestGetAllSeries(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyBranchID := uuid.NewString()
+
+	t.Run("TestGetAllSeries: Scoped To Coordinator Branch", func(t *testing.T) {
+		ctx := authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: uuid.NewString(), Role: authModel.RoleCoordinator, BranchID: &dummyBranchID})
+		mockSeriesRepo.EXPECT().GetSeries(gomock.Any(), model.FilterSeriesRequest{BranchID: dummyBranchID}).Return([]model.ScheduleSeries{{ID: uuid.NewString()}}, nil).Times(1)
+
+		series, err := svc.GetAllSeries(ctx, model.FilterSeriesRequest{})
+		assert.NoError(t, err)
+		assert.Len(t, series, 1)
+	})
+
+	t.Run("TestGetAllSeries: Invalid Filter", func(t *testing.T) {
+		series, err := svc.GetAllSeries(context.Background(), model.FilterSeriesRequest{ClientID: "not-a-uuid"})
+		assert.Error(t, err)
+		assert.Nil(t, series)
+	})
+}
+
+func TestCreateSeries(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyClientID, dummyCaregiverID, dummyBranchID := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	startsAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	dummyRequest := model.CreateSeriesRequest{
+		ClientID:        dummyClientID,
+		CaregiverID:     &dummyCaregiverID,
+		RRule:           "FREQ=DAILY",
+		StartsAt:        startsAt,
+		DurationMinutes: 60,
+		TaskTemplate:    []string{"Medication reminder"},
+	}
+	coordinatorCtx := authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: uuid.NewString(), Role: authModel.RoleCoordinator, BranchID: &dummyBranchID})
+
+	t.Run("TestCreateSeries: OK", func(t *testing.T) {
+		var created model.ScheduleSeries
+		mockClientRepo.EXPECT().GetClientByID(gomock.Any(), dummyClientID).Return(&clientModel.Client{ID: dummyClientID}, nil).Times(1)
+		mockCaregiverRepo.EXPECT().GetCaregiverByID(gomock.Any(), dummyCaregiverID).Return(&caregiverModel.Caregiver{ID: dummyCaregiverID, BranchID: &dummyBranchID}, nil).Times(1)
+		mockSeriesRepo.EXPECT().CreateSeries(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, series model.ScheduleSeries) error {
+				created = series
+				created.Timezone = "UTC"
+				return nil
+			}).Times(1)
+		mockSeriesRepo.EXPECT().GetSeriesByID(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, id string) (*model.ScheduleSeries, error) {
+				return &created, nil
+			}).Times(2)
+		mockCarePlanRepo.EXPECT().GetActiveCarePlan(gomock.Any(), gomock.Any()).Return(nil, exceptions.ErrNotFound).Times(1)
+		mockSeriesRepo.EXPECT().InsertOccurrences(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, series model.ScheduleSeries, occurrences []model.Occurrence, generatedThrough time.Time) (int, error) {
+				// One visit a day for the 28 day horizon, starting at starts_at
+				assert.Len(t, occurrences, 28)
+				assert.True(t, occurrences[0].Time.Equal(startsAt))
+				assert.Equal(t, []string{"Medication reminder"}, occurrences[0].Tasks)
+				return len(occurrences), nil
+			}).Times(1)
+
+		series, err := svc.CreateSeries(coordinatorCtx, dummyRequest)
+		assert.NoError(t, err)
+		assert.Equal(t, dummyClientID, series.ClientID)
+	})
+
+	t.Run("TestCreateSeries: Invalid RRule", func(t *testing.T) {
+		req := dummyRequest
+		req.RRule = "FREQ=SOMETIMES"
+
+		series, err := svc.CreateSeries(coordinatorCtx, req)
+		assert.Error(t, err)
+		assert.Nil(t, series)
+		assert.Equal(t, http.StatusBadRequest, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestCreateSeries: Sub-Daily Frequency", func(t *testing.T) {
+		req := dummyRequest
+		req.RRule = "FREQ=HOURLY"
+
+		series, err := svc.CreateSeries(coordinatorCtx, req)
+		assert.Error(t, err)
+		assert.Nil(t, series)
+	})
+
+	t.Run("TestCreateSeries: Caregiver In Other Branch", func(t *testing.T) {
+		otherBranchID := uuid.NewString()
+		mockClientRepo.EXPECT().GetClientByID(gomock.Any(), dummyClientID).Return(&clientModel.Client{ID: dummyClientID}, nil).Times(1)
+		mockCaregiverRepo.EXPECT().GetCaregiverByID(gomock.Any(), dummyCaregiverID).Return(&caregiverModel.Caregiver{ID: dummyCaregiverID, BranchID: &otherBranchID}, nil).Times(1)
+
+		series, err := svc.CreateSeries(coordinatorCtx, dummyRequest)
+		assert.Error(t, err)
+		assert.Nil(t, series)
+		assert.Equal(t, http.StatusForbidden, err.(*exceptions.CustomError).Code)
+	})
+}
+
+func TestSplitSeries(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyID := uuid.NewString()
+	startsAt := time.Now().Add(-30 * 24 * time.Hour).Truncate(time.Second)
+	from := time.Now().Add(7 * 24 * time.Hour).Truncate(time.Second)
+	current := &model.ScheduleSeries{ID: dummyID, ClientID: uuid.NewString(), RRule: "FREQ=WEEKLY", StartsAt: startsAt, DurationMinutes: 60, TaskTemplate: []string{"Prepare lunch"}, Timezone: "UTC"}
+
+	t.Run("TestSplitSeries: OK", func(t *testing.T) {
+		newRule := "FREQ=DAILY"
+		var next model.ScheduleSeries
+		mockSeriesRepo.EXPECT().GetSeriesByID(gomock.Any(), dummyID).Return(current, nil).Times(1)
+		mockSeriesRepo.EXPECT().SplitSeries(gomock.Any(), dummyID, from, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, _ time.Time, series model.ScheduleSeries) (int64, error) {
+				// Unchanged fields are carried over, the new series starts where the old one ends
+				assert.NotEqual(t, dummyID, series.ID)
+				assert.Equal(t, newRule, series.RRule)
+				assert.Equal(t, from, series.StartsAt)
+				assert.Equal(t, current.TaskTemplate, series.TaskTemplate)
+				next = series
+				next.Timezone = "UTC"
+				return 1, nil
+			}).Times(1)
+		mockSeriesRepo.EXPECT().GetSeriesByID(gomock.Any(), gomock.Not(dummyID)).
+			DoAndReturn(func(_ context.Context, id string) (*model.ScheduleSeries, error) {
+				return &next, nil
+			}).Times(2)
+		mockCarePlanRepo.EXPECT().GetActiveCarePlan(gomock.Any(), gomock.Any()).Return(nil, exceptions.ErrNotFound).Times(1)
+		mockSeriesRepo.EXPECT().InsertOccurrences(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(21, nil).Times(1)
+
+		series, err := svc.SplitSeries(context.Background(), model.SplitSeriesRequest{ID: dummyID, From: from, RRule: &newRule})
+		assert.NoError(t, err)
+		assert.Equal(t, newRule, series.RRule)
+	})
+
+	t.Run("TestSplitSeries: Before Series Start", func(t *testing.T) {
+		mockSeriesRepo.EXPECT().GetSeriesByID(gomock.Any(), dummyID).Return(current, nil).Times(1)
+
+		series, err := svc.SplitSeries(context.Background(), model.SplitSeriesRequest{ID: dummyID, From: startsAt.Add(-time.Hour)})
+		assert.Error(t, err)
+		assert.Nil(t, series)
+		assert.Equal(t, http.StatusBadRequest, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestSplitSeries: Already Ended", func(t *testing.T) {
+		endsAt := from.Add(-time.Hour)
+		ended := *current
+		ended.EndsAt = &endsAt
+		mockSeriesRepo.EXPECT().GetSeriesByID(gomock.Any(), dummyID).Return(&ended, nil).Times(1)
+
+		series, err := svc.SplitSeries(context.Background(), model.SplitSeriesRequest{ID: dummyID, From: from})
+		assert.Error(t, err)
+		assert.Nil(t, series)
+		assert.Equal(t, http.StatusConflict, err.(*exceptions.CustomError).Code)
+	})
+}
+
+func TestGenerateOccurrences(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	t.Run("TestGenerateOccurrences: Keeps Local Time Across DST", func(t *testing.T) {
+		loc, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			t.Skipf("timezone data not available: %v", err)
+		}
+
+		// Weekday visits at 09:00 local time from next March, with a horizon long enough
+		// to cross both DST changes regardless of when the test runs
+		longHorizonSvc := service.NewSeriesService(mockSeriesRepo, mockCaregiverRepo, mockClientRepo, mockCarePlanRepo, service.Settings{Horizon: 3 * 366 * 24 * time.Hour})
+		startsAt := time.Date(time.Now().Year()+1, time.March, 2, 9, 0, 0, 0, loc)
+		generatedThrough := startsAt.Add(-time.Minute)
+		series := model.ScheduleSeries{
+			ID:               uuid.NewString(),
+			RRule:            "RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+			StartsAt:         startsAt.UTC(),
+			GeneratedThrough: &generatedThrough,
+			Timezone:         "America/New_York",
+		}
+		mockSeriesRepo.EXPECT().GetSeriesDueForGeneration(gomock.Any(), gomock.Any()).Return([]model.ScheduleSeries{series}, nil).Times(1)
+		mockCarePlanRepo.EXPECT().GetActiveCarePlan(gomock.Any(), gomock.Any()).Return(nil, exceptions.ErrNotFound).Times(1)
+		mockSeriesRepo.EXPECT().InsertOccurrences(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ model.ScheduleSeries, occurrences []model.Occurrence, _ time.Time) (int, error) {
+				assert.NotEmpty(t, occurrences)
+				for _, occurrence := range occurrences {
+					local := occurrence.Time.In(loc)
+					assert.Equal(t, 9, local.Hour(), "occurrence %s", local)
+					assert.NotEqual(t, time.Saturday, local.Weekday())
+					assert.NotEqual(t, time.Sunday, local.Weekday())
+				}
+				return len(occurrences), nil
+			}).Times(1)
+
+		created, err := longHorizonSvc.GenerateOccurrences(context.Background())
+		assert.NoError(t, err)
+		assert.Positive(t, created)
+	})
+
+	t.Run("TestGenerateOccurrences: Ended Series", func(t *testing.T) {
+		endsAt := time.Now().Add(-time.Hour)
+		series := model.ScheduleSeries{ID: uuid.NewString(), RRule: "FREQ=DAILY", StartsAt: endsAt.Add(-72 * time.Hour), EndsAt: &endsAt, Timezone: "UTC"}
+		mockSeriesRepo.EXPECT().GetSeriesDueForGeneration(gomock.Any(), gomock.Any()).Return([]model.ScheduleSeries{series}, nil).Times(1)
+
+		created, err := svc.GenerateOccurrences(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, created)
+	})
+
+	t.Run("TestGenerateOccurrences: Unknown Timezone", func(t *testing.T) {
+		// Nothing is inserted rather than expanding the series in UTC
+		series := model.ScheduleSeries{ID: uuid.NewString(), RRule: "FREQ=DAILY", StartsAt: time.Now(), Timezone: "Mars/Olympus_Mons"}
+		mockSeriesRepo.EXPECT().GetSeriesDueForGeneration(gomock.Any(), gomock.Any()).Return([]model.ScheduleSeries{series}, nil).Times(1)
+		mockSeriesRepo.EXPECT().InsertOccurrences(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		created, err := svc.GenerateOccurrences(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, created)
+	})
+
+	t.Run("TestGenerateOccurrences: Continues After Failure", func(t *testing.T) {
+		failing := model.ScheduleSeries{ID: uuid.NewString(), RRule: "FREQ=DAILY", StartsAt: time.Now(), Timezone: "UTC"}
+		working := model.ScheduleSeries{ID: uuid.NewString(), RRule: "FREQ=WEEKLY", StartsAt: time.Now(), Timezone: "UTC"}
+		mockSeriesRepo.EXPECT().GetSeriesDueForGeneration(gomock.Any(), gomock.Any()).Return([]model.ScheduleSeries{failing, working}, nil).Times(1)
+		mockCarePlanRepo.EXPECT().GetActiveCarePlan(gomock.Any(), gomock.Any()).Return(nil, exceptions.ErrNotFound).Times(2)
+		mockSeriesRepo.EXPECT().InsertOccurrences(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(0, exceptions.ErrInternalError).Times(1)
+		mockSeriesRepo.EXPECT().InsertOccurrences(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(4, nil).Times(1)
+
+		created, err := svc.GenerateOccurrences(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 4, created)
+	})
+	t.Run("TestGenerateOccurrences: Tasks From Care Plan", func(t *testing.T) {
+		// Daily visits from next Monday, the care plan adds laundry on Mondays only
+		now := time.Now().UTC()
+		monday := time.Date(now.Year(), now.Month(), now.Day()+7-(int(now.Weekday())+6)%7, 9, 0, 0, 0, time.UTC)
+		generatedThrough := monday.Add(-time.Minute)
+		series := model.ScheduleSeries{ID: uuid.NewString(), ClientID: uuid.NewString(), RRule: "FREQ=DAILY", StartsAt: monday,
+			TaskTemplate: []string{"Prepare lunch"}, GeneratedThrough: &generatedThrough, Timezone: "UTC"}
+		plan := &carePlanModel.CarePlan{ID: uuid.NewString(), ClientID: series.ClientID, Version: 2, Tasks: []carePlanModel.CarePlanTask{
+			{Description: "Medication reminder", Frequency: carePlanModel.FrequencyEveryVisit},
+			{Description: "Laundry", Frequency: carePlanModel.FrequencyWeekly, Days: []string{"MO"}},
+		}}
+		mockSeriesRepo.EXPECT().GetSeriesDueForGeneration(gomock.Any(), gomock.Any()).Return([]model.ScheduleSeries{series}, nil).Times(1)
+		mockCarePlanRepo.EXPECT().GetActiveCarePlan(gomock.Any(), series.ClientID).Return(plan, nil).Times(1)
+		mockSeriesRepo.EXPECT().InsertOccurrences(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ model.ScheduleSeries, occurrences []model.Occurrence, _ time.Time) (int, error) {
+				assert.NotEmpty(t, occurrences)
+				for _, occurrence := range occurrences {
+					assert.Equal(t, &plan.ID, occurrence.CarePlanID)
+					if occurrence.Time.Weekday() == time.Monday {
+						assert.Equal(t, []string{"Medication reminder", "Laundry", "Prepare lunch"}, occurrence.Tasks)
+					} else {
+						assert.Equal(t, []string{"Medication reminder", "Prepare lunch"}, occurrence.Tasks)
+					}
+				}
+				return len(occurrences), nil
+			}).Times(1)
+
+		created, err := svc.GenerateOccurrences(context.Background())
+		assert.NoError(t, err)
+		assert.Positive(t, created)
+	})
+}
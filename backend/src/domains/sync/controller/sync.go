@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/policy"
+	"mini-evv-logger-backend/responses"
+	"mini-evv-logger-backend/src/domains/sync/model"
+	"mini-evv-logger-backend/src/domains/sync/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SyncController handles HTTP requests for syncing events recorded offline
+type SyncController struct {
+	svc service.SyncService
+}
+
+// NewSyncController creates a new SyncController
+func NewSyncController(svc service.SyncService) *SyncController {
+	return &SyncController{svc: svc}
+}
+
+// Routes sets up the API endpoints for syncing. Every event is authorized against
+// the visit or task it targets when it is applied.
+func (sc *SyncController) Routes(app fiber.Router) {
+	app.Post("/sync", policy.Require(policy.SyncVisits, nil), sc.Sync)
+}
+
+// Sync handles applying a batch of events recorded on a device
+func (sc *SyncController) Sync(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	req := model.SyncRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	result, err := sc.svc.Sync(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, result, "Events synced successfully")
+}
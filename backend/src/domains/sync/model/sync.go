@@ -0,0 +1,67 @@
+package model
+
+import (
+	"mini-evv-logger-backend/exceptions"
+	scheduleModel "mini-evv-logger-backend/src/domains/schedule/model"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Event types a device records while offline
+const (
+	TypeClockIn    = "clock_in"
+	TypeClockOut   = "clock_out"
+	TypeTaskUpdate = "task_update"
+)
+
+// Outcomes of a synced event
+const (
+	ResultApplied  = "applied"  // The event was applied
+	ResultConflict = "conflict" // The visit or task no longer allows the event, e.g. it was already clocked in
+	ResultRejected = "rejected" // The event is invalid or not allowed and will never apply
+	ResultFailed   = "failed"   // A server error; nothing was recorded and the event can be sent again
+	ResultSkipped  = "skipped"  // Not attempted because an earlier event of the batch failed; send it again
+)
+
+// Event is an action recorded on a device, applied when the device is back online
+type Event struct {
+	ID         string     `json:"id" validate:"required,uuid"` // Generated by the device, identifies the event across retries
+	Type       string     `json:"type" validate:"required,oneof=clock_in clock_out task_update"`
+	OccurredAt time.Time  `json:"occurred_at" validate:"required"`                                        // Device time of the event, RFC 3339
+	ScheduleID string     `json:"schedule_id" validate:"required_unless=Type task_update,omitempty,uuid"` // clock_in and clock_out
+	Latitude   float64    `json:"latitude" validate:"required_unless=Type task_update"`                   // clock_in and clock_out
+	Longitude  float64    `json:"longitude" validate:"required_unless=Type task_update"`                  // clock_in and clock_out
+	GPSFixAt   *time.Time `json:"gps_fix_at"`                                                             // clock_in and clock_out, device time of the location fix
+	TaskID     string     `json:"task_id" validate:"required_if=Type task_update,omitempty,uuid"`         // task_update
+	Status     string     `json:"status" validate:"required_if=Type task_update"`                         // task_update, e.g. "completed"
+	Reason     string     `json:"reason"`                                                                 // task_update, required for "not_completed"
+
+	Attestation *scheduleModel.AttestationRequest `json:"attestation"` // clock_out, the client's confirmation of the visit
+}
+
+// SyncRequest defines the request body for syncing a batch of events, in the order they happened
+type SyncRequest struct {
+	Events []Event `json:"events" validate:"required,min=1,max=100"`
+}
+
+// EventResult is the outcome of one synced event
+type EventResult struct {
+	ID       string                  `json:"id"`
+	Result   string                  `json:"result"`             // e.g. "applied", "conflict"
+	Error    *exceptions.CustomError `json:"error,omitempty"`    // Why the event was not applied
+	Replayed bool                    `json:"replayed,omitempty"` // The event was synced before; this is its stored result
+}
+
+// SyncResponse lists the outcome of every event of a batch, in the same order
+type SyncResponse struct {
+	Results []EventResult `json:"results"`
+}
+
+func (r *Event) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *SyncRequest) Validate() error {
+	return validator.New().Struct(r)
+}
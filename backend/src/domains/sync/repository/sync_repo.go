@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context" // Import context
+	"encoding/json"
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/src/domains/sync/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=./sync_repo.go -destination=../mocks/repository/sync_repo.go -package=mocks
+
+// SyncRepository defines the interface for synced event database operations
+type SyncRepository interface {
+	GetResults(ctx context.Context, userID string, eventIDs []string) (map[string]model.EventResult, error)
+	SaveEvent(ctx context.Context, userID string, event model.Event, result model.EventResult) error
+}
+
+// syncRepositoryImpl implements the SyncRepository interface
+type syncRepositoryImpl struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+// NewSyncRepository creates a new SyncRepository (returns interface)
+func NewSyncRepository(db *sqlx.DB, logger zerolog.Logger) SyncRepository {
+	return &syncRepositoryImpl{db: db, logger: logger}
+}
+
+// syncedEvent is a stored event outcome as read from the database
+type syncedEvent struct {
+	ID     string `db:"id"`
+	Result string `db:"result"`
+	Error  []byte `db:"error"` // JSON of the CustomError, NULL for applied events
+}
+
+// GetResults fetches the stored outcomes of the given events of a user, keyed by event ID.
+// Events that were never synced are left out.
+func (r *syncRepositoryImpl) GetResults(ctx context.Context, userID string, eventIDs []string) (map[string]model.EventResult, error) {
+	var rows []syncedEvent
+	qb := squirrel.Select("id", "result", "error").
+		From("sync_events").
+		Where(squirrel.Eq{"user_id": userID, "id": eventIDs}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to build SQL query for GetResults")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.SelectContext(ctx, &rows, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to execute SQL query for GetResults")
+		return nil, exceptions.ErrInternalError
+	}
+
+	results := make(map[string]model.EventResult, len(rows))
+	for _, row := range rows {
+		result := model.EventResult{ID: row.ID, Result: row.Result}
+		if row.Error != nil {
+			err = json.Unmarshal(row.Error, &result.Error)
+			if err != nil {
+				r.logger.Error().Err(err).Str("event_id", row.ID).Msg("Failed to decode stored error for GetResults")
+				return nil, exceptions.ErrInternalError
+			}
+		}
+		results[row.ID] = result
+	}
+	return results, nil
+}
+
+// SaveEvent stores the outcome of an event. An event the user already stored is left unchanged.
+func (r *syncRepositoryImpl) SaveEvent(ctx context.Context, userID string, event model.Event, result model.EventResult) error {
+	var scheduleID, taskID, errorJSON *string
+	if event.ScheduleID != "" {
+		scheduleID = &event.ScheduleID
+	}
+	if event.TaskID != "" {
+		taskID = &event.TaskID
+	}
+	if result.Error != nil {
+		encoded, err := json.Marshal(result.Error)
+		if err != nil {
+			r.logger.Error().Err(err).Str("event_id", event.ID).Msg("Failed to encode error for SaveEvent")
+			return exceptions.ErrInternalError
+		}
+		errorJSONValue := string(encoded)
+		errorJSON = &errorJSONValue
+	}
+
+	qb := squirrel.Insert("sync_events").
+		Columns("id", "user_id", "type", "schedule_id", "task_id", "occurred_at", "result", "error").
+		Values(event.ID, userID, event.Type, scheduleID, taskID, event.OccurredAt, result.Result, errorJSON).
+		Suffix("ON CONFLICT (user_id, id) DO NOTHING").
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("event_id", event.ID).Msg("Failed to build SQL query for SaveEvent")
+		return exceptions.ErrInternalError
+	}
+
+	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("event_id", event.ID).Str("type", event.Type).Msg("Failed to execute SQL query for SaveEvent")
+		return exceptions.ErrInternalError
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"mini-evv-logger-backend/exceptions"
+	pkgmock "mini-evv-logger-backend/pkg_mock"
+	"mini-evv-logger-backend/src/domains/sync/model"
+	"mini-evv-logger-backend/src/domains/sync/repository"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	dbMock   *sql.DB
+	sqlxMock *sqlx.DB
+	mockSQL  sqlmock.Sqlmock
+	repo     repository.SyncRepository
+)
+
+func initMocks(t *testing.T) {
+	var err error
+	dbMock, mockSQL, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	// Wrap sqlmock in sqlx.DB
+	sqlxMock = sqlx.NewDb(dbMock, "sqlmock")
+	repo = repository.NewSyncRepository(sqlxMock, pkgmock.InitMockLogger())
+}
+
+func TestGetResults(t *testing.T) {
+	initMocks(t)
+
+	userID := uuid.NewString()
+	appliedID, conflictID := uuid.NewString(), uuid.NewString()
+	query := "SELECT id, result, error FROM sync_events WHERE id IN ($1,$2) AND user_id = $3"
+
+	t.Run("TestGetResults: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(appliedID, conflictID, userID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "result", "error"}).
+				AddRow(appliedID, model.ResultApplied, nil).
+				AddRow(conflictID, model.ResultConflict, []byte(`{"code":409,"message":"Conflict","details":"Schedule ID 1 cannot move from completed to in-progress"}`)))
+
+		results, err := repo.GetResults(context.Background(), userID, []string{appliedID, conflictID})
+		assert.NoError(t, err)
+		assert.Len(t, results, 2)
+		assert.Equal(t, model.EventResult{ID: appliedID, Result: model.ResultApplied}, results[appliedID])
+		assert.Equal(t, http.StatusConflict, results[conflictID].Error.Code)
+		assert.Equal(t, "Schedule ID 1 cannot move from completed to in-progress", results[conflictID].Error.Details)
+	})
+
+	t.Run("TestGetResults: None Synced", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(appliedID, conflictID, userID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "result", "error"}))
+
+		results, err := repo.GetResults(context.Background(), userID, []string{appliedID, conflictID})
+		assert.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("TestGetResults: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		results, err := repo.GetResults(context.Background(), userID, []string{appliedID, conflictID})
+		assert.Error(t, err)
+		assert.Nil(t, results)
+	})
+}
+
+func TestSaveEvent(t *testing.T) {
+	initMocks(t)
+
+	userID := uuid.NewString()
+	occurredAt := time.Now().Add(-time.Hour)
+	query := "INSERT INTO sync_events (id,user_id,type,schedule_id,task_id,occurred_at,result,error) VALUES ($1,$2,$3,$4,$5,$6,$7,$8) ON CONFLICT (user_id, id) DO NOTHING"
+
+	t.Run("TestSaveEvent: Applied Clock In", func(t *testing.T) {
+		event := model.Event{ID: uuid.NewString(), Type: model.TypeClockIn, OccurredAt: occurredAt, ScheduleID: uuid.NewString()}
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(event.ID, userID, model.TypeClockIn, &event.ScheduleID, nil, occurredAt, model.ResultApplied, nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.SaveEvent(context.Background(), userID, event, model.EventResult{ID: event.ID, Result: model.ResultApplied})
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestSaveEvent: Conflicting Task Update", func(t *testing.T) {
+		event := model.Event{ID: uuid.NewString(), Type: model.TypeTaskUpdate, OccurredAt: occurredAt, TaskID: uuid.NewString()}
+		errorJSON := `{"code":409,"message":"Conflict","details":"Task is already completed"}`
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(event.ID, userID, model.TypeTaskUpdate, nil, &event.TaskID, occurredAt, model.ResultConflict, &errorJSON).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.SaveEvent(context.Background(), userID, event, model.EventResult{ID: event.ID, Result: model.ResultConflict, Error: exceptions.ErrConflict.WithDetails("Task is already completed")})
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestSaveEvent: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.SaveEvent(context.Background(), userID, model.Event{ID: uuid.NewString(), Type: model.TypeClockOut}, model.EventResult{Result: model.ResultApplied})
+		assert.Error(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+	})
+}
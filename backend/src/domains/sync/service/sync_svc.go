@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context" // Import context
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/policy"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	scheduleModel "mini-evv-logger-backend/src/domains/schedule/model"
+	"mini-evv-logger-backend/src/domains/sync/model"
+	"mini-evv-logger-backend/src/domains/sync/repository"
+	taskModel "mini-evv-logger-backend/src/domains/task/model"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=./sync_svc.go -destination=../mocks/service/sync_svc.go -package=mocks
+
+// maxClockAhead is how far in the future an event's device time may be before it is refused
+const maxClockAhead = 5 * time.Minute
+
+// VisitLogger is the part of the schedule service that applies synced clock-ins and clock-outs
+type VisitLogger interface {
+	GetScheduleOwnership(ctx context.Context, id string) (*scheduleModel.ScheduleOwnership, error)
+	StartVisit(ctx context.Context, req scheduleModel.StartVisitRequest) error
+	EndVisit(ctx context.Context, req scheduleModel.EndVisitRequest) error
+}
+
+// TaskUpdater is the part of the task service that applies synced task updates
+type TaskUpdater interface {
+	GetTaskOwnership(ctx context.Context, taskID string) (*taskModel.TaskOwnership, error)
+	UpdateTaskStatus(ctx context.Context, req taskModel.UpdateTaskStatusRequest) error
+}
+
+// SyncService defines the interface for applying events recorded offline
+type SyncService interface {
+	Sync(ctx context.Context, req model.SyncRequest) (*model.SyncResponse, error)
+}
+
+// syncServiceImpl implements the SyncService interface
+type syncServiceImpl struct {
+	repo   repository.SyncRepository
+	visits VisitLogger
+	tasks  TaskUpdater
+}
+
+// NewSyncService creates a new SyncService (returns interface)
+func NewSyncService(repo repository.SyncRepository, visits VisitLogger, tasks TaskUpdater) SyncService {
+	return &syncServiceImpl{repo: repo, visits: visits, tasks: tasks}
+}
+
+// Sync applies a batch of events in order, each through the same checks as the live endpoints,
+// and returns the outcome of every event. Events that were synced before get their stored outcome
+// back. A server error stops the batch so that later events are not applied out of order.
+func (s *syncServiceImpl) Sync(ctx context.Context, req model.SyncRequest) (*model.SyncResponse, error) {
+	userID := authModel.ActorID(ctx)
+	log.Info().Str("user_id", userID).Int("events", len(req.Events)).Msg("Attempting to sync device events")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for SyncRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	// 1. Look up the events that were synced by an earlier request
+	eventIDs := make([]string, 0, len(req.Events))
+	for _, event := range req.Events {
+		eventIDs = append(eventIDs, event.ID)
+	}
+	synced, err := s.repo.GetResults(ctx, userID, eventIDs)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to retrieve synced events from repository")
+		return nil, err
+	}
+
+	// 2. Apply the remaining events in order
+	receivedAt := time.Now()
+	response := &model.SyncResponse{Results: make([]model.EventResult, 0, len(req.Events))}
+	stopped := false
+	for _, event := range req.Events {
+		if stopped {
+			response.Results = append(response.Results, model.EventResult{ID: event.ID, Result: model.ResultSkipped})
+			continue
+		}
+		if stored, ok := synced[event.ID]; ok {
+			stored.Replayed = true
+			response.Results = append(response.Results, stored)
+			continue
+		}
+
+		err = event.Validate()
+		if err != nil {
+			// Not stored: the event cannot be identified reliably, and it would be refused again anyway
+			response.Results = append(response.Results, model.EventResult{ID: event.ID, Result: model.ResultRejected, Error: exceptions.ErrBadRequest.WithDetails(err.Error())})
+			continue
+		}
+
+		result := outcome(event.ID, s.apply(ctx, event, receivedAt))
+		response.Results = append(response.Results, result)
+		if result.Result == model.ResultFailed {
+			stopped = true
+			continue
+		}
+
+		// 3. Keep the outcome so that the event is not applied again when the batch is resent
+		err = s.repo.SaveEvent(ctx, userID, event, result)
+		if err != nil {
+			log.Error().Err(err).Str("event_id", event.ID).Msg("Failed to store synced event outcome")
+		}
+		synced[event.ID] = result
+	}
+	return response, nil
+}
+
+// apply authorizes an event against the visit or task it targets and applies it at its device time
+func (s *syncServiceImpl) apply(ctx context.Context, event model.Event, receivedAt time.Time) error {
+	if event.OccurredAt.After(receivedAt.Add(maxClockAhead)) {
+		return exceptions.ErrBadRequest.WithDetails(fmt.Sprintf("Event %s occurred_at %s is in the future", event.ID, event.OccurredAt.Format(time.RFC3339)))
+	}
+	occurredAt := event.OccurredAt
+
+	switch event.Type {
+	case model.TypeClockIn, model.TypeClockOut:
+		ownership, err := s.visits.GetScheduleOwnership(ctx, event.ScheduleID)
+		if err != nil {
+			return err
+		}
+		action := policy.StartVisit
+		if event.Type == model.TypeClockOut {
+			action = policy.EndVisit
+		}
+		err = policy.AuthorizeContext(ctx, action, &policy.Resource{CaregiverID: ownership.CaregiverID, BranchID: ownership.BranchID})
+		if err != nil {
+			return err
+		}
+
+		if event.Type == model.TypeClockIn {
+			return s.visits.StartVisit(ctx, scheduleModel.StartVisitRequest{ID: event.ScheduleID, Latitude: event.Latitude, Longitude: event.Longitude,
+				CapturedAt: &occurredAt, GPSFixAt: event.GPSFixAt, Offline: true})
+		}
+		return s.visits.EndVisit(ctx, scheduleModel.EndVisitRequest{ID: event.ScheduleID, Latitude: event.Latitude, Longitude: event.Longitude,
+			CapturedAt: &occurredAt, GPSFixAt: event.GPSFixAt, Offline: true, Attestation: event.Attestation})
+	default:
+		ownership, err := s.tasks.GetTaskOwnership(ctx, event.TaskID)
+		if err != nil {
+			return err
+		}
+		err = policy.AuthorizeContext(ctx, policy.UpdateTask, &policy.Resource{CaregiverID: ownership.CaregiverID, BranchID: ownership.BranchID})
+		if err != nil {
+			return err
+		}
+
+		return s.tasks.UpdateTaskStatus(ctx, taskModel.UpdateTaskStatusRequest{TaskID: event.TaskID, Status: event.Status, Reason: event.Reason, OccurredAt: &occurredAt})
+	}
+}
+
+// outcome classifies the error of an applied event
+func outcome(eventID string, err error) model.EventResult {
+	if err == nil {
+		return model.EventResult{ID: eventID, Result: model.ResultApplied}
+	}
+
+	customErr, ok := err.(*exceptions.CustomError)
+	if !ok {
+		if isContextErr, ctxErr := exceptions.IsContextError(err); isContextErr {
+			customErr = ctxErr
+		} else {
+			customErr = exceptions.ErrInternalError
+		}
+	}
+
+	switch {
+	case customErr.Code >= http.StatusInternalServerError:
+		log.Error().Err(err).Str("event_id", eventID).Msg("Failed to apply synced event")
+		return model.EventResult{ID: eventID, Result: model.ResultFailed, Error: customErr}
+	case customErr.Code == http.StatusConflict || customErr.Code == http.StatusPreconditionFailed:
+		return model.EventResult{ID: eventID, Result: model.ResultConflict, Error: customErr}
+	default:
+		return model.EventResult{ID: eventID, Result: model.ResultRejected, Error: customErr}
+	}
+}
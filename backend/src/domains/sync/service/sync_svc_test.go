@@ -0,0 +1,192 @@
+package service_test
+
+import (
+	"context"
+	"mini-evv-logger-backend/exceptions"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	scheduleModel "mini-evv-logger-backend/src/domains/schedule/model"
+	repoMocks "mini-evv-logger-backend/src/domains/sync/mocks/repository"
+	mocks "mini-evv-logger-backend/src/domains/sync/mocks/service"
+	"mini-evv-logger-backend/src/domains/sync/model"
+	"mini-evv-logger-backend/src/domains/sync/service"
+	taskModel "mini-evv-logger-backend/src/domains/task/model"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	mockSyncRepo    *repoMocks.MockSyncRepository
+	mockVisitLogger *mocks.MockVisitLogger
+	mockTaskUpdater *mocks.MockTaskUpdater
+	ctrl            *gomock.Controller
+	svc             service.SyncService
+)
+
+func initMocks(t *testing.T) {
+	ctrl = gomock.NewController(t)
+
+	mockSyncRepo = repoMocks.NewMockSyncRepository(ctrl)
+	mockVisitLogger = mocks.NewMockVisitLogger(ctrl)
+	mockTaskUpdater = mocks.NewMockTaskUpdater(ctrl)
+
+	svc = service.NewSyncService(mockSyncRepo, mockVisitLogger, mockTaskUpdater)
+}
+
+func TestSync(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	userID, caregiverID := uuid.NewString(), uuid.NewString()
+	ctx := authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: userID, Role: authModel.RoleCaregiver, CaregiverID: &caregiverID})
+	ownSchedule := &scheduleModel.ScheduleOwnership{CaregiverID: &caregiverID}
+	ownTask := &taskModel.TaskOwnership{CaregiverID: &caregiverID}
+
+	scheduleID, taskID := uuid.NewString(), uuid.NewString()
+	clockInAt := time.Now().Add(-2 * time.Hour)
+	taskAt := clockInAt.Add(30 * time.Minute)
+	clockOutAt := clockInAt.Add(time.Hour)
+	clockIn := model.Event{ID: uuid.NewString(), Type: model.TypeClockIn, OccurredAt: clockInAt, ScheduleID: scheduleID, Latitude: 1.3, Longitude: 103.8}
+	taskUpdate := model.Event{ID: uuid.NewString(), Type: model.TypeTaskUpdate, OccurredAt: taskAt, TaskID: taskID, Status: "completed"}
+	clockOut := model.Event{ID: uuid.NewString(), Type: model.TypeClockOut, OccurredAt: clockOutAt, ScheduleID: scheduleID, Latitude: 1.3, Longitude: 103.8}
+
+	t.Run("TestSync: Applies Events In Order At Device Time", func(t *testing.T) {
+		mockSyncRepo.EXPECT().GetResults(gomock.Any(), userID, []string{clockIn.ID, taskUpdate.ID, clockOut.ID}).Return(map[string]model.EventResult{}, nil).Times(1)
+		mockVisitLogger.EXPECT().GetScheduleOwnership(gomock.Any(), scheduleID).Return(ownSchedule, nil).Times(2)
+		mockTaskUpdater.EXPECT().GetTaskOwnership(gomock.Any(), taskID).Return(ownTask, nil).Times(1)
+		gomock.InOrder(
+			mockVisitLogger.EXPECT().StartVisit(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, req scheduleModel.StartVisitRequest) error {
+					assert.Equal(t, scheduleID, req.ID)
+					assert.Equal(t, clockInAt, *req.CapturedAt)
+					assert.True(t, req.Offline)
+					return nil
+				}),
+			mockTaskUpdater.EXPECT().UpdateTaskStatus(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, req taskModel.UpdateTaskStatusRequest) error {
+					assert.Equal(t, taskID, req.TaskID)
+					assert.Equal(t, "completed", req.Status)
+					assert.Equal(t, taskAt, *req.OccurredAt)
+					return nil
+				}),
+			mockVisitLogger.EXPECT().EndVisit(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, req scheduleModel.EndVisitRequest) error {
+					assert.Equal(t, clockOutAt, *req.CapturedAt)
+					assert.True(t, req.Offline)
+					return nil
+				}),
+		)
+		mockSyncRepo.EXPECT().SaveEvent(gomock.Any(), userID, gomock.Any(), gomock.Any()).Return(nil).Times(3)
+
+		resp, err := svc.Sync(ctx, model.SyncRequest{Events: []model.Event{clockIn, taskUpdate, clockOut}})
+		assert.NoError(t, err)
+		assert.Equal(t, []model.EventResult{
+			{ID: clockIn.ID, Result: model.ResultApplied},
+			{ID: taskUpdate.ID, Result: model.ResultApplied},
+			{ID: clockOut.ID, Result: model.ResultApplied},
+		}, resp.Results)
+	})
+
+	t.Run("TestSync: Replays Synced Events", func(t *testing.T) {
+		mockSyncRepo.EXPECT().GetResults(gomock.Any(), userID, gomock.Any()).
+			Return(map[string]model.EventResult{clockIn.ID: {ID: clockIn.ID, Result: model.ResultApplied}}, nil).Times(1)
+		mockVisitLogger.EXPECT().GetScheduleOwnership(gomock.Any(), scheduleID).Return(ownSchedule, nil).Times(1)
+		mockVisitLogger.EXPECT().EndVisit(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		mockSyncRepo.EXPECT().SaveEvent(gomock.Any(), userID, clockOut, gomock.Any()).Return(nil).Times(1)
+
+		resp, err := svc.Sync(ctx, model.SyncRequest{Events: []model.Event{clockIn, clockOut}})
+		assert.NoError(t, err)
+		assert.Equal(t, model.EventResult{ID: clockIn.ID, Result: model.ResultApplied, Replayed: true}, resp.Results[0])
+		assert.Equal(t, model.EventResult{ID: clockOut.ID, Result: model.ResultApplied}, resp.Results[1])
+	})
+
+	t.Run("TestSync: Duplicate Event In Batch", func(t *testing.T) {
+		mockSyncRepo.EXPECT().GetResults(gomock.Any(), userID, gomock.Any()).Return(map[string]model.EventResult{}, nil).Times(1)
+		mockVisitLogger.EXPECT().GetScheduleOwnership(gomock.Any(), scheduleID).Return(ownSchedule, nil).Times(1)
+		mockVisitLogger.EXPECT().StartVisit(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		mockSyncRepo.EXPECT().SaveEvent(gomock.Any(), userID, clockIn, gomock.Any()).Return(nil).Times(1)
+
+		resp, err := svc.Sync(ctx, model.SyncRequest{Events: []model.Event{clockIn, clockIn}})
+		assert.NoError(t, err)
+		assert.Equal(t, model.ResultApplied, resp.Results[0].Result)
+		assert.True(t, resp.Results[1].Replayed)
+	})
+
+	t.Run("TestSync: Conflict", func(t *testing.T) {
+		conflict := exceptions.ErrConflict.WithDetails("Schedule ID " + scheduleID + " cannot move from completed to in-progress")
+		mockSyncRepo.EXPECT().GetResults(gomock.Any(), userID, gomock.Any()).Return(map[string]model.EventResult{}, nil).Times(1)
+		mockVisitLogger.EXPECT().GetScheduleOwnership(gomock.Any(), scheduleID).Return(ownSchedule, nil).Times(1)
+		mockVisitLogger.EXPECT().StartVisit(gomock.Any(), gomock.Any()).Return(conflict).Times(1)
+		mockSyncRepo.EXPECT().SaveEvent(gomock.Any(), userID, clockIn, model.EventResult{ID: clockIn.ID, Result: model.ResultConflict, Error: conflict}).Return(nil).Times(1)
+
+		resp, err := svc.Sync(ctx, model.SyncRequest{Events: []model.Event{clockIn}})
+		assert.NoError(t, err)
+		assert.Equal(t, model.ResultConflict, resp.Results[0].Result)
+		assert.Equal(t, http.StatusConflict, resp.Results[0].Error.Code)
+	})
+
+	t.Run("TestSync: Other Caregiver's Visit", func(t *testing.T) {
+		otherCaregiverID := uuid.NewString()
+		mockSyncRepo.EXPECT().GetResults(gomock.Any(), userID, gomock.Any()).Return(map[string]model.EventResult{}, nil).Times(1)
+		mockVisitLogger.EXPECT().GetScheduleOwnership(gomock.Any(), scheduleID).Return(&scheduleModel.ScheduleOwnership{CaregiverID: &otherCaregiverID}, nil).Times(1)
+		mockSyncRepo.EXPECT().SaveEvent(gomock.Any(), userID, clockIn, gomock.Any()).Return(nil).Times(1)
+
+		resp, err := svc.Sync(ctx, model.SyncRequest{Events: []model.Event{clockIn}})
+		assert.NoError(t, err)
+		assert.Equal(t, model.ResultRejected, resp.Results[0].Result)
+		assert.Equal(t, http.StatusForbidden, resp.Results[0].Error.Code)
+	})
+
+	t.Run("TestSync: Invalid Event", func(t *testing.T) {
+		invalid := model.Event{ID: uuid.NewString(), Type: model.TypeTaskUpdate, OccurredAt: taskAt}
+		mockSyncRepo.EXPECT().GetResults(gomock.Any(), userID, gomock.Any()).Return(map[string]model.EventResult{}, nil).Times(1)
+
+		resp, err := svc.Sync(ctx, model.SyncRequest{Events: []model.Event{invalid}})
+		assert.NoError(t, err)
+		assert.Equal(t, model.ResultRejected, resp.Results[0].Result)
+		assert.Equal(t, http.StatusBadRequest, resp.Results[0].Error.Code)
+	})
+
+	t.Run("TestSync: Future Device Time", func(t *testing.T) {
+		future := clockIn
+		future.OccurredAt = time.Now().Add(time.Hour)
+		mockSyncRepo.EXPECT().GetResults(gomock.Any(), userID, gomock.Any()).Return(map[string]model.EventResult{}, nil).Times(1)
+		mockSyncRepo.EXPECT().SaveEvent(gomock.Any(), userID, future, gomock.Any()).Return(nil).Times(1)
+
+		resp, err := svc.Sync(ctx, model.SyncRequest{Events: []model.Event{future}})
+		assert.NoError(t, err)
+		assert.Equal(t, model.ResultRejected, resp.Results[0].Result)
+	})
+
+	t.Run("TestSync: Server Error Stops The Batch", func(t *testing.T) {
+		mockSyncRepo.EXPECT().GetResults(gomock.Any(), userID, gomock.Any()).Return(map[string]model.EventResult{}, nil).Times(1)
+		mockVisitLogger.EXPECT().GetScheduleOwnership(gomock.Any(), scheduleID).Return(ownSchedule, nil).Times(1)
+		mockVisitLogger.EXPECT().StartVisit(gomock.Any(), gomock.Any()).Return(exceptions.ErrInternalError).Times(1)
+
+		resp, err := svc.Sync(ctx, model.SyncRequest{Events: []model.Event{clockIn, taskUpdate, clockOut}})
+		assert.NoError(t, err)
+		assert.Equal(t, model.ResultFailed, resp.Results[0].Result)
+		assert.Equal(t, model.EventResult{ID: taskUpdate.ID, Result: model.ResultSkipped}, resp.Results[1])
+		assert.Equal(t, model.EventResult{ID: clockOut.ID, Result: model.ResultSkipped}, resp.Results[2])
+	})
+
+	t.Run("TestSync: Empty Batch", func(t *testing.T) {
+		resp, err := svc.Sync(ctx, model.SyncRequest{})
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+		assert.Equal(t, http.StatusBadRequest, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestSync: Repository Error", func(t *testing.T) {
+		mockSyncRepo.EXPECT().GetResults(gomock.Any(), userID, gomock.Any()).Return(nil, exceptions.ErrInternalError).Times(1)
+
+		resp, err := svc.Sync(ctx, model.SyncRequest{Events: []model.Event{clockIn}})
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
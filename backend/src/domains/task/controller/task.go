@@ -3,9 +3,11 @@ package controller
 import (
 	// Import context
 	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/policy"
 	"mini-evv-logger-backend/responses"
 	"mini-evv-logger-backend/src/domains/task/model" // Import model for DTOs
 	"mini-evv-logger-backend/src/domains/task/service"
+	"mini-evv-logger-backend/utils"
 	"net/http"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,18 +15,43 @@ import (
 
 // TaskController handles HTTP requests for tasks
 type TaskController struct {
-	svc service.TaskService
+	svc        service.TaskService
+	idempotent fiber.Handler // Replays retried task status updates
 }
 
 // NewTaskController creates a new TaskController
-func NewTaskController(svc service.TaskService) *TaskController {
-	return &TaskController{svc: svc}
+func NewTaskController(svc service.TaskService, idempotent fiber.Handler) *TaskController {
+	return &TaskController{svc: svc, idempotent: idempotent}
 }
 
 // Routes sets up the API endpoints for tasks
 func (tc *TaskController) Routes(app fiber.Router) {
 	taskRoutes := app.Group("/tasks")
-	taskRoutes.Post("/:taskId/update", tc.UpdateTaskStatus)
+	taskRoutes.Post("/:taskId/update", policy.Require(policy.UpdateTask, tc.taskResource), tc.idempotent, tc.UpdateTaskStatus)
+	taskRoutes.Patch("/:taskId", policy.Require(policy.ManageSchedule, tc.taskResource), tc.UpdateTask)
+	taskRoutes.Delete("/:taskId", policy.Require(policy.ManageSchedule, tc.taskResource), tc.DeleteTask)
+
+	scheduleTaskRoutes := app.Group("/schedules/:id/tasks")
+	scheduleTaskRoutes.Post("/", policy.Require(policy.ManageSchedule, tc.scheduleResource), tc.CreateTask)
+	scheduleTaskRoutes.Put("/order", policy.Require(policy.ManageSchedule, tc.scheduleResource), tc.ReorderTasks)
+}
+
+// taskResource resolves the ownership of the task in the :taskId route parameter
+func (tc *TaskController) taskResource(c *fiber.Ctx) (*policy.Resource, error) {
+	ownership, err := tc.svc.GetTaskOwnership(c.UserContext(), c.Params("taskId"))
+	if err != nil {
+		return nil, err
+	}
+	return &policy.Resource{CaregiverID: ownership.CaregiverID, BranchID: ownership.BranchID}, nil
+}
+
+// scheduleResource resolves the ownership of the schedule in the :id route parameter
+func (tc *TaskController) scheduleResource(c *fiber.Ctx) (*policy.Resource, error) {
+	ownership, err := tc.svc.GetScheduleOwnership(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return nil, err
+	}
+	return &policy.Resource{CaregiverID: ownership.CaregiverID, BranchID: ownership.BranchID}, nil
 }
 
 // UpdateTaskStatus handles updating a task's status
@@ -43,9 +70,110 @@ func (tc *TaskController) UpdateTaskStatus(c *fiber.Ctx) error {
 
 	req.TaskID = taskID // Set the TaskID from the URL parameter
 
-	err := tc.svc.UpdateTaskStatus(ctx, req)
+	ifMatch, err := utils.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid If-Match header", err.Error())
+	}
+	req.IfMatch = ifMatch
+
+	err = tc.svc.UpdateTaskStatus(ctx, req)
 	if err != nil {
 		return exceptions.HandleError(c, err) // Use the new helper
 	}
 	return responses.OK(c, nil, "Task status updated successfully")
 }
+
+// CreateTask handles adding a task to a schedule
+func (tc *TaskController) CreateTask(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	scheduleID := c.Params("id")
+	if scheduleID == "" {
+		return responses.Error(c, http.StatusBadRequest, "Schedule ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	var req model.CreateTaskRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	req.ScheduleID = scheduleID // Set the ScheduleID from the URL parameter
+
+	task, err := tc.svc.CreateTask(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	c.Set(fiber.HeaderETag, utils.ETag(task.Version))
+	return responses.Created(c, task, "Task created successfully")
+}
+
+// UpdateTask handles editing a task
+func (tc *TaskController) UpdateTask(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	taskID := c.Params("taskId")
+	if taskID == "" {
+		return responses.Error(c, http.StatusBadRequest, "Task ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	var req model.UpdateTaskRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	req.ID = taskID // Set the ID from the URL parameter
+
+	ifMatch, err := utils.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid If-Match header", err.Error())
+	}
+	req.IfMatch = ifMatch
+
+	task, err := tc.svc.UpdateTask(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	c.Set(fiber.HeaderETag, utils.ETag(task.Version))
+	return responses.OK(c, task, "Task updated successfully")
+}
+
+// DeleteTask handles removing a task
+func (tc *TaskController) DeleteTask(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	taskID := c.Params("taskId")
+	if taskID == "" {
+		return responses.Error(c, http.StatusBadRequest, "Task ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	ifMatch, err := utils.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid If-Match header", err.Error())
+	}
+
+	err = tc.svc.DeleteTask(ctx, taskID, ifMatch)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, nil, "Task deleted successfully")
+}
+
+// ReorderTasks handles reordering the tasks of a schedule
+func (tc *TaskController) ReorderTasks(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	scheduleID := c.Params("id")
+	if scheduleID == "" {
+		return responses.Error(c, http.StatusBadRequest, "Schedule ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	var req model.ReorderTasksRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	req.ScheduleID = scheduleID // Set the ScheduleID from the URL parameter
+
+	tasks, err := tc.svc.ReorderTasks(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, tasks, "Tasks reordered successfully")
+}
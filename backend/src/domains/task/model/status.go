@@ -0,0 +1,39 @@
+package model
+
+// Task statuses
+const (
+	TaskPending      = "pending"     // Not started yet
+	TaskInProgress   = "in-progress" // Being worked on
+	TaskCompleted    = "completed"
+	TaskNotCompleted = "not_completed" // Requires a reason
+	TaskCancelled    = "cancelled"     // No longer needed on this visit
+)
+
+// transitions lists the statuses a task may move to from each status while its visit is in progress.
+// A task is started before it gets its outcome, and an outcome is final; changing it afterwards
+// takes a visit correction.
+var transitions = map[string][]string{
+	TaskPending:      {TaskInProgress},
+	TaskInProgress:   {TaskCompleted, TaskNotCompleted, TaskCancelled},
+	TaskCompleted:    {},
+	TaskNotCompleted: {},
+	TaskCancelled:    {},
+}
+
+// CanTransition reports whether a task may move from one status to another
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionConflict is returned with the conflict error of a task status change that is not allowed,
+// either by the transition table or because the visit is not in progress
+type TransitionConflict struct {
+	ScheduleStatus  string `json:"schedule_status"`
+	CurrentStatus   string `json:"current_status"`
+	RequestedStatus string `json:"requested_status"`
+}
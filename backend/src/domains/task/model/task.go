@@ -11,19 +11,73 @@ type Task struct {
 	ID          string    `json:"id" db:"id"`
 	ScheduleID  string    `json:"schedule_id" db:"schedule_id"`
 	Description string    `json:"description" db:"description"`
+	Position    int       `json:"position" db:"position"`       // Order within the schedule, starting at 1
 	Status      string    `json:"status" db:"status"`           // e.g., "pending", "completed", "not_completed"
 	Reason      *string   `json:"reason,omitempty" db:"reason"` // Pointer to allow NULL
+	Version     int       `json:"version" db:"version"`         // Incremented by every change, sent as the ETag
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// IsResolved reports whether the task needs no more attention before clock-out: it was completed,
+// explained as not completed, or cancelled
+func (t *Task) IsResolved() bool {
+	switch t.Status {
+	case TaskCompleted, TaskCancelled:
+		return true
+	case TaskNotCompleted:
+		return t.Reason != nil && *t.Reason != ""
+	}
+	return false
+}
+
+// TaskOwnership identifies who a task's schedule belongs to, for authorization checks
+type TaskOwnership struct {
+	CaregiverID *string `db:"caregiver_id"`
+	BranchID    *string `db:"branch_id"`
+}
+
 // UpdateTaskStatusRequest defines the request body for updating a task status
 type UpdateTaskStatusRequest struct {
-	TaskID string `json:"task_id" validate:"required,uuid"`                                                       // Task ID to update
-	Status string `json:"status" validate:"required,oneof=pending completed not_completed cancelled in-progress"` // e.g., "completed", "not_completed"
-	Reason string `json:"reason,omitempty" validate:"required_if=status not_completed"`                           // Required if status is "not_completed"
+	TaskID     string     `json:"task_id" validate:"required,uuid"`                                                       // Task ID to update
+	Status     string     `json:"status" validate:"required,oneof=pending completed not_completed cancelled in-progress"` // e.g., "completed", "not_completed"
+	Reason     string     `json:"reason,omitempty" validate:"required_if=status not_completed"`                           // Required if status is "not_completed"
+	IfMatch    *int       `json:"-"`                                                                                      // Version from the If-Match header, nil to skip the check
+	OccurredAt *time.Time `json:"-"`                                                                                      // Device time of an update recorded offline, nil for now
+}
+
+// CreateTaskRequest defines the request body for adding a task to a schedule
+type CreateTaskRequest struct {
+	ScheduleID  string `json:"-" validate:"required,uuid"`
+	Description string `json:"description" validate:"required,max=1000"`
+}
+
+// UpdateTaskRequest defines the request body for editing a task; nil fields are left unchanged
+type UpdateTaskRequest struct {
+	ID          string  `json:"-" validate:"required,uuid"`
+	Description *string `json:"description" validate:"omitempty,min=1,max=1000"`
+	IfMatch     *int    `json:"-"` // Version from the If-Match header, nil to skip the check
+	Version     int     `json:"-"` // Set by the service to the version it read; the update applies only while it is current
+}
+
+// ReorderTasksRequest defines the request body for reordering the tasks of a schedule
+type ReorderTasksRequest struct {
+	ScheduleID string   `json:"-" validate:"required,uuid"`
+	TaskIDs    []string `json:"task_ids" validate:"required,min=1,unique,dive,uuid"` // Every task of the schedule, in the new order
 }
 
 func (r *UpdateTaskStatusRequest) Validate() error {
 	return validator.New().Struct(r)
 }
+
+func (r *CreateTaskRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *UpdateTaskRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *ReorderTasksRequest) Validate() error {
+	return validator.New().Struct(r)
+}
@@ -3,8 +3,11 @@ package repository
 import (
 	"context" // Import context
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"mini-evv-logger-backend/exceptions"
+	auditModel "mini-evv-logger-backend/src/domains/audit/model"
+	auditRepo "mini-evv-logger-backend/src/domains/audit/repository"
 	"mini-evv-logger-backend/src/domains/task/model"
 	"time"
 
@@ -19,7 +22,12 @@ import (
 type TaskRepository interface {
 	GetTasksByScheduleID(ctx context.Context, scheduleID string) ([]model.Task, error)
 	GetTaskByID(ctx context.Context, taskID string) (*model.Task, error)
-	UpdateTaskStatus(ctx context.Context, taskID, status string, reason *string) error
+	GetTaskOwnership(ctx context.Context, taskID string) (*model.TaskOwnership, error)
+	UpdateTaskStatus(ctx context.Context, taskID string, version int, status string, reason *string, changedAt time.Time) error
+	CreateTask(ctx context.Context, task model.Task) error
+	UpdateTask(ctx context.Context, req model.UpdateTaskRequest) error
+	DeleteTask(ctx context.Context, taskID string, version int) error
+	ReorderTasks(ctx context.Context, scheduleID string, taskIDs []string) error
 }
 
 // taskRepositoryImpl implements the TaskRepository interface
@@ -36,11 +44,11 @@ func NewTaskRepository(db *sqlx.DB, logger zerolog.Logger) TaskRepository {
 // GetTasksByScheduleID fetches tasks for a given schedule
 func (r *taskRepositoryImpl) GetTasksByScheduleID(ctx context.Context, scheduleID string) ([]model.Task, error) {
 	var tasks []model.Task
-	qb := squirrel.Select("id", "schedule_id", "description", "status", "reason",
-		"created_at", "updated_at").
+	qb := squirrel.Select("id", "schedule_id", "description", "position", "status", "reason",
+		"version", "created_at", "updated_at").
 		From("tasks").
 		Where(squirrel.Eq{"schedule_id": scheduleID}).
-		OrderBy("created_at ASC").
+		OrderBy("position ASC", "created_at ASC").
 		PlaceholderFormat(squirrel.Dollar)
 
 	sqlQuery, args, err := qb.ToSql()
@@ -65,8 +73,8 @@ func (r *taskRepositoryImpl) GetTasksByScheduleID(ctx context.Context, scheduleI
 // GetTaskByID fetches a single task by ID
 func (r *taskRepositoryImpl) GetTaskByID(ctx context.Context, taskID string) (*model.Task, error) {
 	var task model.Task
-	qb := squirrel.Select("id", "schedule_id", "description", "status", "reason",
-		"created_at", "updated_at").
+	qb := squirrel.Select("id", "schedule_id", "description", "position", "status", "reason",
+		"version", "created_at", "updated_at").
 		From("tasks").
 		Where(squirrel.Eq{"id": taskID}).
 		PlaceholderFormat(squirrel.Dollar)
@@ -90,14 +98,43 @@ func (r *taskRepositoryImpl) GetTaskByID(ctx context.Context, taskID string) (*m
 	return &task, nil
 }
 
-// UpdateTaskStatus updates the status and optional reason for a task without pre-checking existence.
-// It relies on the service layer to perform existence checks.
-func (r *taskRepositoryImpl) UpdateTaskStatus(ctx context.Context, taskID, status string, reason *string) error {
+// GetTaskOwnership fetches the caregiver and branch of the schedule a task belongs to
+func (r *taskRepositoryImpl) GetTaskOwnership(ctx context.Context, taskID string) (*model.TaskOwnership, error) {
+	var ownership model.TaskOwnership
+	qb := squirrel.Select("s.caregiver_id", "c.branch_id").
+		From("tasks t").
+		Join("schedules s ON s.id = t.schedule_id").
+		LeftJoin("caregivers c ON c.id = s.caregiver_id").
+		Where(squirrel.Eq{"t.id": taskID}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("task_id", taskID).Msg("Failed to build SQL query for GetTaskOwnership")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &ownership, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.Warn().Str("task_id", taskID).Msg("Task not found in database")
+			return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Task with ID %s not found", taskID))
+		}
+		r.logger.Error().Err(err).Str("task_id", taskID).Msg("Failed to execute SQL query for GetTaskOwnership")
+		return nil, exceptions.ErrInternalError
+	}
+	return &ownership, nil
+}
+
+// UpdateTaskStatus updates the status and optional reason for a task while it is still at version.
+// changedAt is stored as the task's updated_at. A task that was changed in the meantime yields a conflict.
+func (r *taskRepositoryImpl) UpdateTaskStatus(ctx context.Context, taskID string, version int, status string, reason *string, changedAt time.Time) error {
 	qb := squirrel.Update("tasks").
 		Set("status", status).
 		Set("reason", reason).
-		Set("updated_at", time.Now()).
-		Where(squirrel.Eq{"id": taskID}).
+		Set("updated_at", changedAt).
+		Set("version", version+1).
+		Where(squirrel.Eq{"id": taskID, "version": version}).
 		PlaceholderFormat(squirrel.Dollar)
 
 	sqlQuery, args, err := qb.ToSql()
@@ -106,11 +143,214 @@ func (r *taskRepositoryImpl) UpdateTaskStatus(ctx context.Context, taskID, statu
 		return exceptions.ErrInternalError
 	}
 
-	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
+	return r.audited(ctx, taskID, auditModel.ActionTaskStatusUpdated, "UpdateTaskStatus", func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, sqlQuery, args...)
+		if err != nil {
+			r.logger.Error().Err(err).Str("task_id", taskID).Str("status", status).Msg("Failed to execute SQL query for UpdateTaskStatus")
+			return exceptions.ErrInternalError
+		}
+		return r.checkApplied(result, taskID, "UpdateTaskStatus")
+	})
+}
+
+// CreateTask inserts a new task at the end of its schedule's task list
+func (r *taskRepositoryImpl) CreateTask(ctx context.Context, task model.Task) error {
+	qb := squirrel.Insert("tasks").
+		Columns("id", "schedule_id", "description", "status", "position").
+		Values(task.ID, task.ScheduleID, task.Description, task.Status,
+			squirrel.Expr("(SELECT COALESCE(MAX(position), 0) + 1 FROM tasks WHERE schedule_id = ?)", task.ScheduleID)).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", task.ScheduleID).Msg("Failed to build SQL query for CreateTask")
+		return exceptions.ErrInternalError
+	}
+
+	return r.audited(ctx, task.ID, auditModel.ActionTaskCreated, "CreateTask", func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, sqlQuery, args...)
+		if err != nil {
+			r.logger.Error().Err(err).Str("schedule_id", task.ScheduleID).Msg("Failed to execute SQL query for CreateTask")
+			return exceptions.ErrInternalError
+		}
+		return nil
+	})
+}
+
+// UpdateTask updates the provided fields of a task while it is still at req.Version.
+// It relies on the service layer to perform lock checks; a task changed in the meantime yields a conflict.
+func (r *taskRepositoryImpl) UpdateTask(ctx context.Context, req model.UpdateTaskRequest) error {
+	qb := squirrel.Update("tasks").
+		Set("updated_at", time.Now()).
+		Set("version", req.Version+1).
+		Where(squirrel.Eq{"id": req.ID, "version": req.Version}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	if req.Description != nil {
+		qb = qb.Set("description", *req.Description)
+	}
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("task_id", req.ID).Msg("Failed to build SQL query for UpdateTask")
+		return exceptions.ErrInternalError
+	}
+
+	return r.audited(ctx, req.ID, auditModel.ActionTaskUpdated, "UpdateTask", func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, sqlQuery, args...)
+		if err != nil {
+			r.logger.Error().Err(err).Str("task_id", req.ID).Msg("Failed to execute SQL query for UpdateTask")
+			return exceptions.ErrInternalError
+		}
+		return r.checkApplied(result, req.ID, "UpdateTask")
+	})
+}
+
+// DeleteTask removes a task while it is still at version.
+// The positions of the remaining tasks keep their order.
+func (r *taskRepositoryImpl) DeleteTask(ctx context.Context, taskID string, version int) error {
+	qb := squirrel.Delete("tasks").
+		Where(squirrel.Eq{"id": taskID, "version": version}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("task_id", taskID).Msg("Failed to build SQL query for DeleteTask")
+		return exceptions.ErrInternalError
+	}
+
+	return r.audited(ctx, taskID, auditModel.ActionTaskDeleted, "DeleteTask", func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, sqlQuery, args...)
+		if err != nil {
+			r.logger.Error().Err(err).Str("task_id", taskID).Msg("Failed to execute SQL query for DeleteTask")
+			return exceptions.ErrInternalError
+		}
+		return r.checkApplied(result, taskID, "DeleteTask")
+	})
+}
+
+// ReorderTasks numbers the tasks of a schedule in the given order, with an audit event per task, in one transaction.
+// The service layer is responsible for checking that taskIDs holds exactly the schedule's tasks.
+func (r *taskRepositoryImpl) ReorderTasks(ctx context.Context, scheduleID string, taskIDs []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to begin transaction for ReorderTasks")
+		return exceptions.ErrInternalError
+	}
+	defer tx.Rollback() // No-op once committed
+
+	now := time.Now()
+	for i, taskID := range taskIDs {
+		sqlQuery, args, err := squirrel.Update("tasks").
+			Set("position", i+1).
+			Set("updated_at", now).
+			Set("version", squirrel.Expr("version + 1")).
+			Where(squirrel.Eq{"id": taskID, "schedule_id": scheduleID}).
+			PlaceholderFormat(squirrel.Dollar).
+			ToSql()
+		if err != nil {
+			r.logger.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to build SQL query for ReorderTasks")
+			return exceptions.ErrInternalError
+		}
+
+		err = r.audit(ctx, tx, taskID, auditModel.ActionTaskReordered, "ReorderTasks", func() error {
+			_, err := tx.ExecContext(ctx, sqlQuery, args...)
+			if err != nil {
+				r.logger.Error().Err(err).Str("schedule_id", scheduleID).Str("task_id", taskID).Msg("Failed to execute SQL query for ReorderTasks")
+				return exceptions.ErrInternalError
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to commit transaction for ReorderTasks")
+		return exceptions.ErrInternalError
+	}
+	return nil
+}
+
+// checkApplied turns a conditional update that matched no row into a conflict.
+// The service layer has already found the task, so no row means another request changed it first.
+func (r *taskRepositoryImpl) checkApplied(result sql.Result, taskID, method string) error {
+	affected, err := result.RowsAffected()
 	if err != nil {
-		r.logger.Error().Err(err).Str("task_id", taskID).Str("status", status).Msg("Failed to execute SQL query for UpdateTaskStatus")
+		r.logger.Error().Err(err).Str("task_id", taskID).Msgf("Failed to read affected rows for %s", method)
 		return exceptions.ErrInternalError
 	}
+	if affected == 0 {
+		r.logger.Warn().Str("task_id", taskID).Msgf("Conditional update for %s matched no row", method)
+		return exceptions.ErrConflict.WithDetails(fmt.Sprintf("Task ID %s was changed by another request. Reload it and try again.", taskID))
+	}
+	return nil
+}
 
+// audited runs change in a transaction together with the audit event for the task, see audit
+func (r *taskRepositoryImpl) audited(ctx context.Context, taskID, action, method string, change func(tx *sqlx.Tx) error) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.Error().Err(err).Str("task_id", taskID).Msgf("Failed to begin transaction for %s", method)
+		return exceptions.ErrInternalError
+	}
+	defer tx.Rollback() // No-op once committed
+
+	err = r.audit(ctx, tx, taskID, action, method, func() error { return change(tx) })
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error().Err(err).Str("task_id", taskID).Msgf("Failed to commit transaction for %s", method)
+		return exceptions.ErrInternalError
+	}
+	return nil
+}
+
+// audit runs change within tx and appends an audit event with the task's row before and after it.
+// The row is locked first, so that the event shows exactly what change did.
+func (r *taskRepositoryImpl) audit(ctx context.Context, tx *sqlx.Tx, taskID, action, method string, change func() error) error {
+	before, err := auditRepo.Snapshot(ctx, tx, "tasks", taskID)
+	if err != nil {
+		r.logger.Error().Err(err).Str("task_id", taskID).Msgf("Failed to read task before %s", method)
+		return exceptions.ErrInternalError
+	}
+
+	err = change()
+	if err != nil {
+		return err
+	}
+
+	after, err := auditRepo.Snapshot(ctx, tx, "tasks", taskID)
+	if err != nil {
+		r.logger.Error().Err(err).Str("task_id", taskID).Msgf("Failed to read task after %s", method)
+		return exceptions.ErrInternalError
+	}
+
+	if before == nil && after == nil {
+		return nil // The task does not exist, nothing was changed
+	}
+
+	// The event is listed with the task's schedule, which only the row itself knows for most changes
+	row := after
+	if row == nil {
+		row = before
+	}
+	var task struct {
+		ScheduleID string `json:"schedule_id"`
+	}
+	err = json.Unmarshal(row, &task)
+	if err != nil {
+		r.logger.Error().Err(err).Str("task_id", taskID).Msgf("Failed to decode task row for %s", method)
+		return exceptions.ErrInternalError
+	}
+
+	err = auditRepo.Append(ctx, tx, auditModel.Event{ScheduleID: task.ScheduleID, EntityType: auditModel.EntityTask, EntityID: taskID, Action: action, Before: before, After: after})
+	if err != nil {
+		r.logger.Error().Err(err).Str("task_id", taskID).Msgf("Failed to append audit event for %s", method)
+		return exceptions.ErrInternalError
+	}
 	return nil
 }
@@ -3,9 +3,13 @@ package repository_test
 import (
 	"context"
 	"database/sql"
+	"mini-evv-logger-backend/exceptions"
 	pkgmock "mini-evv-logger-backend/pkg_mock"
+	"mini-evv-logger-backend/src/domains/task/model"
 	"mini-evv-logger-backend/src/domains/task/repository"
+	"net/http"
 	"regexp"
+	"strconv"
 	"testing"
 	"time"
 
@@ -33,13 +37,34 @@ func initMocks(t *testing.T) {
 	repo = repository.NewTaskRepository(sqlxMock, pkgmock.InitMockLogger())
 }
 
+const (
+	snapshotQuery = "SELECT to_jsonb(t) FROM tasks t WHERE t.id = $1 FOR UPDATE"
+	auditQuery    = "INSERT INTO audit_events (schedule_id,entity_type,entity_id,action,actor_id,before,after,ip_address,user_agent) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)"
+)
+
+// expectSnapshot expects the audit trail to read the task row; an empty row means it does not exist
+func expectSnapshot(taskID, row string) {
+	rows := sqlmock.NewRows([]string{"to_jsonb"})
+	if row != "" {
+		rows.AddRow([]byte(row))
+	}
+	mockSQL.ExpectQuery(regexp.QuoteMeta(snapshotQuery)).WithArgs(taskID).WillReturnRows(rows)
+}
+
+// expectAuditEvent expects an audit event for the task, listed with its schedule
+func expectAuditEvent(scheduleID, taskID, action string) {
+	mockSQL.ExpectExec(regexp.QuoteMeta(auditQuery)).
+		WithArgs(scheduleID, "task", taskID, action, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
 func TestGetTasksByScheduleID(t *testing.T) {
 	initMocks(t)
 
 	// Define the expected query and result
 	scheduleID := "test-schedule-id"
 
-	query := "SELECT id, schedule_id, description, status, reason, created_at, updated_at FROM tasks WHERE schedule_id = $1 ORDER BY created_at ASC"
+	query := "SELECT id, schedule_id, description, position, status, reason, version, created_at, updated_at FROM tasks WHERE schedule_id = $1 ORDER BY position ASC, created_at ASC"
 
 	t.Run("TestGetTasksByScheduleID: OK", func(t *testing.T) {
 		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
@@ -78,7 +103,7 @@ func TestGetTaskByID(t *testing.T) {
 	// Define the expected query and result
 	taskID := "test-task-id"
 
-	query := "SELECT id, schedule_id, description, status, reason, created_at, updated_at FROM tasks WHERE id = $1"
+	query := "SELECT id, schedule_id, description, position, status, reason, version, created_at, updated_at FROM tasks WHERE id = $1"
 
 	t.Run("TestGetTaskByID: OK", func(t *testing.T) {
 		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
@@ -110,6 +135,33 @@ func TestGetTaskByID(t *testing.T) {
 	})
 }
 
+func TestGetTaskOwnership(t *testing.T) {
+	initMocks(t)
+
+	taskID := "test-task-id"
+
+	query := "SELECT s.caregiver_id, c.branch_id FROM tasks t JOIN schedules s ON s.id = t.schedule_id LEFT JOIN caregivers c ON c.id = s.caregiver_id WHERE t.id = $1"
+
+	t.Run("TestGetTaskOwnership: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(taskID).
+			WillReturnRows(sqlmock.NewRows([]string{"caregiver_id", "branch_id"}).AddRow("test-caregiver-id", nil))
+		ownership, err := repo.GetTaskOwnership(context.Background(), taskID)
+		assert.NoError(t, err)
+		assert.Equal(t, "test-caregiver-id", *ownership.CaregiverID)
+		assert.Nil(t, ownership.BranchID)
+	})
+
+	t.Run("TestGetTaskOwnership: No Rows", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(taskID).
+			WillReturnRows(sqlmock.NewRows([]string{"caregiver_id", "branch_id"}))
+		ownership, err := repo.GetTaskOwnership(context.Background(), taskID)
+		assert.Error(t, err)
+		assert.Nil(t, ownership)
+	})
+}
+
 func TestUpdateTaskStatus(t *testing.T) {
 	initMocks(t)
 
@@ -117,22 +169,203 @@ func TestUpdateTaskStatus(t *testing.T) {
 	taskID := "test-task-id"
 	status := "completed"
 	reason := "Task completed successfully"
+	changedAt := time.Now()
 
-	query := "UPDATE tasks SET status = $1, reason = $2, updated_at = $3 WHERE id = $4"
+	query := "UPDATE tasks SET status = $1, reason = $2, updated_at = $3, version = $4 WHERE id = $5 AND version = $6"
+	row := `{"id":"test-task-id","schedule_id":"test-schedule-id","status":"pending"}`
 
 	t.Run("TestUpdateTaskStatus: OK", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(taskID, row)
 		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
-			WithArgs(status, reason, sqlmock.AnyArg(), taskID).
+			WithArgs(status, reason, changedAt, 3, taskID, 2).
 			WillReturnResult(sqlmock.NewResult(1, 1))
-		err := repo.UpdateTaskStatus(context.Background(), taskID, status, &reason)
+		expectSnapshot(taskID, `{"id":"test-task-id","schedule_id":"test-schedule-id","status":"completed"}`)
+		expectAuditEvent("test-schedule-id", taskID, "task_status_updated")
+		mockSQL.ExpectCommit()
+		err := repo.UpdateTaskStatus(context.Background(), taskID, 2, status, &reason, changedAt)
 		assert.NoError(t, err)
+		assert.NoError(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestUpdateTaskStatus: Changed By Another Request", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(taskID, row)
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(status, reason, changedAt, 3, taskID, 2).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mockSQL.ExpectRollback()
+		err := repo.UpdateTaskStatus(context.Background(), taskID, 2, status, &reason, changedAt)
+		assert.Error(t, err)
+		assert.Equal(t, exceptions.ErrConflict.WithDetails("Task ID "+taskID+" was changed by another request. Reload it and try again.").Error(), err.Error())
 	})
 
 	t.Run("TestUpdateTaskStatus: Error", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(taskID, row)
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(status, reason, changedAt, 3, taskID, 2).
+			WillReturnError(sql.ErrConnDone)
+		mockSQL.ExpectRollback()
+		err := repo.UpdateTaskStatus(context.Background(), taskID, 2, status, &reason, changedAt)
+		assert.Error(t, err)
+	})
+}
+
+func TestCreateTask(t *testing.T) {
+	initMocks(t)
+
+	dummyTask := model.Task{ID: "test-task-id", ScheduleID: "test-schedule-id", Description: "Water plants", Status: "pending"}
+	query := "INSERT INTO tasks (id,schedule_id,description,status,position) VALUES ($1,$2,$3,$4,(SELECT COALESCE(MAX(position), 0) + 1 FROM tasks WHERE schedule_id = $5))"
+
+	t.Run("TestCreateTask: OK", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyTask.ID, "")
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(dummyTask.ID, dummyTask.ScheduleID, dummyTask.Description, dummyTask.Status, dummyTask.ScheduleID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectSnapshot(dummyTask.ID, `{"id":"test-task-id","schedule_id":"test-schedule-id","description":"Water plants"}`)
+		expectAuditEvent(dummyTask.ScheduleID, dummyTask.ID, "task_created")
+		mockSQL.ExpectCommit()
+		err := repo.CreateTask(context.Background(), dummyTask)
+		assert.NoError(t, err)
+		assert.NoError(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestCreateTask: Error", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(dummyTask.ID, "")
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+		mockSQL.ExpectRollback()
+		err := repo.CreateTask(context.Background(), dummyTask)
+		assert.Error(t, err)
+	})
+}
+
+func TestUpdateTask(t *testing.T) {
+	initMocks(t)
+
+	taskID := "test-task-id"
+	description := "Water the garden plants"
+	query := "UPDATE tasks SET updated_at = $1, version = $2, description = $3 WHERE id = $4 AND version = $5"
+	row := `{"id":"test-task-id","schedule_id":"test-schedule-id","description":"Water plants"}`
+
+	t.Run("TestUpdateTask: OK", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(taskID, row)
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(sqlmock.AnyArg(), 2, description, taskID, 1).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectSnapshot(taskID, `{"id":"test-task-id","schedule_id":"test-schedule-id","description":"Water the garden plants"}`)
+		expectAuditEvent("test-schedule-id", taskID, "task_updated")
+		mockSQL.ExpectCommit()
+		err := repo.UpdateTask(context.Background(), model.UpdateTaskRequest{ID: taskID, Description: &description, Version: 1})
+		assert.NoError(t, err)
+		assert.NoError(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestUpdateTask: Changed By Another Request", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(taskID, row)
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(sqlmock.AnyArg(), 2, description, taskID, 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mockSQL.ExpectRollback()
+		err := repo.UpdateTask(context.Background(), model.UpdateTaskRequest{ID: taskID, Description: &description, Version: 1})
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusConflict, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestUpdateTask: Error", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(taskID, row)
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+		mockSQL.ExpectRollback()
+		err := repo.UpdateTask(context.Background(), model.UpdateTaskRequest{ID: taskID, Description: &description})
+		assert.Error(t, err)
+	})
+}
+
+func TestDeleteTask(t *testing.T) {
+	initMocks(t)
+
+	taskID := "test-task-id"
+	query := "DELETE FROM tasks WHERE id = $1 AND version = $2"
+	row := `{"id":"test-task-id","schedule_id":"test-schedule-id","version":1}`
+
+	t.Run("TestDeleteTask: OK", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(taskID, row)
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(taskID, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		// The deleted task is listed with its schedule using the row before the change
+		expectSnapshot(taskID, "")
+		mockSQL.ExpectExec(regexp.QuoteMeta(auditQuery)).
+			WithArgs("test-schedule-id", "task", taskID, "task_deleted", nil, &row, nil, nil, nil).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mockSQL.ExpectCommit()
+		err := repo.DeleteTask(context.Background(), taskID, 1)
+		assert.NoError(t, err)
+		assert.NoError(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestDeleteTask: Changed By Another Request", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(taskID, row)
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(taskID, 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mockSQL.ExpectRollback()
+		err := repo.DeleteTask(context.Background(), taskID, 1)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusConflict, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestDeleteTask: Error", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(taskID, row)
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+		mockSQL.ExpectRollback()
+		err := repo.DeleteTask(context.Background(), taskID, 1)
+		assert.Error(t, err)
+	})
+}
+
+func TestReorderTasks(t *testing.T) {
+	initMocks(t)
+
+	scheduleID := "test-schedule-id"
+	taskIDs := []string{"task-id-2", "task-id-1"}
+	query := "UPDATE tasks SET position = $1, updated_at = $2, version = version + 1 WHERE id = $3 AND schedule_id = $4"
+
+	t.Run("TestReorderTasks: OK", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		for i, taskID := range taskIDs {
+			expectSnapshot(taskID, `{"id":"`+taskID+`","schedule_id":"test-schedule-id","position":`+strconv.Itoa(2-i)+`}`)
+			mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+				WithArgs(i+1, sqlmock.AnyArg(), taskID, scheduleID).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			expectSnapshot(taskID, `{"id":"`+taskID+`","schedule_id":"test-schedule-id","position":`+strconv.Itoa(i+1)+`}`)
+			expectAuditEvent(scheduleID, taskID, "task_reordered")
+		}
+		mockSQL.ExpectCommit()
+		err := repo.ReorderTasks(context.Background(), scheduleID, taskIDs)
+		assert.NoError(t, err)
+		assert.NoError(t, mockSQL.ExpectationsWereMet())
+	})
+
+	t.Run("TestReorderTasks: Error", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		expectSnapshot(taskIDs[0], `{"id":"task-id-2","schedule_id":"test-schedule-id","position":2}`)
 		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
-			WithArgs(status, reason, sqlmock.AnyArg(), taskID).
 			WillReturnError(sql.ErrConnDone)
-		err := repo.UpdateTaskStatus(context.Background(), taskID, status, &reason)
+		mockSQL.ExpectRollback()
+		err := repo.ReorderTasks(context.Background(), scheduleID, taskIDs)
 		assert.Error(t, err)
+		assert.NoError(t, mockSQL.ExpectationsWereMet())
 	})
 }
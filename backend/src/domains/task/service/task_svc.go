@@ -4,26 +4,38 @@ import (
 	"context" // Import context
 	"fmt"
 	"mini-evv-logger-backend/exceptions"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	scheduleModel "mini-evv-logger-backend/src/domains/schedule/model"
+	scheduleRepo "mini-evv-logger-backend/src/domains/schedule/repository"
 	"mini-evv-logger-backend/src/domains/task/model"
 	"mini-evv-logger-backend/src/domains/task/repository"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
 
 // TaskService defines the interface for task business logic
 type TaskService interface {
 	GetTasksBySchedule(ctx context.Context, scheduleID string) ([]model.Task, error)
+	GetTaskOwnership(ctx context.Context, taskID string) (*model.TaskOwnership, error)
+	GetScheduleOwnership(ctx context.Context, scheduleID string) (*model.TaskOwnership, error)
 	UpdateTaskStatus(ctx context.Context, req model.UpdateTaskStatusRequest) error
+	CreateTask(ctx context.Context, req model.CreateTaskRequest) (*model.Task, error)
+	UpdateTask(ctx context.Context, req model.UpdateTaskRequest) (*model.Task, error)
+	DeleteTask(ctx context.Context, taskID string, ifMatch *int) error
+	ReorderTasks(ctx context.Context, req model.ReorderTasksRequest) ([]model.Task, error)
 }
 
 // taskServiceImpl implements the TaskService interface
 type taskServiceImpl struct {
-	repo repository.TaskRepository
+	repo         repository.TaskRepository
+	scheduleRepo scheduleRepo.ScheduleRepository
 }
 
 // NewTaskService creates a new TaskService (returns interface)
-func NewTaskService(repo repository.TaskRepository) TaskService {
-	return &taskServiceImpl{repo: repo}
+func NewTaskService(repo repository.TaskRepository, scheduleRepo scheduleRepo.ScheduleRepository) TaskService {
+	return &taskServiceImpl{repo: repo, scheduleRepo: scheduleRepo}
 }
 
 // GetTasksBySchedule fetches tasks for a specific schedule
@@ -37,9 +49,41 @@ func (s *taskServiceImpl) GetTasksBySchedule(ctx context.Context, scheduleID str
 	return tasks, nil
 }
 
+// GetTaskOwnership fetches who a task's schedule belongs to, for authorization checks
+func (s *taskServiceImpl) GetTaskOwnership(ctx context.Context, taskID string) (*model.TaskOwnership, error) {
+	_, err := uuid.Parse(taskID)
+	if err != nil {
+		log.Error().Err(err).Str("task_id", taskID).Msg("Invalid UUID format for task ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid task ID format")
+	}
+
+	ownership, err := s.repo.GetTaskOwnership(ctx, taskID)
+	if err != nil {
+		log.Error().Err(err).Str("task_id", taskID).Msg("Failed to fetch task ownership from repository")
+		return nil, err
+	}
+	return ownership, nil
+}
+
+// GetScheduleOwnership fetches who a schedule belongs to, for authorizing changes to its task list
+func (s *taskServiceImpl) GetScheduleOwnership(ctx context.Context, scheduleID string) (*model.TaskOwnership, error) {
+	_, err := uuid.Parse(scheduleID)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", scheduleID).Msg("Invalid UUID format for schedule ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid schedule ID format")
+	}
+
+	ownership, err := s.scheduleRepo.GetScheduleOwnership(ctx, scheduleID)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to fetch schedule ownership from repository")
+		return nil, err
+	}
+	return &model.TaskOwnership{CaregiverID: ownership.CaregiverID, BranchID: ownership.BranchID}, nil
+}
+
 // UpdateTaskStatus updates a task's status and optional reason
 func (s *taskServiceImpl) UpdateTaskStatus(ctx context.Context, req model.UpdateTaskStatusRequest) error {
-	log.Info().Str("task_id", req.TaskID).Str("status", req.Status).Str("reason", req.Reason).Msg("Attempting to update task status")
+	log.Info().Str("task_id", req.TaskID).Str("user_id", authModel.ActorID(ctx)).Str("status", req.Status).Str("reason", req.Reason).Msg("Attempting to update task status")
 
 	err := req.Validate()
 	if err != nil {
@@ -47,16 +91,26 @@ func (s *taskServiceImpl) UpdateTaskStatus(ctx context.Context, req model.Update
 		return exceptions.ErrBadRequest.WithDetails(err.Error())
 	}
 
-	// 1. Check if the task exists
+	// 1. Check if the task exists and the state of its visit
 	task, err := s.repo.GetTaskByID(ctx, req.TaskID)
 	if err != nil {
 		log.Error().Err(err).Str("task_id", req.TaskID).Msg("Failed to retrieve task before updating status")
 		return err
 	}
+	schedule, err := s.scheduleRepo.GetScheduleByID(ctx, task.ScheduleID)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", task.ScheduleID).Msg("Failed to retrieve schedule before updating task status")
+		return err
+	}
 
-	// 2. Apply business logic: validate status transition if needed
-	if task.Status == "completed" && req.Status == "pending" {
-		return exceptions.ErrConflict.WithDetails(fmt.Sprintf("Task ID %s is already completed. Cannot change to pending.", req.TaskID))
+	// 2. Apply business logic: the client's copy must be current, the visit in progress and the transition allowed
+	err = checkIfMatch(task, req.IfMatch)
+	if err != nil {
+		return err
+	}
+	err = checkTransition(task, schedule, req.Status)
+	if err != nil {
+		return err
 	}
 
 	var reasonPtr *string
@@ -64,11 +118,207 @@ func (s *taskServiceImpl) UpdateTaskStatus(ctx context.Context, req model.Update
 		reasonPtr = &req.Reason
 	}
 
+	changedAt := time.Now()
+	if req.OccurredAt != nil {
+		changedAt = *req.OccurredAt
+	}
+
 	// 3. Perform the update via repository
-	err = s.repo.UpdateTaskStatus(ctx, req.TaskID, req.Status, reasonPtr)
+	err = s.repo.UpdateTaskStatus(ctx, req.TaskID, task.Version, req.Status, reasonPtr, changedAt)
 	if err != nil {
 		log.Error().Err(err).Str("task_id", req.TaskID).Msg("Failed to update task status in repository")
 		return err
 	}
 	return nil
 }
+
+// CreateTask adds a pending task to the end of a schedule's task list
+func (s *taskServiceImpl) CreateTask(ctx context.Context, req model.CreateTaskRequest) (*model.Task, error) {
+	log.Info().Str("schedule_id", req.ScheduleID).Str("user_id", authModel.ActorID(ctx)).Msg("Attempting to create task")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for CreateTaskRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	// 1. Check that the schedule exists and its tasks can still be edited
+	err = s.checkTasksEditable(ctx, req.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Perform the insert via repository
+	task := model.Task{
+		ID:          uuid.NewString(),
+		ScheduleID:  req.ScheduleID,
+		Description: req.Description,
+		Status:      "pending",
+	}
+	err = s.repo.CreateTask(ctx, task)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", req.ScheduleID).Msg("Failed to create task in repository")
+		return nil, err
+	}
+
+	return s.repo.GetTaskByID(ctx, task.ID)
+}
+
+// UpdateTask edits the description of a task
+func (s *taskServiceImpl) UpdateTask(ctx context.Context, req model.UpdateTaskRequest) (*model.Task, error) {
+	log.Info().Str("task_id", req.ID).Str("user_id", authModel.ActorID(ctx)).Msg("Attempting to update task")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for UpdateTaskRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	// 1. Check if the task exists and its schedule can still be edited
+	task, err := s.repo.GetTaskByID(ctx, req.ID)
+	if err != nil {
+		log.Error().Err(err).Str("task_id", req.ID).Msg("Failed to retrieve task before update")
+		return nil, err
+	}
+	err = s.checkTasksEditable(ctx, task.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	err = checkIfMatch(task, req.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	req.Version = task.Version
+
+	// 2. Perform the update via repository
+	err = s.repo.UpdateTask(ctx, req)
+	if err != nil {
+		log.Error().Err(err).Str("task_id", req.ID).Msg("Failed to update task in repository")
+		return nil, err
+	}
+
+	return s.repo.GetTaskByID(ctx, req.ID)
+}
+
+// DeleteTask removes a task from its schedule. A non-nil ifMatch must be the task's current version.
+func (s *taskServiceImpl) DeleteTask(ctx context.Context, taskID string, ifMatch *int) error {
+	log.Info().Str("task_id", taskID).Str("user_id", authModel.ActorID(ctx)).Msg("Attempting to delete task")
+
+	_, err := uuid.Parse(taskID)
+	if err != nil {
+		log.Error().Err(err).Str("task_id", taskID).Msg("Invalid UUID format for task ID")
+		return exceptions.ErrBadRequest.WithDetails("Invalid task ID format")
+	}
+
+	// 1. Check if the task exists and its schedule can still be edited
+	task, err := s.repo.GetTaskByID(ctx, taskID)
+	if err != nil {
+		log.Error().Err(err).Str("task_id", taskID).Msg("Failed to retrieve task before delete")
+		return err
+	}
+	err = s.checkTasksEditable(ctx, task.ScheduleID)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(task, ifMatch)
+	if err != nil {
+		return err
+	}
+
+	// 2. Perform the delete via repository
+	err = s.repo.DeleteTask(ctx, taskID, task.Version)
+	if err != nil {
+		log.Error().Err(err).Str("task_id", taskID).Msg("Failed to delete task in repository")
+		return err
+	}
+	return nil
+}
+
+// ReorderTasks puts the tasks of a schedule in the given order
+func (s *taskServiceImpl) ReorderTasks(ctx context.Context, req model.ReorderTasksRequest) ([]model.Task, error) {
+	log.Info().Str("schedule_id", req.ScheduleID).Str("user_id", authModel.ActorID(ctx)).Msg("Attempting to reorder tasks")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for ReorderTasksRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	// 1. Check that the schedule exists and its tasks can still be edited
+	err = s.checkTasksEditable(ctx, req.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Check that the new order lists every task of the schedule exactly once
+	tasks, err := s.repo.GetTasksByScheduleID(ctx, req.ScheduleID)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", req.ScheduleID).Msg("Failed to fetch tasks before reordering")
+		return nil, err
+	}
+	current := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		current[task.ID] = true
+	}
+	if len(req.TaskIDs) != len(current) {
+		return nil, exceptions.ErrBadRequest.WithDetails(fmt.Sprintf("task_ids must list all %d tasks of the schedule", len(current)))
+	}
+	for _, taskID := range req.TaskIDs {
+		if !current[taskID] {
+			return nil, exceptions.ErrBadRequest.WithDetails(fmt.Sprintf("Task ID %s does not belong to schedule ID %s", taskID, req.ScheduleID))
+		}
+	}
+
+	// 3. Perform the update via repository
+	err = s.repo.ReorderTasks(ctx, req.ScheduleID, req.TaskIDs)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", req.ScheduleID).Msg("Failed to reorder tasks in repository")
+		return nil, err
+	}
+
+	return s.repo.GetTasksByScheduleID(ctx, req.ScheduleID)
+}
+
+// checkTasksEditable verifies that the schedule exists and has not been completed.
+// The task list of a completed visit is part of the visit record and is locked until the visit is reopened.
+func (s *taskServiceImpl) checkTasksEditable(ctx context.Context, scheduleID string) error {
+	schedule, err := s.scheduleRepo.GetScheduleByID(ctx, scheduleID)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to retrieve schedule before editing tasks")
+		return err
+	}
+	if schedule.Status == scheduleModel.StatusCompleted {
+		return exceptions.ErrConflict.WithDetails(fmt.Sprintf("Schedule ID %s is completed. Tasks can no longer be edited.", scheduleID))
+	}
+	return nil
+}
+
+// checkTransition verifies that a task may move to status: only while its visit is in progress, and only
+// as the transition table allows. Tasks of a completed visit are changed through a visit correction.
+func checkTransition(task *model.Task, schedule *scheduleModel.Schedule, status string) error {
+	conflict := model.TransitionConflict{ScheduleStatus: string(schedule.Status), CurrentStatus: task.Status, RequestedStatus: status}
+	switch {
+	case schedule.Status == scheduleModel.StatusCompleted:
+		return exceptions.ErrConflict.
+			WithDetails(fmt.Sprintf("Schedule ID %s is completed. Request a correction to change the status of task ID %s.", schedule.ID, task.ID)).
+			WithData(conflict)
+	case schedule.Status != scheduleModel.StatusInProgress:
+		return exceptions.ErrConflict.
+			WithDetails(fmt.Sprintf("Schedule ID %s is %s. Task status can only be updated while the visit is in progress.", schedule.ID, schedule.Status)).
+			WithData(conflict)
+	case !model.CanTransition(task.Status, status):
+		return exceptions.ErrConflict.
+			WithDetails(fmt.Sprintf("Task ID %s cannot move from %s to %s", task.ID, task.Status, status)).
+			WithData(conflict)
+	}
+	return nil
+}
+
+// checkIfMatch refuses a change made from an outdated copy of the task.
+// A nil ifMatch means the client did not send If-Match and skips the check.
+func checkIfMatch(task *model.Task, ifMatch *int) error {
+	if ifMatch != nil && *ifMatch != task.Version {
+		return exceptions.ErrPreconditionFailed.WithDetails(fmt.Sprintf("Task ID %s is at version %d, not %d", task.ID, task.Version, *ifMatch))
+	}
+	return nil
+}
@@ -2,10 +2,15 @@ package service_test
 
 import (
 	"context"
+	"mini-evv-logger-backend/exceptions"
+	scheduleMocks "mini-evv-logger-backend/src/domains/schedule/mocks/repository"
+	scheduleModel "mini-evv-logger-backend/src/domains/schedule/model"
 	mocks "mini-evv-logger-backend/src/domains/task/mocks/repository"
 	"mini-evv-logger-backend/src/domains/task/model"
 	"mini-evv-logger-backend/src/domains/task/service"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -13,17 +18,19 @@ import (
 )
 
 var (
-	mockTaskRepo *mocks.MockTaskRepository
-	ctrl         *gomock.Controller
-	svc          service.TaskService
+	mockTaskRepo     *mocks.MockTaskRepository
+	mockScheduleRepo *scheduleMocks.MockScheduleRepository
+	ctrl             *gomock.Controller
+	svc              service.TaskService
 )
 
 func initMocks(t *testing.T) {
 	ctrl = gomock.NewController(t)
 
 	mockTaskRepo = mocks.NewMockTaskRepository(ctrl)
+	mockScheduleRepo = scheduleMocks.NewMockScheduleRepository(ctrl)
 
-	svc = service.NewTaskService(mockTaskRepo)
+	svc = service.NewTaskService(mockTaskRepo, mockScheduleRepo)
 }
 
 func TestGetTasksByScheduleID(t *testing.T) {
@@ -61,18 +68,47 @@ func TestGetTasksByScheduleID(t *testing.T) {
 	})
 }
 
-func TestUpdateTaskStatus(t *testing.T) {
+func TestGetTaskOwnership(t *testing.T) {
 	initMocks(t)
 
 	defer ctrl.Finish()
 
 	dummyTaskID := uuid.NewString()
+
+	t.Run("TestGetTaskOwnership: OK", func(t *testing.T) {
+		caregiverID := uuid.NewString()
+		mockTaskRepo.EXPECT().GetTaskOwnership(gomock.Any(), dummyTaskID).Return(&model.TaskOwnership{CaregiverID: &caregiverID}, nil).Times(1)
+
+		ownership, err := svc.GetTaskOwnership(context.Background(), dummyTaskID)
+		assert.NoError(t, err)
+		assert.Equal(t, caregiverID, *ownership.CaregiverID)
+	})
+
+	t.Run("TestGetTaskOwnership: Invalid UUID", func(t *testing.T) {
+		ownership, err := svc.GetTaskOwnership(context.Background(), "invalid-uuid")
+		assert.Error(t, err)
+		assert.Nil(t, ownership)
+	})
+}
+
+func TestUpdateTaskStatus(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyTaskID, dummyScheduleID := uuid.NewString(), uuid.NewString()
 	dummyStatus := "completed"
 	dummyReason := "Task completed successfully"
+	inProgress := &scheduleModel.Schedule{ID: dummyScheduleID, Status: scheduleModel.StatusInProgress}
 
 	t.Run("TestUpdateTaskStatus: OK", func(t *testing.T) {
-		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), dummyTaskID).Return(&model.Task{ID: dummyTaskID, Status: "pending"}, nil).Times(1)
-		mockTaskRepo.EXPECT().UpdateTaskStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), dummyTaskID).Return(&model.Task{ID: dummyTaskID, ScheduleID: dummyScheduleID, Status: "in-progress", Version: 2}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(inProgress, nil).Times(1)
+		mockTaskRepo.EXPECT().UpdateTaskStatus(gomock.Any(), dummyTaskID, 2, dummyStatus, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, _ int, _ string, _ *string, changedAt time.Time) error {
+				assert.WithinDuration(t, time.Now(), changedAt, time.Minute)
+				return nil
+			}).Times(1)
 
 		err := svc.UpdateTaskStatus(context.Background(), model.UpdateTaskStatusRequest{
 			TaskID: dummyTaskID,
@@ -82,6 +118,36 @@ func TestUpdateTaskStatus(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("TestUpdateTaskStatus: Device Time", func(t *testing.T) {
+		deviceTime := time.Now().Add(-2 * time.Hour)
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), dummyTaskID).Return(&model.Task{ID: dummyTaskID, ScheduleID: dummyScheduleID, Status: "in-progress", Version: 1}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(inProgress, nil).Times(1)
+		mockTaskRepo.EXPECT().UpdateTaskStatus(gomock.Any(), dummyTaskID, 1, dummyStatus, gomock.Any(), deviceTime).Return(nil).Times(1)
+
+		err := svc.UpdateTaskStatus(context.Background(), model.UpdateTaskStatusRequest{
+			TaskID:     dummyTaskID,
+			Status:     dummyStatus,
+			Reason:     dummyReason,
+			OccurredAt: &deviceTime,
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestUpdateTaskStatus: Stale If-Match", func(t *testing.T) {
+		staleVersion := 1
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), dummyTaskID).Return(&model.Task{ID: dummyTaskID, ScheduleID: dummyScheduleID, Status: "pending", Version: 2}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(inProgress, nil).Times(1)
+
+		err := svc.UpdateTaskStatus(context.Background(), model.UpdateTaskStatusRequest{
+			TaskID:  dummyTaskID,
+			Status:  dummyStatus,
+			Reason:  dummyReason,
+			IfMatch: &staleVersion,
+		})
+		assert.Error(t, err)
+		assert.Equal(t, exceptions.ErrPreconditionFailed.WithDetails("Task ID "+dummyTaskID+" is at version 2, not 1").Error(), err.Error())
+	})
+
 	t.Run("TestUpdateTaskStatus: Task Not Found", func(t *testing.T) {
 		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), gomock.Any()).Return(nil, assert.AnError).Times(1)
 
@@ -94,7 +160,8 @@ func TestUpdateTaskStatus(t *testing.T) {
 	})
 
 	t.Run("TestUpdateTaskStatus: Invalid Status Transition", func(t *testing.T) {
-		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), gomock.Any()).Return(&model.Task{ID: dummyTaskID, Status: "completed"}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), gomock.Any()).Return(&model.Task{ID: dummyTaskID, ScheduleID: dummyScheduleID, Status: "completed"}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(inProgress, nil).Times(1)
 
 		err := svc.UpdateTaskStatus(context.Background(), model.UpdateTaskStatusRequest{
 			TaskID: dummyTaskID,
@@ -102,5 +169,227 @@ func TestUpdateTaskStatus(t *testing.T) {
 			Reason: dummyReason,
 		})
 		assert.Error(t, err)
+		assert.Equal(t, exceptions.ErrConflict.WithDetails("Task ID "+dummyTaskID+" cannot move from completed to pending").Error(), err.Error())
+		assert.Equal(t, model.TransitionConflict{ScheduleStatus: "in-progress", CurrentStatus: "completed", RequestedStatus: "pending"}, err.(*exceptions.CustomError).Data)
+	})
+
+	t.Run("TestUpdateTaskStatus: Start Task", func(t *testing.T) {
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), dummyTaskID).Return(&model.Task{ID: dummyTaskID, ScheduleID: dummyScheduleID, Status: "pending", Version: 1}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(inProgress, nil).Times(1)
+		mockTaskRepo.EXPECT().UpdateTaskStatus(gomock.Any(), dummyTaskID, 1, "in-progress", gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		err := svc.UpdateTaskStatus(context.Background(), model.UpdateTaskStatusRequest{TaskID: dummyTaskID, Status: "in-progress"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestUpdateTaskStatus: Outcome Before Start", func(t *testing.T) {
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), dummyTaskID).Return(&model.Task{ID: dummyTaskID, ScheduleID: dummyScheduleID, Status: "pending"}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(inProgress, nil).Times(1)
+
+		err := svc.UpdateTaskStatus(context.Background(), model.UpdateTaskStatusRequest{TaskID: dummyTaskID, Status: dummyStatus})
+		assert.Error(t, err)
+		assert.Equal(t, model.TransitionConflict{ScheduleStatus: "in-progress", CurrentStatus: "pending", RequestedStatus: "completed"}, err.(*exceptions.CustomError).Data)
+	})
+
+	t.Run("TestUpdateTaskStatus: Outcome Is Final", func(t *testing.T) {
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), dummyTaskID).Return(&model.Task{ID: dummyTaskID, ScheduleID: dummyScheduleID, Status: "completed"}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(inProgress, nil).Times(1)
+
+		err := svc.UpdateTaskStatus(context.Background(), model.UpdateTaskStatusRequest{TaskID: dummyTaskID, Status: "not_completed", Reason: "Client declined"})
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusConflict, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestUpdateTaskStatus: Cancelled Task", func(t *testing.T) {
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), gomock.Any()).Return(&model.Task{ID: dummyTaskID, ScheduleID: dummyScheduleID, Status: "cancelled"}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(inProgress, nil).Times(1)
+
+		err := svc.UpdateTaskStatus(context.Background(), model.UpdateTaskStatusRequest{TaskID: dummyTaskID, Status: "completed"})
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusConflict, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestUpdateTaskStatus: Visit Not Started", func(t *testing.T) {
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), dummyTaskID).Return(&model.Task{ID: dummyTaskID, ScheduleID: dummyScheduleID, Status: "pending"}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(&scheduleModel.Schedule{ID: dummyScheduleID, Status: scheduleModel.StatusUpcoming}, nil).Times(1)
+
+		err := svc.UpdateTaskStatus(context.Background(), model.UpdateTaskStatusRequest{TaskID: dummyTaskID, Status: dummyStatus})
+		assert.Error(t, err)
+		assert.Equal(t, exceptions.ErrConflict.WithDetails("Schedule ID "+dummyScheduleID+" is upcoming. Task status can only be updated while the visit is in progress.").Error(), err.Error())
+		assert.Equal(t, model.TransitionConflict{ScheduleStatus: "upcoming", CurrentStatus: "pending", RequestedStatus: "completed"}, err.(*exceptions.CustomError).Data)
+	})
+
+	t.Run("TestUpdateTaskStatus: Visit Completed", func(t *testing.T) {
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), dummyTaskID).Return(&model.Task{ID: dummyTaskID, ScheduleID: dummyScheduleID, Status: "not_completed"}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(&scheduleModel.Schedule{ID: dummyScheduleID, Status: scheduleModel.StatusCompleted}, nil).Times(1)
+
+		err := svc.UpdateTaskStatus(context.Background(), model.UpdateTaskStatusRequest{TaskID: dummyTaskID, Status: dummyStatus})
+		assert.Error(t, err)
+		assert.Equal(t, exceptions.ErrConflict.WithDetails("Schedule ID "+dummyScheduleID+" is completed. Request a correction to change the status of task ID "+dummyTaskID+".").Error(), err.Error())
+	})
+
+	t.Run("TestUpdateTaskStatus: Schedule Not Found", func(t *testing.T) {
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), dummyTaskID).Return(&model.Task{ID: dummyTaskID, ScheduleID: dummyScheduleID, Status: "pending"}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(nil, exceptions.ErrNotFound).Times(1)
+
+		err := svc.UpdateTaskStatus(context.Background(), model.UpdateTaskStatusRequest{TaskID: dummyTaskID, Status: dummyStatus})
+		assert.Error(t, err)
+		assert.Equal(t, exceptions.ErrNotFound.Error(), err.Error())
+	})
+}
+
+func TestCreateTask(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyScheduleID := uuid.NewString()
+	dummyRequest := model.CreateTaskRequest{ScheduleID: dummyScheduleID, Description: "Water plants"}
+
+	t.Run("TestCreateTask: OK", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(&scheduleModel.Schedule{ID: dummyScheduleID, Status: "upcoming"}, nil).Times(1)
+		mockTaskRepo.EXPECT().CreateTask(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, task model.Task) error {
+				assert.Equal(t, dummyScheduleID, task.ScheduleID)
+				assert.Equal(t, "pending", task.Status)
+				return nil
+			}).Times(1)
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), gomock.Any()).Return(&model.Task{ScheduleID: dummyScheduleID, Description: "Water plants", Position: 3}, nil).Times(1)
+
+		task, err := svc.CreateTask(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, task.Position)
+	})
+
+	t.Run("TestCreateTask: Schedule Completed", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(&scheduleModel.Schedule{ID: dummyScheduleID, Status: "completed"}, nil).Times(1)
+
+		task, err := svc.CreateTask(context.Background(), dummyRequest)
+		assert.Error(t, err)
+		assert.Nil(t, task)
+		assert.Equal(t, exceptions.ErrConflict.WithDetails("Schedule ID "+dummyScheduleID+" is completed. Tasks can no longer be edited.").Error(), err.Error())
+	})
+
+	t.Run("TestCreateTask: Missing Description", func(t *testing.T) {
+		task, err := svc.CreateTask(context.Background(), model.CreateTaskRequest{ScheduleID: dummyScheduleID})
+		assert.Error(t, err)
+		assert.Nil(t, task)
+	})
+}
+
+func TestUpdateTask(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyTaskID, dummyScheduleID := uuid.NewString(), uuid.NewString()
+	description := "Water the garden plants"
+	dummyRequest := model.UpdateTaskRequest{ID: dummyTaskID, Description: &description}
+
+	t.Run("TestUpdateTask: OK", func(t *testing.T) {
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), dummyTaskID).Return(&model.Task{ID: dummyTaskID, ScheduleID: dummyScheduleID, Version: 4}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(&scheduleModel.Schedule{ID: dummyScheduleID, Status: "in-progress"}, nil).Times(1)
+		expectedRequest := dummyRequest
+		expectedRequest.Version = 4
+		mockTaskRepo.EXPECT().UpdateTask(gomock.Any(), expectedRequest).Return(nil).Times(1)
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), dummyTaskID).Return(&model.Task{ID: dummyTaskID, Description: description}, nil).Times(1)
+
+		task, err := svc.UpdateTask(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+		assert.Equal(t, description, task.Description)
+	})
+
+	t.Run("TestUpdateTask: Schedule Completed", func(t *testing.T) {
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), dummyTaskID).Return(&model.Task{ID: dummyTaskID, ScheduleID: dummyScheduleID}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(&scheduleModel.Schedule{ID: dummyScheduleID, Status: "completed"}, nil).Times(1)
+
+		task, err := svc.UpdateTask(context.Background(), dummyRequest)
+		assert.Error(t, err)
+		assert.Nil(t, task)
+	})
+}
+
+func TestDeleteTask(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyTaskID, dummyScheduleID := uuid.NewString(), uuid.NewString()
+
+	t.Run("TestDeleteTask: OK", func(t *testing.T) {
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), dummyTaskID).Return(&model.Task{ID: dummyTaskID, ScheduleID: dummyScheduleID}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(&scheduleModel.Schedule{ID: dummyScheduleID, Status: "upcoming"}, nil).Times(1)
+		mockTaskRepo.EXPECT().DeleteTask(gomock.Any(), dummyTaskID, 0).Return(nil).Times(1)
+
+		err := svc.DeleteTask(context.Background(), dummyTaskID, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestDeleteTask: Stale If-Match", func(t *testing.T) {
+		staleVersion := 2
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), dummyTaskID).Return(&model.Task{ID: dummyTaskID, ScheduleID: dummyScheduleID, Version: 3}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(&scheduleModel.Schedule{ID: dummyScheduleID, Status: "upcoming"}, nil).Times(1)
+
+		err := svc.DeleteTask(context.Background(), dummyTaskID, &staleVersion)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestDeleteTask: Schedule Completed", func(t *testing.T) {
+		mockTaskRepo.EXPECT().GetTaskByID(gomock.Any(), dummyTaskID).Return(&model.Task{ID: dummyTaskID, ScheduleID: dummyScheduleID}, nil).Times(1)
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(&scheduleModel.Schedule{ID: dummyScheduleID, Status: "completed"}, nil).Times(1)
+
+		err := svc.DeleteTask(context.Background(), dummyTaskID, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("TestDeleteTask: Invalid UUID", func(t *testing.T) {
+		err := svc.DeleteTask(context.Background(), "invalid-uuid", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestReorderTasks(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyScheduleID := uuid.NewString()
+	first, second := uuid.NewString(), uuid.NewString()
+	currentTasks := []model.Task{{ID: first, Position: 1}, {ID: second, Position: 2}}
+
+	t.Run("TestReorderTasks: OK", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(&scheduleModel.Schedule{ID: dummyScheduleID, Status: "upcoming"}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyScheduleID).Return(currentTasks, nil).Times(1)
+		mockTaskRepo.EXPECT().ReorderTasks(gomock.Any(), dummyScheduleID, []string{second, first}).Return(nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyScheduleID).Return([]model.Task{{ID: second, Position: 1}, {ID: first, Position: 2}}, nil).Times(1)
+
+		tasks, err := svc.ReorderTasks(context.Background(), model.ReorderTasksRequest{ScheduleID: dummyScheduleID, TaskIDs: []string{second, first}})
+		assert.NoError(t, err)
+		assert.Equal(t, second, tasks[0].ID)
+	})
+
+	t.Run("TestReorderTasks: Missing Task", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(&scheduleModel.Schedule{ID: dummyScheduleID, Status: "upcoming"}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyScheduleID).Return(currentTasks, nil).Times(1)
+
+		tasks, err := svc.ReorderTasks(context.Background(), model.ReorderTasksRequest{ScheduleID: dummyScheduleID, TaskIDs: []string{second}})
+		assert.Error(t, err)
+		assert.Nil(t, tasks)
+	})
+
+	t.Run("TestReorderTasks: Foreign Task", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleByID(gomock.Any(), dummyScheduleID).Return(&scheduleModel.Schedule{ID: dummyScheduleID, Status: "upcoming"}, nil).Times(1)
+		mockTaskRepo.EXPECT().GetTasksByScheduleID(gomock.Any(), dummyScheduleID).Return(currentTasks, nil).Times(1)
+
+		tasks, err := svc.ReorderTasks(context.Background(), model.ReorderTasksRequest{ScheduleID: dummyScheduleID, TaskIDs: []string{second, uuid.NewString()}})
+		assert.Error(t, err)
+		assert.Nil(t, tasks)
+	})
+
+	t.Run("TestReorderTasks: Duplicate Task", func(t *testing.T) {
+		tasks, err := svc.ReorderTasks(context.Background(), model.ReorderTasksRequest{ScheduleID: dummyScheduleID, TaskIDs: []string{first, first}})
+		assert.Error(t, err)
+		assert.Nil(t, tasks)
 	})
 }
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/policy"
+	"mini-evv-logger-backend/responses"
+	"mini-evv-logger-backend/src/domains/visitexception/model"
+	"mini-evv-logger-backend/src/domains/visitexception/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// VisitExceptionController handles HTTP requests for visit exceptions
+type VisitExceptionController struct {
+	svc service.VisitExceptionService
+}
+
+// NewVisitExceptionController creates a new VisitExceptionController
+func NewVisitExceptionController(svc service.VisitExceptionService) *VisitExceptionController {
+	return &VisitExceptionController{svc: svc}
+}
+
+// Routes sets up the API endpoints for visit exceptions
+func (vc *VisitExceptionController) Routes(app fiber.Router) {
+	exceptionRoutes := app.Group("/visit-exceptions")
+	exceptionRoutes.Get("/", policy.Require(policy.ViewExceptions, nil), vc.GetExceptions)
+	exceptionRoutes.Get("/:id", policy.Require(policy.ViewExceptions, vc.exceptionResource), vc.GetExceptionDetails)
+	exceptionRoutes.Post("/:id/reason", policy.Require(policy.ExplainException, vc.exceptionResource), vc.SubmitReason)
+	exceptionRoutes.Post("/:id/approve", policy.Require(policy.ResolveException, vc.exceptionResource), vc.ApproveException)
+	exceptionRoutes.Post("/:id/reject", policy.Require(policy.ResolveException, vc.exceptionResource), vc.RejectException)
+}
+
+// exceptionResource resolves the ownership of the exception in the :id route parameter
+func (vc *VisitExceptionController) exceptionResource(c *fiber.Ctx) (*policy.Resource, error) {
+	ownership, err := vc.svc.GetExceptionOwnership(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return nil, err
+	}
+	return &policy.Resource{CaregiverID: ownership.CaregiverID, BranchID: ownership.BranchID}, nil
+}
+
+// GetExceptions handles fetching the visit exceptions matching the query
+func (vc *VisitExceptionController) GetExceptions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	filter := model.FilterVisitExceptionsRequest{}
+
+	err := c.QueryParser(&filter)
+	if err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+	}
+
+	visitExceptions, err := vc.svc.GetAllExceptions(ctx, filter)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, visitExceptions, "Visit exceptions retrieved successfully")
+}
+
+// GetExceptionDetails handles fetching a single visit exception
+func (vc *VisitExceptionController) GetExceptionDetails(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Visit exception ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	exc, err := vc.svc.GetExceptionByID(ctx, id)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, exc, "Visit exception retrieved successfully")
+}
+
+// SubmitReason handles attaching a reason code and comment to a visit exception
+func (vc *VisitExceptionController) SubmitReason(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Visit exception ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	var req model.SubmitReasonRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	req.ID = id // Set the ID from the URL parameter
+
+	exc, err := vc.svc.SubmitReason(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, exc, "Visit exception reason submitted successfully")
+}
+
+// ApproveException handles a coordinator approving a visit exception
+func (vc *VisitExceptionController) ApproveException(c *fiber.Ctx) error {
+	return vc.resolve(c, model.StatusApproved, "Visit exception approved successfully")
+}
+
+// RejectException handles a coordinator rejecting a visit exception
+func (vc *VisitExceptionController) RejectException(c *fiber.Ctx) error {
+	return vc.resolve(c, model.StatusRejected, "Visit exception rejected successfully")
+}
+
+// resolve parses a resolution request and applies the given status
+func (vc *VisitExceptionController) resolve(c *fiber.Ctx, status, message string) error {
+	ctx := c.UserContext()
+
+	id := c.Params("id")
+	if id == "" {
+		return responses.Error(c, http.StatusBadRequest, "Visit exception ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	var req model.ResolveExceptionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		}
+	}
+	req.ID = id // Set the ID from the URL parameter
+	req.Status = status
+
+	exc, err := vc.svc.ResolveException(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, exc, message)
+}
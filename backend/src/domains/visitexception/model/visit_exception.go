@@ -0,0 +1,96 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Exception types raised against a visit
+const (
+	TypeLateClockIn           = "late_clock_in"
+	TypeClockInOutOfGeofence  = "clock_in_out_of_geofence"
+	TypeClockOutOutOfGeofence = "clock_out_out_of_geofence"
+	TypeClockInClockSkew      = "clock_in_clock_skew"
+	TypeClockOutClockSkew     = "clock_out_clock_skew"
+	TypeMissingClockOut       = "missing_clock_out"
+)
+
+// Exception statuses, in the order an exception normally moves through them
+const (
+	StatusOpen      = "open"      // Raised, waiting for the caregiver's explanation
+	StatusSubmitted = "submitted" // Explained, waiting for a coordinator
+	StatusApproved  = "approved"
+	StatusRejected  = "rejected"
+)
+
+// VisitException is a compliance issue on a visit that must be resolved before billing
+type VisitException struct {
+	ID             string     `json:"id" db:"id"`
+	ScheduleID     string     `json:"schedule_id" db:"schedule_id"`
+	Type           string     `json:"type" db:"type"`       // e.g., "late_clock_in", "missing_clock_out"
+	Status         string     `json:"status" db:"status"`   // e.g., "open", "submitted", "approved", "rejected"
+	Details        *string    `json:"details" db:"details"` // What triggered the exception, e.g. "Clocked in 42 minutes late"
+	ReasonCode     *string    `json:"reason_code" db:"reason_code"`
+	Comment        *string    `json:"comment" db:"comment"`
+	SubmittedBy    *string    `json:"submitted_by" db:"submitted_by"` // User who attached the reason
+	SubmittedAt    *time.Time `json:"submitted_at" db:"submitted_at"`
+	ResolvedBy     *string    `json:"resolved_by" db:"resolved_by"` // Coordinator who approved or rejected
+	ResolvedAt     *time.Time `json:"resolved_at" db:"resolved_at"`
+	ResolutionNote *string    `json:"resolution_note" db:"resolution_note"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// IsResolved reports whether a coordinator has already approved or rejected the exception
+func (e *VisitException) IsResolved() bool {
+	return e.Status == StatusApproved || e.Status == StatusRejected
+}
+
+// ExceptionOwnership identifies who an exception's schedule belongs to, for authorization checks
+type ExceptionOwnership struct {
+	CaregiverID *string `db:"caregiver_id"`
+	BranchID    *string `db:"branch_id"`
+}
+
+// FilterVisitExceptionsRequest defines the query parameters for listing exceptions
+type FilterVisitExceptionsRequest struct {
+	Status      string `query:"status" validate:"omitempty,oneof=open submitted approved rejected"`
+	Type        string `query:"type" validate:"omitempty,oneof=late_clock_in clock_in_out_of_geofence clock_out_out_of_geofence clock_in_clock_skew clock_out_clock_skew missing_clock_out"`
+	ScheduleID  string `query:"schedule_id" validate:"omitempty,uuid"`
+	CaregiverID string `query:"-"` // Set from the principal, only exceptions on this caregiver's schedules
+	BranchID    string `query:"-"` // Set from the principal, only exceptions on schedules in this branch
+}
+
+func (r *FilterVisitExceptionsRequest) String() string {
+	return fmt.Sprintf("FilterVisitExceptionsRequest{Status: %s, Type: %s, ScheduleID: %s, CaregiverID: %s, BranchID: %s}", r.Status, r.Type, r.ScheduleID, r.CaregiverID, r.BranchID)
+}
+
+// SubmitReasonRequest defines the request body for explaining an exception
+type SubmitReasonRequest struct {
+	ID          string  `json:"-" validate:"required,uuid"`
+	ReasonCode  string  `json:"reason_code" validate:"required,oneof=traffic client_not_home client_request gps_inaccurate forgot_to_clock device_issue emergency other"`
+	Comment     *string `json:"comment" validate:"required_if=ReasonCode other,omitempty,min=1,max=1000"` // Required if reason_code is "other"
+	SubmittedBy string  `json:"-"`
+}
+
+// ResolveExceptionRequest defines the request body for approving or rejecting an exception
+type ResolveExceptionRequest struct {
+	ID         string  `json:"-" validate:"required,uuid"`
+	Note       *string `json:"note" validate:"omitempty,min=1,max=1000"`
+	Status     string  `json:"-" validate:"required,oneof=approved rejected"`
+	ResolvedBy string  `json:"-"`
+}
+
+func (r *FilterVisitExceptionsRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *SubmitReasonRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *ResolveExceptionRequest) Validate() error {
+	return validator.New().Struct(r)
+}
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context" // Import context
+	"database/sql"
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/src/domains/visitexception/model"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=./visit_exception_repo.go -destination=../mocks/repository/visit_exception_repo.go -package=mocks
+
+var exceptionColumns = []string{"e.id", "e.schedule_id", "e.type", "e.status", "e.details", "e.reason_code", "e.comment",
+	"e.submitted_by", "e.submitted_at", "e.resolved_by", "e.resolved_at", "e.resolution_note", "e.created_at", "e.updated_at"}
+
+// VisitExceptionRepository defines the interface for visit exception database operations
+type VisitExceptionRepository interface {
+	CreateException(ctx context.Context, exc model.VisitException) error
+	RaiseMissingClockOuts(ctx context.Context, startedBefore time.Time) (int64, error)
+	GetExceptions(ctx context.Context, filter model.FilterVisitExceptionsRequest) ([]model.VisitException, error)
+	GetExceptionByID(ctx context.Context, id string) (*model.VisitException, error)
+	GetExceptionOwnership(ctx context.Context, id string) (*model.ExceptionOwnership, error)
+	SubmitReason(ctx context.Context, req model.SubmitReasonRequest) error
+	ResolveException(ctx context.Context, req model.ResolveExceptionRequest) error
+}
+
+// onUnresolvedConflict skips an exception that is already raised and not yet resolved for the visit
+const onUnresolvedConflict = "ON CONFLICT (schedule_id, type) WHERE status IN ('open', 'submitted') DO NOTHING"
+
+// unresolvedStatuses are the statuses in which an exception may still be explained or resolved
+var unresolvedStatuses = []string{model.StatusOpen, model.StatusSubmitted}
+
+// visitExceptionRepositoryImpl implements the VisitExceptionRepository interface
+type visitExceptionRepositoryImpl struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+// NewVisitExceptionRepository creates a new VisitExceptionRepository (returns interface)
+func NewVisitExceptionRepository(db *sqlx.DB, logger zerolog.Logger) VisitExceptionRepository {
+	return &visitExceptionRepositoryImpl{db: db, logger: logger}
+}
+
+// CreateException stores a new exception. A schedule holds at most one unresolved exception of each
+// type, so raising the same exception again before it is resolved is a no-op.
+func (r *visitExceptionRepositoryImpl) CreateException(ctx context.Context, exc model.VisitException) error {
+	qb := squirrel.Insert("visit_exceptions").
+		Columns("id", "schedule_id", "type", "status", "details").
+		Values(exc.ID, exc.ScheduleID, exc.Type, exc.Status, exc.Details).
+		Suffix(onUnresolvedConflict).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", exc.ScheduleID).Msg("Failed to build SQL query for CreateException")
+		return exceptions.ErrInternalError
+	}
+
+	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", exc.ScheduleID).Str("type", exc.Type).Msg("Failed to execute SQL query for CreateException")
+		return exceptions.ErrInternalError
+	}
+	return nil
+}
+
+// RaiseMissingClockOuts opens a missing clock-out exception for every visit that is still
+// in progress and was started before startedBefore. It returns the number of new exceptions.
+func (r *visitExceptionRepositoryImpl) RaiseMissingClockOuts(ctx context.Context, startedBefore time.Time) (int64, error) {
+	sel := squirrel.Select("uuid_generate_v4()", "s.id").
+		Column("?", model.TypeMissingClockOut).
+		Column("?", model.StatusOpen).
+		Column("'Visit was never clocked out'").
+		From("schedules s").
+		Where(squirrel.Eq{"s.status": "in-progress"}).
+		Where(squirrel.Lt{"s.start_time": startedBefore})
+
+	qb := squirrel.Insert("visit_exceptions").
+		Columns("id", "schedule_id", "type", "status", "details").
+		Select(sel).
+		Suffix(onUnresolvedConflict).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to build SQL query for RaiseMissingClockOuts")
+		return 0, exceptions.ErrInternalError
+	}
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to execute SQL query for RaiseMissingClockOuts")
+		return 0, exceptions.ErrInternalError
+	}
+
+	raised, err := res.RowsAffected()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to read affected rows for RaiseMissingClockOuts")
+		return 0, exceptions.ErrInternalError
+	}
+	return raised, nil
+}
+
+// GetExceptions fetches the exceptions matching the filter, newest first
+func (r *visitExceptionRepositoryImpl) GetExceptions(ctx context.Context, filter model.FilterVisitExceptionsRequest) ([]model.VisitException, error) {
+	var visitExceptions []model.VisitException
+	qb := squirrel.Select(exceptionColumns...).
+		From("visit_exceptions e").
+		OrderBy("e.created_at DESC").
+		PlaceholderFormat(squirrel.Dollar)
+
+	if filter.Status != "" {
+		qb = qb.Where(squirrel.Eq{"e.status": filter.Status})
+	}
+	if filter.Type != "" {
+		qb = qb.Where(squirrel.Eq{"e.type": filter.Type})
+	}
+	if filter.ScheduleID != "" {
+		qb = qb.Where(squirrel.Eq{"e.schedule_id": filter.ScheduleID})
+	}
+	if filter.CaregiverID != "" {
+		// Only return the exceptions on schedules assigned to the requested caregiver
+		qb = qb.Where(squirrel.Expr("e.schedule_id IN (SELECT id FROM schedules WHERE caregiver_id = ?)", filter.CaregiverID))
+	}
+	if filter.BranchID != "" {
+		// Only return the exceptions on schedules assigned to caregivers of the requested branch
+		qb = qb.Where(squirrel.Expr("e.schedule_id IN (SELECT s.id FROM schedules s JOIN caregivers c ON c.id = s.caregiver_id WHERE c.branch_id = ?)", filter.BranchID))
+	}
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to build SQL query for GetExceptions")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.SelectContext(ctx, &visitExceptions, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return []model.VisitException{}, nil
+		}
+		r.logger.Error().Err(err).Msg("Failed to execute SQL query for GetExceptions")
+		return nil, exceptions.ErrInternalError
+	}
+	return visitExceptions, nil
+}
+
+// GetExceptionByID fetches a single exception by ID
+func (r *visitExceptionRepositoryImpl) GetExceptionByID(ctx context.Context, id string) (*model.VisitException, error) {
+	var exc model.VisitException
+	qb := squirrel.Select(exceptionColumns...).
+		From("visit_exceptions e").
+		Where(squirrel.Eq{"e.id": id}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("exception_id", id).Msg("Failed to build SQL query for GetExceptionByID")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &exc, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.Warn().Str("exception_id", id).Msg("Visit exception not found in database")
+			return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Visit exception with ID %s not found", id))
+		}
+		r.logger.Error().Err(err).Str("exception_id", id).Msg("Failed to execute SQL query for GetExceptionByID")
+		return nil, exceptions.ErrInternalError
+	}
+	return &exc, nil
+}
+
+// GetExceptionOwnership fetches the caregiver and branch of the schedule an exception was raised on
+func (r *visitExceptionRepositoryImpl) GetExceptionOwnership(ctx context.Context, id string) (*model.ExceptionOwnership, error) {
+	var ownership model.ExceptionOwnership
+	qb := squirrel.Select("s.caregiver_id", "c.branch_id").
+		From("visit_exceptions e").
+		Join("schedules s ON s.id = e.schedule_id").
+		LeftJoin("caregivers c ON c.id = s.caregiver_id").
+		Where(squirrel.Eq{"e.id": id}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("exception_id", id).Msg("Failed to build SQL query for GetExceptionOwnership")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &ownership, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.Warn().Str("exception_id", id).Msg("Visit exception not found in database")
+			return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Visit exception with ID %s not found", id))
+		}
+		r.logger.Error().Err(err).Str("exception_id", id).Msg("Failed to execute SQL query for GetExceptionOwnership")
+		return nil, exceptions.ErrInternalError
+	}
+	return &ownership, nil
+}
+
+// SubmitReason attaches the caregiver's reason to an exception and marks it as submitted.
+// It only applies while the exception is unresolved, so a concurrent resolution turns it into a conflict.
+func (r *visitExceptionRepositoryImpl) SubmitReason(ctx context.Context, req model.SubmitReasonRequest) error {
+	now := time.Now()
+	qb := squirrel.Update("visit_exceptions").
+		Set("reason_code", req.ReasonCode).
+		Set("comment", req.Comment).
+		Set("status", model.StatusSubmitted).
+		Set("submitted_by", req.SubmittedBy).
+		Set("submitted_at", now).
+		Set("updated_at", now).
+		Where(squirrel.Eq{"id": req.ID, "status": unresolvedStatuses}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("exception_id", req.ID).Msg("Failed to build SQL query for SubmitReason")
+		return exceptions.ErrInternalError
+	}
+
+	result, err := r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("exception_id", req.ID).Msg("Failed to execute SQL query for SubmitReason")
+		return exceptions.ErrInternalError
+	}
+	return r.checkApplied(result, req.ID, "SubmitReason")
+}
+
+// ResolveException records a coordinator's approval or rejection of an exception.
+// It only applies while the exception is unresolved, so a concurrent resolution turns it into a conflict.
+func (r *visitExceptionRepositoryImpl) ResolveException(ctx context.Context, req model.ResolveExceptionRequest) error {
+	now := time.Now()
+	qb := squirrel.Update("visit_exceptions").
+		Set("status", req.Status).
+		Set("resolved_by", req.ResolvedBy).
+		Set("resolved_at", now).
+		Set("resolution_note", req.Note).
+		Set("updated_at", now).
+		Where(squirrel.Eq{"id": req.ID, "status": unresolvedStatuses}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("exception_id", req.ID).Msg("Failed to build SQL query for ResolveException")
+		return exceptions.ErrInternalError
+	}
+
+	result, err := r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("exception_id", req.ID).Msg("Failed to execute SQL query for ResolveException")
+		return exceptions.ErrInternalError
+	}
+	return r.checkApplied(result, req.ID, "ResolveException")
+}
+
+// checkApplied turns a conditional update that matched no row into a conflict.
+// The service layer has already found the exception, so no row means another request resolved it first.
+func (r *visitExceptionRepositoryImpl) checkApplied(result sql.Result, id, method string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error().Err(err).Str("exception_id", id).Msgf("Failed to read affected rows for %s", method)
+		return exceptions.ErrInternalError
+	}
+	if affected == 0 {
+		r.logger.Warn().Str("exception_id", id).Msgf("Conditional update for %s matched no row", method)
+		return exceptions.ErrConflict.WithDetails(fmt.Sprintf("Visit exception with ID %s was resolved by another request. Reload it and try again.", id))
+	}
+	return nil
+}
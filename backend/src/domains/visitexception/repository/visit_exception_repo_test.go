@@ -0,0 +1,263 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	pkgmock "mini-evv-logger-backend/pkg_mock"
+	"mini-evv-logger-backend/src/domains/visitexception/model"
+	"mini-evv-logger-backend/src/domains/visitexception/repository"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	dbMock   *sql.DB
+	sqlxMock *sqlx.DB
+	mockSQL  sqlmock.Sqlmock
+	repo     repository.VisitExceptionRepository
+)
+
+func initMocks(t *testing.T) {
+	var err error
+	dbMock, mockSQL, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	// Wrap sqlmock in sqlx.DB
+	sqlxMock = sqlx.NewDb(dbMock, "sqlmock")
+	repo = repository.NewVisitExceptionRepository(sqlxMock, pkgmock.InitMockLogger())
+}
+
+const exceptionSelect = `SELECT e.id, e.schedule_id, e.type, e.status, e.details, e.reason_code, e.comment, e.submitted_by, e.submitted_at, e.resolved_by, e.resolved_at, e.resolution_note, e.created_at, e.updated_at FROM visit_exceptions e`
+
+var exceptionColumns = []string{"id", "schedule_id", "type", "status", "details", "reason_code", "comment", "submitted_by", "submitted_at", "resolved_by", "resolved_at", "resolution_note", "created_at", "updated_at"}
+
+func TestCreateException(t *testing.T) {
+	initMocks(t)
+
+	details := "Clocked in 42 minutes late"
+	dummyException := model.VisitException{ID: uuid.NewString(), ScheduleID: uuid.NewString(), Type: model.TypeLateClockIn, Status: model.StatusOpen, Details: &details}
+	query := `INSERT INTO visit_exceptions (id,schedule_id,type,status,details) VALUES ($1,$2,$3,$4,$5) ON CONFLICT (schedule_id, type) WHERE status IN ('open', 'submitted') DO NOTHING`
+
+	t.Run("TestCreateException: OK", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(dummyException.ID, dummyException.ScheduleID, model.TypeLateClockIn, model.StatusOpen, &details).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := repo.CreateException(context.Background(), dummyException)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestCreateException: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.CreateException(context.Background(), dummyException)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+	})
+}
+
+func TestRaiseMissingClockOuts(t *testing.T) {
+	initMocks(t)
+
+	cutoff := time.Now().Add(-12 * time.Hour)
+	query := `INSERT INTO visit_exceptions (id,schedule_id,type,status,details) SELECT uuid_generate_v4(), s.id, $1, $2, 'Visit was never clocked out' FROM schedules s WHERE s.status = $3 AND s.start_time < $4 ON CONFLICT (schedule_id, type) WHERE status IN ('open', 'submitted') DO NOTHING`
+
+	t.Run("TestRaiseMissingClockOuts: OK", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(model.TypeMissingClockOut, model.StatusOpen, "in-progress", cutoff).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		raised, err := repo.RaiseMissingClockOuts(context.Background(), cutoff)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), raised)
+	})
+
+	t.Run("TestRaiseMissingClockOuts: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		raised, err := repo.RaiseMissingClockOuts(context.Background(), cutoff)
+		assert.NotNil(t, err)
+		assert.Zero(t, raised)
+	})
+}
+
+func TestGetExceptions(t *testing.T) {
+	initMocks(t)
+
+	dummyScheduleID := uuid.NewString()
+
+	t.Run("TestGetExceptions: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(exceptionSelect + ` WHERE e.status = $1 ORDER BY e.created_at DESC`)).
+			WithArgs(model.StatusOpen).
+			WillReturnRows(sqlmock.NewRows(exceptionColumns).
+				AddRow(uuid.NewString(), dummyScheduleID, model.TypeLateClockIn, model.StatusOpen, nil, nil, nil, nil, nil, nil, nil, nil, time.Now(), time.Now()))
+
+		visitExceptions, err := repo.GetExceptions(context.Background(), model.FilterVisitExceptionsRequest{Status: model.StatusOpen})
+		assert.NoError(t, err)
+		assert.Len(t, visitExceptions, 1)
+		assert.Equal(t, dummyScheduleID, visitExceptions[0].ScheduleID)
+	})
+
+	t.Run("TestGetExceptions: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(exceptionSelect + ` ORDER BY e.created_at DESC`)).
+			WillReturnError(sql.ErrConnDone)
+
+		visitExceptions, err := repo.GetExceptions(context.Background(), model.FilterVisitExceptionsRequest{})
+		assert.NotNil(t, err)
+		assert.Nil(t, visitExceptions)
+	})
+}
+
+func TestGetExceptionsByBranch(t *testing.T) {
+	initMocks(t)
+
+	dummyBranchID := uuid.NewString()
+
+	t.Run("TestGetExceptions: Branch Filter", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(exceptionSelect + ` WHERE e.schedule_id IN (SELECT s.id FROM schedules s JOIN caregivers c ON c.id = s.caregiver_id WHERE c.branch_id = $1) ORDER BY e.created_at DESC`)).
+			WithArgs(dummyBranchID).
+			WillReturnRows(sqlmock.NewRows(exceptionColumns))
+
+		visitExceptions, err := repo.GetExceptions(context.Background(), model.FilterVisitExceptionsRequest{BranchID: dummyBranchID})
+		assert.NoError(t, err)
+		assert.Empty(t, visitExceptions)
+	})
+}
+
+func TestGetExceptionByID(t *testing.T) {
+	initMocks(t)
+
+	dummyID := uuid.NewString()
+	query := exceptionSelect + ` WHERE e.id = $1`
+
+	t.Run("TestGetExceptionByID: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows(exceptionColumns).
+				AddRow(dummyID, uuid.NewString(), model.TypeMissingClockOut, model.StatusSubmitted, nil, "forgot_to_clock", nil, uuid.NewString(), time.Now(), nil, nil, nil, time.Now(), time.Now()))
+
+		exc, err := repo.GetExceptionByID(context.Background(), dummyID)
+		assert.NoError(t, err)
+		assert.Equal(t, dummyID, exc.ID)
+		assert.Equal(t, "forgot_to_clock", *exc.ReasonCode)
+	})
+
+	t.Run("TestGetExceptionByID: No Rows", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnError(sql.ErrNoRows)
+
+		exc, err := repo.GetExceptionByID(context.Background(), dummyID)
+		assert.NotNil(t, err)
+		assert.Nil(t, exc)
+		assert.Equal(t, "Error 404: Resource not found - Visit exception with ID "+dummyID+" not found", err.Error())
+	})
+}
+
+func TestGetExceptionOwnership(t *testing.T) {
+	initMocks(t)
+
+	dummyID, dummyCaregiverID, dummyBranchID := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	query := `SELECT s.caregiver_id, c.branch_id FROM visit_exceptions e JOIN schedules s ON s.id = e.schedule_id LEFT JOIN caregivers c ON c.id = s.caregiver_id WHERE e.id = $1`
+
+	t.Run("TestGetExceptionOwnership: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows([]string{"caregiver_id", "branch_id"}).AddRow(dummyCaregiverID, dummyBranchID))
+
+		ownership, err := repo.GetExceptionOwnership(context.Background(), dummyID)
+		assert.NoError(t, err)
+		assert.Equal(t, dummyCaregiverID, *ownership.CaregiverID)
+		assert.Equal(t, dummyBranchID, *ownership.BranchID)
+	})
+
+	t.Run("TestGetExceptionOwnership: No Rows", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnError(sql.ErrNoRows)
+
+		ownership, err := repo.GetExceptionOwnership(context.Background(), dummyID)
+		assert.NotNil(t, err)
+		assert.Nil(t, ownership)
+	})
+}
+
+func TestSubmitReason(t *testing.T) {
+	initMocks(t)
+
+	comment := "Bus was late"
+	dummyRequest := model.SubmitReasonRequest{ID: uuid.NewString(), ReasonCode: "traffic", Comment: &comment, SubmittedBy: uuid.NewString()}
+	query := `UPDATE visit_exceptions SET reason_code = $1, comment = $2, status = $3, submitted_by = $4, submitted_at = $5, updated_at = $6 WHERE id = $7 AND status IN ($8,$9)`
+
+	t.Run("TestSubmitReason: OK", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs("traffic", &comment, model.StatusSubmitted, dummyRequest.SubmittedBy, sqlmock.AnyArg(), sqlmock.AnyArg(), dummyRequest.ID, model.StatusOpen, model.StatusSubmitted).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := repo.SubmitReason(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestSubmitReason: Already Resolved", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.SubmitReason(context.Background(), dummyRequest)
+		assert.NotNil(t, err)
+		assert.Equal(t, fmt.Sprintf("Error 409: Conflict - Visit exception with ID %s was resolved by another request. Reload it and try again.", dummyRequest.ID), err.Error())
+	})
+
+	t.Run("TestSubmitReason: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.SubmitReason(context.Background(), dummyRequest)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+	})
+}
+
+func TestResolveException(t *testing.T) {
+	initMocks(t)
+
+	dummyRequest := model.ResolveExceptionRequest{ID: uuid.NewString(), Status: model.StatusApproved, ResolvedBy: uuid.NewString()}
+	query := `UPDATE visit_exceptions SET status = $1, resolved_by = $2, resolved_at = $3, resolution_note = $4, updated_at = $5 WHERE id = $6 AND status IN ($7,$8)`
+
+	t.Run("TestResolveException: OK", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(model.StatusApproved, dummyRequest.ResolvedBy, sqlmock.AnyArg(), nil, sqlmock.AnyArg(), dummyRequest.ID, model.StatusOpen, model.StatusSubmitted).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := repo.ResolveException(context.Background(), dummyRequest)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestResolveException: Already Resolved", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.ResolveException(context.Background(), dummyRequest)
+		assert.NotNil(t, err)
+		assert.Equal(t, fmt.Sprintf("Error 409: Conflict - Visit exception with ID %s was resolved by another request. Reload it and try again.", dummyRequest.ID), err.Error())
+	})
+
+	t.Run("TestResolveException: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.ResolveException(context.Background(), dummyRequest)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+	})
+}
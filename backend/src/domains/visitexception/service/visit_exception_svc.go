@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context" // Import context
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	"mini-evv-logger-backend/src/domains/visitexception/model"
+	"mini-evv-logger-backend/src/domains/visitexception/repository"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// VisitExceptionService defines the interface for visit exception business logic
+type VisitExceptionService interface {
+	GetAllExceptions(ctx context.Context, filter model.FilterVisitExceptionsRequest) ([]model.VisitException, error)
+	GetExceptionByID(ctx context.Context, id string) (*model.VisitException, error)
+	GetExceptionOwnership(ctx context.Context, id string) (*model.ExceptionOwnership, error)
+	SubmitReason(ctx context.Context, req model.SubmitReasonRequest) (*model.VisitException, error)
+	ResolveException(ctx context.Context, req model.ResolveExceptionRequest) (*model.VisitException, error)
+}
+
+// visitExceptionServiceImpl implements the VisitExceptionService interface
+type visitExceptionServiceImpl struct {
+	repo repository.VisitExceptionRepository
+}
+
+// NewVisitExceptionService creates a new VisitExceptionService (returns interface)
+func NewVisitExceptionService(repo repository.VisitExceptionRepository) VisitExceptionService {
+	return &visitExceptionServiceImpl{repo: repo}
+}
+
+// GetAllExceptions fetches the exceptions visible to the principal
+func (s *visitExceptionServiceImpl) GetAllExceptions(ctx context.Context, filter model.FilterVisitExceptionsRequest) ([]model.VisitException, error) {
+	log.Info().Msgf("Fetching visit exceptions with filter %s", filter.String())
+
+	err := filter.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for FilterVisitExceptionsRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	// Restrict the listing to what the principal is allowed to see
+	if principal, ok := authModel.PrincipalFromContext(ctx); ok {
+		switch principal.Role {
+		case authModel.RoleCaregiver:
+			if principal.CaregiverID == nil {
+				return nil, exceptions.ErrForbidden.WithDetails("User is not linked to a caregiver")
+			}
+			filter.CaregiverID = *principal.CaregiverID
+		case authModel.RoleCoordinator:
+			if principal.BranchID == nil {
+				return nil, exceptions.ErrForbidden.WithDetails("Coordinator is not assigned to a branch")
+			}
+			filter.BranchID = *principal.BranchID
+		}
+	}
+
+	visitExceptions, err := s.repo.GetExceptions(ctx, filter)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch visit exceptions from repository")
+		return nil, err
+	}
+	return visitExceptions, nil
+}
+
+// GetExceptionByID fetches a single exception by its ID
+func (s *visitExceptionServiceImpl) GetExceptionByID(ctx context.Context, id string) (*model.VisitException, error) {
+	log.Info().Str("exception_id", id).Msg("Fetching visit exception by ID")
+
+	_, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Str("exception_id", id).Msg("Invalid UUID format for visit exception ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid visit exception ID format")
+	}
+
+	exc, err := s.repo.GetExceptionByID(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("exception_id", id).Msg("Failed to fetch visit exception by ID from repository")
+		return nil, err
+	}
+	return exc, nil
+}
+
+// GetExceptionOwnership fetches who an exception's schedule belongs to, for authorization checks
+func (s *visitExceptionServiceImpl) GetExceptionOwnership(ctx context.Context, id string) (*model.ExceptionOwnership, error) {
+	_, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Str("exception_id", id).Msg("Invalid UUID format for visit exception ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid visit exception ID format")
+	}
+
+	ownership, err := s.repo.GetExceptionOwnership(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("exception_id", id).Msg("Failed to fetch visit exception ownership from repository")
+		return nil, err
+	}
+	return ownership, nil
+}
+
+// SubmitReason attaches a reason code and comment to an unresolved exception
+func (s *visitExceptionServiceImpl) SubmitReason(ctx context.Context, req model.SubmitReasonRequest) (*model.VisitException, error) {
+	log.Info().Str("exception_id", req.ID).Str("user_id", authModel.ActorID(ctx)).Str("reason_code", req.ReasonCode).Msg("Attempting to submit visit exception reason")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for SubmitReasonRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+	req.SubmittedBy = authModel.ActorID(ctx)
+
+	// 1. Check if the exception exists and its current status
+	exc, err := s.repo.GetExceptionByID(ctx, req.ID)
+	if err != nil {
+		log.Error().Err(err).Str("exception_id", req.ID).Msg("Failed to retrieve visit exception before submitting reason")
+		return nil, err
+	}
+
+	// 2. Apply business logic: resolved exceptions can no longer be explained
+	if exc.IsResolved() {
+		return nil, exceptions.ErrConflict.WithDetails(fmt.Sprintf("Visit exception %s is already %s", req.ID, exc.Status))
+	}
+
+	// 3. Perform the update via repository
+	err = s.repo.SubmitReason(ctx, req)
+	if err != nil {
+		log.Error().Err(err).Str("exception_id", req.ID).Msg("Failed to submit visit exception reason in repository")
+		return nil, err
+	}
+
+	return s.repo.GetExceptionByID(ctx, req.ID)
+}
+
+// ResolveException approves or rejects an unresolved exception
+func (s *visitExceptionServiceImpl) ResolveException(ctx context.Context, req model.ResolveExceptionRequest) (*model.VisitException, error) {
+	log.Info().Str("exception_id", req.ID).Str("user_id", authModel.ActorID(ctx)).Str("status", req.Status).Msg("Attempting to resolve visit exception")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for ResolveExceptionRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+	if req.Status == model.StatusRejected && req.Note == nil {
+		return nil, exceptions.ErrBadRequest.WithDetails("A note is required when rejecting a visit exception")
+	}
+	req.ResolvedBy = authModel.ActorID(ctx)
+
+	// 1. Check if the exception exists and its current status
+	exc, err := s.repo.GetExceptionByID(ctx, req.ID)
+	if err != nil {
+		log.Error().Err(err).Str("exception_id", req.ID).Msg("Failed to retrieve visit exception before resolving")
+		return nil, err
+	}
+
+	// 2. Apply business logic: an exception is resolved only once
+	if exc.IsResolved() {
+		return nil, exceptions.ErrConflict.WithDetails(fmt.Sprintf("Visit exception %s is already %s", req.ID, exc.Status))
+	}
+
+	// 3. Perform the update via repository
+	err = s.repo.ResolveException(ctx, req)
+	if err != nil {
+		log.Error().Err(err).Str("exception_id", req.ID).Msg("Failed to resolve visit exception in repository")
+		return nil, err
+	}
+
+	return s.repo.GetExceptionByID(ctx, req.ID)
+}
@@ -0,0 +1,190 @@
+package service_test
+
+import (
+	"context"
+	"mini-evv-logger-backend/exceptions"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	mocks "mini-evv-logger-backend/src/domains/visitexception/mocks/repository"
+	"mini-evv-logger-backend/src/domains/visitexception/model"
+	"mini-evv-logger-backend/src/domains/visitexception/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	mockExceptionRepo *mocks.MockVisitExceptionRepository
+	ctrl              *gomock.Controller
+	svc               service.VisitExceptionService
+)
+
+func initMocks(t *testing.T) {
+	ctrl = gomock.NewController(t)
+
+	mockExceptionRepo = mocks.NewMockVisitExceptionRepository(ctrl)
+
+	svc = service.NewVisitExceptionService(mockExceptionRepo)
+}
+
+func TestGetAllExceptions(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	t.Run("TestGetAllExceptions: OK", func(t *testing.T) {
+		mockExceptionRepo.EXPECT().GetExceptions(gomock.Any(), gomock.Any()).Return([]model.VisitException{{ID: uuid.NewString()}}, nil).Times(1)
+
+		visitExceptions, err := svc.GetAllExceptions(context.Background(), model.FilterVisitExceptionsRequest{})
+		assert.NoError(t, err)
+		assert.Len(t, visitExceptions, 1)
+	})
+
+	t.Run("TestGetAllExceptions: Scoped To Caregiver Principal", func(t *testing.T) {
+		caregiverID := uuid.NewString()
+		ctx := authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: uuid.NewString(), Role: authModel.RoleCaregiver, CaregiverID: &caregiverID})
+		mockExceptionRepo.EXPECT().GetExceptions(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, filter model.FilterVisitExceptionsRequest) ([]model.VisitException, error) {
+				assert.Equal(t, caregiverID, filter.CaregiverID)
+				return []model.VisitException{}, nil
+			}).Times(1)
+
+		_, err := svc.GetAllExceptions(ctx, model.FilterVisitExceptionsRequest{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestGetAllExceptions: Scoped To Coordinator Branch", func(t *testing.T) {
+		branchID := uuid.NewString()
+		ctx := authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: uuid.NewString(), Role: authModel.RoleCoordinator, BranchID: &branchID})
+		mockExceptionRepo.EXPECT().GetExceptions(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, filter model.FilterVisitExceptionsRequest) ([]model.VisitException, error) {
+				assert.Equal(t, branchID, filter.BranchID)
+				return []model.VisitException{}, nil
+			}).Times(1)
+
+		_, err := svc.GetAllExceptions(ctx, model.FilterVisitExceptionsRequest{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestGetAllExceptions: Validation error", func(t *testing.T) {
+		visitExceptions, err := svc.GetAllExceptions(context.Background(), model.FilterVisitExceptionsRequest{Status: "pending"})
+		assert.Error(t, err)
+		assert.Nil(t, visitExceptions)
+	})
+}
+
+func TestGetExceptionByID(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyID := uuid.NewString()
+
+	t.Run("TestGetExceptionByID: OK", func(t *testing.T) {
+		mockExceptionRepo.EXPECT().GetExceptionByID(gomock.Any(), dummyID).Return(&model.VisitException{ID: dummyID}, nil).Times(1)
+
+		exc, err := svc.GetExceptionByID(context.Background(), dummyID)
+		assert.NoError(t, err)
+		assert.Equal(t, dummyID, exc.ID)
+	})
+
+	t.Run("TestGetExceptionByID: Invalid UUID", func(t *testing.T) {
+		exc, err := svc.GetExceptionByID(context.Background(), "invalid-uuid")
+		assert.Error(t, err)
+		assert.Nil(t, exc)
+		assert.Equal(t, exceptions.ErrBadRequest.WithDetails("Invalid visit exception ID format").Error(), err.Error())
+	})
+}
+
+func TestSubmitReason(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyID, userID := uuid.NewString(), uuid.NewString()
+	ctx := authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: userID, Role: authModel.RoleCaregiver})
+	dummyRequest := model.SubmitReasonRequest{ID: dummyID, ReasonCode: "traffic"}
+
+	t.Run("TestSubmitReason: OK", func(t *testing.T) {
+		mockExceptionRepo.EXPECT().GetExceptionByID(gomock.Any(), dummyID).Return(&model.VisitException{ID: dummyID, Status: model.StatusOpen}, nil).Times(1)
+		mockExceptionRepo.EXPECT().SubmitReason(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req model.SubmitReasonRequest) error {
+				assert.Equal(t, userID, req.SubmittedBy)
+				return nil
+			}).Times(1)
+		mockExceptionRepo.EXPECT().GetExceptionByID(gomock.Any(), dummyID).Return(&model.VisitException{ID: dummyID, Status: model.StatusSubmitted}, nil).Times(1)
+
+		exc, err := svc.SubmitReason(ctx, dummyRequest)
+		assert.NoError(t, err)
+		assert.Equal(t, model.StatusSubmitted, exc.Status)
+	})
+
+	t.Run("TestSubmitReason: Other Requires Comment", func(t *testing.T) {
+		exc, err := svc.SubmitReason(ctx, model.SubmitReasonRequest{ID: dummyID, ReasonCode: "other"})
+		assert.Error(t, err)
+		assert.Nil(t, exc)
+	})
+
+	t.Run("TestSubmitReason: Unknown Reason Code", func(t *testing.T) {
+		exc, err := svc.SubmitReason(ctx, model.SubmitReasonRequest{ID: dummyID, ReasonCode: "overslept"})
+		assert.Error(t, err)
+		assert.Nil(t, exc)
+	})
+
+	t.Run("TestSubmitReason: Already Resolved", func(t *testing.T) {
+		mockExceptionRepo.EXPECT().GetExceptionByID(gomock.Any(), dummyID).Return(&model.VisitException{ID: dummyID, Status: model.StatusApproved}, nil).Times(1)
+
+		exc, err := svc.SubmitReason(ctx, dummyRequest)
+		assert.Error(t, err)
+		assert.Nil(t, exc)
+		assert.Equal(t, exceptions.ErrConflict.WithDetails("Visit exception "+dummyID+" is already approved").Error(), err.Error())
+	})
+}
+
+func TestResolveException(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyID, userID := uuid.NewString(), uuid.NewString()
+	ctx := authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: userID, Role: authModel.RoleCoordinator})
+
+	t.Run("TestResolveException: Approve", func(t *testing.T) {
+		mockExceptionRepo.EXPECT().GetExceptionByID(gomock.Any(), dummyID).Return(&model.VisitException{ID: dummyID, Status: model.StatusSubmitted}, nil).Times(1)
+		mockExceptionRepo.EXPECT().ResolveException(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req model.ResolveExceptionRequest) error {
+				assert.Equal(t, model.StatusApproved, req.Status)
+				assert.Equal(t, userID, req.ResolvedBy)
+				return nil
+			}).Times(1)
+		mockExceptionRepo.EXPECT().GetExceptionByID(gomock.Any(), dummyID).Return(&model.VisitException{ID: dummyID, Status: model.StatusApproved}, nil).Times(1)
+
+		exc, err := svc.ResolveException(ctx, model.ResolveExceptionRequest{ID: dummyID, Status: model.StatusApproved})
+		assert.NoError(t, err)
+		assert.Equal(t, model.StatusApproved, exc.Status)
+	})
+
+	t.Run("TestResolveException: Reject Requires Note", func(t *testing.T) {
+		exc, err := svc.ResolveException(ctx, model.ResolveExceptionRequest{ID: dummyID, Status: model.StatusRejected})
+		assert.Error(t, err)
+		assert.Nil(t, exc)
+	})
+
+	t.Run("TestResolveException: Already Resolved", func(t *testing.T) {
+		note := "Not a valid reason"
+		mockExceptionRepo.EXPECT().GetExceptionByID(gomock.Any(), dummyID).Return(&model.VisitException{ID: dummyID, Status: model.StatusRejected}, nil).Times(1)
+
+		exc, err := svc.ResolveException(ctx, model.ResolveExceptionRequest{ID: dummyID, Status: model.StatusRejected, Note: &note})
+		assert.Error(t, err)
+		assert.Nil(t, exc)
+	})
+
+	t.Run("TestResolveException: Not Found", func(t *testing.T) {
+		mockExceptionRepo.EXPECT().GetExceptionByID(gomock.Any(), dummyID).Return(nil, exceptions.ErrNotFound).Times(1)
+
+		exc, err := svc.ResolveException(ctx, model.ResolveExceptionRequest{ID: dummyID, Status: model.StatusApproved})
+		assert.Error(t, err)
+		assert.Nil(t, exc)
+	})
+}
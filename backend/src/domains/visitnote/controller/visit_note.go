@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/policy"
+	"mini-evv-logger-backend/responses"
+	"mini-evv-logger-backend/src/domains/visitnote/model"
+	"mini-evv-logger-backend/src/domains/visitnote/service"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// VisitNoteController handles HTTP requests for visit notes
+type VisitNoteController struct {
+	svc service.VisitNoteService
+}
+
+// NewVisitNoteController creates a new VisitNoteController
+func NewVisitNoteController(svc service.VisitNoteService) *VisitNoteController {
+	return &VisitNoteController{svc: svc}
+}
+
+// Routes sets up the API endpoints for visit notes
+func (nc *VisitNoteController) Routes(app fiber.Router) {
+	noteRoutes := app.Group("/schedules/:id/notes")
+	noteRoutes.Get("/", policy.Require(policy.ViewSchedule, nc.scheduleResource), nc.GetNotes)
+	noteRoutes.Post("/", policy.Require(policy.WriteVisitNote, nc.scheduleResource), nc.CreateNote)
+	noteRoutes.Patch("/:noteId", policy.Require(policy.WriteVisitNote, nc.scheduleResource), nc.UpdateNote)
+}
+
+// scheduleResource resolves the ownership of the schedule in the :id route parameter
+func (nc *VisitNoteController) scheduleResource(c *fiber.Ctx) (*policy.Resource, error) {
+	ownership, err := nc.svc.GetScheduleOwnership(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return nil, err
+	}
+	return &policy.Resource{CaregiverID: ownership.CaregiverID, BranchID: ownership.BranchID}, nil
+}
+
+// GetNotes handles fetching the notes of a schedule
+func (nc *VisitNoteController) GetNotes(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	scheduleID := c.Params("id")
+	if scheduleID == "" {
+		return responses.Error(c, http.StatusBadRequest, "Schedule ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	notes, err := nc.svc.GetNotes(ctx, scheduleID)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, notes, "Visit notes retrieved successfully")
+}
+
+// CreateNote handles adding a note to a schedule
+func (nc *VisitNoteController) CreateNote(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	scheduleID := c.Params("id")
+	if scheduleID == "" {
+		return responses.Error(c, http.StatusBadRequest, "Schedule ID is required", exceptions.ErrBadRequest.Error())
+	}
+
+	var req model.CreateVisitNoteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	req.ScheduleID = scheduleID // Set the ScheduleID from the URL parameter
+
+	note, err := nc.svc.CreateNote(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.Created(c, note, "Visit note created successfully")
+}
+
+// UpdateNote handles the author editing a note
+func (nc *VisitNoteController) UpdateNote(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	scheduleID, noteID := c.Params("id"), c.Params("noteId")
+	if scheduleID == "" || noteID == "" {
+		return responses.Error(c, http.StatusBadRequest, "Schedule ID and visit note ID are required", exceptions.ErrBadRequest.Error())
+	}
+
+	var req model.UpdateVisitNoteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	req.ID = noteID // Set the IDs from the URL parameters
+	req.ScheduleID = scheduleID
+
+	note, err := nc.svc.UpdateNote(ctx, req)
+	if err != nil {
+		return exceptions.HandleError(c, err)
+	}
+	return responses.OK(c, note, "Visit note updated successfully")
+}
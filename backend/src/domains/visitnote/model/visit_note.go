@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Note categories
+const (
+	CategoryObservation         = "observation"
+	CategoryChangeInCondition   = "change_in_condition"
+	CategoryFamilyCommunication = "family_communication"
+)
+
+// VisitNote is a free-text note written about a visit by a caregiver or coordinator
+type VisitNote struct {
+	ID          string    `json:"id" db:"id"`
+	ScheduleID  string    `json:"schedule_id" db:"schedule_id"`
+	AuthorID    string    `json:"author_id" db:"author_id"` // User who wrote the note, the only one who may edit it
+	AuthorEmail string    `json:"author_email" db:"author_email"`
+	Category    string    `json:"category" db:"category"` // e.g., "observation", "change_in_condition"
+	Text        string    `json:"text" db:"text"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateVisitNoteRequest defines the request body for adding a note to a visit
+type CreateVisitNoteRequest struct {
+	ScheduleID string `json:"-" validate:"required,uuid"`
+	Category   string `json:"category" validate:"required,oneof=observation change_in_condition family_communication"`
+	Text       string `json:"text" validate:"required,max=5000"`
+}
+
+// UpdateVisitNoteRequest defines the request body for editing a note; only the provided fields are changed
+type UpdateVisitNoteRequest struct {
+	ID         string  `json:"-" validate:"required,uuid"`
+	ScheduleID string  `json:"-" validate:"required,uuid"`
+	Category   *string `json:"category" validate:"omitempty,oneof=observation change_in_condition family_communication"`
+	Text       *string `json:"text" validate:"omitempty,min=1,max=5000"`
+
+	AuthorID     string    `json:"-"` // Set by the service; the update applies only to notes by this author
+	CreatedAfter time.Time `json:"-"` // Set by the service to the start of the edit window; older notes are left alone
+}
+
+func (r *CreateVisitNoteRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *UpdateVisitNoteRequest) Validate() error {
+	return validator.New().Struct(r)
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context" // Import context
+	"database/sql"
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	"mini-evv-logger-backend/src/domains/visitnote/model"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=./visit_note_repo.go -destination=../mocks/repository/visit_note_repo.go -package=mocks
+
+var noteColumns = []string{"n.id", "n.schedule_id", "n.author_id", "u.email AS author_email", "n.category", "n.text", "n.created_at", "n.updated_at"}
+
+// VisitNoteRepository defines the interface for visit note database operations
+type VisitNoteRepository interface {
+	CreateNote(ctx context.Context, note model.VisitNote) error
+	GetNotesByScheduleID(ctx context.Context, scheduleID string) ([]model.VisitNote, error)
+	GetNoteByID(ctx context.Context, id string) (*model.VisitNote, error)
+	UpdateNote(ctx context.Context, req model.UpdateVisitNoteRequest) error
+}
+
+// visitNoteRepositoryImpl implements the VisitNoteRepository interface
+type visitNoteRepositoryImpl struct {
+	db     *sqlx.DB
+	logger zerolog.Logger
+}
+
+// NewVisitNoteRepository creates a new VisitNoteRepository (returns interface)
+func NewVisitNoteRepository(db *sqlx.DB, logger zerolog.Logger) VisitNoteRepository {
+	return &visitNoteRepositoryImpl{db: db, logger: logger}
+}
+
+// CreateNote stores a new note
+func (r *visitNoteRepositoryImpl) CreateNote(ctx context.Context, note model.VisitNote) error {
+	qb := squirrel.Insert("visit_notes").
+		Columns("id", "schedule_id", "author_id", "category", "text").
+		Values(note.ID, note.ScheduleID, note.AuthorID, note.Category, note.Text).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", note.ScheduleID).Msg("Failed to build SQL query for CreateNote")
+		return exceptions.ErrInternalError
+	}
+
+	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", note.ScheduleID).Msg("Failed to execute SQL query for CreateNote")
+		return exceptions.ErrInternalError
+	}
+	return nil
+}
+
+// GetNotesByScheduleID fetches the notes of a schedule, oldest first
+func (r *visitNoteRepositoryImpl) GetNotesByScheduleID(ctx context.Context, scheduleID string) ([]model.VisitNote, error) {
+	notes := []model.VisitNote{}
+	qb := squirrel.Select(noteColumns...).
+		From("visit_notes n").
+		Join("users u ON u.id = n.author_id").
+		Where(squirrel.Eq{"n.schedule_id": scheduleID}).
+		OrderBy("n.created_at ASC").
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to build SQL query for GetNotesByScheduleID")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.SelectContext(ctx, &notes, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to execute SQL query for GetNotesByScheduleID")
+		return nil, exceptions.ErrInternalError
+	}
+	return notes, nil
+}
+
+// GetNoteByID fetches a single note by its ID
+func (r *visitNoteRepositoryImpl) GetNoteByID(ctx context.Context, id string) (*model.VisitNote, error) {
+	var note model.VisitNote
+	qb := squirrel.Select(noteColumns...).
+		From("visit_notes n").
+		Join("users u ON u.id = n.author_id").
+		Where(squirrel.Eq{"n.id": id}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("note_id", id).Msg("Failed to build SQL query for GetNoteByID")
+		return nil, exceptions.ErrInternalError
+	}
+
+	err = r.db.GetContext(ctx, &note, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.Warn().Str("note_id", id).Msg("Visit note not found in database")
+			return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Visit note with ID %s not found", id))
+		}
+		r.logger.Error().Err(err).Str("note_id", id).Msg("Failed to execute SQL query for GetNoteByID")
+		return nil, exceptions.ErrInternalError
+	}
+	return &note, nil
+}
+
+// UpdateNote updates the provided fields of a note written by req.AuthorID after req.CreatedAfter.
+// The service checks both first; a note that no longer matches, e.g. because the edit window closed
+// in the meantime, yields a conflict.
+func (r *visitNoteRepositoryImpl) UpdateNote(ctx context.Context, req model.UpdateVisitNoteRequest) error {
+	qb := squirrel.Update("visit_notes").
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": req.ID, "author_id": req.AuthorID}).
+		Where(squirrel.Gt{"created_at": req.CreatedAfter}).
+		PlaceholderFormat(squirrel.Dollar)
+
+	if req.Category != nil {
+		qb = qb.Set("category", *req.Category)
+	}
+	if req.Text != nil {
+		qb = qb.Set("text", *req.Text)
+	}
+
+	sqlQuery, args, err := qb.ToSql()
+	if err != nil {
+		r.logger.Error().Err(err).Str("note_id", req.ID).Msg("Failed to build SQL query for UpdateNote")
+		return exceptions.ErrInternalError
+	}
+
+	result, err := r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("note_id", req.ID).Msg("Failed to execute SQL query for UpdateNote")
+		return exceptions.ErrInternalError
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error().Err(err).Str("note_id", req.ID).Msg("Failed to read affected rows for UpdateNote")
+		return exceptions.ErrInternalError
+	}
+	if rows == 0 {
+		return exceptions.ErrConflict.WithDetails(fmt.Sprintf("Visit note with ID %s can no longer be edited", req.ID))
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	pkgmock "mini-evv-logger-backend/pkg_mock"
+	"mini-evv-logger-backend/src/domains/visitnote/model"
+	"mini-evv-logger-backend/src/domains/visitnote/repository"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	dbMock   *sql.DB
+	sqlxMock *sqlx.DB
+	mockSQL  sqlmock.Sqlmock
+	repo     repository.VisitNoteRepository
+)
+
+func initMocks(t *testing.T) {
+	var err error
+	dbMock, mockSQL, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	// Wrap sqlmock in sqlx.DB
+	sqlxMock = sqlx.NewDb(dbMock, "sqlmock")
+	repo = repository.NewVisitNoteRepository(sqlxMock, pkgmock.InitMockLogger())
+}
+
+const noteSelect = `SELECT n.id, n.schedule_id, n.author_id, u.email AS author_email, n.category, n.text, n.created_at, n.updated_at FROM visit_notes n JOIN users u ON u.id = n.author_id`
+
+var noteColumns = []string{"id", "schedule_id", "author_id", "author_email", "category", "text", "created_at", "updated_at"}
+
+func TestCreateNote(t *testing.T) {
+	initMocks(t)
+
+	dummyNote := model.VisitNote{ID: uuid.NewString(), ScheduleID: uuid.NewString(), AuthorID: uuid.NewString(), Category: model.CategoryObservation, Text: "Client was in good spirits"}
+	query := `INSERT INTO visit_notes (id,schedule_id,author_id,category,text) VALUES ($1,$2,$3,$4,$5)`
+
+	t.Run("TestCreateNote: OK", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(dummyNote.ID, dummyNote.ScheduleID, dummyNote.AuthorID, model.CategoryObservation, dummyNote.Text).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := repo.CreateNote(context.Background(), dummyNote)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestCreateNote: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.CreateNote(context.Background(), dummyNote)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+	})
+}
+
+func TestGetNotesByScheduleID(t *testing.T) {
+	initMocks(t)
+
+	dummyScheduleID := uuid.NewString()
+	query := noteSelect + ` WHERE n.schedule_id = $1 ORDER BY n.created_at ASC`
+
+	t.Run("TestGetNotesByScheduleID: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyScheduleID).
+			WillReturnRows(sqlmock.NewRows(noteColumns).
+				AddRow(uuid.NewString(), dummyScheduleID, uuid.NewString(), "caregiver@example.com", model.CategoryObservation, "Client was in good spirits", time.Now(), time.Now()).
+				AddRow(uuid.NewString(), dummyScheduleID, uuid.NewString(), "coordinator@example.com", model.CategoryFamilyCommunication, "Called the daughter", time.Now(), time.Now()))
+
+		notes, err := repo.GetNotesByScheduleID(context.Background(), dummyScheduleID)
+		assert.NoError(t, err)
+		assert.Len(t, notes, 2)
+		assert.Equal(t, "caregiver@example.com", notes[0].AuthorEmail)
+		assert.Equal(t, model.CategoryFamilyCommunication, notes[1].Category)
+	})
+
+	t.Run("TestGetNotesByScheduleID: No Notes", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyScheduleID).
+			WillReturnRows(sqlmock.NewRows(noteColumns))
+
+		notes, err := repo.GetNotesByScheduleID(context.Background(), dummyScheduleID)
+		assert.NoError(t, err)
+		assert.NotNil(t, notes)
+		assert.Empty(t, notes)
+	})
+
+	t.Run("TestGetNotesByScheduleID: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyScheduleID).
+			WillReturnError(sql.ErrConnDone)
+
+		notes, err := repo.GetNotesByScheduleID(context.Background(), dummyScheduleID)
+		assert.NotNil(t, err)
+		assert.Nil(t, notes)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+	})
+}
+
+func TestGetNoteByID(t *testing.T) {
+	initMocks(t)
+
+	dummyID := uuid.NewString()
+	query := noteSelect + ` WHERE n.id = $1`
+
+	t.Run("TestGetNoteByID: OK", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnRows(sqlmock.NewRows(noteColumns).
+				AddRow(dummyID, uuid.NewString(), uuid.NewString(), "caregiver@example.com", model.CategoryChangeInCondition, "New bruise on the left arm", time.Now(), time.Now()))
+
+		note, err := repo.GetNoteByID(context.Background(), dummyID)
+		assert.NoError(t, err)
+		assert.Equal(t, dummyID, note.ID)
+		assert.Equal(t, model.CategoryChangeInCondition, note.Category)
+	})
+
+	t.Run("TestGetNoteByID: No Rows", func(t *testing.T) {
+		mockSQL.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(dummyID).
+			WillReturnError(sql.ErrNoRows)
+
+		note, err := repo.GetNoteByID(context.Background(), dummyID)
+		assert.NotNil(t, err)
+		assert.Nil(t, note)
+		assert.Equal(t, "Error 404: Resource not found - Visit note with ID "+dummyID+" not found", err.Error())
+	})
+}
+
+func TestUpdateNote(t *testing.T) {
+	initMocks(t)
+
+	dummyID := uuid.NewString()
+	text := "Client was tired but in good spirits"
+	dummyAuthorID, createdAfter := uuid.NewString(), time.Now().Add(-time.Hour)
+	req := model.UpdateVisitNoteRequest{ID: dummyID, ScheduleID: uuid.NewString(), Text: &text, AuthorID: dummyAuthorID, CreatedAfter: createdAfter}
+	query := `UPDATE visit_notes SET updated_at = $1, text = $2 WHERE author_id = $3 AND id = $4 AND created_at > $5`
+
+	t.Run("TestUpdateNote: OK", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(sqlmock.AnyArg(), text, dummyAuthorID, dummyID, createdAfter).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.UpdateNote(context.Background(), req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestUpdateNote: Edit Window Closed", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(sqlmock.AnyArg(), text, dummyAuthorID, dummyID, createdAfter).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.UpdateNote(context.Background(), req)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 409: Conflict - Visit note with ID "+dummyID+" can no longer be edited", err.Error())
+	})
+
+	t.Run("TestUpdateNote: SQL Error", func(t *testing.T) {
+		mockSQL.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.UpdateNote(context.Background(), req)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Error 500: Internal server error", err.Error())
+	})
+}
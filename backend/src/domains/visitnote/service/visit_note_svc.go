@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context" // Import context
+	"fmt"
+	"mini-evv-logger-backend/exceptions"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	scheduleModel "mini-evv-logger-backend/src/domains/schedule/model"
+	scheduleRepo "mini-evv-logger-backend/src/domains/schedule/repository"
+	"mini-evv-logger-backend/src/domains/visitnote/model"
+	"mini-evv-logger-backend/src/domains/visitnote/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// VisitNoteService defines the interface for visit note business logic
+type VisitNoteService interface {
+	GetScheduleOwnership(ctx context.Context, scheduleID string) (*scheduleModel.ScheduleOwnership, error)
+	GetNotes(ctx context.Context, scheduleID string) ([]model.VisitNote, error)
+	CreateNote(ctx context.Context, req model.CreateVisitNoteRequest) (*model.VisitNote, error)
+	UpdateNote(ctx context.Context, req model.UpdateVisitNoteRequest) (*model.VisitNote, error)
+}
+
+// Settings holds the configurable rules applied by the visit note service
+type Settings struct {
+	EditWindow time.Duration // How long after writing it the author may still edit a note
+}
+
+// visitNoteServiceImpl implements the VisitNoteService interface
+type visitNoteServiceImpl struct {
+	repo         repository.VisitNoteRepository
+	scheduleRepo scheduleRepo.ScheduleRepository
+	settings     Settings
+}
+
+// NewVisitNoteService creates a new VisitNoteService (returns interface)
+func NewVisitNoteService(repo repository.VisitNoteRepository, scheduleRepo scheduleRepo.ScheduleRepository, settings Settings) VisitNoteService {
+	return &visitNoteServiceImpl{repo: repo, scheduleRepo: scheduleRepo, settings: settings}
+}
+
+// GetScheduleOwnership fetches who a schedule belongs to, for authorizing access to its notes
+func (s *visitNoteServiceImpl) GetScheduleOwnership(ctx context.Context, scheduleID string) (*scheduleModel.ScheduleOwnership, error) {
+	_, err := uuid.Parse(scheduleID)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", scheduleID).Msg("Invalid UUID format for schedule ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid schedule ID format")
+	}
+
+	ownership, err := s.scheduleRepo.GetScheduleOwnership(ctx, scheduleID)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to fetch schedule ownership from repository")
+		return nil, err
+	}
+	return ownership, nil
+}
+
+// GetNotes fetches the notes of a schedule, oldest first
+func (s *visitNoteServiceImpl) GetNotes(ctx context.Context, scheduleID string) ([]model.VisitNote, error) {
+	log.Info().Str("schedule_id", scheduleID).Msg("Fetching visit notes by schedule ID")
+
+	_, err := uuid.Parse(scheduleID)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", scheduleID).Msg("Invalid UUID format for schedule ID")
+		return nil, exceptions.ErrBadRequest.WithDetails("Invalid schedule ID format")
+	}
+
+	notes, err := s.repo.GetNotesByScheduleID(ctx, scheduleID)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to fetch visit notes from repository")
+		return nil, err
+	}
+	return notes, nil
+}
+
+// CreateNote adds a note written by the current user to a schedule
+func (s *visitNoteServiceImpl) CreateNote(ctx context.Context, req model.CreateVisitNoteRequest) (*model.VisitNote, error) {
+	log.Info().Str("schedule_id", req.ScheduleID).Str("user_id", authModel.ActorID(ctx)).Str("category", req.Category).Msg("Attempting to create visit note")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for CreateVisitNoteRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+
+	authorID := authModel.ActorID(ctx)
+	if authorID == "" {
+		return nil, exceptions.ErrUnauthorized
+	}
+
+	note := model.VisitNote{
+		ID:         uuid.NewString(),
+		ScheduleID: req.ScheduleID,
+		AuthorID:   authorID,
+		Category:   req.Category,
+		Text:       req.Text,
+	}
+	err = s.repo.CreateNote(ctx, note)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", req.ScheduleID).Msg("Failed to create visit note in repository")
+		return nil, err
+	}
+
+	return s.repo.GetNoteByID(ctx, note.ID)
+}
+
+// UpdateNote changes the category or text of a note. Only the author may edit a note,
+// and only within the edit window after it was written.
+func (s *visitNoteServiceImpl) UpdateNote(ctx context.Context, req model.UpdateVisitNoteRequest) (*model.VisitNote, error) {
+	log.Info().Str("note_id", req.ID).Str("user_id", authModel.ActorID(ctx)).Msg("Attempting to update visit note")
+
+	err := req.Validate()
+	if err != nil {
+		log.Error().Err(err).Msg("Validation failed for UpdateVisitNoteRequest")
+		return nil, exceptions.ErrBadRequest.WithDetails(err.Error())
+	}
+	if req.Category == nil && req.Text == nil {
+		return nil, exceptions.ErrBadRequest.WithDetails("Nothing to update, provide category or text")
+	}
+
+	// 1. Check if the note exists and belongs to the schedule in the URL
+	note, err := s.repo.GetNoteByID(ctx, req.ID)
+	if err != nil {
+		log.Error().Err(err).Str("note_id", req.ID).Msg("Failed to retrieve visit note before update")
+		return nil, err
+	}
+	if note.ScheduleID != req.ScheduleID {
+		return nil, exceptions.ErrNotFound.WithDetails(fmt.Sprintf("Visit note with ID %s not found", req.ID))
+	}
+
+	// 2. Apply business logic: only the author may edit, and only for a limited time
+	if note.AuthorID != authModel.ActorID(ctx) {
+		return nil, exceptions.ErrForbidden.WithDetails("Visit notes can only be edited by their author")
+	}
+	if time.Since(note.CreatedAt) > s.settings.EditWindow {
+		return nil, exceptions.ErrConflict.WithDetails(fmt.Sprintf("Visit note %s can no longer be edited, notes can only be edited for %s after they are written", req.ID, s.settings.EditWindow))
+	}
+
+	// 3. Perform the update via repository, which checks the author and the window again as it writes
+	req.AuthorID = note.AuthorID
+	req.CreatedAfter = time.Now().Add(-s.settings.EditWindow)
+	err = s.repo.UpdateNote(ctx, req)
+	if err != nil {
+		log.Error().Err(err).Str("note_id", req.ID).Msg("Failed to update visit note in repository")
+		return nil, err
+	}
+
+	return s.repo.GetNoteByID(ctx, req.ID)
+}
@@ -0,0 +1,223 @@
+package service_test
+
+import (
+	"context"
+	"mini-evv-logger-backend/exceptions"
+	authModel "mini-evv-logger-backend/src/domains/auth/model"
+	scheduleMocks "mini-evv-logger-backend/src/domains/schedule/mocks/repository"
+	scheduleModel "mini-evv-logger-backend/src/domains/schedule/model"
+	mocks "mini-evv-logger-backend/src/domains/visitnote/mocks/repository"
+	"mini-evv-logger-backend/src/domains/visitnote/model"
+	"mini-evv-logger-backend/src/domains/visitnote/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	mockNoteRepo     *mocks.MockVisitNoteRepository
+	mockScheduleRepo *scheduleMocks.MockScheduleRepository
+	ctrl             *gomock.Controller
+	svc              service.VisitNoteService
+)
+
+func initMocks(t *testing.T) {
+	ctrl = gomock.NewController(t)
+
+	mockNoteRepo = mocks.NewMockVisitNoteRepository(ctrl)
+	mockScheduleRepo = scheduleMocks.NewMockScheduleRepository(ctrl)
+
+	svc = service.NewVisitNoteService(mockNoteRepo, mockScheduleRepo, service.Settings{EditWindow: time.Hour})
+}
+
+// authorContext returns a context for the caregiver user authorID
+func authorContext(authorID string) context.Context {
+	caregiverID := uuid.NewString()
+	return authModel.WithPrincipal(context.Background(), &authModel.Principal{UserID: authorID, Role: authModel.RoleCaregiver, CaregiverID: &caregiverID})
+}
+
+func TestGetScheduleOwnership(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyScheduleID, dummyCaregiverID := uuid.NewString(), uuid.NewString()
+
+	t.Run("TestGetScheduleOwnership: OK", func(t *testing.T) {
+		mockScheduleRepo.EXPECT().GetScheduleOwnership(gomock.Any(), dummyScheduleID).Return(&scheduleModel.ScheduleOwnership{CaregiverID: &dummyCaregiverID}, nil).Times(1)
+
+		ownership, err := svc.GetScheduleOwnership(context.Background(), dummyScheduleID)
+		assert.NoError(t, err)
+		assert.Equal(t, dummyCaregiverID, *ownership.CaregiverID)
+	})
+
+	t.Run("TestGetScheduleOwnership: Invalid ID", func(t *testing.T) {
+		ownership, err := svc.GetScheduleOwnership(context.Background(), "not-a-uuid")
+		assert.NotNil(t, err)
+		assert.Nil(t, ownership)
+		assert.Equal(t, "Error 400: Bad request - Invalid schedule ID format", err.Error())
+	})
+}
+
+func TestGetNotes(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyScheduleID := uuid.NewString()
+
+	t.Run("TestGetNotes: OK", func(t *testing.T) {
+		mockNoteRepo.EXPECT().GetNotesByScheduleID(gomock.Any(), dummyScheduleID).Return([]model.VisitNote{{ID: uuid.NewString(), ScheduleID: dummyScheduleID}}, nil).Times(1)
+
+		notes, err := svc.GetNotes(context.Background(), dummyScheduleID)
+		assert.NoError(t, err)
+		assert.Len(t, notes, 1)
+	})
+
+	t.Run("TestGetNotes: Repository Error", func(t *testing.T) {
+		mockNoteRepo.EXPECT().GetNotesByScheduleID(gomock.Any(), dummyScheduleID).Return(nil, exceptions.ErrInternalError).Times(1)
+
+		notes, err := svc.GetNotes(context.Background(), dummyScheduleID)
+		assert.Equal(t, exceptions.ErrInternalError, err)
+		assert.Nil(t, notes)
+	})
+}
+
+func TestCreateNote(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyScheduleID, dummyAuthorID := uuid.NewString(), uuid.NewString()
+	req := model.CreateVisitNoteRequest{ScheduleID: dummyScheduleID, Category: model.CategoryChangeInCondition, Text: "Client reports dizziness when standing up"}
+
+	t.Run("TestCreateNote: OK", func(t *testing.T) {
+		var created model.VisitNote
+		mockNoteRepo.EXPECT().CreateNote(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, note model.VisitNote) error {
+				created = note
+				return nil
+			}).Times(1)
+		mockNoteRepo.EXPECT().GetNoteByID(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, id string) (*model.VisitNote, error) {
+				assert.Equal(t, created.ID, id)
+				return &created, nil
+			}).Times(1)
+
+		note, err := svc.CreateNote(authorContext(dummyAuthorID), req)
+		assert.NoError(t, err)
+		assert.Equal(t, dummyAuthorID, note.AuthorID)
+		assert.Equal(t, dummyScheduleID, note.ScheduleID)
+		assert.Equal(t, model.CategoryChangeInCondition, note.Category)
+	})
+
+	t.Run("TestCreateNote: Unknown Category", func(t *testing.T) {
+		badReq := req
+		badReq.Category = "gossip"
+
+		note, err := svc.CreateNote(authorContext(dummyAuthorID), badReq)
+		assert.NotNil(t, err)
+		assert.Nil(t, note)
+		assert.Equal(t, 400, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestCreateNote: Empty Text", func(t *testing.T) {
+		badReq := req
+		badReq.Text = ""
+
+		note, err := svc.CreateNote(authorContext(dummyAuthorID), badReq)
+		assert.NotNil(t, err)
+		assert.Nil(t, note)
+		assert.Equal(t, 400, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestCreateNote: No Principal", func(t *testing.T) {
+		note, err := svc.CreateNote(context.Background(), req)
+		assert.Equal(t, exceptions.ErrUnauthorized, err)
+		assert.Nil(t, note)
+	})
+}
+
+func TestUpdateNote(t *testing.T) {
+	initMocks(t)
+
+	defer ctrl.Finish()
+
+	dummyNoteID, dummyScheduleID, dummyAuthorID := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	text := "Client reports dizziness when standing up quickly"
+	req := model.UpdateVisitNoteRequest{ID: dummyNoteID, ScheduleID: dummyScheduleID, Text: &text}
+	recentNote := &model.VisitNote{ID: dummyNoteID, ScheduleID: dummyScheduleID, AuthorID: dummyAuthorID, Category: model.CategoryChangeInCondition, CreatedAt: time.Now().Add(-10 * time.Minute)}
+
+	t.Run("TestUpdateNote: OK", func(t *testing.T) {
+		mockNoteRepo.EXPECT().GetNoteByID(gomock.Any(), dummyNoteID).Return(recentNote, nil).Times(1)
+		mockNoteRepo.EXPECT().UpdateNote(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, update model.UpdateVisitNoteRequest) error {
+				assert.Equal(t, dummyAuthorID, update.AuthorID)
+				assert.WithinDuration(t, time.Now().Add(-time.Hour), update.CreatedAfter, time.Second)
+				return nil
+			}).Times(1)
+		mockNoteRepo.EXPECT().GetNoteByID(gomock.Any(), dummyNoteID).Return(&model.VisitNote{ID: dummyNoteID, Text: text}, nil).Times(1)
+
+		note, err := svc.UpdateNote(authorContext(dummyAuthorID), req)
+		assert.NoError(t, err)
+		assert.Equal(t, text, note.Text)
+	})
+
+	t.Run("TestUpdateNote: Nothing To Update", func(t *testing.T) {
+		note, err := svc.UpdateNote(authorContext(dummyAuthorID), model.UpdateVisitNoteRequest{ID: dummyNoteID, ScheduleID: dummyScheduleID})
+		assert.NotNil(t, err)
+		assert.Nil(t, note)
+		assert.Equal(t, "Error 400: Bad request - Nothing to update, provide category or text", err.Error())
+	})
+
+	t.Run("TestUpdateNote: Note Of Another Schedule", func(t *testing.T) {
+		mockNoteRepo.EXPECT().GetNoteByID(gomock.Any(), dummyNoteID).Return(recentNote, nil).Times(1)
+
+		otherReq := req
+		otherReq.ScheduleID = uuid.NewString()
+		note, err := svc.UpdateNote(authorContext(dummyAuthorID), otherReq)
+		assert.NotNil(t, err)
+		assert.Nil(t, note)
+		assert.Equal(t, "Error 404: Resource not found - Visit note with ID "+dummyNoteID+" not found", err.Error())
+	})
+
+	t.Run("TestUpdateNote: Not The Author", func(t *testing.T) {
+		mockNoteRepo.EXPECT().GetNoteByID(gomock.Any(), dummyNoteID).Return(recentNote, nil).Times(1)
+
+		note, err := svc.UpdateNote(authorContext(uuid.NewString()), req)
+		assert.NotNil(t, err)
+		assert.Nil(t, note)
+		assert.Equal(t, "Error 403: Forbidden - Visit notes can only be edited by their author", err.Error())
+	})
+
+	t.Run("TestUpdateNote: Edit Window Passed", func(t *testing.T) {
+		oldNote := *recentNote
+		oldNote.CreatedAt = time.Now().Add(-2 * time.Hour)
+		mockNoteRepo.EXPECT().GetNoteByID(gomock.Any(), dummyNoteID).Return(&oldNote, nil).Times(1)
+
+		note, err := svc.UpdateNote(authorContext(dummyAuthorID), req)
+		assert.NotNil(t, err)
+		assert.Nil(t, note)
+		assert.Equal(t, 409, err.(*exceptions.CustomError).Code)
+	})
+
+	t.Run("TestUpdateNote: Edit Window Closes While Saving", func(t *testing.T) {
+		mockNoteRepo.EXPECT().GetNoteByID(gomock.Any(), dummyNoteID).Return(recentNote, nil).Times(1)
+		mockNoteRepo.EXPECT().UpdateNote(gomock.Any(), gomock.Any()).Return(exceptions.ErrConflict).Times(1)
+
+		note, err := svc.UpdateNote(authorContext(dummyAuthorID), req)
+		assert.Equal(t, exceptions.ErrConflict, err)
+		assert.Nil(t, note)
+	})
+
+	t.Run("TestUpdateNote: Not Found", func(t *testing.T) {
+		mockNoteRepo.EXPECT().GetNoteByID(gomock.Any(), dummyNoteID).Return(nil, exceptions.ErrNotFound).Times(1)
+
+		note, err := svc.UpdateNote(authorContext(dummyAuthorID), req)
+		assert.Equal(t, exceptions.ErrNotFound, err)
+		assert.Nil(t, note)
+	})
+}
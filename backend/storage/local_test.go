@@ -0,0 +1,59 @@
+package storage_test
+
+import (
+	"context"
+	"io"
+	"mini-evv-logger-backend/storage"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStorage(t *testing.T) {
+	store, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create local storage: %v", err)
+	}
+	ctx := context.Background()
+
+	t.Run("TestLocalStorage: Put And Get", func(t *testing.T) {
+		err := store.Put(ctx, "schedules/1/a", strings.NewReader("wound photo"), 11, "image/png")
+		assert.NoError(t, err)
+
+		rc, err := store.Get(ctx, "schedules/1/a")
+		if assert.NoError(t, err) {
+			defer rc.Close()
+			content, _ := io.ReadAll(rc)
+			assert.Equal(t, "wound photo", string(content))
+		}
+	})
+
+	t.Run("TestLocalStorage: Short Upload", func(t *testing.T) {
+		err := store.Put(ctx, "schedules/1/b", strings.NewReader("short"), 100, "image/png")
+		assert.Error(t, err)
+
+		_, err = store.Get(ctx, "schedules/1/b")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("TestLocalStorage: Delete", func(t *testing.T) {
+		err := store.Put(ctx, "schedules/1/c", strings.NewReader("receipt"), 7, "application/pdf")
+		assert.NoError(t, err)
+
+		assert.NoError(t, store.Delete(ctx, "schedules/1/c"))
+		assert.NoError(t, store.Delete(ctx, "schedules/1/c")) // Deleting twice is fine
+
+		_, err = store.Get(ctx, "schedules/1/c")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("TestLocalStorage: Key Outside Root", func(t *testing.T) {
+		err := store.Put(ctx, "../escape", strings.NewReader("x"), 1, "text/plain")
+		assert.Error(t, err)
+
+		_, err = store.Get(ctx, "/etc/passwd")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, storage.ErrNotFound)
+	})
+}
@@ -0,0 +1,97 @@
+package storage_test
+
+import (
+	"context"
+	"io"
+	"mini-evv-logger-backend/storage"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeS3 is a minimal stand-in for an S3-compatible server such as MinIO, addressed path-style
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=minio/") || r.Header.Get("X-Amz-Date") == "" {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = body
+		f.types[r.URL.Path] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Storage(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := storage.NewS3Storage(storage.S3Config{Endpoint: server.URL, Region: "us-east-1", Bucket: "evv", AccessKeyID: "minio", SecretAccessKey: "minio123", ForcePathStyle: true})
+	if err != nil {
+		t.Fatalf("failed to create S3 storage: %v", err)
+	}
+	ctx := context.Background()
+
+	t.Run("TestS3Storage: Put And Get", func(t *testing.T) {
+		err := store.Put(ctx, "schedules/1/a", strings.NewReader("wound photo"), 11, "image/png")
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", fake.types["/evv/schedules/1/a"])
+
+		rc, err := store.Get(ctx, "schedules/1/a")
+		if assert.NoError(t, err) {
+			defer rc.Close()
+			content, _ := io.ReadAll(rc)
+			assert.Equal(t, "wound photo", string(content))
+		}
+	})
+
+	t.Run("TestS3Storage: Missing Object", func(t *testing.T) {
+		_, err := store.Get(ctx, "schedules/1/missing")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("TestS3Storage: Delete", func(t *testing.T) {
+		assert.NoError(t, store.Delete(ctx, "schedules/1/a"))
+
+		_, err := store.Get(ctx, "schedules/1/a")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("TestS3Storage: Access Denied", func(t *testing.T) {
+		denied, _ := storage.NewS3Storage(storage.S3Config{Endpoint: server.URL, Region: "us-east-1", Bucket: "evv", AccessKeyID: "someone-else", ForcePathStyle: true})
+
+		err := denied.Put(ctx, "schedules/1/b", strings.NewReader("x"), 1, "text/plain")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("TestS3Storage: Invalid Config", func(t *testing.T) {
+		_, err := storage.NewS3Storage(storage.S3Config{Endpoint: "localhost:9000", Region: "us-east-1", Bucket: "evv"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"context" // Import context
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when no object is stored under a key
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage keeps the contents of uploaded files. Keys are slash-separated paths chosen by the caller,
+// e.g. "schedules/<schedule ID>/<attachment ID>".
+type Storage interface {
+	// Put stores size bytes read from body under key, replacing any object already stored there
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get opens the object stored under key; the caller must close it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object stored under key; removing a missing object is not an error
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ETag formats the row version of a record as a strong entity tag, e.g. "3"
+func ETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ParseIfMatch reads the row version from an If-Match header value.
+// It returns nil when the header is empty or "*", in which case no version check is requested.
+func ParseIfMatch(value string) (*int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+	if strings.HasPrefix(value, "W/") {
+		return nil, fmt.Errorf("weak entity tag %s cannot be used with If-Match", value)
+	}
+
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		return nil, fmt.Errorf("entity tag %s must be a single quoted version, e.g. \"3\"", value)
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version < 1 {
+		return nil, fmt.Errorf("entity tag %s is not a version", value)
+	}
+	return &version, nil
+}
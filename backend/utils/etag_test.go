@@ -0,0 +1,42 @@
+package utils_test
+
+import (
+	"mini-evv-logger-backend/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETag(t *testing.T) {
+	assert.Equal(t, `"3"`, utils.ETag(3))
+}
+
+func TestParseIfMatch(t *testing.T) {
+	t.Run("TestParseIfMatch: Version", func(t *testing.T) {
+		version, err := utils.ParseIfMatch(`"3"`)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, *version)
+	})
+
+	t.Run("TestParseIfMatch: Round Trip", func(t *testing.T) {
+		version, err := utils.ParseIfMatch(utils.ETag(12))
+		assert.NoError(t, err)
+		assert.Equal(t, 12, *version)
+	})
+
+	t.Run("TestParseIfMatch: Empty And Wildcard", func(t *testing.T) {
+		for _, value := range []string{"", "*", " * "} {
+			version, err := utils.ParseIfMatch(value)
+			assert.NoError(t, err)
+			assert.Nil(t, version)
+		}
+	})
+
+	t.Run("TestParseIfMatch: Invalid", func(t *testing.T) {
+		for _, value := range []string{`W/"3"`, "3", `"abc"`, `"0"`, `"1", "2"`} {
+			version, err := utils.ParseIfMatch(value)
+			assert.Error(t, err, value)
+			assert.Nil(t, version)
+		}
+	})
+}
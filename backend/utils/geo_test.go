@@ -0,0 +1,24 @@
+package utils_test
+
+import (
+	"mini-evv-logger-backend/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHaversineMeters(t *testing.T) {
+	t.Run("TestHaversineMeters: Same Point", func(t *testing.T) {
+		assert.Equal(t, 0.0, utils.HaversineMeters(34.0522, -118.2437, 34.0522, -118.2437))
+	})
+
+	t.Run("TestHaversineMeters: Los Angeles To New York", func(t *testing.T) {
+		// Roughly 3,936 km
+		assert.InDelta(t, 3935746, utils.HaversineMeters(34.0522, -118.2437, 40.7128, -74.0060), 1000)
+	})
+
+	t.Run("TestHaversineMeters: Short Distance", func(t *testing.T) {
+		// 0.001 degrees of latitude is about 111 m
+		assert.InDelta(t, 111.2, utils.HaversineMeters(30.2672, -97.7431, 30.2682, -97.7431), 0.5)
+	})
+}